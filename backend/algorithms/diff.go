@@ -0,0 +1,350 @@
+// This file builds word level diffs of text on top of ComputeDiff and renders them for people to read
+package algorithms
+
+import (
+	"fmt"
+	"html"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Tokenise splits text into the tokens a word level diff compares, tokens are either words (runs of letters,
+// digits and underscores, apostrophes within a word are part of it), runs of whitespace (newlines are tokens of
+// their own so that lines start with a new token) or single punctuation characters. Joining the tokens gives back the original text so a diff of the tokens can be rendered exactly
+func Tokenise(text string) []string {
+	tokens := []string{}
+	for start := 0; start < len(text); {
+		first, size := utf8.DecodeRuneInString(text[start:])
+		class := tokenClass(first)
+
+		end := start + size
+		for end < len(text) && class != punctuation && first != '\n' {
+			next, size := utf8.DecodeRuneInString(text[end:])
+			if next == '\n' {
+				break
+			} else if nextClass := tokenClass(next); nextClass != class && !(class == word && isApostrophe(next) && isWordAt(text, end+size)) {
+				break
+			}
+			end += size
+		}
+
+		tokens = append(tokens, text[start:end])
+		start = end
+	}
+
+	return tokens
+}
+
+const (
+	word = iota
+	whitespace
+	punctuation
+)
+
+// tokenClass determines what kind of token a character belongs to
+func tokenClass(character rune) int {
+	switch {
+	case unicode.IsLetter(character) || unicode.IsDigit(character) || unicode.IsMark(character) || character == '_':
+		return word
+	case unicode.IsSpace(character):
+		return whitespace
+	}
+
+	return punctuation
+}
+
+func isApostrophe(character rune) bool { return character == '\'' || character == '’' }
+
+// isWordAt determines if the character at an offset within text is part of a word
+func isWordAt(text string, offset int) bool {
+	if offset >= len(text) {
+		return false
+	}
+
+	character, _ := utf8.DecodeRuneInString(text[offset:])
+	return tokenClass(character) == word
+}
+
+// Diff is the word level difference between two texts
+type Diff struct {
+	From, To []string
+	Edits    []Edit
+}
+
+// WordDiff computes the word level difference between two texts
+func WordDiff(from, to string) Diff {
+	fromTokens, toTokens := Tokenise(from), Tokenise(to)
+	return Diff{From: fromTokens, To: toTokens, Edits: ComputeDiff(fromTokens, toTokens)}
+}
+
+// IsEmpty determines if the texts are identical
+func (d Diff) IsEmpty() bool { return len(d.Edits) == 0 }
+
+type opType int
+
+const (
+	equal opType = iota
+	removed
+	added
+)
+
+// diffOp is a single token of a diff, tokens that are in both texts are equal
+type diffOp struct {
+	Type  opType
+	Token string
+}
+
+// DiffHunk is a run of changes along with the unchanged tokens surrounding it, starts are token indexes and lines
+// are 1-indexed line numbers
+type DiffHunk struct {
+	FromStart, FromLength, FromLine, FromLines int
+	ToStart, ToLength, ToLine, ToLines         int
+
+	ops []diffOp
+}
+
+// ops expands the edit script of a diff into every token of both texts in order, within each run of changes the
+// removed tokens come before the added ones
+func (d Diff) ops() []diffOp {
+	ops := make([]diffOp, 0, len(d.From)+len(d.Edits))
+	pending := []diffOp{}
+	edit := 0
+	for x := 0; x <= len(d.From); x++ {
+		isRemoved := false
+		for ; edit < len(d.Edits) && d.Edits[edit].Index == x; edit++ {
+			if d.Edits[edit].Type == Add {
+				pending = append(pending, diffOp{added, d.Edits[edit].Val})
+			} else {
+				ops = append(ops, diffOp{removed, d.Edits[edit].Val})
+				isRemoved = true
+			}
+		}
+
+		if isRemoved {
+			continue
+		}
+
+		// the run of changes is over
+		ops = append(ops, pending...)
+		pending = pending[:0]
+		if x < len(d.From) {
+			ops = append(ops, diffOp{equal, d.From[x]})
+		}
+	}
+
+	return ops
+}
+
+// Hunks groups the changes of a diff into hunks, each hunk covers the lines containing its changes along with (up
+// to) context unchanged lines on either side, hunks that would overlap are merged
+func (d Diff) Hunks(context int) []DiffHunk {
+	ops := d.ops()
+
+	// the ranges of ops within each hunk
+	type span struct{ start, end int }
+	spans := []span{}
+	for i := 0; i < len(ops); i++ {
+		if ops[i].Type == equal {
+			continue
+		}
+
+		end := i
+		for end < len(ops) && ops[end].Type != equal {
+			end++
+		}
+
+		current := span{lineStart(ops, i, context), lineEnd(ops, end, context)}
+		if last := len(spans) - 1; last >= 0 && current.start <= spans[last].end {
+			spans[last].end = current.end
+		} else {
+			spans = append(spans, current)
+		}
+
+		i = end
+	}
+
+	hunks := []DiffHunk{}
+	fromIndex, toIndex, fromLine, toLine := 0, 0, 1, 1
+	next := 0
+	for i, op := range ops {
+		if next < len(spans) && spans[next].start == i {
+			hunk := DiffHunk{FromStart: fromIndex, ToStart: toIndex, FromLine: fromLine, ToLine: toLine, ops: ops[i:spans[next].end]}
+			hunk.measure()
+			hunks = append(hunks, hunk)
+			next++
+		}
+
+		lines := strings.Count(op.Token, "\n")
+		if op.Type != added {
+			fromIndex++
+			fromLine += lines
+		}
+		if op.Type != removed {
+			toIndex++
+			toLine += lines
+		}
+	}
+
+	return hunks
+}
+
+// lineStart finds the start of the line context lines before the op at index
+func lineStart(ops []diffOp, index, context int) int {
+	lines := 0
+	for i := index - 1; i >= 0; i-- {
+		if ops[i].Type == equal {
+			if lines += strings.Count(ops[i].Token, "\n"); lines > context {
+				return i + 1
+			}
+		}
+	}
+
+	return 0
+}
+
+// lineEnd finds the end of the line context lines after the op at index (excluding the newline ending it)
+func lineEnd(ops []diffOp, index, context int) int {
+	lines := 0
+	for i := index; i < len(ops); i++ {
+		if ops[i].Type == equal {
+			if lines += strings.Count(ops[i].Token, "\n"); lines > context {
+				return i
+			}
+		}
+	}
+
+	return len(ops)
+}
+
+// measure computes the length of a hunk in each text
+func (h *DiffHunk) measure() {
+	h.FromLines, h.ToLines = 1, 1
+	for _, op := range h.ops {
+		lines := strings.Count(op.Token, "\n")
+		if op.Type != added {
+			h.FromLength++
+			h.FromLines += lines
+		}
+		if op.Type != removed {
+			h.ToLength++
+			h.ToLines += lines
+		}
+	}
+}
+
+// header is the unified diff style header of a hunk
+func (h DiffHunk) header() string {
+	return fmt.Sprintf("@@ -%d,%d +%d,%d @@", h.FromLine, h.FromLines, h.ToLine, h.ToLines)
+}
+
+// Unified renders a diff in the style of a unified diff with the changes shown inline (like git's word diff):
+// removed words are wrapped in [-...-] and added words in {+...+}, fromName and toName label the two texts
+func (d Diff) Unified(fromName, toName string, context int) string {
+	rendered := strings.Builder{}
+	fmt.Fprintf(&rendered, "--- %s\n+++ %s\n", fromName, toName)
+
+	for _, hunk := range d.Hunks(context) {
+		rendered.WriteString(hunk.header())
+		rendered.WriteByte('\n')
+
+		for i, op := range hunk.ops {
+			isFirst := i == 0 || hunk.ops[i-1].Type != op.Type
+			isLast := i == len(hunk.ops)-1 || hunk.ops[i+1].Type != op.Type
+
+			if isFirst && op.Type == removed {
+				rendered.WriteString("[-")
+			} else if isFirst && op.Type == added {
+				rendered.WriteString("{+")
+			}
+
+			rendered.WriteString(op.Token)
+
+			if isLast && op.Type == removed {
+				rendered.WriteString("-]")
+			} else if isLast && op.Type == added {
+				rendered.WriteString("+}")
+			}
+		}
+
+		rendered.WriteByte('\n')
+	}
+
+	return rendered.String()
+}
+
+// SideBySide renders a diff as an HTML table with the original text on the left and the new text on the right,
+// each hunk is a set of rows (one per line) with removed words wrapped in <del> and added words in <ins>. The table
+// isn't styled, the classes diff, diff-hunk, diff-line, diff-from and diff-to are there to hang styles off
+func (d Diff) SideBySide(fromName, toName string, context int) string {
+	rendered := strings.Builder{}
+	rendered.WriteString(`<table class="diff">`)
+	fmt.Fprintf(&rendered, `<thead><tr><th colspan="2">%s</th><th colspan="2">%s</th></tr></thead><tbody>`,
+		html.EscapeString(fromName), html.EscapeString(toName))
+
+	for _, hunk := range d.Hunks(context) {
+		fmt.Fprintf(&rendered, `<tr class="diff-hunk"><td colspan="4">%s</td></tr>`, html.EscapeString(hunk.header()))
+
+		fromLines := renderLines(hunk.ops, removed, "del")
+		toLines := renderLines(hunk.ops, added, "ins")
+		for i := 0; i < len(fromLines) || i < len(toLines); i++ {
+			rendered.WriteString("<tr>")
+			writeCell(&rendered, "diff-from", hunk.FromLine+i, fromLines, i)
+			writeCell(&rendered, "diff-to", hunk.ToLine+i, toLines, i)
+			rendered.WriteString("</tr>")
+		}
+	}
+
+	rendered.WriteString("</tbody></table>")
+	return rendered.String()
+}
+
+// renderLines renders one side of a hunk as HTML lines, the ops of the other side are skipped and each run of
+// changes is wrapped in the given tag
+func renderLines(ops []diffOp, changed opType, tag string) []string {
+	lines := []string{}
+	line := strings.Builder{}
+	for i := 0; i < len(ops); i++ {
+		if ops[i].Type != equal && ops[i].Type != changed {
+			continue
+		}
+
+		// consecutive ops of the same type are rendered together
+		run := strings.Builder{}
+		runType := ops[i].Type
+		for ; i < len(ops) && (ops[i].Type == runType || (ops[i].Type != equal && ops[i].Type != changed)); i++ {
+			if ops[i].Type == runType {
+				run.WriteString(ops[i].Token)
+			}
+		}
+		i--
+
+		// tags can't span rows so changes containing newlines are closed at the end of each line
+		for j, segment := range strings.Split(run.String(), "\n") {
+			if j > 0 {
+				lines = append(lines, line.String())
+				line.Reset()
+			}
+
+			if segment == "" {
+				continue
+			} else if runType == changed {
+				fmt.Fprintf(&line, "<%s>%s</%s>", tag, html.EscapeString(segment), tag)
+			} else {
+				line.WriteString(html.EscapeString(segment))
+			}
+		}
+	}
+
+	return append(lines, line.String())
+}
+
+// writeCell writes the line number and contents of a line of a hunk, sides that have run out of lines are blank
+func writeCell(rendered *strings.Builder, class string, number int, lines []string, index int) {
+	if index >= len(lines) {
+		fmt.Fprintf(rendered, `<td class="diff-line"></td><td class="%s"></td>`, class)
+		return
+	}
+
+	fmt.Fprintf(rendered, `<td class="diff-line">%d</td><td class="%s">%s</td>`, number, class, lines[index])
+}
@@ -45,6 +45,9 @@ func fastReverse(arr []string) []string {
 // sentences represented as an array of words
 func CommonPrefix(a, b []string) int {
 	bound := min(len(a), len(b))
+	if bound == 0 {
+		return -1
+	}
 
 	// allow compiler to eliminate
 	// bounds check
@@ -126,13 +129,12 @@ func CommonSuffix(a, b []string) int {
 	return CommonPrefix(reversedA, reversedB)
 }
 
-// ComputeDiff returns an edit-script of the difference
-// between two word arrays, implementation is just a BFS
-// variant of the greedy strategy presented in the paper
-// to sumarise the paper here the general idea is that the differences
-// between two strings can be modelled as a graph, for example consider
-// the strings abc and adc; if the original string abc is aligned vertically
-// and the new string adc horizontally then the graph looks like:
+// ComputeDiff returns an edit-script of the difference between two word arrays, the indexes of the edits are
+// indexes into a: additions are inserted before a[Index] and within each run of changes the additions come before
+// the removals. The common prefix and suffix of the arrays are trimmed before the remainder is diffed with the
+// linear space variant of Myers' algorithm (see the README), the general idea is that the differences between two
+// strings can be modelled as a graph, for example consider the strings abc and adc; if the original string abc is
+// aligned horizontally and the new string adc vertically then the graph looks like:
 // where a dot represents a vertex
 // [x] a    b    c
 // a   . -> . -> .
@@ -141,89 +143,148 @@ func CommonSuffix(a, b []string) int {
 //     |    |   |
 // c   . -> . -> .
 //
-// movement along a hoizontal edge represents deleting a charachter from the origional
-// string and movment along the vertical edge represents inserting a char from the new string
-// there are also zero cost edges (not pictured) that represent match points (where the two strings)
-// match, since these are zero cost edges we can construct an auxillary unweighted graph from this representation
-// and traverse it using BFS, we are guaranteed that our BFS traversal will result in a shortest path :)
-type coord struct {
-	x, y int
-}
-
+// movement along a horizontal edge represents deleting a character from the original string and movement along
+// a vertical edge represents inserting a character from the new string, there are also zero cost diagonal edges
+// (not pictured) that represent match points (where the two strings match). The shortest path through the graph is
+// the shortest edit script, rather than remembering every vertex it visits the linear space variant searches from
+// both corners at once until the searches meet at a "middle snake" and then recursively solves the two halves on
+// either side of it
 func ComputeDiff(a, b []string) []Edit {
-	// utility function for cleanly extending a path
-	var extendPath = func(v coord) coord {
-		for v.x < len(a) && v.y < len(b) && a[v.x] == b[v.y] {
-			v.x++
-			v.y++
-		}
-		return v
+	prefix := 0
+	if len(a) > 0 && len(b) > 0 {
+		prefix = CommonPrefixConcurrent(a, b) + 1
 	}
 
-	max := len(a) + len(b)
-	initCoord := extendPath(coord{0, 0})
+	suffix := 0
+	if len(a) > prefix && len(b) > prefix {
+		suffix = CommonSuffix(a[prefix:], b[prefix:]) + 1
+	}
+
+	d := differ{a: a, b: b, removed: make([]bool, len(a)), added: make([]bool, len(b))}
+	d.compare(prefix, len(a)-suffix, prefix, len(b)-suffix)
+	return d.editScript()
+}
+
+// differ holds the state of a single diff, removed and added mark the elements of a and b that aren't part of the
+// longest common subsequence
+type differ struct {
+	a, b           []string
+	removed, added []bool
 
-	predMatrix := make(map[coord]coord)
-	predMatrix[initCoord] = coord{0, 0}
-	frontier := []coord{initCoord}
+	// forward and backward are the furthest reaching x coordinates of each diagonal, they're reused between
+	// searches so the diff only ever allocates space linear in the size of its input
+	forward, backward []int
+}
+
+// compare marks the differences between a[aLow:aHigh] and b[bLow:bHigh]
+func (d *differ) compare(aLow, aHigh, bLow, bHigh int) {
+	// trim the common prefix and suffix of the sub-problem
+	for aLow < aHigh && bLow < bHigh && d.a[aLow] == d.b[bLow] {
+		aLow++
+		bLow++
+	}
+
+	for aLow < aHigh && bLow < bHigh && d.a[aHigh-1] == d.b[bHigh-1] {
+		aHigh--
+		bHigh--
+	}
 
-	// another utility function for marking a vertex
-	// as visited
-	var markAsVisited = func(v, pred coord) {
-		if _, ok := predMatrix[v]; !ok {
-			predMatrix[v] = pred
+	switch {
+	case aLow == aHigh:
+		for y := bLow; y < bHigh; y++ {
+			d.added[y] = true
+		}
+	case bLow == bHigh:
+		for x := aLow; x < aHigh; x++ {
+			d.removed[x] = true
 		}
+	default:
+		// since the ends of the sub-problem differ it has at least 2 differences and both halves are smaller
+		startX, startY, endX, endY := d.middleSnake(aLow, aHigh, bLow, bHigh)
+		d.compare(aLow, startX, bLow, startY)
+		d.compare(endX, aHigh, endY, bHigh)
 	}
+}
 
-	// iterate over all possible distances
-	for d := 0; d < max; d++ {
-		nextFrontier := []coord{}
+// middleSnake finds the middle snake of the shortest edit script between a[aLow:aHigh] and b[bLow:bHigh], the
+// snake starts at (startX, startY) and ends at (endX, endY)
+func (d *differ) middleSnake(aLow, aHigh, bLow, bHigh int) (startX, startY, endX, endY int) {
+	n, m := aHigh-aLow, bHigh-bLow
+	delta := n - m
+	isOdd := delta%2 != 0
+
+	// diagonal k = x - y is stored at index k + offset, the backward search is performed on the reversed
+	// strings where the diagonal k of the forward search is the diagonal delta - k
+	offset := n + m + 1
+	if len(d.forward) < 2*offset+1 {
+		d.forward, d.backward = make([]int, 2*offset+1), make([]int, 2*offset+1)
+	}
+	forward, backward := d.forward, d.backward
+	forward[offset+1], backward[offset+1] = 0, 0
+
+	for depth := 0; depth <= (n+m+1)/2; depth++ {
+		for k := -depth; k <= depth; k += 2 {
+			x := forward[offset+k-1] + 1
+			if k == -depth || (k != depth && forward[offset+k-1] < forward[offset+k+1]) {
+				x = forward[offset+k+1]
+			}
 
-		for _, v := range frontier {
-			if v.x >= len(a) && v.y >= len(b) {
-				return computeEditScript(coord{0, 0}, coord{len(a), len(b)}, predMatrix, a, b)
+			y := x - k
+			snakeX, snakeY := x, y
+			for x < n && y < m && d.a[aLow+x] == d.b[bLow+y] {
+				x++
+				y++
 			}
+			forward[offset+k] = x
 
-			addFromB := extendPath(coord{v.x, v.y + 1})
-			delFromA := extendPath(coord{v.x + 1, v.y})
+			// the searches have met if the furthest reaching paths on this diagonal overlap
+			if reversed := delta - k; isOdd && reversed >= -(depth-1) && reversed <= depth-1 && x+backward[offset+reversed] >= n {
+				return aLow + snakeX, bLow + snakeY, aLow + x, bLow + y
+			}
+		}
 
-			markAsVisited(addFromB, v)
-			markAsVisited(delFromA, v)
+		for k := -depth; k <= depth; k += 2 {
+			x := backward[offset+k-1] + 1
+			if k == -depth || (k != depth && backward[offset+k-1] < backward[offset+k+1]) {
+				x = backward[offset+k+1]
+			}
 
-			nextFrontier = append(nextFrontier, addFromB, delFromA)
-		}
+			y := x - k
+			snakeX, snakeY := x, y
+			for x < n && y < m && d.a[aHigh-1-x] == d.b[bHigh-1-y] {
+				x++
+				y++
+			}
+			backward[offset+k] = x
 
-		frontier = nextFrontier
+			if reversed := delta - k; !isOdd && reversed >= -depth && reversed <= depth && x+forward[offset+reversed] >= n {
+				return aHigh - x, bHigh - y, aHigh - snakeX, bHigh - snakeY
+			}
+		}
 	}
 
-	return []Edit{}
+	// unreachable, the searches always meet by the time they've covered every difference
+	panic("algorithms: the forward and backward searches of the diff never met")
 }
 
-// computeEditScript takes a predecessor matrix
-// and computes a final edit script from the matrix
-func computeEditScript(s, t coord, predMatrix map[coord]coord, a, b []string) []Edit {
-	if s == t {
-		return []Edit{}
-	}
-
-	v := t
-	n := predMatrix[t]
-	editScript := computeEditScript(s, n, predMatrix, a, b)
+// editScript converts the marked differences into an edit script
+func (d *differ) editScript() []Edit {
+	editScript := []Edit{}
+	x, y := 0, 0
+	for x < len(d.a) || y < len(d.b) {
+		if x < len(d.a) && y < len(d.b) && !d.removed[x] && !d.added[y] {
+			x++
+			y++
+			continue
+		}
 
-	// modify to so that it moves off a 0 cost edge
-	// and rewinds to start
-	for v.x > n.x && v.y > n.y {
-		v.x--
-		v.y--
-	}
+		for start := x; y < len(d.b) && d.added[y]; y++ {
+			editScript = append(editScript, Edit{start, d.b[y], Add})
+		}
 
-	// actually compute the edit now
-	if v.x-v.y == n.x-n.y {
-		return editScript
-	} else if n.y < v.y {
-		editScript = append(editScript, Edit{n.x, b[n.y], Add})
-	} else {
-		editScript = append(editScript, Edit{n.x, a[n.x], Remove})
+		for ; x < len(d.a) && d.removed[x]; x++ {
+			editScript = append(editScript, Edit{x, d.a[x], Remove})
+		}
 	}
 
 	return editScript
@@ -0,0 +1,77 @@
+package algorithms
+
+import (
+	"strings"
+	"testing"
+
+	"cms.csesoc.unsw.edu.au/algorithms"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	publishedText   = "The quick brown fox\njumps over the lazy dog.\nIt was a <good> day.\n\nThe end"
+	unpublishedText = "The quick red fox\njumps over the lazy dog.\nIt was a <good> day.\n\nThe very end"
+)
+
+func TestTokenise(t *testing.T) {
+	assert := assert.New(t)
+
+	text := "Don't panic, it's 3.14  ok\n\n  indented"
+	assert.Equal([]string{
+		"Don't", " ", "panic", ",", " ", "it's", " ", "3", ".", "14", "  ", "ok", "\n", "\n", "  ", "indented",
+	}, algorithms.Tokenise(text))
+	assert.Equal(text, strings.Join(algorithms.Tokenise(text), ""))
+	assert.Equal([]string{}, algorithms.Tokenise(""))
+}
+
+func TestDiffIsMinimal(t *testing.T) {
+	assert := assert.New(t)
+
+	// the diff of two long arrays with a handful of changes only contains the changes
+	a := make([]string, 20000)
+	for i := range a {
+		a[i] = randomWord(4)
+	}
+
+	b := append([]string{}, a...)
+	b[100], b[15000] = "changed", "changed"
+	b = append(b[:5000], append([]string{"inserted"}, b[5000:]...)...)
+
+	assert.Equal(5, len(algorithms.ComputeDiff(a, b)))
+	assert.Equal([]algorithms.Edit{
+		{Index: 1, Val: "b", Type: algorithms.Remove}, {Index: 4, Val: "d", Type: algorithms.Add},
+	}, algorithms.ComputeDiff(strings.Fields("a b c a b"), strings.Fields("a c a d b")))
+}
+
+func TestUnifiedDiff(t *testing.T) {
+	diff := algorithms.WordDiff(publishedText, unpublishedText)
+
+	assert.Equal(t, strings.Join([]string{
+		"--- published",
+		"+++ unpublished",
+		"@@ -1,1 +1,1 @@",
+		"The quick [-brown-]{+red+} fox",
+		"@@ -5,1 +5,1 @@",
+		"The{+ very+} end",
+		"",
+	}, "\n"), diff.Unified("published", "unpublished", 0))
+
+	// with enough context the hunks are merged
+	assert.Equal(t, 1, len(diff.Hunks(3)))
+	assert.Equal(t, "--- a\n+++ b\n", algorithms.WordDiff(publishedText, publishedText).Unified("a", "b", 3))
+}
+
+func TestSideBySideDiff(t *testing.T) {
+	diff := algorithms.WordDiff(publishedText, unpublishedText)
+
+	assert.Equal(t, `<table class="diff"><thead><tr><th colspan="2">published</th><th colspan="2">unpublished</th></tr></thead><tbody>`+
+		`<tr class="diff-hunk"><td colspan="4">@@ -1,2 +1,2 @@</td></tr>`+
+		`<tr><td class="diff-line">1</td><td class="diff-from">The quick <del>brown</del> fox</td>`+
+		`<td class="diff-line">1</td><td class="diff-to">The quick <ins>red</ins> fox</td></tr>`+
+		`<tr><td class="diff-line">2</td><td class="diff-from">jumps over the lazy dog.</td>`+
+		`<td class="diff-line">2</td><td class="diff-to">jumps over the lazy dog.</td></tr>`+
+		`<tr class="diff-hunk"><td colspan="4">@@ -4,2 +4,2 @@</td></tr>`+
+		`<tr><td class="diff-line">4</td><td class="diff-from"></td><td class="diff-line">4</td><td class="diff-to"></td></tr>`+
+		`<tr><td class="diff-line">5</td><td class="diff-from">The end</td><td class="diff-line">5</td><td class="diff-to">The<ins> very</ins> end</td></tr>`+
+		`</tbody></table>`, diff.SideBySide("published", "unpublished", 1))
+}
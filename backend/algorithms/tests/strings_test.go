@@ -132,8 +132,10 @@ func TestStringDifference(t *testing.T) {
 		expected []algorithms.Edit
 	}{
 		{"hello world", "hello world", []algorithms.Edit{}},
-		{"Hey Don't Borgir Write yourself off", "Hey dont Borgir Write yourself off", []algorithms.Edit{{1, "dont", algorithms.Add}, {1, "Don't", algorithms.Remove}}},
-		{"Hello there Jacob", "Hello there", []algorithms.Edit{{2, "Jacob", algorithms.Remove}}},
+		{"Hey Don't Borgir Write yourself off", "Hey dont Borgir Write yourself off", []algorithms.Edit{{Index: 1, Val: "dont", Type: algorithms.Add}, {Index: 1, Val: "Don't", Type: algorithms.Remove}}},
+		{"Hello there Jacob", "Hello there", []algorithms.Edit{{Index: 2, Val: "Jacob", Type: algorithms.Remove}}},
+		{"Hello there", "", []algorithms.Edit{{Index: 0, Val: "Hello", Type: algorithms.Remove}, {Index: 1, Val: "there", Type: algorithms.Remove}}},
+		{"", "General Kenobi", []algorithms.Edit{{Index: 0, Val: "General", Type: algorithms.Add}, {Index: 0, Val: "Kenobi", Type: algorithms.Add}}},
 	}
 	assert := assert.New(t)
 
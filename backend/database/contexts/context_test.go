@@ -1,6 +1,8 @@
 package contexts
 
 import (
+	"context"
+	"errors"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -19,7 +21,7 @@ func TestCannotUseLiveContext(t *testing.T) {
 
 	var garbage int = 0
 	assert.Panics(func() {
-		liveContext.Query("SELECT EntityID FROM filesystem WHERE Parent = NULL", []interface{}{}, &garbage)
+		liveContext.Query(context.Background(), "SELECT EntityID FROM filesystem WHERE Parent = NULL", []interface{}{}, &garbage)
 	}, "do not query a live context db from a test!")
 }
 
@@ -32,13 +34,13 @@ func TestCannotUseTestingContext(t *testing.T) {
 	defer testContext.Close()
 
 	assert.Panics(func() {
-		testContext.Exec("DROP TABLE filesystem", []interface{}{})
+		testContext.Exec(context.Background(), "DROP TABLE filesystem", []interface{}{})
 	})
 
 	// I'm so sorry about this
 	assert.NotPanics(func() {
 		testContext.RunTest(func() {
-			testContext.Exec("DROP TABLE IF EXISTS person", []interface{}{})
+			testContext.Exec(context.Background(), "DROP TABLE IF EXISTS person", []interface{}{})
 		})
 	})
 }
@@ -53,7 +55,7 @@ func TestUpdatesAreIsolated(t *testing.T) {
 
 	// create a table in a test env
 	testContext.RunTest(func() {
-		err := testContext.Exec(`CREATE TABLE test_table(joe SERIAL PRIMARY KEY)`, []interface{}{})
+		err := testContext.Exec(context.Background(), `CREATE TABLE test_table(joe SERIAL PRIMARY KEY)`, []interface{}{})
 		if assert.Nil(err) {
 			assert.True(tableExists("test_table", testContext))
 		}
@@ -68,7 +70,7 @@ func TestUpdatesAreIsolated(t *testing.T) {
 // util function to querying the existence of a table
 func tableExists(tableName string, ctx *TestingContext) bool {
 	var existence bool
-	if err := ctx.Query(`SELECT EXISTS (
+	if err := ctx.Query(context.Background(), `SELECT EXISTS (
 			SELECT * FROM information_schema.tables
 			WHERE table_schema = 'public' AND table_name = $1
 		)`, []interface{}{tableName}, &existence); err == nil {
@@ -77,3 +79,46 @@ func tableExists(tableName string, ctx *TestingContext) bool {
 	}
 	return false
 }
+
+// units of work should be committed if they succeed and rolled back if they fail
+func TestUnitsOfWork(t *testing.T) {
+	assert := assert.New(t)
+
+	testContext := newTestingContext()
+	defer testContext.Close()
+
+	testContext.RunTest(func() {
+		err := testContext.WithTx(context.Background(), func(tx DatabaseContext) error {
+			return tx.Exec(context.Background(), `CREATE TABLE committed_table(joe SERIAL PRIMARY KEY)`, []interface{}{})
+		})
+		assert.Nil(err)
+		assert.True(tableExists("committed_table", testContext))
+
+		err = testContext.WithTx(context.Background(), func(tx DatabaseContext) error {
+			if err := tx.Exec(context.Background(), `CREATE TABLE rolled_back_table(joe SERIAL PRIMARY KEY)`, []interface{}{}); err != nil {
+				return err
+			}
+
+			return errors.New("something went wrong")
+		})
+		assert.NotNil(err)
+		assert.False(tableExists("rolled_back_table", testContext))
+	})
+}
+
+// cancelling a context should stop its queries
+func TestCancelledQueries(t *testing.T) {
+	assert := assert.New(t)
+
+	testContext := newTestingContext()
+	defer testContext.Close()
+
+	testContext.RunTest(func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		assert.True(testContext.WillFail(func() error {
+			return testContext.Exec(ctx, "SELECT pg_sleep(1)", []interface{}{})
+		}))
+	})
+}
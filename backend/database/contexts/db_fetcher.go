@@ -15,10 +15,13 @@ import (
 	"log"
 	"time"
 
+	"cms.csesoc.unsw.edu.au/database/migrations"
 	lls "github.com/emirpasic/gods/stacks/linkedliststack"
 	"github.com/jackc/pgx/v4/pgxpool"
 	"github.com/ory/dockertest"
 	"github.com/ory/dockertest/docker"
+
+	_ "github.com/lib/pq"
 )
 
 // File contains methods and global variables related to fetching
@@ -64,35 +67,44 @@ func getTestDatabase() *pgxpool.Pool {
 }
 
 // tryConnect attempts to connect to a testingDatabase given its host string, if
-// it fails to connect to the database it just throws an error; otherwise it throws
-// back a connection
+// it fails to connect to the database it just throws an error; otherwise it migrates
+// the database to the latest schema and throws back a connection
 func tryConnect(host string) (*pgxpool.Pool, error) {
 	conn, err := pgxpool.Connect(context.Background(),
 		fmt.Sprintf("postgres://%s:%s@%s/%s", TEST_USER, TEST_PASSWORD, host, TESTING_DB_NAME))
-	if err == nil {
-		// looks like we could connect
-		return conn, nil
+	if err != nil {
+		return nil, err
+	}
+
+	// looks like we could connect
+	if err := migrateTestDatabase(conn); err != nil {
+		conn.Close()
+		return nil, err
 	}
-	return nil, err
+
+	return conn, nil
+}
+
+// migrateTestDatabase applies any pending migrations to a testing database
+func migrateTestDatabase(conn *pgxpool.Pool) error {
+	scripts, err := migrations.GetMigrations()
+	if err != nil {
+		return err
+	}
+
+	_, err = migrations.NewRunner(conn, scripts).Up(context.Background())
+	return err
 }
 
 // createNewTestDB creates a new instance of the testing database and returns the host details
-// the schema underlying the test database is read directly from the postgres create_tables.sql file
+// the schema underlying the test database is created by the migrations once we connect to it
 // the testing database is spun up as a docker container with a 3 minute expiry due to inactivity
 func createNewTestDB() string {
 	pool, resource := createDatabaseContainer()
 
 	hostAndPort := resource.GetHostPort("5432/tcp")
 	databaseURL := fmt.Sprintf("postgres://%s:%s@%s/%s", TEST_USER, TEST_PASSWORD, hostAndPort, TESTING_DB_NAME)
-	db := verifyConnection(pool, databaseURL)
-
-	startupScript, err := importSchema()
-	if err != nil {
-		panic(err)
-	}
-
-	db.Query(startupScript)
-	db.Close()
+	verifyConnection(pool, databaseURL)
 
 	return hostAndPort
 }
@@ -132,18 +144,15 @@ func createDatabaseContainer() (*dockertest.Pool, *dockertest.Resource) {
 
 // verifyConnection attempts to check that database conneciton
 // was actually established using an exponential backoff procedure
-// if we manage to connect it returns the db connection
-func verifyConnection(pool *dockertest.Pool, databaseURL string) *sql.DB {
-	var db *sql.DB
-
+func verifyConnection(pool *dockertest.Pool, databaseURL string) {
 	if err := pool.Retry(func() error {
 		db, err := sql.Open("postgres", databaseURL)
 		if err != nil {
 			return err
 		}
+		defer db.Close()
 		return db.Ping()
 	}); err != nil {
 		log.Fatalf("Could not connect to docker: %s", err)
 	}
-	return db
 }
@@ -27,8 +27,7 @@ type liveContext struct {
 
 // NewPool returns a new pool from a given configuration
 func newLiveContext() (*liveContext, error) {
-	conn, err := pgxpool.Connect(context.Background(),
-		fmt.Sprintf("postgres://%s:%s@%s/%s", USER, PASSWORD, HOST, DATABASE))
+	conn, err := pgxpool.Connect(context.Background(), GetConnectionString())
 
 	log.Print("== Acquired live DB context == ")
 	if err != nil {
@@ -40,21 +39,30 @@ func newLiveContext() (*liveContext, error) {
 	}, nil
 }
 
+// GetConnectionString returns the connection string for the live database
+func GetConnectionString() string {
+	return fmt.Sprintf("postgres://%s:%s@%s/%s", USER, PASSWORD, HOST, DATABASE)
+}
+
 // Regular DatabaseContext methods
-func (ctx *liveContext) Query(query string, sqlArgs []interface{}, resultOutput ...interface{}) error {
+func (ctx *liveContext) Query(reqCtx context.Context, query string, sqlArgs []interface{}, resultOutput ...interface{}) error {
+	ctx.verifyEnvironment()
+	return queryScan(reqCtx, ctx.conn, query, sqlArgs, resultOutput...)
+}
+
+func (ctx *liveContext) QueryRow(reqCtx context.Context, query string, sqlArgs []interface{}) (pgx.Rows, error) {
 	ctx.verifyEnvironment()
-	return ctx.conn.QueryRow(context.Background(), query, sqlArgs...).Scan(resultOutput...)
+	return queryRows(reqCtx, ctx.conn, query, sqlArgs)
 }
 
-func (ctx *liveContext) QueryRow(query string, sqlArgs []interface{}) (pgx.Rows, error) {
+func (ctx *liveContext) Exec(reqCtx context.Context, query string, sqlArgs []interface{}) error {
 	ctx.verifyEnvironment()
-	return ctx.conn.Query(context.Background(), query, sqlArgs...)
+	return exec(reqCtx, ctx.conn, query, sqlArgs)
 }
 
-func (ctx *liveContext) Exec(query string, sqlArgs []interface{}) error {
+func (ctx *liveContext) WithTx(reqCtx context.Context, fn func(tx DatabaseContext) error) error {
 	ctx.verifyEnvironment()
-	_, err := ctx.conn.Exec(context.Background(), query, sqlArgs...)
-	return err
+	return withTx(reqCtx, ctx.conn, fn)
 }
 
 func (context *liveContext) Close() {
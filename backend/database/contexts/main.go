@@ -1,7 +1,9 @@
 package contexts
 
 import (
+	"context"
 	"log"
+	"sync"
 
 	"cms.csesoc.unsw.edu.au/environment"
 	"github.com/jackc/pgx/v4"
@@ -19,26 +21,45 @@ const TESTING_DB_NAME = "cms_testing_db"
 
 const TEST_DB_EXPIRY_TIME = 180
 
-// DatabaseContext exposes 3 methods to the user, by using an interface
+// QueryTimeout is the longest any single query may run for, queries are also cancelled
+// as soon as the context they were issued with is (eg. when a client disconnects)
+var QueryTimeout = environment.GetDBQueryTimeout()
+
+// DatabaseContext exposes methods for querying the database, by using an interface
 // it allows us to easilly swap what context (and consequently database) a method is actually using.
 // Any connection to the database implements the database context interface
 type DatabaseContext interface {
-	Query(query string, sqlArgs []interface{}, resultOutput ...interface{}) error
-	QueryRow(query string, sqlArgs []interface{}) (pgx.Rows, error)
-	Exec(query string, sqlArgs []interface{}) error
+	Query(ctx context.Context, query string, sqlArgs []interface{}, resultOutput ...interface{}) error
+	QueryRow(ctx context.Context, query string, sqlArgs []interface{}) (pgx.Rows, error)
+	Exec(ctx context.Context, query string, sqlArgs []interface{}) error
+
+	// WithTx runs fn as a single unit of work, every query made through the provided context happens within
+	// one transaction that is committed if fn succeeds and rolled back if it returns an error. Calling
+	// WithTx on a transaction's context creates a nested transaction (savepoint)
+	WithTx(ctx context.Context, fn func(tx DatabaseContext) error) error
 	Close()
 }
 
-// returns a new database context based on the current environment
+// the live context wraps a connection pool so it is shared by everything that needs it
+var (
+	sharedLiveContext     *liveContext
+	sharedLiveContextOnce sync.Once
+)
+
+// returns a database context based on the current environment
 func GetDatabaseContext() DatabaseContext {
 	if environment.IsTestingEnvironment() {
 		return newTestingContext()
 	}
 
-	context, err := newLiveContext()
-	if err != nil {
-		log.Fatalf("failed to fetch database context: %v", err)
-	}
+	sharedLiveContextOnce.Do(func() {
+		context, err := newLiveContext()
+		if err != nil {
+			log.Fatalf("failed to fetch database context: %v", err)
+		}
+
+		sharedLiveContext = context
+	})
 
-	return context
+	return sharedLiveContext
 }
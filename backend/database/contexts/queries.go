@@ -0,0 +1,112 @@
+// TITLE: Queries
+// # # #
+/*
+	File contains the query implementations shared by all the database contexts, every query
+	is bounded by the QueryTimeout and transactions are implemented on top of pgx's transactions
+**/
+package contexts
+
+import (
+	"context"
+
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+)
+
+// querier is the subset of methods shared by pgx's connection pools and transactions
+type querier interface {
+	Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row
+	Exec(ctx context.Context, sql string, args ...interface{}) (pgconn.CommandTag, error)
+	Begin(ctx context.Context) (pgx.Tx, error)
+}
+
+// @implements DatabaseContext
+// txContext is the context handed to units of work, all its queries run within a single transaction
+type txContext struct {
+	tx pgx.Tx
+}
+
+func (ctx txContext) Query(reqCtx context.Context, query string, sqlArgs []interface{}, resultOutput ...interface{}) error {
+	return queryScan(reqCtx, ctx.tx, query, sqlArgs, resultOutput...)
+}
+
+func (ctx txContext) QueryRow(reqCtx context.Context, query string, sqlArgs []interface{}) (pgx.Rows, error) {
+	return queryRows(reqCtx, ctx.tx, query, sqlArgs)
+}
+
+func (ctx txContext) Exec(reqCtx context.Context, query string, sqlArgs []interface{}) error {
+	return exec(reqCtx, ctx.tx, query, sqlArgs)
+}
+
+func (ctx txContext) WithTx(reqCtx context.Context, fn func(tx DatabaseContext) error) error {
+	return withTx(reqCtx, ctx.tx, fn)
+}
+
+// Close is a no-op, the transaction is finished by whoever started it
+func (ctx txContext) Close() {}
+
+// timedRows cancels the query's timeout once all the rows have been read (or the rows are closed)
+type timedRows struct {
+	pgx.Rows
+	cancel context.CancelFunc
+}
+
+func (rows timedRows) Next() bool {
+	if rows.Rows.Next() {
+		return true
+	}
+
+	rows.cancel()
+	return false
+}
+
+func (rows timedRows) Close() {
+	rows.Rows.Close()
+	rows.cancel()
+}
+
+// queryScan runs a query expected to return a single row and scans it into resultOutput
+func queryScan(ctx context.Context, conn querier, query string, sqlArgs []interface{}, resultOutput ...interface{}) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeout)
+	defer cancel()
+
+	return conn.QueryRow(ctx, query, sqlArgs...).Scan(resultOutput...)
+}
+
+// queryRows runs a query returning many rows, the query's timeout lasts until the rows are consumed
+func queryRows(ctx context.Context, conn querier, query string, sqlArgs []interface{}) (pgx.Rows, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeout)
+	rows, err := conn.Query(ctx, query, sqlArgs...)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	return timedRows{rows, cancel}, nil
+}
+
+func exec(ctx context.Context, conn querier, query string, sqlArgs []interface{}) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeout)
+	defer cancel()
+
+	_, err := conn.Exec(ctx, query, sqlArgs...)
+	return err
+}
+
+// withTx runs fn within a new transaction (or a savepoint if conn is already a transaction),
+// the transaction is committed if fn succeeds and rolled back otherwise
+func withTx(ctx context.Context, conn querier, fn func(tx DatabaseContext) error) error {
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err := fn(txContext{tx}); err != nil {
+		// the rollback must happen even if ctx was what caused fn to fail
+		tx.Rollback(context.Background())
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
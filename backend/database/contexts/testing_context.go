@@ -36,20 +36,25 @@ func newTestingContext() *TestingContext {
 }
 
 // Implementation of regular DatabaseContext methods
-func (ctx *TestingContext) Query(query string, sqlArgs []interface{}, resultOutput ...interface{}) error {
+func (ctx *TestingContext) Query(reqCtx context.Context, query string, sqlArgs []interface{}, resultOutput ...interface{}) error {
 	ctx.verifyEnvironment()
-	return ctx.activeTransaction.QueryRow(context.Background(), query, sqlArgs...).Scan(resultOutput...)
+	return queryScan(reqCtx, ctx.activeTransaction, query, sqlArgs, resultOutput...)
 }
 
-func (ctx *TestingContext) QueryRow(query string, sqlArgs []interface{}) (pgx.Rows, error) {
+func (ctx *TestingContext) QueryRow(reqCtx context.Context, query string, sqlArgs []interface{}) (pgx.Rows, error) {
 	ctx.verifyEnvironment()
-	return ctx.activeTransaction.Query(context.Background(), query, sqlArgs...)
+	return queryRows(reqCtx, ctx.activeTransaction, query, sqlArgs)
 }
 
-func (ctx *TestingContext) Exec(query string, sqlArgs []interface{}) error {
+func (ctx *TestingContext) Exec(reqCtx context.Context, query string, sqlArgs []interface{}) error {
 	ctx.verifyEnvironment()
-	_, err := ctx.activeTransaction.Exec(context.Background(), query, sqlArgs...)
-	return err
+	return exec(reqCtx, ctx.activeTransaction, query, sqlArgs)
+}
+
+// WithTx runs the unit of work within a nested transaction of the test's transaction
+func (ctx *TestingContext) WithTx(reqCtx context.Context, fn func(tx DatabaseContext) error) error {
+	ctx.verifyEnvironment()
+	return withTx(reqCtx, ctx.activeTransaction, fn)
 }
 
 func (context *TestingContext) Close() {
@@ -0,0 +1,127 @@
+package migrations
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+// legacyHistoryTable is the table the old postgres/migrate.py script tracked the database version in
+const legacyHistoryTable = "migrations"
+
+// legacyMigrations are the migrations that used to be applied by postgres/migrate.py along with the tables each of
+// them creates. The script ran every script at once without recording which ones it ran, so a database created by it
+// has the tables of some prefix of these migrations but no migration history
+var legacyMigrations = []struct {
+	Version int
+	Tables  []string
+}{
+	{Version: 1, Tables: []string{"groups"}},
+	{Version: 2, Tables: []string{"person", "group_membership"}},
+	{Version: 3, Tables: []string{"metadata", "filesystem", "permissions"}},
+	{Version: 4, Tables: []string{"frontend", "frontend_membership"}},
+	// only inserts the dummy data, the script always ran it alongside the scripts before it
+	{Version: 5},
+	{Version: 6, Tables: []string{"auth_tokens"}},
+	{Version: 7, Tables: []string{"login_attempts"}},
+	{Version: 8, Tables: []string{"two_factor", "recovery_codes"}},
+}
+
+// Baseline marks every migration up to and including version as applied without running them, it is used to take
+// over a database whose schema was created by postgres/migrate.py. The database must not have a migration history
+// yet and the tables created by each of the migrations must already exist
+func (runner *Runner) Baseline(ctx context.Context, version int) ([]Migration, error) {
+	baselined := []Migration{}
+	err := runner.withLock(ctx, func(conn *pgxpool.Conn, history []AppliedMigration) error {
+		if len(history) != 0 {
+			return errors.New("the database already has a migration history, it cannot be baselined")
+		}
+
+		var err error
+		baselined, err = baseline(ctx, conn, runner.migrations, version, tableExistsOn(ctx, conn))
+		return err
+	})
+
+	return baselined, err
+}
+
+// adoptLegacySchema baselines a database created by postgres/migrate.py (recognised by its history table) to the last
+// legacy migration whose tables exist, databases without any migration history are otherwise assumed to be empty
+func adoptLegacySchema(ctx context.Context, conn *pgxpool.Conn, migrations []Migration) ([]Migration, error) {
+	exists := tableExistsOn(ctx, conn)
+	if isLegacy, err := exists(legacyHistoryTable); err != nil || !isLegacy {
+		return nil, err
+	}
+
+	version, err := detectLegacyVersion(exists)
+	if err != nil {
+		return nil, err
+	}
+
+	return baseline(ctx, conn, migrations, version, exists)
+}
+
+// detectLegacyVersion finds the last legacy migration that was applied to a database, every legacy migration before
+// it must have been applied too
+func detectLegacyVersion(exists func(table string) (bool, error)) (int, error) {
+	version := 0
+	for _, legacy := range legacyMigrations {
+		for _, table := range legacy.Tables {
+			if ok, err := exists(table); err != nil || !ok {
+				return version, err
+			}
+		}
+
+		version = legacy.Version
+	}
+
+	return version, nil
+}
+
+// baseline records the migrations up to and including version as applied after verifying that their tables exist
+func baseline(ctx context.Context, conn *pgxpool.Conn, migrations []Migration, version int, exists func(table string) (bool, error)) ([]Migration, error) {
+	if last := legacyMigrations[len(legacyMigrations)-1].Version; version < 0 || version > last {
+		return nil, fmt.Errorf("migration %d was never applied by postgres/migrate.py, only migrations up to %d can be baselined", version, last)
+	}
+
+	for _, legacy := range legacyMigrations[:version] {
+		for _, table := range legacy.Tables {
+			ok, err := exists(table)
+			if err != nil {
+				return nil, err
+			} else if !ok {
+				return nil, fmt.Errorf("cannot baseline migration %d, the %s table does not exist", legacy.Version, table)
+			}
+		}
+	}
+
+	baselined := migrations[:version]
+	err := inTransaction(ctx, conn, func(tx pgx.Tx) error {
+		for _, migration := range baselined {
+			if _, err := tx.Exec(ctx, "INSERT INTO schema_migrations (Version, Name, Checksum) VALUES ($1, $2, $3)",
+				migration.Version, migration.Name, migration.Checksum); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to baseline the migration history: %w", err)
+	}
+
+	return baselined, nil
+}
+
+// tableExistsOn creates a function that determines if a table exists within the database
+func tableExistsOn(ctx context.Context, conn *pgxpool.Conn) func(table string) (bool, error) {
+	return func(table string) (bool, error) {
+		exists := false
+		err := conn.QueryRow(ctx, "SELECT to_regclass($1) IS NOT NULL", table).Scan(&exists)
+		return exists, err
+	}
+}
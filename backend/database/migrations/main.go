@@ -0,0 +1,104 @@
+// Package migrations manages the database schema. Migrations are numbered pairs of SQL scripts
+// (NNNN_name.up.sql and NNNN_name.down.sql) embedded into the binary, each migration is applied within
+// its own transaction and the checksum of its up script is recorded so that migrations that were
+// edited after being applied are detected rather than silently ignored.
+package migrations
+
+import (
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+)
+
+//go:embed sql/*.sql
+var embedded embed.FS
+
+// Migration is a single numbered change to the database schema
+type Migration struct {
+	Version  int
+	Name     string
+	Up       string
+	Down     string
+	Checksum string
+}
+
+// migrationFile matches the file names of migration scripts, eg: 0001_create_groups_table.up.sql
+var migrationFile = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+
+// GetMigrations returns the migrations embedded within the binary
+func GetMigrations() ([]Migration, error) {
+	scripts, err := fs.Sub(embedded, "sql")
+	if err != nil {
+		return nil, err
+	}
+
+	return Load(scripts)
+}
+
+// Load reads all the migrations within the root of a filesystem, the migrations are returned
+// sorted by version. Every migration must have an up script, down scripts are optional although
+// migrations without one cannot be rolled back
+func Load(scripts fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(scripts, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := map[int]*Migration{}
+	for _, entry := range entries {
+		if entry.IsDir() || path.Ext(entry.Name()) != ".sql" {
+			continue
+		}
+
+		parts := migrationFile.FindStringSubmatch(entry.Name())
+		if parts == nil {
+			return nil, fmt.Errorf("malformed migration file name %s", entry.Name())
+		}
+
+		version, _ := strconv.Atoi(parts[1])
+		contents, err := fs.ReadFile(scripts, entry.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: parts[2]}
+			byVersion[version] = migration
+		} else if migration.Name != parts[2] {
+			return nil, fmt.Errorf("migration %d has conflicting names %s and %s", version, migration.Name, parts[2])
+		}
+
+		if parts[3] == "up" {
+			migration.Up = string(contents)
+		} else {
+			migration.Down = string(contents)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.Up == "" {
+			return nil, fmt.Errorf("migration %d (%s) has no up script", migration.Version, migration.Name)
+		}
+
+		migration.Checksum = checksum(migration.Up)
+		migrations = append(migrations, *migration)
+	}
+
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// checksum computes the checksum recorded for a migration, only the up script is included
+// as editing a down script cannot change the state of a database that has already been migrated
+func checksum(script string) string {
+	sum := sha256.Sum256([]byte(script))
+	return hex.EncodeToString(sum[:])
+}
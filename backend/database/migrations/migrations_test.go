@@ -0,0 +1,167 @@
+package migrations
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadSortsAndPairsScripts(t *testing.T) {
+	assert := assert.New(t)
+
+	migrations, err := Load(fstest.MapFS{
+		"0002_add_column.up.sql":     {Data: []byte("ALTER TABLE a ADD COLUMN b INT;")},
+		"0001_create_table.up.sql":   {Data: []byte("CREATE TABLE a ();")},
+		"0001_create_table.down.sql": {Data: []byte("DROP TABLE a;")},
+		"README.md":                  {Data: []byte("not a migration")},
+	})
+
+	assert.Nil(err)
+	assert.Len(migrations, 2)
+	assert.Equal(Migration{
+		Version:  1,
+		Name:     "create_table",
+		Up:       "CREATE TABLE a ();",
+		Down:     "DROP TABLE a;",
+		Checksum: checksum("CREATE TABLE a ();"),
+	}, migrations[0])
+	assert.Equal(2, migrations[1].Version)
+	assert.Equal("", migrations[1].Down)
+}
+
+func TestLoadRejectsMalformedMigrations(t *testing.T) {
+	assert := assert.New(t)
+
+	// CASE: badly named file
+	_, err := Load(fstest.MapFS{"create_table.sql": {Data: []byte("")}})
+	assert.NotNil(err)
+
+	// CASE: a down script without an up script
+	_, err = Load(fstest.MapFS{"0001_create_table.down.sql": {Data: []byte("DROP TABLE a;")}})
+	assert.NotNil(err)
+
+	// CASE: two migrations sharing a version
+	_, err = Load(fstest.MapFS{
+		"0001_create_table.up.sql": {Data: []byte("CREATE TABLE a ();")},
+		"0001_other_table.up.sql":  {Data: []byte("CREATE TABLE b ();")},
+	})
+	assert.NotNil(err)
+}
+
+func TestEmbeddedMigrations(t *testing.T) {
+	assert := assert.New(t)
+
+	migrations, err := GetMigrations()
+	assert.Nil(err)
+	assert.NotEmpty(migrations)
+
+	// versions should be contiguous and every migration should be reversible
+	for i, migration := range migrations {
+		assert.Equal(i+1, migration.Version)
+		assert.NotEmpty(migration.Down, "migration %d has no down script", migration.Version)
+	}
+}
+
+func TestVerifyHistory(t *testing.T) {
+	assert := assert.New(t)
+
+	migrations := []Migration{
+		{Version: 1, Name: "first", Checksum: checksum("first")},
+		{Version: 2, Name: "second", Checksum: checksum("second")},
+		{Version: 3, Name: "third", Checksum: checksum("third")},
+	}
+
+	// CASE: partially migrated database
+	history := []AppliedMigration{
+		{Version: 1, Name: "first", Checksum: checksum("first")},
+		{Version: 2, Name: "second", Checksum: checksum("second")},
+	}
+	assert.Nil(verifyHistory(history, migrations))
+	assert.Nil(verifyHistory([]AppliedMigration{}, migrations))
+
+	// CASE: an applied migration was edited
+	history[1].Checksum = checksum("edited")
+	assert.NotNil(verifyHistory(history, migrations))
+
+	// CASE: a migration was added before one that was already applied
+	history = []AppliedMigration{
+		{Version: 1, Name: "first", Checksum: checksum("first")},
+		{Version: 3, Name: "third", Checksum: checksum("third")},
+	}
+	assert.NotNil(verifyHistory(history, migrations))
+
+	// CASE: the database was migrated by a newer backend
+	history = []AppliedMigration{
+		{Version: 1, Name: "first", Checksum: checksum("first")},
+		{Version: 2, Name: "second", Checksum: checksum("second")},
+		{Version: 3, Name: "third", Checksum: checksum("third")},
+		{Version: 4, Name: "fourth", Checksum: checksum("fourth")},
+	}
+	assert.NotNil(verifyHistory(history, migrations))
+}
+
+func TestDetectLegacyVersion(t *testing.T) {
+	assert := assert.New(t)
+
+	existsIn := func(tables ...string) func(string) (bool, error) {
+		return func(table string) (bool, error) {
+			for _, existing := range tables {
+				if existing == table {
+					return true, nil
+				}
+			}
+
+			return false, nil
+		}
+	}
+
+	// CASE: the original schema, the dummy data is assumed to have been inserted alongside it
+	version, err := detectLegacyVersion(existsIn("migrations", "groups", "person", "group_membership",
+		"metadata", "filesystem", "permissions", "frontend", "frontend_membership"))
+	assert.Nil(err)
+	assert.Equal(5, version)
+
+	// CASE: tables from later migrations don't count if an earlier one is missing
+	version, _ = detectLegacyVersion(existsIn("migrations", "groups", "auth_tokens"))
+	assert.Equal(1, version)
+
+	// CASE: an empty database
+	version, _ = detectLegacyVersion(existsIn())
+	assert.Equal(0, version)
+}
+
+func TestLegacyMigrationsCreateTheirTables(t *testing.T) {
+	assert := assert.New(t)
+
+	migrations, err := GetMigrations()
+	assert.Nil(err)
+
+	// the tables used to recognise the legacy schema must be the ones the migrations actually create
+	for i, legacy := range legacyMigrations {
+		assert.Equal(legacy.Version, migrations[i].Version)
+		for _, table := range legacy.Tables {
+			assert.Contains(migrations[i].Up, "CREATE TABLE "+table, "migration %d doesn't create %s", legacy.Version, table)
+		}
+	}
+}
+
+func TestLegacyMigrationsAreUnchanged(t *testing.T) {
+	assert := assert.New(t)
+
+	migrations, err := GetMigrations()
+	assert.Nil(err)
+
+	// adopted databases are assumed to have exactly the schema these created, so they must match the scripts
+	// postgres/migrate.py ran (postgres/up/02-06), changes to their tables belong in new migrations
+	legacyChecksums := []string{
+		"817d175cf04a91fb087608b9a6c8922382f14dfc14a7eedc04a5119f46502cca",
+		"6cb0c84ba8475be8fa6263f29394bc08420a1064284954aeba827784eb09c1a0",
+		"7da9d9610b614cb27b5b5056ac33bba1904380b43f209fc681ddc5825f4af85b",
+		"c0a34506ca86244a80cd4517ed14bcaf24099f2a65b064dc3d5ae6529a9289d7",
+		"0bcb1cf0048a42c7a4ea6f4d1570c6423d8f26f24bb16b289aaa0df2ac537128",
+	}
+	for i, expected := range legacyChecksums {
+		assert.Equal(expected, migrations[i].Checksum, "migration %d differs from the script migrate.py ran", migrations[i].Version)
+	}
+}
@@ -0,0 +1,237 @@
+package migrations
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+// lockKey identifies the advisory lock held while migrating, it is shared by every replica
+// of the backend so only one of them can migrate the database at a time
+const lockKey int64 = 0x636d732d6d6967 // "cms-mig"
+
+const createHistoryTable = `CREATE TABLE IF NOT EXISTS schema_migrations (
+  Version       INT PRIMARY KEY,
+  Name          VARCHAR(100) NOT NULL,
+  Checksum      CHAR(64) NOT NULL,
+  AppliedAt     TIMESTAMP NOT NULL DEFAULT NOW()
+)`
+
+// ErrIrreversible is returned when rolling back a migration that has no down script
+var ErrIrreversible = errors.New("migration cannot be rolled back")
+
+// AppliedMigration is the record kept of a migration that has been applied to the database
+type AppliedMigration struct {
+	Version   int
+	Name      string
+	Checksum  string
+	AppliedAt time.Time
+}
+
+// MigrationStatus describes if a migration known to the backend has been applied
+type MigrationStatus struct {
+	Migration
+	Applied   bool
+	AppliedAt time.Time
+}
+
+// Runner applies and rolls back migrations against a database
+type Runner struct {
+	pool       *pgxpool.Pool
+	migrations []Migration
+}
+
+// NewRunner creates a runner for the provided migrations, the migrations must be sorted by version
+func NewRunner(pool *pgxpool.Pool, migrations []Migration) *Runner {
+	return &Runner{
+		pool:       pool,
+		migrations: migrations,
+	}
+}
+
+// Up applies all pending migrations in order, it returns the migrations that were applied. A database that was
+// created by postgres/migrate.py is baselined first so the migrations it already applied aren't reapplied
+func (runner *Runner) Up(ctx context.Context) ([]Migration, error) {
+	applied := []Migration{}
+	err := runner.withLock(ctx, func(conn *pgxpool.Conn, history []AppliedMigration) error {
+		pending := runner.migrations[len(history):]
+		if len(history) == 0 {
+			baselined, err := adoptLegacySchema(ctx, conn, runner.migrations)
+			if err != nil {
+				return fmt.Errorf("failed to adopt the schema created by postgres/migrate.py: %w", err)
+			}
+
+			pending = runner.migrations[len(baselined):]
+		}
+
+		for _, migration := range pending {
+			err := inTransaction(ctx, conn, func(tx pgx.Tx) error {
+				if _, err := tx.Exec(ctx, migration.Up); err != nil {
+					return err
+				}
+
+				_, err := tx.Exec(ctx, "INSERT INTO schema_migrations (Version, Name, Checksum) VALUES ($1, $2, $3)",
+					migration.Version, migration.Name, migration.Checksum)
+				return err
+			})
+
+			if err != nil {
+				return fmt.Errorf("failed to apply migration %d (%s): %w", migration.Version, migration.Name, err)
+			}
+
+			applied = append(applied, migration)
+		}
+
+		return nil
+	})
+
+	return applied, err
+}
+
+// Down rolls back the most recently applied migrations, at most steps migrations are rolled back
+// and the rolled back migrations are returned in the order they were rolled back in
+func (runner *Runner) Down(ctx context.Context, steps int) ([]Migration, error) {
+	rolledBack := []Migration{}
+	err := runner.withLock(ctx, func(conn *pgxpool.Conn, history []AppliedMigration) error {
+		for i := len(history) - 1; i >= 0 && len(rolledBack) < steps; i-- {
+			migration := runner.migrations[i]
+			if migration.Down == "" {
+				return fmt.Errorf("failed to roll back migration %d (%s): %w", migration.Version, migration.Name, ErrIrreversible)
+			}
+
+			err := inTransaction(ctx, conn, func(tx pgx.Tx) error {
+				if _, err := tx.Exec(ctx, migration.Down); err != nil {
+					return err
+				}
+
+				_, err := tx.Exec(ctx, "DELETE FROM schema_migrations WHERE Version = $1", migration.Version)
+				return err
+			})
+
+			if err != nil {
+				return fmt.Errorf("failed to roll back migration %d (%s): %w", migration.Version, migration.Name, err)
+			}
+
+			rolledBack = append(rolledBack, migration)
+		}
+
+		return nil
+	})
+
+	return rolledBack, err
+}
+
+// Status reports which migrations have been applied to the database
+func (runner *Runner) Status(ctx context.Context) ([]MigrationStatus, error) {
+	statuses := []MigrationStatus{}
+	err := runner.withLock(ctx, func(conn *pgxpool.Conn, history []AppliedMigration) error {
+		for i, migration := range runner.migrations {
+			status := MigrationStatus{Migration: migration}
+			if i < len(history) {
+				status.Applied = true
+				status.AppliedAt = history[i].AppliedAt
+			}
+
+			statuses = append(statuses, status)
+		}
+
+		return nil
+	})
+
+	return statuses, err
+}
+
+// withLock acquires the migration lock and validates the database's migration history before calling
+// the provided function, the lock is held on a single connection so the function must only use that connection
+func (runner *Runner) withLock(ctx context.Context, fn func(*pgxpool.Conn, []AppliedMigration) error) error {
+	conn, err := runner.pool.Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to acquire a connection: %w", err)
+	}
+	defer conn.Release()
+
+	if _, err := conn.Exec(ctx, "SELECT pg_advisory_lock($1)", lockKey); err != nil {
+		return fmt.Errorf("failed to acquire the migration lock: %w", err)
+	}
+	// the lock must be released even if the context was cancelled part way through migrating
+	defer conn.Exec(context.Background(), "SELECT pg_advisory_unlock($1)", lockKey)
+
+	if _, err := conn.Exec(ctx, createHistoryTable); err != nil {
+		return fmt.Errorf("failed to create the migration history table: %w", err)
+	}
+
+	history, err := getHistory(ctx, conn)
+	if err != nil {
+		return fmt.Errorf("failed to read the migration history: %w", err)
+	}
+
+	if err := verifyHistory(history, runner.migrations); err != nil {
+		return err
+	}
+
+	return fn(conn, history)
+}
+
+// getHistory fetches the migrations applied to the database sorted by version
+func getHistory(ctx context.Context, conn *pgxpool.Conn) ([]AppliedMigration, error) {
+	rows, err := conn.Query(ctx, "SELECT Version, Name, Checksum, AppliedAt FROM schema_migrations ORDER BY Version")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	history := []AppliedMigration{}
+	for rows.Next() {
+		applied := AppliedMigration{}
+		if err := rows.Scan(&applied.Version, &applied.Name, &applied.Checksum, &applied.AppliedAt); err != nil {
+			return nil, err
+		}
+
+		history = append(history, applied)
+	}
+
+	return history, rows.Err()
+}
+
+// verifyHistory ensures that the migrations applied to the database are exactly the first len(history)
+// migrations known to the backend, this catches migrations that have been edited after being applied,
+// databases that were migrated by a newer version of the backend and migrations added out of order
+func verifyHistory(history []AppliedMigration, migrations []Migration) error {
+	for i, applied := range history {
+		if i >= len(migrations) {
+			return fmt.Errorf("migration %d (%s) has been applied but is unknown to this version of the backend", applied.Version, applied.Name)
+		}
+
+		migration := migrations[i]
+		if migration.Version != applied.Version {
+			return fmt.Errorf("migration %d (%s) has been applied but migration %d (%s) has not, migrations must be applied in order",
+				applied.Version, applied.Name, migration.Version, migration.Name)
+		}
+
+		if migration.Checksum != applied.Checksum {
+			return fmt.Errorf("migration %d (%s) has been edited since it was applied, add a new migration instead", migration.Version, migration.Name)
+		}
+	}
+
+	return nil
+}
+
+// inTransaction runs fn within a transaction on the provided connection, the transaction
+// is committed if fn succeeds and rolled back otherwise
+func inTransaction(ctx context.Context, conn *pgxpool.Conn, fn func(pgx.Tx) error) error {
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err := fn(tx); err != nil {
+		tx.Rollback(ctx)
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
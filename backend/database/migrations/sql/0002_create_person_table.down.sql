@@ -0,0 +1,3 @@
+DROP TABLE group_membership;
+DROP FUNCTION create_normal_user;
+DROP TABLE person;
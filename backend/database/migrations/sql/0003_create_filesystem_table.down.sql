@@ -0,0 +1,6 @@
+DROP TABLE permissions;
+DROP TYPE permissions_enum;
+DROP FUNCTION delete_entity;
+DROP FUNCTION new_entity;
+DROP TABLE filesystem;
+DROP TABLE metadata;
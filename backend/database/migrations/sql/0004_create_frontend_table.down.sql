@@ -0,0 +1,3 @@
+DROP TABLE frontend_membership;
+DROP FUNCTION new_frontend;
+DROP TABLE frontend;
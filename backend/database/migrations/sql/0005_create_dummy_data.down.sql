@@ -0,0 +1,2 @@
+/* removes everything the dummy data created, the schema itself is left untouched */
+TRUNCATE frontend_membership, group_membership, permissions, frontend, filesystem, metadata, groups, person RESTART IDENTITY CASCADE;
@@ -0,0 +1,18 @@
+SET timezone = 'Australia/Sydney';
+CREATE EXTENSION IF NOT EXISTS "uuid-ossp";
+
+/* Single-use tokens handed out to users (password resets, email verification), 
+   the token itself is signed by the backend, this table just enforces that it is only used once */
+CREATE TABLE auth_tokens (
+  TokenID       uuid PRIMARY KEY DEFAULT uuid_generate_v4(),
+  UID           INT NOT NULL,
+  Purpose       VARCHAR(50) NOT NULL,
+
+  CreatedAt     TIMESTAMP NOT NULL DEFAULT NOW(),
+  ExpiresAt     TIMESTAMP NOT NULL,
+  /* NULL until the token is redeemed */
+  UsedAt        TIMESTAMP,
+
+  CONSTRAINT fk_TokenOwner FOREIGN KEY (UID)
+    REFERENCES person(UID) ON DELETE CASCADE
+);
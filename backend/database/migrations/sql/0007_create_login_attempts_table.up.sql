@@ -0,0 +1,16 @@
+SET timezone = 'Australia/Sydney';
+
+/* Audit log of every attempt to log in, admins can query this to investigate brute force attempts.
+   Email is not a foreign key as we also want to record attempts against accounts that don't exist */
+CREATE TABLE login_attempts (
+  AttemptID     SERIAL PRIMARY KEY,
+  Email         VARCHAR(50) NOT NULL,
+  IPAddress     VARCHAR(45) NOT NULL,
+  Successful    BOOLEAN NOT NULL,
+  /* why the attempt failed (or 'success') */
+  Outcome       VARCHAR(50) NOT NULL,
+  AttemptedAt   TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX login_attempts_email_idx ON login_attempts (Email, AttemptedAt DESC);
+CREATE INDEX login_attempts_ip_idx ON login_attempts (IPAddress, AttemptedAt DESC);
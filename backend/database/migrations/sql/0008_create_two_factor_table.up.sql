@@ -0,0 +1,27 @@
+SET timezone = 'Australia/Sydney';
+
+/* TOTP enrolment of each user, a row exists (with Enabled = false) as soon as a user begins enrolling
+   but 2FA is only enforced once they have confirmed a code */
+CREATE TABLE two_factor (
+  UID           INT PRIMARY KEY,
+  /* base32 encoded shared secret */
+  Secret        VARCHAR(64) NOT NULL,
+  Enabled       BOOLEAN NOT NULL DEFAULT false,
+  /* the time step of the last accepted code, codes can't be replayed */
+  LastUsedStep  BIGINT,
+  EnabledAt     TIMESTAMP,
+
+  CONSTRAINT fk_TwoFactorOwner FOREIGN KEY (UID)
+    REFERENCES person(UID) ON DELETE CASCADE
+);
+
+/* Single-use recovery codes, only the SHA256 of each code is stored */
+CREATE TABLE recovery_codes (
+  UID           INT NOT NULL,
+  CodeHash      CHAR(64) NOT NULL,
+  UsedAt        TIMESTAMP,
+
+  PRIMARY KEY (UID, CodeHash),
+  CONSTRAINT fk_RecoveryCodeOwner FOREIGN KEY (UID)
+    REFERENCES person(UID) ON DELETE CASCADE
+);
@@ -0,0 +1,3 @@
+DROP TABLE entity_tags;
+DROP TABLE tags;
+DROP TABLE entity_metadata;
@@ -0,0 +1,36 @@
+SET timezone = 'Australia/Sydney';
+
+/* Arbitrary key/value metadata attached to an entity (eg: SEO title, description, author, cover image) */
+CREATE TABLE entity_metadata (
+  EntityID      uuid PRIMARY KEY,
+  Metadata      hstore NOT NULL DEFAULT '',
+
+  CONSTRAINT fk_MetadataEntity FOREIGN KEY (EntityID)
+    REFERENCES filesystem(EntityID) ON DELETE CASCADE
+);
+
+/* Tags form a taxonomy, a tag nested within another (eg: events -> workshops) is more specific than its parent
+   so an entity tagged with "workshops" is also considered to be tagged with "events" */
+CREATE TABLE tags (
+  TagID         SERIAL PRIMARY KEY,
+  Name          VARCHAR(50) NOT NULL,
+  /* NULL if the tag is at the top of the taxonomy */
+  Parent        INT,
+
+  CONSTRAINT fk_TagParent FOREIGN KEY (Parent)
+    REFERENCES tags(TagID) ON DELETE CASCADE
+);
+
+/* tag names are unique amongst their siblings */
+CREATE UNIQUE INDEX unique_tag_name ON tags (COALESCE(Parent, 0), Name);
+
+CREATE TABLE entity_tags (
+  EntityID      uuid NOT NULL,
+  TagID         INT NOT NULL,
+
+  PRIMARY KEY (EntityID, TagID),
+  CONSTRAINT fk_TaggedEntity FOREIGN KEY (EntityID)
+    REFERENCES filesystem(EntityID) ON DELETE CASCADE,
+  CONSTRAINT fk_EntityTag FOREIGN KEY (TagID)
+    REFERENCES tags(TagID) ON DELETE CASCADE
+);
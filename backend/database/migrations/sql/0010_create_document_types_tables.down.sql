@@ -0,0 +1,2 @@
+DROP TABLE directory_types;
+DROP TABLE document_types;
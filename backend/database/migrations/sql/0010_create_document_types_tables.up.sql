@@ -0,0 +1,20 @@
+SET timezone = 'Australia/Sydney';
+
+/* Document types describe the fields a document must have (eg: a blog post needs a title, author, date and cover image),
+   Fields is an array of {"Name": ..., "Kind": ..., "Required": ...} objects */
+CREATE TABLE document_types (
+  TypeID        SERIAL PRIMARY KEY,
+  Name          VARCHAR(50) UNIQUE NOT NULL,
+  Fields        JSONB NOT NULL DEFAULT '[]'
+);
+
+/* Every document directly within a directory must match the type attached to it */
+CREATE TABLE directory_types (
+  EntityID      uuid PRIMARY KEY,
+  TypeID        INT NOT NULL,
+
+  CONSTRAINT fk_TypedDirectory FOREIGN KEY (EntityID)
+    REFERENCES filesystem(EntityID) ON DELETE CASCADE,
+  CONSTRAINT fk_DirectoryType FOREIGN KEY (TypeID)
+    REFERENCES document_types(TypeID) ON DELETE CASCADE
+);
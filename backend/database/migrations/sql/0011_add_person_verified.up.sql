@@ -0,0 +1,5 @@
+/* users must confirm their email before they can log in. Accounts that existed before email verification was
+   introduced are trusted, so the column is backfilled with true before new accounts default to unverified
+   (databases adopted from migrate.py after the column was added to its person table already have it) */
+ALTER TABLE person ADD COLUMN IF NOT EXISTS Verified BOOLEAN NOT NULL DEFAULT true;
+ALTER TABLE person ALTER COLUMN Verified SET DEFAULT false;
@@ -0,0 +1 @@
+ALTER TABLE frontend DROP COLUMN RequireAdmin2FA;
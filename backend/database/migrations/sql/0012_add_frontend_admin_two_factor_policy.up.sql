@@ -0,0 +1,2 @@
+/* when set members of the admin group must use 2FA to log into this frontend */
+ALTER TABLE frontend ADD COLUMN IF NOT EXISTS RequireAdmin2FA BOOLEAN NOT NULL DEFAULT false;
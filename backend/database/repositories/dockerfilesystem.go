@@ -14,6 +14,11 @@ const (
 	unpublishedVolumePath = "/var/lib/documents/unpublished/data"
 )
 
+// VolumePaths are the directories the published and unpublished docker volumes are mounted at
+func VolumePaths() []string {
+	return []string{publishedVolumePath, unpublishedVolumePath}
+}
+
 type dockerFileSystemRepositoryCore struct {
 	dockerCli  *client.Client
 	volumePath string
@@ -121,6 +126,11 @@ func (c *dockerFileSystemRepositoryCore) GetFromVolumeTruncated(filename string)
 	return os.OpenFile(filepath.Join(c.volumePath, filename), os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0o755)
 }
 
+// Open file from volume in read only mode, unlike GetFromVolume the file isn't created if it doesn't exist
+func (c *dockerFileSystemRepositoryCore) ReadFromVolume(filename string) (*os.File, error) {
+	return os.Open(filepath.Join(c.volumePath, filename))
+}
+
 // Delete file from volume
 func (c *dockerFileSystemRepositoryCore) DeleteFromVolume(filename string) error {
 	filepath := filepath.Join(c.volumePath, filename)
@@ -129,9 +139,19 @@ func (c *dockerFileSystemRepositoryCore) DeleteFromVolume(filename string) error
 		return errors.New("file doesn't exist")
 	}
 	file.Close()
-	os.Remove(filepath)
 	if err = os.Remove(filepath); err != nil {
 		return errors.New("couldn't remove the source file")
 	}
 	return nil
 }
+
+// Create instance of DockerFileSystemRepositoryCore backed by a local directory rather than a docker volume
+func newLocalFilesystemRepositoryCore(directory string) (*dockerFileSystemRepositoryCore, error) {
+	if err := os.MkdirAll(directory, 0o755); err != nil {
+		return nil, err
+	}
+
+	return &dockerFileSystemRepositoryCore{
+		volumePath: directory,
+	}, nil
+}
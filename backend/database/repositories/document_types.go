@@ -0,0 +1,99 @@
+package repositories
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v4"
+)
+
+// Implements DocumentTypesRepository
+type documentTypesRepository struct {
+	embeddedContext
+}
+
+// GetDocumentTypes fetches every document type in the order they were created
+func (rep documentTypesRepository) GetDocumentTypes() ([]DocumentType, error) {
+	rows, err := rep.db.QueryRow(rep.ctx, "SELECT TypeID, Name, Fields::text FROM document_types ORDER BY TypeID;", []interface{}{})
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	documentTypes := []DocumentType{}
+	for rows.Next() {
+		var documentType DocumentType
+		var fields string
+		if err := rows.Scan(&documentType.TypeID, &documentType.Name, &fields); err != nil {
+			return nil, err
+		}
+
+		if err := json.Unmarshal([]byte(fields), &documentType.Fields); err != nil {
+			return nil, err
+		}
+
+		documentTypes = append(documentTypes, documentType)
+	}
+
+	return documentTypes, rows.Err()
+}
+
+// CreateDocumentType creates a new document type, the TypeID of the provided type is ignored
+func (rep documentTypesRepository) CreateDocumentType(documentType DocumentType) (DocumentType, error) {
+	fields, err := json.Marshal(normaliseFields(documentType.Fields))
+	if err != nil {
+		return DocumentType{}, err
+	}
+
+	err = rep.db.Query(rep.ctx, "INSERT INTO document_types (Name, Fields) VALUES ($1, $2::jsonb) RETURNING TypeID;",
+		[]interface{}{documentType.Name, string(fields)}, &documentType.TypeID)
+	if err != nil {
+		return DocumentType{}, err
+	}
+
+	documentType.Fields = normaliseFields(documentType.Fields)
+	return documentType, nil
+}
+
+// DeleteDocumentType deletes a document type, detaching it from every directory it was attached to
+func (rep documentTypesRepository) DeleteDocumentType(typeID int) error {
+	return rep.db.Exec(rep.ctx, "DELETE FROM document_types WHERE TypeID = $1;", []interface{}{typeID})
+}
+
+func (rep documentTypesRepository) GetDirectoryType(directoryID uuid.UUID) (DocumentType, error) {
+	var documentType DocumentType
+	var fields string
+	err := rep.db.Query(rep.ctx, `SELECT t.TypeID, t.Name, t.Fields::text FROM document_types t
+			JOIN directory_types d ON d.TypeID = t.TypeID
+			WHERE d.EntityID = $1;`, []interface{}{directoryID}, &documentType.TypeID, &documentType.Name, &fields)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return DocumentType{TypeID: NoDocumentType, Fields: []DocumentField{}}, nil
+	} else if err != nil {
+		return DocumentType{}, err
+	}
+
+	return documentType, json.Unmarshal([]byte(fields), &documentType.Fields)
+}
+
+// SetDirectoryType attaches a type to a directory, it fails with pgx.ErrNoRows if the entity isn't a directory
+func (rep documentTypesRepository) SetDirectoryType(directoryID uuid.UUID, typeID int) error {
+	if typeID == NoDocumentType {
+		return rep.db.Exec(rep.ctx, "DELETE FROM directory_types WHERE EntityID = $1;", []interface{}{directoryID})
+	}
+
+	var attachedTo uuid.UUID
+	return rep.db.Query(rep.ctx, `INSERT INTO directory_types (EntityID, TypeID)
+			SELECT EntityID, $2 FROM filesystem WHERE EntityID = $1 AND NOT IsDocument
+			ON CONFLICT (EntityID) DO UPDATE SET TypeID = EXCLUDED.TypeID
+			RETURNING EntityID;`, []interface{}{directoryID, typeID}, &attachedTo)
+}
+
+// normaliseFields ensures a type without any fields is stored as an empty array rather than null
+func normaliseFields(fields []DocumentField) []DocumentField {
+	if fields == nil {
+		return []DocumentField{}
+	}
+
+	return fields
+}
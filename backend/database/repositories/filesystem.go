@@ -5,7 +5,9 @@ import (
 	"errors"
 	"strings"
 
+	"cms.csesoc.unsw.edu.au/database/contexts"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v4"
 )
 
 // Implements IRepositoryInterface
@@ -22,7 +24,7 @@ func (rep filesystemRepository) query(query string, input ...interface{}) (Files
 	entity := FilesystemEntry{}
 	children := []uuid.UUID{}
 
-	err := rep.ctx.Query(query,
+	err := rep.db.Query(rep.ctx, query,
 		input,
 		&entity.EntityID, &entity.LogicalName, &entity.IsDocument, &entity.IsPublished,
 		&entity.CreatedAt, &entity.OwnerUserId, &entity.ParentFileID)
@@ -30,10 +32,11 @@ func (rep filesystemRepository) query(query string, input ...interface{}) (Files
 		return FilesystemEntry{}, err
 	}
 
-	rows, err := rep.ctx.QueryRow("SELECT EntityID FROM filesystem WHERE Parent = $1", []interface{}{entity.EntityID})
+	rows, err := rep.db.QueryRow(rep.ctx, "SELECT EntityID FROM filesystem WHERE Parent = $1", []interface{}{entity.EntityID})
 	if err != nil {
 		return FilesystemEntry{}, err
 	}
+	defer rows.Close()
 
 	// finally scan in the rows
 	for rows.Next() {
@@ -50,58 +53,168 @@ func (rep filesystemRepository) query(query string, input ...interface{}) (Files
 	return entity, nil
 }
 
-// Returns: entry struct containing the entity that was just created
+// Returns: entry struct containing the entity that was just created, the entity is created and
+// read back within a single transaction
 func (rep filesystemRepository) CreateEntry(file FilesystemEntry) (FilesystemEntry, error) {
-	var newID uuid.UUID
-	err := rep.ctx.Query("SELECT new_entity($1, $2, $3, $4)", []interface{}{file.ParentFileID, file.LogicalName, file.OwnerUserId, file.IsDocument}, &newID)
+	var created FilesystemEntry
+	err := rep.db.WithTx(rep.ctx, func(tx contexts.DatabaseContext) error {
+		var newID uuid.UUID
+		err := tx.Query(rep.ctx, "SELECT new_entity($1, $2, $3, $4)", []interface{}{file.ParentFileID, file.LogicalName, file.OwnerUserId, file.IsDocument}, &newID)
+		if err != nil {
+			return err
+		}
+
+		txRep := rep
+		txRep.db = tx
+		created, err = txRep.GetEntryWithID(newID)
+		return err
+	})
+
 	if err != nil {
 		return FilesystemEntry{}, err
 	}
-	return rep.GetEntryWithID(newID)
+	return created, nil
 }
 
 func (rep filesystemRepository) GetEntryWithID(ID uuid.UUID) (FilesystemEntry, error) {
-	result, err := rep.query("SELECT * FROM filesystem WHERE EntityID = $1", ID)
-	return result, err
+	subtree, err := rep.GetSubtree(ID, 0)
+	if err != nil {
+		return FilesystemEntry{}, err
+	}
+
+	return subtree[0], nil
 }
 
 func (rep filesystemRepository) GetRoot() (FilesystemEntry, error) {
-	return rep.query("SELECT * FROM filesystem WHERE EntityID = $1", rep.frontendRoot)
+	return rep.GetEntryWithID(rep.frontendRoot)
 }
 
 func (rep filesystemRepository) GetEntryWithParentID(ID uuid.UUID) (FilesystemEntry, error) {
 	return rep.query("SELECT * FROM filesystem WHERE Parent = $1", ID)
 }
 
+// GetIDWithPath resolves a path (eg. /a/b/c) relative to the frontend's root in a single query, the query walks
+// down the tree one path segment at a time. Names aren't unique so, like the memory repository, each segment
+// resolves to the oldest matching entry, "/" resolves to the root
 func (rep filesystemRepository) GetIDWithPath(path string) (uuid.UUID, error) {
-	// I could do this with one query, where I query the repository for all files in parentNames and process that here
 	parentNames := strings.Split(path, "/")
 	if parentNames[0] != "" {
 		return uuid.Nil, errors.New("path must start with /")
+	} else if path == "/" {
+		parentNames = parentNames[:1]
 	}
 
-	// Determine main parent
-	parent, err := rep.query("SELECT * FROM filesystem WHERE LogicalName = $1 AND Parent = $2", parentNames[1], rep.frontendRoot)
+	var entityID uuid.UUID
+	err := rep.db.Query(rep.ctx, `WITH RECURSIVE walk AS (
+				SELECT $1::uuid AS EntityID, 0 AS depth
+				UNION ALL
+				SELECT (SELECT f.EntityID FROM filesystem f
+							WHERE f.Parent = w.EntityID AND f.LogicalName = ($2::text[])[w.depth + 1]
+							ORDER BY f.CreatedAt, f.EntityID LIMIT 1), w.depth + 1
+					FROM walk w WHERE w.EntityID IS NOT NULL AND w.depth < cardinality($2::text[])
+			)
+			SELECT EntityID FROM walk WHERE depth = cardinality($2::text[]) AND EntityID IS NOT NULL;`,
+		[]interface{}{rep.frontendRoot, parentNames[1:]}, &entityID)
+
+	return entityID, err
+}
+
+// GetSubtree fetches an entity and its descendants up to depth levels beneath it in a single query. The query walks
+// one level further than requested so that the ChildrenIDs of the deepest entries can be filled in, those extra rows
+// are not returned
+func (rep filesystemRepository) GetSubtree(ID uuid.UUID, depth int) ([]FilesystemEntry, error) {
+	rows, err := rep.db.QueryRow(rep.ctx, `WITH RECURSIVE subtree AS (
+				SELECT EntityID, LogicalName, IsDocument, IsPublished, CreatedAt, OwnedBy, Parent, 0 AS depth
+					FROM filesystem WHERE EntityID = $1
+				UNION ALL
+				SELECT f.EntityID, f.LogicalName, f.IsDocument, f.IsPublished, f.CreatedAt, f.OwnedBy, f.Parent, s.depth + 1
+					FROM filesystem f JOIN subtree s ON f.Parent = s.EntityID
+					WHERE s.depth <= $2
+			)
+			SELECT EntityID, LogicalName, IsDocument, IsPublished, CreatedAt, OwnedBy, Parent, depth
+				FROM subtree ORDER BY depth, CreatedAt;`,
+		[]interface{}{ID, depth})
 	if err != nil {
-		return uuid.Nil, err
+		return nil, err
 	}
-	// Loop through children
-	for i := 2; i < len(parentNames); i++ {
-		child, err := rep.query("SELECT * FROM filesystem WHERE LogicalName = $1 AND Parent = $2", parentNames[i], parent.EntityID)
-		if err != nil {
-			return uuid.Nil, err
+	defer rows.Close()
+
+	entries := []FilesystemEntry{}
+	positions := map[uuid.UUID]int{}
+	for rows.Next() {
+		var entry FilesystemEntry
+		var entryDepth int
+		if err := rows.Scan(&entry.EntityID, &entry.LogicalName, &entry.IsDocument, &entry.IsPublished,
+			&entry.CreatedAt, &entry.OwnerUserId, &entry.ParentFileID, &entryDepth); err != nil {
+			return nil, err
+		}
+
+		if parent, ok := positions[entry.ParentFileID]; ok && entryDepth > 0 {
+			entries[parent].ChildrenIDs = append(entries[parent].ChildrenIDs, entry.EntityID)
+		}
+
+		if entryDepth <= depth {
+			entry.ChildrenIDs = []uuid.UUID{}
+			positions[entry.EntityID] = len(entries)
+			entries = append(entries, entry)
 		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(entries) == 0 {
+		return nil, pgx.ErrNoRows
+	}
+
+	return entries, nil
+}
+
+// GetBreadcrumbs fetches the entities along the path from the root down to (and including) an entity in a single query,
+// the ChildrenIDs of the returned entries are not filled in
+func (rep filesystemRepository) GetBreadcrumbs(ID uuid.UUID) ([]FilesystemEntry, error) {
+	rows, err := rep.db.QueryRow(rep.ctx, `WITH RECURSIVE ancestors AS (
+				SELECT EntityID, LogicalName, IsDocument, IsPublished, CreatedAt, OwnedBy, Parent, 0 AS height
+					FROM filesystem WHERE EntityID = $1
+				UNION ALL
+				SELECT f.EntityID, f.LogicalName, f.IsDocument, f.IsPublished, f.CreatedAt, f.OwnedBy, f.Parent, a.height + 1
+					FROM filesystem f JOIN ancestors a ON f.EntityID = a.Parent
+			)
+			SELECT EntityID, LogicalName, IsDocument, IsPublished, CreatedAt, OwnedBy, Parent
+				FROM ancestors ORDER BY height DESC;`,
+		[]interface{}{ID})
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	breadcrumbs := []FilesystemEntry{}
+	for rows.Next() {
+		var entry FilesystemEntry
+		if err := rows.Scan(&entry.EntityID, &entry.LogicalName, &entry.IsDocument, &entry.IsPublished,
+			&entry.CreatedAt, &entry.OwnerUserId, &entry.ParentFileID); err != nil {
+			return nil, err
+		}
+
+		breadcrumbs = append(breadcrumbs, entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
 
-		parent = child
+	if len(breadcrumbs) == 0 {
+		return nil, pgx.ErrNoRows
 	}
 
-	return parent.EntityID, err
+	return breadcrumbs, nil
 }
 
 func (rep filesystemRepository) DeleteEntryWithID(ID uuid.UUID) error {
-	return rep.ctx.Exec("SELECT delete_entity($1)", []interface{}{ID})
+	return rep.db.Exec(rep.ctx, "SELECT delete_entity($1)", []interface{}{ID})
 }
 
 func (rep filesystemRepository) RenameEntity(ID uuid.UUID, name string) error {
-	return rep.ctx.Exec("UPDATE filesystem SET LogicalName = ($1) WHERE EntityId = ($2)", []interface{}{name, ID})
+	return rep.db.Exec(rep.ctx, "UPDATE filesystem SET LogicalName = ($1) WHERE EntityId = ($2)", []interface{}{name, ID})
 }
@@ -13,7 +13,7 @@ type frontendsRepository struct {
 const InvalidFrontend = -1
 
 func NewFrontendRepo(logicalName string, URL string, embeddedContext embeddedContext) (filesystemRepository, error) {
-	rows, err := embeddedContext.ctx.QueryRow("SELECT * from new_frontend($1, $2)", []interface{}{logicalName, URL})
+	rows, err := embeddedContext.db.QueryRow(embeddedContext.ctx, "SELECT * from new_frontend($1, $2)", []interface{}{logicalName, URL})
 	if err != nil {
 		return filesystemRepository{}, fmt.Errorf("Error setting up frontend in Postgres (new_frontend): %w", err)
 	}
@@ -42,10 +42,29 @@ func NewFrontendRepo(logicalName string, URL string, embeddedContext embeddedCon
 // GetFrontendFromURL is the implementation of the frontend repository for frontendRepository
 func (rep frontendsRepository) GetFrontendFromURL(url string) int {
 	var frontendId int
-	err := rep.ctx.Query("SELECT ID from frontend where URL = $1;", []interface{}{url}, &frontendId)
+	err := rep.db.Query(rep.ctx, "SELECT ID from frontend where URL = $1;", []interface{}{url}, &frontendId)
 	if err != nil {
 		return InvalidFrontend
 	}
 
 	return frontendId
 }
+
+// RequiresAdminTwoFactor is the implementation of the frontend repository for frontendRepository
+func (rep frontendsRepository) RequiresAdminTwoFactor(frontendID uuid.UUID) (bool, error) {
+	var required bool
+	err := rep.db.Query(rep.ctx, `SELECT COALESCE(
+			(SELECT RequireAdmin2FA FROM frontend WHERE ID = $1),
+			(SELECT bool_or(RequireAdmin2FA) FROM frontend),
+			false);`, []interface{}{frontendID}, &required)
+
+	return required, err
+}
+
+// IsRegisteredURL is the implementation of the frontend repository for frontendRepository
+func (rep frontendsRepository) IsRegisteredURL(url string) (bool, error) {
+	var count int
+	err := rep.db.Query(rep.ctx, "SELECT count(*) FROM frontend WHERE rtrim(URL, '/') = rtrim($1, '/');", []interface{}{url}, &count)
+
+	return count > 0, err
+}
@@ -11,10 +11,21 @@ type groupsRepository struct {
 
 func (rep groupsRepository) GetGroupInfo(g Groups) Groups {
 	var result Groups
-	err := rep.ctx.Query("SELECT * from groups where name = $1;", []interface{}{g.Name},
+	err := rep.db.Query(rep.ctx, "SELECT * from groups where name = $1;", []interface{}{g.Name},
 		&g.UID, &g.Name, &g.Permission)
 	if err != nil {
 		log.Print("get permissions error", err.Error())
 	}
 	return result
 }
+
+// IsMemberOf determines if a person belongs to a specific group
+func (rep groupsRepository) IsMemberOf(UID int, groupID int) (bool, error) {
+	var count int
+	err := rep.db.Query(rep.ctx, "SELECT count(*) FROM group_membership WHERE UID = $1 AND GroupID = $2;", []interface{}{UID, groupID}, &count)
+	if err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
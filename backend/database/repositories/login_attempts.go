@@ -0,0 +1,91 @@
+package repositories
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Implements LoginAttemptsRepository
+type loginAttemptsRepository struct {
+	embeddedContext
+}
+
+// the most attempts a single query can return
+const maxLoginAttempts = 500
+
+// the widths of the login_attempts columns, the email and address are provided by the client so they are
+// truncated to fit rather than failing to record the attempt
+const (
+	maxAttemptEmailLength   = 50
+	maxAttemptAddressLength = 45
+)
+
+// RecordAttempt appends an attempt to the audit log
+func (rep loginAttemptsRepository) RecordAttempt(attempt LoginAttempt) error {
+	return rep.db.Exec(rep.ctx, "INSERT INTO login_attempts (Email, IPAddress, Successful, Outcome) VALUES ($1, $2, $3, $4);",
+		[]interface{}{truncate(attempt.Email, maxAttemptEmailLength), truncate(attempt.IPAddress, maxAttemptAddressLength), attempt.Successful, attempt.Outcome})
+}
+
+// truncate shortens a string to at most length characters
+func truncate(value string, length int) string {
+	if runes := []rune(value); len(runes) > length {
+		return string(runes[:length])
+	}
+
+	return value
+}
+
+// GetAttempts fetches the attempts matching the provided filter, most recent first
+func (rep loginAttemptsRepository) GetAttempts(filter LoginAttemptFilter) ([]LoginAttempt, error) {
+	conditions := []string{}
+	args := []interface{}{}
+	addCondition := func(condition string, arg interface{}) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	if filter.Email != "" {
+		addCondition("Email = $%d", filter.Email)
+	}
+	if filter.IPAddress != "" {
+		addCondition("IPAddress = $%d", filter.IPAddress)
+	}
+	if filter.OnlyFailures {
+		addCondition("Successful = $%d", false)
+	}
+	if !filter.Since.IsZero() {
+		addCondition("AttemptedAt >= $%d", filter.Since)
+	}
+
+	limit := filter.Limit
+	if limit <= 0 || limit > maxLoginAttempts {
+		limit = maxLoginAttempts
+	}
+
+	query := "SELECT AttemptID, Email, IPAddress, Successful, Outcome, AttemptedAt FROM login_attempts"
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	args = append(args, limit)
+	query += fmt.Sprintf(" ORDER BY AttemptedAt DESC LIMIT $%d;", len(args))
+
+	rows, err := rep.db.QueryRow(rep.ctx, query, args)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	attempts := []LoginAttempt{}
+	for rows.Next() {
+		var attempt LoginAttempt
+		if err := rows.Scan(&attempt.AttemptID, &attempt.Email, &attempt.IPAddress,
+			&attempt.Successful, &attempt.Outcome, &attempt.AttemptedAt); err != nil {
+			return nil, err
+		}
+
+		attempts = append(attempts, attempt)
+	}
+
+	return attempts, rows.Err()
+}
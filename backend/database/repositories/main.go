@@ -1,44 +1,89 @@
 package repositories
 
 import (
-	"sync"
+	"context"
 
 	"cms.csesoc.unsw.edu.au/database/contexts"
 	"github.com/google/uuid"
 )
 
-// Start up a database connection with a provided context
-// TODO: this is technical a global shared variable and should be treated as such, this should be wrapped in a mutex
-// or eliminated of entirely :D
-var contextLock = sync.Mutex{}
-var context contexts.DatabaseContext = nil
-
-// Open constructors available for everyone
+// Open constructors available for everyone, every repository makes its queries under the provided context.Context
+// (cancelling it cancels any outstanding queries) against the provided database, repositories constructed from the
+// same transaction (see DatabaseContext.WithTx) share a single unit of work
 
 // NewFilesystemRepo instantiates a new file system repository with the current embedded context
-func NewFilesystemRepo(logicalName string, URL string, context contexts.DatabaseContext) (FilesystemRepository, error) {
-	return NewFrontendRepo(logicalName, URL, embeddedContext{context})
+func NewFilesystemRepo(ctx context.Context, logicalName string, URL string, db contexts.DatabaseContext) (FilesystemRepository, error) {
+	return NewFrontendRepo(logicalName, URL, embeddedContext{ctx, db})
 }
 
 // NewGroupsRepo instantiates a new groups repository
-func NewGroupsRepo(context contexts.DatabaseContext) GroupsRepository {
+func NewGroupsRepo(ctx context.Context, db contexts.DatabaseContext) GroupsRepository {
 	return groupsRepository{
-		embeddedContext{context},
+		embeddedContext{ctx, db},
 	}
 }
 
 // NewFrontendsRepo instantiates a new frontends repository
-func NewFrontendsRepo(context contexts.DatabaseContext) FrontendsRepository {
+func NewFrontendsRepo(ctx context.Context, db contexts.DatabaseContext) FrontendsRepository {
 	return frontendsRepository{
-		embeddedContext{context},
+		embeddedContext{ctx, db},
 	}
 }
 
 // NewPersonRepo instantiates a new person repository
-func NewPersonRepo(frontendId uuid.UUID) PersonRepository {
+func NewPersonRepo(ctx context.Context, frontendId uuid.UUID, db contexts.DatabaseContext) PersonRepository {
 	return personRepository{
 		frontendId,
-		embeddedContext{getContext()},
+		embeddedContext{ctx, db},
+	}
+}
+
+// NewTokensRepo instantiates a new tokens repository
+func NewTokensRepo(ctx context.Context, db contexts.DatabaseContext) TokensRepository {
+	return tokensRepository{
+		embeddedContext{ctx, db},
+	}
+}
+
+// NewPermissionsRepo instantiates a new permissions repository
+func NewPermissionsRepo(ctx context.Context, db contexts.DatabaseContext) PermissionsRepository {
+	return permissionsRepository{
+		embeddedContext{ctx, db},
+	}
+}
+
+// NewTwoFactorRepo instantiates a new 2FA repository
+func NewTwoFactorRepo(ctx context.Context, db contexts.DatabaseContext) TwoFactorRepository {
+	return twoFactorRepository{
+		embeddedContext{ctx, db},
+	}
+}
+
+// NewLoginAttemptsRepo instantiates a new login attempts repository
+func NewLoginAttemptsRepo(ctx context.Context, db contexts.DatabaseContext) LoginAttemptsRepository {
+	return loginAttemptsRepository{
+		embeddedContext{ctx, db},
+	}
+}
+
+// NewMetadataRepo instantiates a new metadata repository
+func NewMetadataRepo(ctx context.Context, db contexts.DatabaseContext) MetadataRepository {
+	return metadataRepository{
+		embeddedContext{ctx, db},
+	}
+}
+
+// NewTagsRepo instantiates a new tags repository
+func NewTagsRepo(ctx context.Context, db contexts.DatabaseContext) TagsRepository {
+	return tagsRepository{
+		embeddedContext{ctx, db},
+	}
+}
+
+// NewDocumentTypesRepo instantiates a new document types repository
+func NewDocumentTypesRepo(ctx context.Context, db contexts.DatabaseContext) DocumentTypesRepository {
+	return documentTypesRepository{
+		embeddedContext{ctx, db},
 	}
 }
 
@@ -63,13 +108,25 @@ func NewPublishedRepo() PublishedVolumeRepository {
 	return fs
 }
 
-func getContext() contexts.DatabaseContext {
-	contextLock.Lock()
-	defer contextLock.Unlock()
+// Local volume constructors, these are used by dev mode alongside the in-memory repositories (see MemoryDatabase)
+// so that the backend can run without docker
+
+// NewLocalUnpublishedRepo instantiates an unpublished volume repository backed by a local directory
+func NewLocalUnpublishedRepo(directory string) (UnpublishedVolumeRepository, error) {
+	inner, err := newLocalFilesystemRepositoryCore(directory)
+	if err != nil {
+		return nil, err
+	}
 
-	if context == nil {
-		context = contexts.GetDatabaseContext()
+	return &dockerUnpublishedFileSystemRepository{*inner}, nil
+}
+
+// NewLocalPublishedRepo instantiates a published volume repository backed by a local directory
+func NewLocalPublishedRepo(directory string) (PublishedVolumeRepository, error) {
+	inner, err := newLocalFilesystemRepositoryCore(directory)
+	if err != nil {
+		return nil, err
 	}
 
-	return context
+	return &dockerPublishedFileSystemRepository{*inner}, nil
 }
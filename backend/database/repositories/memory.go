@@ -0,0 +1,370 @@
+package repositories
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v4"
+)
+
+// Errors mirroring the constraints enforced by the database schema, the in-memory repositories return these
+// wherever the SQL implementations would have failed with a constraint violation or exception
+var (
+	ErrDuplicateName  = errors.New("an entity with the same name already exists within the parent directory")
+	ErrDocumentParent = errors.New("cannot make parent a document")
+	ErrHasChildren    = errors.New("entity has children (please dont orphan them O_O )")
+	ErrDeleteRoot     = errors.New("stop trying to delete root >:(")
+	ErrUnknownGroup   = errors.New("group does not exist")
+	ErrDuplicateEmail = errors.New("a person with that email already exists")
+	ErrUnknownEntity  = errors.New("entity does not exist")
+	ErrUnknownTag     = errors.New("tag does not exist")
+	ErrDuplicateTag   = errors.New("a tag with the same name already exists within the parent tag")
+	ErrDuplicateType  = errors.New("a document type with the same name already exists")
+	ErrUnknownType    = errors.New("document type does not exist")
+)
+
+// errNotFound is returned when a lookup matches nothing, it is pgx's error so callers can't tell the
+// in-memory repositories apart from the SQL ones
+var errNotFound = pgx.ErrNoRows
+
+// MemoryDatabase is an in-memory stand-in for the CMS database, the in-memory repositories are all views over a shared
+// MemoryDatabase so (like the SQL repositories) changes made through one repository are visible through the others
+type MemoryDatabase struct {
+	lock   sync.RWMutex
+	txLock sync.Mutex
+	tables memoryTables
+}
+
+// memoryTables are the "tables" of the in-memory database
+type memoryTables struct {
+	entities      map[uuid.UUID]memoryEntity
+	frontends     []memoryFrontend
+	people        map[int]Person
+	groups        map[int]string
+	memberships   map[int]map[int]bool
+	permissions   []memoryPermission
+	tokens        map[uuid.UUID]memoryToken
+	twoFactor     map[int]memoryTwoFactor
+	recoveryCodes map[int]map[string]bool
+	loginAttempts []LoginAttempt
+	metadata      map[uuid.UUID]map[string]string
+	tags          map[int]Tag
+	entityTags    map[uuid.UUID]map[int]bool
+	documentTypes map[int]DocumentType
+	directoryType map[uuid.UUID]int
+
+	nextUID, nextGroupID, nextAttemptID, nextTagID, nextTypeID, nextSequence int
+}
+
+type (
+	memoryEntity struct {
+		EntityID    uuid.UUID
+		LogicalName string
+		IsDocument  bool
+		IsPublished bool
+		CreatedAt   time.Time
+		OwnedBy     int
+		Parent      uuid.UUID
+
+		// sequence is the order entities were inserted in
+		sequence int
+	}
+
+	memoryFrontend struct {
+		ID              uuid.UUID
+		LogicalName     string
+		URL             string
+		Root            uuid.UUID
+		RequireAdmin2FA bool
+	}
+
+	memoryPermission struct {
+		EntityID   uuid.UUID
+		GroupID    int
+		Permission Permission
+	}
+
+	memoryToken struct {
+		AuthToken
+		Used bool
+	}
+
+	memoryTwoFactor struct {
+		TwoFactor
+		LastUsedStep *int64
+	}
+)
+
+// NewMemoryDatabase creates a new empty in-memory database
+func NewMemoryDatabase() *MemoryDatabase {
+	return &MemoryDatabase{
+		tables: memoryTables{
+			entities:      map[uuid.UUID]memoryEntity{},
+			frontends:     []memoryFrontend{},
+			people:        map[int]Person{},
+			groups:        map[int]string{},
+			memberships:   map[int]map[int]bool{},
+			permissions:   []memoryPermission{},
+			tokens:        map[uuid.UUID]memoryToken{},
+			twoFactor:     map[int]memoryTwoFactor{},
+			recoveryCodes: map[int]map[string]bool{},
+			loginAttempts: []LoginAttempt{},
+			metadata:      map[uuid.UUID]map[string]string{},
+			tags:          map[int]Tag{},
+			entityTags:    map[uuid.UUID]map[int]bool{},
+			documentTypes: map[int]DocumentType{},
+			directoryType: map[uuid.UUID]int{},
+			nextUID:       1, nextGroupID: 1, nextAttemptID: 1, nextTagID: 1, nextTypeID: 1,
+		},
+	}
+}
+
+// WithTx runs fn as a unit of work, if fn fails every change made to the database while it ran is rolled back.
+// Units of work are run one at a time, note that this provides no isolation from changes made outside of a unit of
+// work (these are also rolled back) but for dev mode that is good enough
+func (db *MemoryDatabase) WithTx(fn func() error) error {
+	db.txLock.Lock()
+	defer db.txLock.Unlock()
+
+	return db.Savepoint(fn)
+}
+
+// Savepoint runs fn as a nested unit of work, if fn fails only the changes it made are rolled back. It must only be
+// called from within WithTx (calling WithTx from within WithTx deadlocks)
+func (db *MemoryDatabase) Savepoint(fn func() error) error {
+	db.lock.RLock()
+	snapshot := db.tables.clone()
+	db.lock.RUnlock()
+
+	if err := fn(); err != nil {
+		db.lock.Lock()
+		db.tables = snapshot
+		db.lock.Unlock()
+
+		return err
+	}
+
+	return nil
+}
+
+// SeedDummyData populates the database with the same dummy data as the 0005_create_dummy_data migration
+func (db *MemoryDatabase) SeedDummyData() error {
+	db.lock.Lock()
+	defer db.lock.Unlock()
+	t := &db.tables
+
+	users := []int{}
+	for _, user := range []struct{ email, name string }{
+		{"z0000000@ad.unsw.edu.au", "adam"}, {"john.smith@gmail.com", "john"}, {"jane.doe@gmail.com", "jane"},
+	} {
+		hashedPassword := sha256.Sum256([]byte("password"))
+		UID, err := t.createPerson(Person{Email: user.email, FirstName: user.name, Password: hex.EncodeToString(hashedPassword[:]), Verified: true})
+		if err != nil {
+			return err
+		}
+
+		users = append(users, UID)
+	}
+
+	// the first group is the admin group (see GROUPS_ADMIN)
+	blogGroup, aboutGroup := t.createGroup("blog_owners"), t.createGroup("about_owners")
+	t.addMember(blogGroup, users[0])
+	t.addMember(aboutGroup, users[1])
+	t.addMember(aboutGroup, users[2])
+
+	frontend, err := t.createFrontend("CSESoc Main Website", "http://localhost:3000")
+	if err != nil {
+		return err
+	}
+
+	var blogDirectory, aboutDirectory uuid.UUID
+	for _, entity := range []struct {
+		parent     *uuid.UUID
+		name       string
+		isDocument bool
+		result     *uuid.UUID
+	}{
+		{&frontend.Root, "downloads", false, nil},
+		{&frontend.Root, "blog_documents", false, &blogDirectory},
+		{&blogDirectory, "cool_document.txt", true, nil},
+		{&blogDirectory, "cool_document2.txt", true, nil},
+		{&frontend.Root, "about_page", false, &aboutDirectory},
+		{&aboutDirectory, "about_projects", false, nil},
+	} {
+		created, err := t.createEntity(memoryEntity{Parent: *entity.parent, LogicalName: entity.name, IsDocument: entity.isDocument, OwnedBy: blogGroup})
+		if err != nil {
+			return err
+		}
+
+		if entity.result != nil {
+			*entity.result = created
+		}
+	}
+
+	return nil
+}
+
+// Operations mirroring the functions defined within the database schema
+
+// createEntity mirrors new_entity, it enforces the unique_name constraint and refuses to make a document a parent
+func (t *memoryTables) createEntity(entity memoryEntity) (uuid.UUID, error) {
+	if parent, ok := t.entities[entity.Parent]; ok && parent.IsDocument {
+		return uuid.Nil, ErrDocumentParent
+	}
+
+	if _, ok := t.groups[entity.OwnedBy]; !ok {
+		return uuid.Nil, ErrUnknownGroup
+	}
+
+	if t.nameTaken(entity.Parent, entity.LogicalName, entity.IsDocument, uuid.Nil) {
+		return uuid.Nil, ErrDuplicateName
+	}
+
+	entity.EntityID = uuid.New()
+	entity.CreatedAt = time.Now()
+	entity.sequence = t.nextSequence
+	t.nextSequence++
+	t.entities[entity.EntityID] = entity
+
+	return entity.EntityID, nil
+}
+
+// deleteEntity mirrors delete_entity, entities with children and roots cannot be deleted
+func (t *memoryTables) deleteEntity(entityID uuid.UUID) error {
+	if len(t.children(entityID)) > 0 {
+		return ErrHasChildren
+	}
+
+	if entity, ok := t.entities[entityID]; ok && entity.Parent == uuid.Nil {
+		return ErrDeleteRoot
+	}
+
+	delete(t.entities, entityID)
+	delete(t.metadata, entityID)
+	delete(t.entityTags, entityID)
+	delete(t.directoryType, entityID)
+	return nil
+}
+
+// createFrontend mirrors new_frontend, it creates a new frontend along with its root directory
+func (t *memoryTables) createFrontend(logicalName string, URL string) (memoryFrontend, error) {
+	root, err := t.createEntity(memoryEntity{Parent: uuid.Nil, LogicalName: logicalName, OwnedBy: GROUPS_ADMIN})
+	if err != nil {
+		return memoryFrontend{}, err
+	}
+
+	frontend := memoryFrontend{ID: uuid.New(), LogicalName: logicalName, URL: URL, Root: root}
+	t.frontends = append(t.frontends, frontend)
+	return frontend, nil
+}
+
+func (t *memoryTables) createPerson(person Person) (int, error) {
+	for _, existing := range t.people {
+		if existing.Email == person.Email {
+			return 0, ErrDuplicateEmail
+		}
+	}
+
+	person.UID = t.nextUID
+	t.nextUID++
+	t.people[person.UID] = person
+
+	return person.UID, nil
+}
+
+func (t *memoryTables) createGroup(name string) int {
+	groupID := t.nextGroupID
+	t.nextGroupID++
+	t.groups[groupID] = name
+
+	return groupID
+}
+
+func (t *memoryTables) addMember(groupID int, UID int) {
+	if t.memberships[UID] == nil {
+		t.memberships[UID] = map[int]bool{}
+	}
+
+	t.memberships[UID][groupID] = true
+}
+
+// nameTaken determines if the unique_name constraint would prevent an entity (other than ignoring) from taking a name
+func (t *memoryTables) nameTaken(parent uuid.UUID, logicalName string, isDocument bool, ignoring uuid.UUID) bool {
+	for _, entity := range t.entities {
+		if entity.EntityID != ignoring && entity.Parent == parent && entity.LogicalName == logicalName && entity.IsDocument == isDocument {
+			return true
+		}
+	}
+
+	return false
+}
+
+// children fetches the IDs of an entity's children in the order they were created
+func (t *memoryTables) children(entityID uuid.UUID) []uuid.UUID {
+	children := []memoryEntity{}
+	for _, entity := range t.entities {
+		if entity.Parent == entityID && entity.EntityID != entityID {
+			children = append(children, entity)
+		}
+	}
+
+	sortEntities(children)
+	childIDs := make([]uuid.UUID, len(children))
+	for i, child := range children {
+		childIDs[i] = child.EntityID
+	}
+
+	return childIDs
+}
+
+// clone deep copies the tables so that they can be restored if a unit of work fails
+func (t memoryTables) clone() memoryTables {
+	cloned := t
+	cloned.entities = cloneMap(t.entities)
+	cloned.frontends = append([]memoryFrontend{}, t.frontends...)
+	cloned.people = cloneMap(t.people)
+	cloned.groups = cloneMap(t.groups)
+	cloned.permissions = append([]memoryPermission{}, t.permissions...)
+	cloned.tokens = cloneMap(t.tokens)
+	cloned.twoFactor = cloneMap(t.twoFactor)
+	cloned.loginAttempts = append([]LoginAttempt{}, t.loginAttempts...)
+
+	cloned.memberships = map[int]map[int]bool{}
+	for UID, groups := range t.memberships {
+		cloned.memberships[UID] = cloneMap(groups)
+	}
+
+	cloned.recoveryCodes = map[int]map[string]bool{}
+	for UID, codes := range t.recoveryCodes {
+		cloned.recoveryCodes[UID] = cloneMap(codes)
+	}
+
+	cloned.tags = cloneMap(t.tags)
+	cloned.metadata = map[uuid.UUID]map[string]string{}
+	for entityID, metadata := range t.metadata {
+		cloned.metadata[entityID] = cloneMap(metadata)
+	}
+
+	cloned.documentTypes = cloneMap(t.documentTypes)
+	cloned.directoryType = cloneMap(t.directoryType)
+
+	cloned.entityTags = map[uuid.UUID]map[int]bool{}
+	for entityID, tags := range t.entityTags {
+		cloned.entityTags[entityID] = cloneMap(tags)
+	}
+
+	return cloned
+}
+
+func cloneMap[K comparable, V any](source map[K]V) map[K]V {
+	cloned := make(map[K]V, len(source))
+	for key, value := range source {
+		cloned[key] = value
+	}
+
+	return cloned
+}
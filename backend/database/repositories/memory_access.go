@@ -0,0 +1,117 @@
+package repositories
+
+import (
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// Implements GroupsRepository
+type memoryGroupsRepository struct {
+	db *MemoryDatabase
+}
+
+// NewMemoryGroupsRepo instantiates a new in-memory groups repository
+func NewMemoryGroupsRepo(db *MemoryDatabase) GroupsRepository {
+	return memoryGroupsRepository{db}
+}
+
+func (rep memoryGroupsRepository) GetGroupInfo(g Groups) Groups {
+	rep.db.lock.RLock()
+	defer rep.db.lock.RUnlock()
+
+	for groupID, name := range rep.db.tables.groups {
+		if name == g.Name {
+			return Groups{UID: groupID, Name: name}
+		}
+	}
+
+	return Groups{}
+}
+
+func (rep memoryGroupsRepository) IsMemberOf(UID int, groupID int) (bool, error) {
+	rep.db.lock.RLock()
+	defer rep.db.lock.RUnlock()
+
+	return rep.db.tables.memberships[UID][groupID], nil
+}
+
+// Implements FrontendsRepository
+type memoryFrontendsRepository struct {
+	db *MemoryDatabase
+}
+
+// NewMemoryFrontendsRepo instantiates a new in-memory frontends repository
+func NewMemoryFrontendsRepo(db *MemoryDatabase) FrontendsRepository {
+	return memoryFrontendsRepository{db}
+}
+
+// GetFrontendFromURL always returns InvalidFrontend, frontend IDs are UUIDs so (like the SQL implementation) they
+// can't be represented by the int this returns
+func (rep memoryFrontendsRepository) GetFrontendFromURL(url string) int {
+	return InvalidFrontend
+}
+
+func (rep memoryFrontendsRepository) RequiresAdminTwoFactor(frontendID uuid.UUID) (bool, error) {
+	rep.db.lock.RLock()
+	defer rep.db.lock.RUnlock()
+
+	anyRequired := false
+	for _, frontend := range rep.db.tables.frontends {
+		if frontend.ID == frontendID {
+			return frontend.RequireAdmin2FA, nil
+		}
+
+		anyRequired = anyRequired || frontend.RequireAdmin2FA
+	}
+
+	return anyRequired, nil
+}
+
+func (rep memoryFrontendsRepository) IsRegisteredURL(url string) (bool, error) {
+	rep.db.lock.RLock()
+	defer rep.db.lock.RUnlock()
+
+	for _, frontend := range rep.db.tables.frontends {
+		if strings.TrimRight(frontend.URL, "/") == strings.TrimRight(url, "/") {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// Implements PermissionsRepository
+type memoryPermissionsRepository struct {
+	db *MemoryDatabase
+}
+
+// NewMemoryPermissionsRepo instantiates a new in-memory permissions repository
+func NewMemoryPermissionsRepo(db *MemoryDatabase) PermissionsRepository {
+	return memoryPermissionsRepository{db}
+}
+
+// GetPermission mirrors the SQL implementation, see permissionsRepository.GetPermission
+func (rep memoryPermissionsRepository) GetPermission(UID int, entityID uuid.UUID) (Permission, error) {
+	rep.db.lock.RLock()
+	defer rep.db.lock.RUnlock()
+
+	memberships := rep.db.tables.memberships[UID]
+	ancestors := map[uuid.UUID]bool{}
+	for entity, ok := rep.db.tables.entities[entityID]; ok; entity, ok = rep.db.tables.entities[entity.Parent] {
+		if memberships[entity.OwnedBy] || memberships[GROUPS_ADMIN] {
+			return PermissionDelete, nil
+		}
+
+		ancestors[entity.EntityID] = true
+	}
+
+	highest := PermissionNone
+	for _, permission := range rep.db.tables.permissions {
+		if ancestors[permission.EntityID] && memberships[permission.GroupID] && !highest.Allows(permission.Permission) {
+			highest = permission.Permission
+		}
+	}
+
+	return highest, nil
+}
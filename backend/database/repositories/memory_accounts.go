@@ -0,0 +1,250 @@
+package repositories
+
+import (
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Implements PersonRepository
+type memoryPersonRepository struct {
+	db *MemoryDatabase
+}
+
+// NewMemoryPersonRepo instantiates a new in-memory person repository
+func NewMemoryPersonRepo(db *MemoryDatabase) PersonRepository {
+	return memoryPersonRepository{db}
+}
+
+func (rep memoryPersonRepository) PersonExists(p Person) bool {
+	return rep.GetPersonWithDetails(p).UID != 0
+}
+
+func (rep memoryPersonRepository) GetPersonWithDetails(p Person) Person {
+	person, err := rep.GetPersonWithEmail(p.Email)
+	if err != nil || person.Password != p.Password {
+		return Person{}
+	}
+
+	return person
+}
+
+func (rep memoryPersonRepository) GetPersonWithEmail(email string) (Person, error) {
+	rep.db.lock.RLock()
+	defer rep.db.lock.RUnlock()
+
+	for _, person := range rep.db.tables.people {
+		if person.Email == email {
+			return person, nil
+		}
+	}
+
+	return Person{}, errNotFound
+}
+
+func (rep memoryPersonRepository) SetPassword(UID int, hashedPassword string) error {
+	return rep.update(UID, func(person *Person) { person.Password = hashedPassword })
+}
+
+func (rep memoryPersonRepository) MarkAsVerified(UID int) error {
+	return rep.update(UID, func(person *Person) { person.Verified = true })
+}
+
+// update applies an update to a person, like an UPDATE it does nothing if the person doesn't exist
+func (rep memoryPersonRepository) update(UID int, apply func(*Person)) error {
+	rep.db.lock.Lock()
+	defer rep.db.lock.Unlock()
+
+	if person, ok := rep.db.tables.people[UID]; ok {
+		apply(&person)
+		rep.db.tables.people[UID] = person
+	}
+
+	return nil
+}
+
+// Implements TokensRepository
+type memoryTokensRepository struct {
+	db *MemoryDatabase
+}
+
+// NewMemoryTokensRepo instantiates a new in-memory tokens repository
+func NewMemoryTokensRepo(db *MemoryDatabase) TokensRepository {
+	return memoryTokensRepository{db}
+}
+
+func (rep memoryTokensRepository) CreateToken(token AuthToken) error {
+	rep.db.lock.Lock()
+	defer rep.db.lock.Unlock()
+
+	rep.db.tables.tokens[token.TokenID] = memoryToken{AuthToken: token}
+	return nil
+}
+
+func (rep memoryTokensRepository) RedeemToken(tokenID uuid.UUID, purpose string) (AuthToken, error) {
+	rep.db.lock.Lock()
+	defer rep.db.lock.Unlock()
+
+	token, ok := rep.db.tables.tokens[tokenID]
+	if !ok || token.Purpose != purpose || token.Used || !token.ExpiresAt.After(time.Now()) {
+		return AuthToken{}, errNotFound
+	}
+
+	token.Used = true
+	rep.db.tables.tokens[tokenID] = token
+	return token.AuthToken, nil
+}
+
+func (rep memoryTokensRepository) InvalidateTokensForPerson(UID int, purpose string) error {
+	rep.db.lock.Lock()
+	defer rep.db.lock.Unlock()
+
+	for tokenID, token := range rep.db.tables.tokens {
+		if token.UID == UID && token.Purpose == purpose {
+			token.Used = true
+			rep.db.tables.tokens[tokenID] = token
+		}
+	}
+
+	return nil
+}
+
+// Implements TwoFactorRepository
+type memoryTwoFactorRepository struct {
+	db *MemoryDatabase
+}
+
+// NewMemoryTwoFactorRepo instantiates a new in-memory 2FA repository
+func NewMemoryTwoFactorRepo(db *MemoryDatabase) TwoFactorRepository {
+	return memoryTwoFactorRepository{db}
+}
+
+func (rep memoryTwoFactorRepository) GetTwoFactor(UID int) (TwoFactor, error) {
+	rep.db.lock.RLock()
+	defer rep.db.lock.RUnlock()
+
+	if twoFactor, ok := rep.db.tables.twoFactor[UID]; ok {
+		return twoFactor.TwoFactor, nil
+	}
+
+	return TwoFactor{UID: UID}, nil
+}
+
+func (rep memoryTwoFactorRepository) BeginEnrolment(UID int, secret string) error {
+	rep.db.lock.Lock()
+	defer rep.db.lock.Unlock()
+
+	if existing, ok := rep.db.tables.twoFactor[UID]; !ok || !existing.Enabled {
+		rep.db.tables.twoFactor[UID] = memoryTwoFactor{TwoFactor: TwoFactor{UID: UID, Secret: secret}}
+	}
+
+	return nil
+}
+
+func (rep memoryTwoFactorRepository) Enable(UID int, step int64, recoveryCodeHashes []string) error {
+	rep.db.lock.Lock()
+	defer rep.db.lock.Unlock()
+
+	if twoFactor, ok := rep.db.tables.twoFactor[UID]; ok {
+		twoFactor.Enabled = true
+		twoFactor.LastUsedStep = &step
+		rep.db.tables.twoFactor[UID] = twoFactor
+	}
+
+	codes := map[string]bool{}
+	for _, hash := range recoveryCodeHashes {
+		codes[hash] = false
+	}
+
+	rep.db.tables.recoveryCodes[UID] = codes
+	return nil
+}
+
+func (rep memoryTwoFactorRepository) Disable(UID int) error {
+	rep.db.lock.Lock()
+	defer rep.db.lock.Unlock()
+
+	delete(rep.db.tables.twoFactor, UID)
+	delete(rep.db.tables.recoveryCodes, UID)
+	return nil
+}
+
+func (rep memoryTwoFactorRepository) MarkStepUsed(UID int, step int64) (bool, error) {
+	rep.db.lock.Lock()
+	defer rep.db.lock.Unlock()
+
+	twoFactor, ok := rep.db.tables.twoFactor[UID]
+	if !ok || (twoFactor.LastUsedStep != nil && *twoFactor.LastUsedStep >= step) {
+		return false, nil
+	}
+
+	twoFactor.LastUsedStep = &step
+	rep.db.tables.twoFactor[UID] = twoFactor
+	return true, nil
+}
+
+func (rep memoryTwoFactorRepository) ConsumeRecoveryCode(UID int, codeHash string) (bool, error) {
+	rep.db.lock.Lock()
+	defer rep.db.lock.Unlock()
+
+	used, ok := rep.db.tables.recoveryCodes[UID][codeHash]
+	if !ok || used {
+		return false, nil
+	}
+
+	rep.db.tables.recoveryCodes[UID][codeHash] = true
+	return true, nil
+}
+
+// Implements LoginAttemptsRepository
+type memoryLoginAttemptsRepository struct {
+	db *MemoryDatabase
+}
+
+// NewMemoryLoginAttemptsRepo instantiates a new in-memory login attempts repository
+func NewMemoryLoginAttemptsRepo(db *MemoryDatabase) LoginAttemptsRepository {
+	return memoryLoginAttemptsRepository{db}
+}
+
+func (rep memoryLoginAttemptsRepository) RecordAttempt(attempt LoginAttempt) error {
+	rep.db.lock.Lock()
+	defer rep.db.lock.Unlock()
+
+	attempt.AttemptID = rep.db.tables.nextAttemptID
+	attempt.AttemptedAt = time.Now()
+	attempt.Email, attempt.IPAddress = truncate(attempt.Email, maxAttemptEmailLength), truncate(attempt.IPAddress, maxAttemptAddressLength)
+	rep.db.tables.nextAttemptID++
+	rep.db.tables.loginAttempts = append(rep.db.tables.loginAttempts, attempt)
+
+	return nil
+}
+
+func (rep memoryLoginAttemptsRepository) GetAttempts(filter LoginAttemptFilter) ([]LoginAttempt, error) {
+	rep.db.lock.RLock()
+	defer rep.db.lock.RUnlock()
+
+	attempts := []LoginAttempt{}
+	for _, attempt := range rep.db.tables.loginAttempts {
+		if (filter.Email == "" || filter.Email == attempt.Email) &&
+			(filter.IPAddress == "" || filter.IPAddress == attempt.IPAddress) &&
+			(!filter.OnlyFailures || !attempt.Successful) &&
+			(filter.Since.IsZero() || !attempt.AttemptedAt.Before(filter.Since)) {
+			attempts = append(attempts, attempt)
+		}
+	}
+
+	// most recent first
+	sort.SliceStable(attempts, func(i, j int) bool { return attempts[i].AttemptID > attempts[j].AttemptID })
+
+	limit := filter.Limit
+	if limit <= 0 || limit > maxLoginAttempts {
+		limit = maxLoginAttempts
+	}
+
+	if len(attempts) > limit {
+		attempts = attempts[:limit]
+	}
+
+	return attempts, nil
+}
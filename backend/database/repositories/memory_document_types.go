@@ -0,0 +1,94 @@
+package repositories
+
+import (
+	"sort"
+
+	"github.com/google/uuid"
+)
+
+// Implements DocumentTypesRepository
+type memoryDocumentTypesRepository struct {
+	db *MemoryDatabase
+}
+
+// NewMemoryDocumentTypesRepo instantiates a new in-memory document types repository
+func NewMemoryDocumentTypesRepo(db *MemoryDatabase) DocumentTypesRepository {
+	return memoryDocumentTypesRepository{db}
+}
+
+func (rep memoryDocumentTypesRepository) GetDocumentTypes() ([]DocumentType, error) {
+	rep.db.lock.RLock()
+	defer rep.db.lock.RUnlock()
+
+	documentTypes := []DocumentType{}
+	for _, documentType := range rep.db.tables.documentTypes {
+		documentTypes = append(documentTypes, documentType)
+	}
+
+	sort.Slice(documentTypes, func(i, j int) bool { return documentTypes[i].TypeID < documentTypes[j].TypeID })
+	return documentTypes, nil
+}
+
+func (rep memoryDocumentTypesRepository) CreateDocumentType(documentType DocumentType) (DocumentType, error) {
+	rep.db.lock.Lock()
+	defer rep.db.lock.Unlock()
+
+	for _, existing := range rep.db.tables.documentTypes {
+		if existing.Name == documentType.Name {
+			return DocumentType{}, ErrDuplicateType
+		}
+	}
+
+	documentType.TypeID = rep.db.tables.nextTypeID
+	documentType.Fields = append([]DocumentField{}, documentType.Fields...)
+	rep.db.tables.nextTypeID++
+	rep.db.tables.documentTypes[documentType.TypeID] = documentType
+
+	return documentType, nil
+}
+
+func (rep memoryDocumentTypesRepository) DeleteDocumentType(typeID int) error {
+	rep.db.lock.Lock()
+	defer rep.db.lock.Unlock()
+
+	delete(rep.db.tables.documentTypes, typeID)
+	for directoryID, attached := range rep.db.tables.directoryType {
+		if attached == typeID {
+			delete(rep.db.tables.directoryType, directoryID)
+		}
+	}
+
+	return nil
+}
+
+func (rep memoryDocumentTypesRepository) GetDirectoryType(directoryID uuid.UUID) (DocumentType, error) {
+	rep.db.lock.RLock()
+	defer rep.db.lock.RUnlock()
+
+	if typeID, ok := rep.db.tables.directoryType[directoryID]; ok {
+		return rep.db.tables.documentTypes[typeID], nil
+	}
+
+	return DocumentType{TypeID: NoDocumentType, Fields: []DocumentField{}}, nil
+}
+
+func (rep memoryDocumentTypesRepository) SetDirectoryType(directoryID uuid.UUID, typeID int) error {
+	rep.db.lock.Lock()
+	defer rep.db.lock.Unlock()
+
+	if typeID == NoDocumentType {
+		delete(rep.db.tables.directoryType, directoryID)
+		return nil
+	}
+
+	if _, ok := rep.db.tables.documentTypes[typeID]; !ok {
+		return ErrUnknownType
+	}
+
+	if entity, ok := rep.db.tables.entities[directoryID]; !ok || entity.IsDocument {
+		return errNotFound
+	}
+
+	rep.db.tables.directoryType[directoryID] = typeID
+	return nil
+}
@@ -0,0 +1,200 @@
+package repositories
+
+import (
+	"errors"
+	"sort"
+	"strings"
+
+	"cms.csesoc.unsw.edu.au/database/contexts"
+	"github.com/google/uuid"
+)
+
+// Implements FilesystemRepository
+type memoryFilesystemRepository struct {
+	frontend memoryFrontend
+	db       *MemoryDatabase
+}
+
+// NewMemoryFilesystemRepo instantiates a filesystem repository for the frontend with the provided URL, unlike
+// NewFilesystemRepo an existing frontend is reused (rather than a new one being created every time)
+func NewMemoryFilesystemRepo(logicalName string, URL string, db *MemoryDatabase) (FilesystemRepository, error) {
+	db.lock.Lock()
+	defer db.lock.Unlock()
+
+	for _, frontend := range db.tables.frontends {
+		if frontend.URL == URL {
+			return memoryFilesystemRepository{frontend, db}, nil
+		}
+	}
+
+	frontend, err := db.tables.createFrontend(logicalName, URL)
+	if err != nil {
+		return nil, err
+	}
+
+	return memoryFilesystemRepository{frontend, db}, nil
+}
+
+func (rep memoryFilesystemRepository) CreateEntry(file FilesystemEntry) (FilesystemEntry, error) {
+	rep.db.lock.Lock()
+	defer rep.db.lock.Unlock()
+
+	newID, err := rep.db.tables.createEntity(memoryEntity{
+		LogicalName: file.LogicalName,
+		IsDocument:  file.IsDocument,
+		OwnedBy:     file.OwnerUserId,
+		Parent:      file.ParentFileID,
+	})
+	if err != nil {
+		return FilesystemEntry{}, err
+	}
+
+	return rep.toEntry(rep.db.tables.entities[newID]), nil
+}
+
+func (rep memoryFilesystemRepository) GetEntryWithID(ID uuid.UUID) (FilesystemEntry, error) {
+	return rep.findEntry(func(entity memoryEntity) bool { return entity.EntityID == ID })
+}
+
+func (rep memoryFilesystemRepository) GetRoot() (FilesystemEntry, error) {
+	return rep.GetEntryWithID(rep.frontend.Root)
+}
+
+func (rep memoryFilesystemRepository) GetEntryWithParentID(ID uuid.UUID) (FilesystemEntry, error) {
+	return rep.findEntry(func(entity memoryEntity) bool { return entity.Parent == ID })
+}
+
+func (rep memoryFilesystemRepository) GetIDWithPath(path string) (uuid.UUID, error) {
+	parentNames := strings.Split(path, "/")
+	if parentNames[0] != "" {
+		return uuid.Nil, errors.New("path must start with /")
+	} else if path == "/" {
+		parentNames = parentNames[:1]
+	}
+
+	parent := rep.frontend.Root
+	for _, name := range parentNames[1:] {
+		child, err := rep.findEntry(func(entity memoryEntity) bool { return entity.LogicalName == name && entity.Parent == parent })
+		if err != nil {
+			return uuid.Nil, err
+		}
+
+		parent = child.EntityID
+	}
+
+	return parent, nil
+}
+
+func (rep memoryFilesystemRepository) GetSubtree(ID uuid.UUID, depth int) ([]FilesystemEntry, error) {
+	rep.db.lock.RLock()
+	defer rep.db.lock.RUnlock()
+
+	entity, ok := rep.db.tables.entities[ID]
+	if !ok || depth < 0 {
+		return nil, errNotFound
+	}
+
+	// breadth first, so like the SQL implementation entries are ordered by their depth
+	subtree := []FilesystemEntry{rep.toEntry(entity)}
+	level := subtree
+	for currentDepth := 1; currentDepth <= depth; currentDepth++ {
+		nextLevel := []FilesystemEntry{}
+		for _, parent := range level {
+			for _, childID := range parent.ChildrenIDs {
+				nextLevel = append(nextLevel, rep.toEntry(rep.db.tables.entities[childID]))
+			}
+		}
+
+		subtree = append(subtree, nextLevel...)
+		level = nextLevel
+	}
+
+	return subtree, nil
+}
+
+func (rep memoryFilesystemRepository) GetBreadcrumbs(ID uuid.UUID) ([]FilesystemEntry, error) {
+	rep.db.lock.RLock()
+	defer rep.db.lock.RUnlock()
+
+	breadcrumbs := []FilesystemEntry{}
+	for entity, ok := rep.db.tables.entities[ID]; ok; entity, ok = rep.db.tables.entities[entity.Parent] {
+		breadcrumb := rep.toEntry(entity)
+		breadcrumb.ChildrenIDs = nil
+		breadcrumbs = append([]FilesystemEntry{breadcrumb}, breadcrumbs...)
+	}
+
+	if len(breadcrumbs) == 0 {
+		return nil, errNotFound
+	}
+
+	return breadcrumbs, nil
+}
+
+func (rep memoryFilesystemRepository) DeleteEntryWithID(ID uuid.UUID) error {
+	rep.db.lock.Lock()
+	defer rep.db.lock.Unlock()
+
+	return rep.db.tables.deleteEntity(ID)
+}
+
+func (rep memoryFilesystemRepository) RenameEntity(ID uuid.UUID, name string) error {
+	rep.db.lock.Lock()
+	defer rep.db.lock.Unlock()
+
+	entity, ok := rep.db.tables.entities[ID]
+	if !ok {
+		return nil
+	}
+
+	if rep.db.tables.nameTaken(entity.Parent, name, entity.IsDocument, ID) {
+		return ErrDuplicateName
+	}
+
+	entity.LogicalName = name
+	rep.db.tables.entities[ID] = entity
+	return nil
+}
+
+// GetContext has no meaning for an in-memory repository as there is no database behind it
+func (rep memoryFilesystemRepository) GetContext() contexts.DatabaseContext {
+	return nil
+}
+
+// findEntry finds the first (oldest) entity matching the predicate
+func (rep memoryFilesystemRepository) findEntry(matches func(memoryEntity) bool) (FilesystemEntry, error) {
+	rep.db.lock.RLock()
+	defer rep.db.lock.RUnlock()
+
+	candidates := []memoryEntity{}
+	for _, entity := range rep.db.tables.entities {
+		if matches(entity) {
+			candidates = append(candidates, entity)
+		}
+	}
+
+	if len(candidates) == 0 {
+		return FilesystemEntry{}, errNotFound
+	}
+
+	sortEntities(candidates)
+	return rep.toEntry(candidates[0]), nil
+}
+
+// toEntry converts an entity to the model the repository returns, the database must be locked
+func (rep memoryFilesystemRepository) toEntry(entity memoryEntity) FilesystemEntry {
+	return FilesystemEntry{
+		EntityID:     entity.EntityID,
+		LogicalName:  entity.LogicalName,
+		IsDocument:   entity.IsDocument,
+		IsPublished:  entity.IsPublished,
+		CreatedAt:    entity.CreatedAt,
+		OwnerUserId:  entity.OwnedBy,
+		ParentFileID: entity.Parent,
+		ChildrenIDs:  rep.db.tables.children(entity.EntityID),
+	}
+}
+
+// sortEntities sorts entities into the order they were inserted in, the order rows are (typically) returned in by Postgres
+func sortEntities(entities []memoryEntity) {
+	sort.Slice(entities, func(i, j int) bool { return entities[i].sequence < entities[j].sequence })
+}
@@ -0,0 +1,184 @@
+package repositories
+
+import (
+	"sort"
+
+	"github.com/google/uuid"
+)
+
+// Implements MetadataRepository
+type memoryMetadataRepository struct {
+	db *MemoryDatabase
+}
+
+// NewMemoryMetadataRepo instantiates a new in-memory metadata repository
+func NewMemoryMetadataRepo(db *MemoryDatabase) MetadataRepository {
+	return memoryMetadataRepository{db}
+}
+
+func (rep memoryMetadataRepository) GetMetadata(entityID uuid.UUID) (map[string]string, error) {
+	rep.db.lock.RLock()
+	defer rep.db.lock.RUnlock()
+
+	return cloneMap(rep.db.tables.metadata[entityID]), nil
+}
+
+func (rep memoryMetadataRepository) SetMetadata(entityID uuid.UUID, key string, value string) error {
+	rep.db.lock.Lock()
+	defer rep.db.lock.Unlock()
+
+	if _, ok := rep.db.tables.entities[entityID]; !ok {
+		return ErrUnknownEntity
+	}
+
+	if rep.db.tables.metadata[entityID] == nil {
+		rep.db.tables.metadata[entityID] = map[string]string{}
+	}
+
+	rep.db.tables.metadata[entityID][key] = value
+	return nil
+}
+
+func (rep memoryMetadataRepository) DeleteMetadata(entityID uuid.UUID, key string) error {
+	rep.db.lock.Lock()
+	defer rep.db.lock.Unlock()
+
+	delete(rep.db.tables.metadata[entityID], key)
+	return nil
+}
+
+// Implements TagsRepository
+type memoryTagsRepository struct {
+	db *MemoryDatabase
+}
+
+// NewMemoryTagsRepo instantiates a new in-memory tags repository
+func NewMemoryTagsRepo(db *MemoryDatabase) TagsRepository {
+	return memoryTagsRepository{db}
+}
+
+func (rep memoryTagsRepository) GetTags() ([]Tag, error) {
+	rep.db.lock.RLock()
+	defer rep.db.lock.RUnlock()
+
+	return rep.sortedTags(func(Tag) bool { return true }), nil
+}
+
+func (rep memoryTagsRepository) CreateTag(name string, parent int) (Tag, error) {
+	rep.db.lock.Lock()
+	defer rep.db.lock.Unlock()
+
+	if _, ok := rep.db.tables.tags[parent]; !ok && parent != NoParentTag {
+		return Tag{}, ErrUnknownTag
+	}
+
+	for _, tag := range rep.db.tables.tags {
+		if tag.Parent == parent && tag.Name == name {
+			return Tag{}, ErrDuplicateTag
+		}
+	}
+
+	tag := Tag{TagID: rep.db.tables.nextTagID, Name: name, Parent: parent}
+	rep.db.tables.nextTagID++
+	rep.db.tables.tags[tag.TagID] = tag
+
+	return tag, nil
+}
+
+// DeleteTag deletes a tag, like ON DELETE CASCADE the tags nested within it are also deleted
+func (rep memoryTagsRepository) DeleteTag(tagID int) error {
+	rep.db.lock.Lock()
+	defer rep.db.lock.Unlock()
+
+	for _, nestedID := range rep.db.tables.nestedTags(tagID) {
+		delete(rep.db.tables.tags, nestedID)
+		for _, tags := range rep.db.tables.entityTags {
+			delete(tags, nestedID)
+		}
+	}
+
+	return nil
+}
+
+func (rep memoryTagsRepository) GetEntityTags(entityID uuid.UUID) ([]Tag, error) {
+	rep.db.lock.RLock()
+	defer rep.db.lock.RUnlock()
+
+	entityTags := rep.db.tables.entityTags[entityID]
+	return rep.sortedTags(func(tag Tag) bool { return entityTags[tag.TagID] }), nil
+}
+
+func (rep memoryTagsRepository) TagEntity(entityID uuid.UUID, tagID int) error {
+	rep.db.lock.Lock()
+	defer rep.db.lock.Unlock()
+
+	if _, ok := rep.db.tables.entities[entityID]; !ok {
+		return ErrUnknownEntity
+	} else if _, ok := rep.db.tables.tags[tagID]; !ok {
+		return ErrUnknownTag
+	}
+
+	if rep.db.tables.entityTags[entityID] == nil {
+		rep.db.tables.entityTags[entityID] = map[int]bool{}
+	}
+
+	rep.db.tables.entityTags[entityID][tagID] = true
+	return nil
+}
+
+func (rep memoryTagsRepository) UntagEntity(entityID uuid.UUID, tagID int) error {
+	rep.db.lock.Lock()
+	defer rep.db.lock.Unlock()
+
+	delete(rep.db.tables.entityTags[entityID], tagID)
+	return nil
+}
+
+func (rep memoryTagsRepository) GetChildrenWithTag(parentID uuid.UUID, tagID int) ([]uuid.UUID, error) {
+	rep.db.lock.RLock()
+	defer rep.db.lock.RUnlock()
+
+	nested := rep.db.tables.nestedTags(tagID)
+	children := []uuid.UUID{}
+	for _, childID := range rep.db.tables.children(parentID) {
+		for _, nestedID := range nested {
+			if rep.db.tables.entityTags[childID][nestedID] {
+				children = append(children, childID)
+				break
+			}
+		}
+	}
+
+	return children, nil
+}
+
+// sortedTags fetches the tags matching a predicate ordered by their IDs, the database must be locked
+func (rep memoryTagsRepository) sortedTags(matches func(Tag) bool) []Tag {
+	tags := []Tag{}
+	for _, tag := range rep.db.tables.tags {
+		if matches(tag) {
+			tags = append(tags, tag)
+		}
+	}
+
+	sort.Slice(tags, func(i, j int) bool { return tags[i].TagID < tags[j].TagID })
+	return tags
+}
+
+// nestedTags fetches the IDs of a tag and every tag nested (however deeply) within it
+func (t *memoryTables) nestedTags(tagID int) []int {
+	if _, ok := t.tags[tagID]; !ok {
+		return []int{}
+	}
+
+	nested := []int{tagID}
+	for i := 0; i < len(nested); i++ {
+		for _, tag := range t.tags {
+			if tag.Parent == nested[i] {
+				nested = append(nested, tag.TagID)
+			}
+		}
+	}
+
+	return nested
+}
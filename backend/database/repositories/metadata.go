@@ -0,0 +1,43 @@
+package repositories
+
+import (
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v4"
+)
+
+// Implements MetadataRepository, metadata is stored within a hstore so it is read as a pair of key/value arrays
+type metadataRepository struct {
+	embeddedContext
+}
+
+// GetMetadata fetches all the metadata attached to an entity, entities without any metadata have an empty map
+func (rep metadataRepository) GetMetadata(entityID uuid.UUID) (map[string]string, error) {
+	var keys, values []string
+	err := rep.db.Query(rep.ctx, "SELECT akeys(Metadata), avals(Metadata) FROM entity_metadata WHERE EntityID = $1;",
+		[]interface{}{entityID}, &keys, &values)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return nil, err
+	}
+
+	metadata := map[string]string{}
+	for i, key := range keys {
+		metadata[key] = values[i]
+	}
+
+	return metadata, nil
+}
+
+// SetMetadata sets (or overwrites) a single key within an entity's metadata
+func (rep metadataRepository) SetMetadata(entityID uuid.UUID, key string, value string) error {
+	return rep.db.Exec(rep.ctx, `INSERT INTO entity_metadata (EntityID, Metadata) VALUES ($1, hstore($2::text, $3::text))
+			ON CONFLICT (EntityID) DO UPDATE SET Metadata = entity_metadata.Metadata || EXCLUDED.Metadata;`,
+		[]interface{}{entityID, key, value})
+}
+
+// DeleteMetadata removes a single key from an entity's metadata
+func (rep metadataRepository) DeleteMetadata(entityID uuid.UUID, key string) error {
+	return rep.db.Exec(rep.ctx, "UPDATE entity_metadata SET Metadata = delete(Metadata, $2::text) WHERE EntityID = $1;",
+		[]interface{}{entityID, key})
+}
@@ -66,6 +66,21 @@ func (mr *MockIFilesystemRepositoryMockRecorder) DeleteEntryWithID(ID interface{
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteEntryWithID", reflect.TypeOf((*MockIFilesystemRepository)(nil).DeleteEntryWithID), ID)
 }
 
+// GetBreadcrumbs mocks base method.
+func (m *MockIFilesystemRepository) GetBreadcrumbs(ID uuid.UUID) ([]repositories.FilesystemEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBreadcrumbs", ID)
+	ret0, _ := ret[0].([]repositories.FilesystemEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBreadcrumbs indicates an expected call of GetBreadcrumbs.
+func (mr *MockIFilesystemRepositoryMockRecorder) GetBreadcrumbs(ID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBreadcrumbs", reflect.TypeOf((*MockIFilesystemRepository)(nil).GetBreadcrumbs), ID)
+}
+
 // GetContext mocks base method.
 func (m *MockIFilesystemRepository) GetContext() contexts.DatabaseContext {
 	m.ctrl.T.Helper()
@@ -140,6 +155,21 @@ func (mr *MockIFilesystemRepositoryMockRecorder) GetRoot() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRoot", reflect.TypeOf((*MockIFilesystemRepository)(nil).GetRoot))
 }
 
+// GetSubtree mocks base method.
+func (m *MockIFilesystemRepository) GetSubtree(ID uuid.UUID, depth int) ([]repositories.FilesystemEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSubtree", ID, depth)
+	ret0, _ := ret[0].([]repositories.FilesystemEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSubtree indicates an expected call of GetSubtree.
+func (mr *MockIFilesystemRepositoryMockRecorder) GetSubtree(ID, depth interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSubtree", reflect.TypeOf((*MockIFilesystemRepository)(nil).GetSubtree), ID, depth)
+}
+
 // RenameEntity mocks base method.
 func (m *MockIFilesystemRepository) RenameEntity(ID uuid.UUID, name string) error {
 	m.ctrl.T.Helper()
@@ -249,6 +279,21 @@ func (mr *MockIUnpublishedVolumeRepositoryMockRecorder) GetFromVolumeTruncated(f
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFromVolumeTruncated", reflect.TypeOf((*MockIUnpublishedVolumeRepository)(nil).GetFromVolumeTruncated), filename)
 }
 
+// ReadFromVolume mocks base method.
+func (m *MockIUnpublishedVolumeRepository) ReadFromVolume(filename string) (*os.File, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadFromVolume", filename)
+	ret0, _ := ret[0].(*os.File)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadFromVolume indicates an expected call of ReadFromVolume.
+func (mr *MockIUnpublishedVolumeRepositoryMockRecorder) ReadFromVolume(filename interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadFromVolume", reflect.TypeOf((*MockIUnpublishedVolumeRepository)(nil).ReadFromVolume), filename)
+}
+
 // MockIPublishedVolumeRepository is a mock of IPublishedVolumeRepository interface.
 type MockIPublishedVolumeRepository struct {
 	ctrl     *gomock.Controller
@@ -344,6 +389,21 @@ func (mr *MockIPublishedVolumeRepositoryMockRecorder) GetFromVolumeTruncated(fil
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFromVolumeTruncated", reflect.TypeOf((*MockIPublishedVolumeRepository)(nil).GetFromVolumeTruncated), filename)
 }
 
+// ReadFromVolume mocks base method.
+func (m *MockIPublishedVolumeRepository) ReadFromVolume(filename string) (*os.File, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadFromVolume", filename)
+	ret0, _ := ret[0].(*os.File)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadFromVolume indicates an expected call of ReadFromVolume.
+func (mr *MockIPublishedVolumeRepositoryMockRecorder) ReadFromVolume(filename interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadFromVolume", reflect.TypeOf((*MockIPublishedVolumeRepository)(nil).ReadFromVolume), filename)
+}
+
 // MockIPersonRepository is a mock of IPersonRepository interface.
 type MockIPersonRepository struct {
 	ctrl     *gomock.Controller
@@ -66,6 +66,21 @@ func (mr *MockFilesystemRepositoryMockRecorder) DeleteEntryWithID(ID interface{}
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteEntryWithID", reflect.TypeOf((*MockFilesystemRepository)(nil).DeleteEntryWithID), ID)
 }
 
+// GetBreadcrumbs mocks base method.
+func (m *MockFilesystemRepository) GetBreadcrumbs(ID uuid.UUID) ([]repositories.FilesystemEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBreadcrumbs", ID)
+	ret0, _ := ret[0].([]repositories.FilesystemEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBreadcrumbs indicates an expected call of GetBreadcrumbs.
+func (mr *MockFilesystemRepositoryMockRecorder) GetBreadcrumbs(ID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBreadcrumbs", reflect.TypeOf((*MockFilesystemRepository)(nil).GetBreadcrumbs), ID)
+}
+
 // GetContext mocks base method.
 func (m *MockFilesystemRepository) GetContext() contexts.DatabaseContext {
 	m.ctrl.T.Helper()
@@ -140,6 +155,21 @@ func (mr *MockFilesystemRepositoryMockRecorder) GetRoot() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRoot", reflect.TypeOf((*MockFilesystemRepository)(nil).GetRoot))
 }
 
+// GetSubtree mocks base method.
+func (m *MockFilesystemRepository) GetSubtree(ID uuid.UUID, depth int) ([]repositories.FilesystemEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSubtree", ID, depth)
+	ret0, _ := ret[0].([]repositories.FilesystemEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSubtree indicates an expected call of GetSubtree.
+func (mr *MockFilesystemRepositoryMockRecorder) GetSubtree(ID, depth interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSubtree", reflect.TypeOf((*MockFilesystemRepository)(nil).GetSubtree), ID, depth)
+}
+
 // RenameEntity mocks base method.
 func (m *MockFilesystemRepository) RenameEntity(ID uuid.UUID, name string) error {
 	m.ctrl.T.Helper()
@@ -249,6 +279,21 @@ func (mr *MockUnpublishedVolumeRepositoryMockRecorder) GetFromVolumeTruncated(fi
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFromVolumeTruncated", reflect.TypeOf((*MockUnpublishedVolumeRepository)(nil).GetFromVolumeTruncated), filename)
 }
 
+// ReadFromVolume mocks base method.
+func (m *MockUnpublishedVolumeRepository) ReadFromVolume(filename string) (*os.File, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadFromVolume", filename)
+	ret0, _ := ret[0].(*os.File)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadFromVolume indicates an expected call of ReadFromVolume.
+func (mr *MockUnpublishedVolumeRepositoryMockRecorder) ReadFromVolume(filename interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadFromVolume", reflect.TypeOf((*MockUnpublishedVolumeRepository)(nil).ReadFromVolume), filename)
+}
+
 // MockPublishedVolumeRepository is a mock of PublishedVolumeRepository interface.
 type MockPublishedVolumeRepository struct {
 	ctrl     *gomock.Controller
@@ -344,6 +389,21 @@ func (mr *MockPublishedVolumeRepositoryMockRecorder) GetFromVolumeTruncated(file
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFromVolumeTruncated", reflect.TypeOf((*MockPublishedVolumeRepository)(nil).GetFromVolumeTruncated), filename)
 }
 
+// ReadFromVolume mocks base method.
+func (m *MockPublishedVolumeRepository) ReadFromVolume(filename string) (*os.File, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadFromVolume", filename)
+	ret0, _ := ret[0].(*os.File)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadFromVolume indicates an expected call of ReadFromVolume.
+func (mr *MockPublishedVolumeRepositoryMockRecorder) ReadFromVolume(filename interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadFromVolume", reflect.TypeOf((*MockPublishedVolumeRepository)(nil).ReadFromVolume), filename)
+}
+
 // MockPersonRepository is a mock of PersonRepository interface.
 type MockPersonRepository struct {
 	ctrl     *gomock.Controller
@@ -381,6 +441,35 @@ func (mr *MockPersonRepositoryMockRecorder) GetPersonWithDetails(arg0 interface{
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPersonWithDetails", reflect.TypeOf((*MockPersonRepository)(nil).GetPersonWithDetails), arg0)
 }
 
+// GetPersonWithEmail mocks base method.
+func (m *MockPersonRepository) GetPersonWithEmail(email string) (repositories.Person, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPersonWithEmail", email)
+	ret0, _ := ret[0].(repositories.Person)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPersonWithEmail indicates an expected call of GetPersonWithEmail.
+func (mr *MockPersonRepositoryMockRecorder) GetPersonWithEmail(email interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPersonWithEmail", reflect.TypeOf((*MockPersonRepository)(nil).GetPersonWithEmail), email)
+}
+
+// MarkAsVerified mocks base method.
+func (m *MockPersonRepository) MarkAsVerified(UID int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkAsVerified", UID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkAsVerified indicates an expected call of MarkAsVerified.
+func (mr *MockPersonRepositoryMockRecorder) MarkAsVerified(UID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkAsVerified", reflect.TypeOf((*MockPersonRepository)(nil).MarkAsVerified), UID)
+}
+
 // PersonExists mocks base method.
 func (m *MockPersonRepository) PersonExists(arg0 repositories.Person) bool {
 	m.ctrl.T.Helper()
@@ -395,6 +484,573 @@ func (mr *MockPersonRepositoryMockRecorder) PersonExists(arg0 interface{}) *gomo
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PersonExists", reflect.TypeOf((*MockPersonRepository)(nil).PersonExists), arg0)
 }
 
+// SetPassword mocks base method.
+func (m *MockPersonRepository) SetPassword(UID int, hashedPassword string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetPassword", UID, hashedPassword)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetPassword indicates an expected call of SetPassword.
+func (mr *MockPersonRepositoryMockRecorder) SetPassword(UID, hashedPassword interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetPassword", reflect.TypeOf((*MockPersonRepository)(nil).SetPassword), UID, hashedPassword)
+}
+
+// MockTokensRepository is a mock of TokensRepository interface.
+type MockTokensRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockTokensRepositoryMockRecorder
+}
+
+// MockTokensRepositoryMockRecorder is the mock recorder for MockTokensRepository.
+type MockTokensRepositoryMockRecorder struct {
+	mock *MockTokensRepository
+}
+
+// NewMockTokensRepository creates a new mock instance.
+func NewMockTokensRepository(ctrl *gomock.Controller) *MockTokensRepository {
+	mock := &MockTokensRepository{ctrl: ctrl}
+	mock.recorder = &MockTokensRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTokensRepository) EXPECT() *MockTokensRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CreateToken mocks base method.
+func (m *MockTokensRepository) CreateToken(arg0 repositories.AuthToken) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateToken", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateToken indicates an expected call of CreateToken.
+func (mr *MockTokensRepositoryMockRecorder) CreateToken(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateToken", reflect.TypeOf((*MockTokensRepository)(nil).CreateToken), arg0)
+}
+
+// InvalidateTokensForPerson mocks base method.
+func (m *MockTokensRepository) InvalidateTokensForPerson(UID int, purpose string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InvalidateTokensForPerson", UID, purpose)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// InvalidateTokensForPerson indicates an expected call of InvalidateTokensForPerson.
+func (mr *MockTokensRepositoryMockRecorder) InvalidateTokensForPerson(UID, purpose interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InvalidateTokensForPerson", reflect.TypeOf((*MockTokensRepository)(nil).InvalidateTokensForPerson), UID, purpose)
+}
+
+// RedeemToken mocks base method.
+func (m *MockTokensRepository) RedeemToken(tokenID uuid.UUID, purpose string) (repositories.AuthToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RedeemToken", tokenID, purpose)
+	ret0, _ := ret[0].(repositories.AuthToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RedeemToken indicates an expected call of RedeemToken.
+func (mr *MockTokensRepositoryMockRecorder) RedeemToken(tokenID, purpose interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RedeemToken", reflect.TypeOf((*MockTokensRepository)(nil).RedeemToken), tokenID, purpose)
+}
+
+// MockPermissionsRepository is a mock of PermissionsRepository interface.
+type MockPermissionsRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockPermissionsRepositoryMockRecorder
+}
+
+// MockPermissionsRepositoryMockRecorder is the mock recorder for MockPermissionsRepository.
+type MockPermissionsRepositoryMockRecorder struct {
+	mock *MockPermissionsRepository
+}
+
+// NewMockPermissionsRepository creates a new mock instance.
+func NewMockPermissionsRepository(ctrl *gomock.Controller) *MockPermissionsRepository {
+	mock := &MockPermissionsRepository{ctrl: ctrl}
+	mock.recorder = &MockPermissionsRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPermissionsRepository) EXPECT() *MockPermissionsRepositoryMockRecorder {
+	return m.recorder
+}
+
+// GetPermission mocks base method.
+func (m *MockPermissionsRepository) GetPermission(UID int, entityID uuid.UUID) (repositories.Permission, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPermission", UID, entityID)
+	ret0, _ := ret[0].(repositories.Permission)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPermission indicates an expected call of GetPermission.
+func (mr *MockPermissionsRepositoryMockRecorder) GetPermission(UID, entityID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPermission", reflect.TypeOf((*MockPermissionsRepository)(nil).GetPermission), UID, entityID)
+}
+
+// MockTwoFactorRepository is a mock of TwoFactorRepository interface.
+type MockTwoFactorRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockTwoFactorRepositoryMockRecorder
+}
+
+// MockTwoFactorRepositoryMockRecorder is the mock recorder for MockTwoFactorRepository.
+type MockTwoFactorRepositoryMockRecorder struct {
+	mock *MockTwoFactorRepository
+}
+
+// NewMockTwoFactorRepository creates a new mock instance.
+func NewMockTwoFactorRepository(ctrl *gomock.Controller) *MockTwoFactorRepository {
+	mock := &MockTwoFactorRepository{ctrl: ctrl}
+	mock.recorder = &MockTwoFactorRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTwoFactorRepository) EXPECT() *MockTwoFactorRepositoryMockRecorder {
+	return m.recorder
+}
+
+// BeginEnrolment mocks base method.
+func (m *MockTwoFactorRepository) BeginEnrolment(UID int, secret string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BeginEnrolment", UID, secret)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// BeginEnrolment indicates an expected call of BeginEnrolment.
+func (mr *MockTwoFactorRepositoryMockRecorder) BeginEnrolment(UID, secret interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BeginEnrolment", reflect.TypeOf((*MockTwoFactorRepository)(nil).BeginEnrolment), UID, secret)
+}
+
+// ConsumeRecoveryCode mocks base method.
+func (m *MockTwoFactorRepository) ConsumeRecoveryCode(UID int, codeHash string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConsumeRecoveryCode", UID, codeHash)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ConsumeRecoveryCode indicates an expected call of ConsumeRecoveryCode.
+func (mr *MockTwoFactorRepositoryMockRecorder) ConsumeRecoveryCode(UID, codeHash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConsumeRecoveryCode", reflect.TypeOf((*MockTwoFactorRepository)(nil).ConsumeRecoveryCode), UID, codeHash)
+}
+
+// Disable mocks base method.
+func (m *MockTwoFactorRepository) Disable(UID int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Disable", UID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Disable indicates an expected call of Disable.
+func (mr *MockTwoFactorRepositoryMockRecorder) Disable(UID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Disable", reflect.TypeOf((*MockTwoFactorRepository)(nil).Disable), UID)
+}
+
+// Enable mocks base method.
+func (m *MockTwoFactorRepository) Enable(UID int, step int64, recoveryCodeHashes []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Enable", UID, step, recoveryCodeHashes)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Enable indicates an expected call of Enable.
+func (mr *MockTwoFactorRepositoryMockRecorder) Enable(UID, step, recoveryCodeHashes interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Enable", reflect.TypeOf((*MockTwoFactorRepository)(nil).Enable), UID, step, recoveryCodeHashes)
+}
+
+// GetTwoFactor mocks base method.
+func (m *MockTwoFactorRepository) GetTwoFactor(UID int) (repositories.TwoFactor, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTwoFactor", UID)
+	ret0, _ := ret[0].(repositories.TwoFactor)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTwoFactor indicates an expected call of GetTwoFactor.
+func (mr *MockTwoFactorRepositoryMockRecorder) GetTwoFactor(UID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTwoFactor", reflect.TypeOf((*MockTwoFactorRepository)(nil).GetTwoFactor), UID)
+}
+
+// MarkStepUsed mocks base method.
+func (m *MockTwoFactorRepository) MarkStepUsed(UID int, step int64) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkStepUsed", UID, step)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MarkStepUsed indicates an expected call of MarkStepUsed.
+func (mr *MockTwoFactorRepositoryMockRecorder) MarkStepUsed(UID, step interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkStepUsed", reflect.TypeOf((*MockTwoFactorRepository)(nil).MarkStepUsed), UID, step)
+}
+
+// MockLoginAttemptsRepository is a mock of LoginAttemptsRepository interface.
+type MockLoginAttemptsRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockLoginAttemptsRepositoryMockRecorder
+}
+
+// MockLoginAttemptsRepositoryMockRecorder is the mock recorder for MockLoginAttemptsRepository.
+type MockLoginAttemptsRepositoryMockRecorder struct {
+	mock *MockLoginAttemptsRepository
+}
+
+// NewMockLoginAttemptsRepository creates a new mock instance.
+func NewMockLoginAttemptsRepository(ctrl *gomock.Controller) *MockLoginAttemptsRepository {
+	mock := &MockLoginAttemptsRepository{ctrl: ctrl}
+	mock.recorder = &MockLoginAttemptsRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockLoginAttemptsRepository) EXPECT() *MockLoginAttemptsRepositoryMockRecorder {
+	return m.recorder
+}
+
+// GetAttempts mocks base method.
+func (m *MockLoginAttemptsRepository) GetAttempts(arg0 repositories.LoginAttemptFilter) ([]repositories.LoginAttempt, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAttempts", arg0)
+	ret0, _ := ret[0].([]repositories.LoginAttempt)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAttempts indicates an expected call of GetAttempts.
+func (mr *MockLoginAttemptsRepositoryMockRecorder) GetAttempts(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAttempts", reflect.TypeOf((*MockLoginAttemptsRepository)(nil).GetAttempts), arg0)
+}
+
+// RecordAttempt mocks base method.
+func (m *MockLoginAttemptsRepository) RecordAttempt(arg0 repositories.LoginAttempt) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordAttempt", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecordAttempt indicates an expected call of RecordAttempt.
+func (mr *MockLoginAttemptsRepositoryMockRecorder) RecordAttempt(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordAttempt", reflect.TypeOf((*MockLoginAttemptsRepository)(nil).RecordAttempt), arg0)
+}
+
+// MockMetadataRepository is a mock of MetadataRepository interface.
+type MockMetadataRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockMetadataRepositoryMockRecorder
+}
+
+// MockMetadataRepositoryMockRecorder is the mock recorder for MockMetadataRepository.
+type MockMetadataRepositoryMockRecorder struct {
+	mock *MockMetadataRepository
+}
+
+// NewMockMetadataRepository creates a new mock instance.
+func NewMockMetadataRepository(ctrl *gomock.Controller) *MockMetadataRepository {
+	mock := &MockMetadataRepository{ctrl: ctrl}
+	mock.recorder = &MockMetadataRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockMetadataRepository) EXPECT() *MockMetadataRepositoryMockRecorder {
+	return m.recorder
+}
+
+// DeleteMetadata mocks base method.
+func (m *MockMetadataRepository) DeleteMetadata(entityID uuid.UUID, key string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteMetadata", entityID, key)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteMetadata indicates an expected call of DeleteMetadata.
+func (mr *MockMetadataRepositoryMockRecorder) DeleteMetadata(entityID, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteMetadata", reflect.TypeOf((*MockMetadataRepository)(nil).DeleteMetadata), entityID, key)
+}
+
+// GetMetadata mocks base method.
+func (m *MockMetadataRepository) GetMetadata(entityID uuid.UUID) (map[string]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMetadata", entityID)
+	ret0, _ := ret[0].(map[string]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMetadata indicates an expected call of GetMetadata.
+func (mr *MockMetadataRepositoryMockRecorder) GetMetadata(entityID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMetadata", reflect.TypeOf((*MockMetadataRepository)(nil).GetMetadata), entityID)
+}
+
+// SetMetadata mocks base method.
+func (m *MockMetadataRepository) SetMetadata(entityID uuid.UUID, key, value string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetMetadata", entityID, key, value)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetMetadata indicates an expected call of SetMetadata.
+func (mr *MockMetadataRepositoryMockRecorder) SetMetadata(entityID, key, value interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetMetadata", reflect.TypeOf((*MockMetadataRepository)(nil).SetMetadata), entityID, key, value)
+}
+
+// MockTagsRepository is a mock of TagsRepository interface.
+type MockTagsRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockTagsRepositoryMockRecorder
+}
+
+// MockTagsRepositoryMockRecorder is the mock recorder for MockTagsRepository.
+type MockTagsRepositoryMockRecorder struct {
+	mock *MockTagsRepository
+}
+
+// NewMockTagsRepository creates a new mock instance.
+func NewMockTagsRepository(ctrl *gomock.Controller) *MockTagsRepository {
+	mock := &MockTagsRepository{ctrl: ctrl}
+	mock.recorder = &MockTagsRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTagsRepository) EXPECT() *MockTagsRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CreateTag mocks base method.
+func (m *MockTagsRepository) CreateTag(name string, parent int) (repositories.Tag, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateTag", name, parent)
+	ret0, _ := ret[0].(repositories.Tag)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateTag indicates an expected call of CreateTag.
+func (mr *MockTagsRepositoryMockRecorder) CreateTag(name, parent interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTag", reflect.TypeOf((*MockTagsRepository)(nil).CreateTag), name, parent)
+}
+
+// DeleteTag mocks base method.
+func (m *MockTagsRepository) DeleteTag(tagID int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteTag", tagID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteTag indicates an expected call of DeleteTag.
+func (mr *MockTagsRepositoryMockRecorder) DeleteTag(tagID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteTag", reflect.TypeOf((*MockTagsRepository)(nil).DeleteTag), tagID)
+}
+
+// GetChildrenWithTag mocks base method.
+func (m *MockTagsRepository) GetChildrenWithTag(parentID uuid.UUID, tagID int) ([]uuid.UUID, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetChildrenWithTag", parentID, tagID)
+	ret0, _ := ret[0].([]uuid.UUID)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetChildrenWithTag indicates an expected call of GetChildrenWithTag.
+func (mr *MockTagsRepositoryMockRecorder) GetChildrenWithTag(parentID, tagID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetChildrenWithTag", reflect.TypeOf((*MockTagsRepository)(nil).GetChildrenWithTag), parentID, tagID)
+}
+
+// GetEntityTags mocks base method.
+func (m *MockTagsRepository) GetEntityTags(entityID uuid.UUID) ([]repositories.Tag, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetEntityTags", entityID)
+	ret0, _ := ret[0].([]repositories.Tag)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetEntityTags indicates an expected call of GetEntityTags.
+func (mr *MockTagsRepositoryMockRecorder) GetEntityTags(entityID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEntityTags", reflect.TypeOf((*MockTagsRepository)(nil).GetEntityTags), entityID)
+}
+
+// GetTags mocks base method.
+func (m *MockTagsRepository) GetTags() ([]repositories.Tag, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTags")
+	ret0, _ := ret[0].([]repositories.Tag)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTags indicates an expected call of GetTags.
+func (mr *MockTagsRepositoryMockRecorder) GetTags() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTags", reflect.TypeOf((*MockTagsRepository)(nil).GetTags))
+}
+
+// TagEntity mocks base method.
+func (m *MockTagsRepository) TagEntity(entityID uuid.UUID, tagID int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TagEntity", entityID, tagID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// TagEntity indicates an expected call of TagEntity.
+func (mr *MockTagsRepositoryMockRecorder) TagEntity(entityID, tagID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TagEntity", reflect.TypeOf((*MockTagsRepository)(nil).TagEntity), entityID, tagID)
+}
+
+// UntagEntity mocks base method.
+func (m *MockTagsRepository) UntagEntity(entityID uuid.UUID, tagID int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UntagEntity", entityID, tagID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UntagEntity indicates an expected call of UntagEntity.
+func (mr *MockTagsRepositoryMockRecorder) UntagEntity(entityID, tagID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UntagEntity", reflect.TypeOf((*MockTagsRepository)(nil).UntagEntity), entityID, tagID)
+}
+
+// MockDocumentTypesRepository is a mock of DocumentTypesRepository interface.
+type MockDocumentTypesRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockDocumentTypesRepositoryMockRecorder
+}
+
+// MockDocumentTypesRepositoryMockRecorder is the mock recorder for MockDocumentTypesRepository.
+type MockDocumentTypesRepositoryMockRecorder struct {
+	mock *MockDocumentTypesRepository
+}
+
+// NewMockDocumentTypesRepository creates a new mock instance.
+func NewMockDocumentTypesRepository(ctrl *gomock.Controller) *MockDocumentTypesRepository {
+	mock := &MockDocumentTypesRepository{ctrl: ctrl}
+	mock.recorder = &MockDocumentTypesRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockDocumentTypesRepository) EXPECT() *MockDocumentTypesRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CreateDocumentType mocks base method.
+func (m *MockDocumentTypesRepository) CreateDocumentType(arg0 repositories.DocumentType) (repositories.DocumentType, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateDocumentType", arg0)
+	ret0, _ := ret[0].(repositories.DocumentType)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateDocumentType indicates an expected call of CreateDocumentType.
+func (mr *MockDocumentTypesRepositoryMockRecorder) CreateDocumentType(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateDocumentType", reflect.TypeOf((*MockDocumentTypesRepository)(nil).CreateDocumentType), arg0)
+}
+
+// DeleteDocumentType mocks base method.
+func (m *MockDocumentTypesRepository) DeleteDocumentType(typeID int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteDocumentType", typeID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteDocumentType indicates an expected call of DeleteDocumentType.
+func (mr *MockDocumentTypesRepositoryMockRecorder) DeleteDocumentType(typeID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteDocumentType", reflect.TypeOf((*MockDocumentTypesRepository)(nil).DeleteDocumentType), typeID)
+}
+
+// GetDirectoryType mocks base method.
+func (m *MockDocumentTypesRepository) GetDirectoryType(directoryID uuid.UUID) (repositories.DocumentType, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDirectoryType", directoryID)
+	ret0, _ := ret[0].(repositories.DocumentType)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDirectoryType indicates an expected call of GetDirectoryType.
+func (mr *MockDocumentTypesRepositoryMockRecorder) GetDirectoryType(directoryID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDirectoryType", reflect.TypeOf((*MockDocumentTypesRepository)(nil).GetDirectoryType), directoryID)
+}
+
+// GetDocumentTypes mocks base method.
+func (m *MockDocumentTypesRepository) GetDocumentTypes() ([]repositories.DocumentType, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDocumentTypes")
+	ret0, _ := ret[0].([]repositories.DocumentType)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDocumentTypes indicates an expected call of GetDocumentTypes.
+func (mr *MockDocumentTypesRepositoryMockRecorder) GetDocumentTypes() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDocumentTypes", reflect.TypeOf((*MockDocumentTypesRepository)(nil).GetDocumentTypes))
+}
+
+// SetDirectoryType mocks base method.
+func (m *MockDocumentTypesRepository) SetDirectoryType(directoryID uuid.UUID, typeID int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetDirectoryType", directoryID, typeID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetDirectoryType indicates an expected call of SetDirectoryType.
+func (mr *MockDocumentTypesRepositoryMockRecorder) SetDirectoryType(directoryID, typeID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetDirectoryType", reflect.TypeOf((*MockDocumentTypesRepository)(nil).SetDirectoryType), directoryID, typeID)
+}
+
 // MockGroupsRepository is a mock of GroupsRepository interface.
 type MockGroupsRepository struct {
 	ctrl     *gomock.Controller
@@ -432,6 +1088,21 @@ func (mr *MockGroupsRepositoryMockRecorder) GetGroupInfo(arg0 interface{}) *gomo
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetGroupInfo", reflect.TypeOf((*MockGroupsRepository)(nil).GetGroupInfo), arg0)
 }
 
+// IsMemberOf mocks base method.
+func (m *MockGroupsRepository) IsMemberOf(UID, groupID int) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsMemberOf", UID, groupID)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IsMemberOf indicates an expected call of IsMemberOf.
+func (mr *MockGroupsRepositoryMockRecorder) IsMemberOf(UID, groupID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsMemberOf", reflect.TypeOf((*MockGroupsRepository)(nil).IsMemberOf), UID, groupID)
+}
+
 // MockFrontendsRepository is a mock of FrontendsRepository interface.
 type MockFrontendsRepository struct {
 	ctrl     *gomock.Controller
@@ -468,3 +1139,33 @@ func (mr *MockFrontendsRepositoryMockRecorder) GetFrontendFromURL(url interface{
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFrontendFromURL", reflect.TypeOf((*MockFrontendsRepository)(nil).GetFrontendFromURL), url)
 }
+
+// IsRegisteredURL mocks base method.
+func (m *MockFrontendsRepository) IsRegisteredURL(url string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsRegisteredURL", url)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IsRegisteredURL indicates an expected call of IsRegisteredURL.
+func (mr *MockFrontendsRepositoryMockRecorder) IsRegisteredURL(url interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsRegisteredURL", reflect.TypeOf((*MockFrontendsRepository)(nil).IsRegisteredURL), url)
+}
+
+// RequiresAdminTwoFactor mocks base method.
+func (m *MockFrontendsRepository) RequiresAdminTwoFactor(frontendID uuid.UUID) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RequiresAdminTwoFactor", frontendID)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RequiresAdminTwoFactor indicates an expected call of RequiresAdminTwoFactor.
+func (mr *MockFrontendsRepositoryMockRecorder) RequiresAdminTwoFactor(frontendID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RequiresAdminTwoFactor", reflect.TypeOf((*MockFrontendsRepository)(nil).RequiresAdminTwoFactor), frontendID)
+}
@@ -0,0 +1,42 @@
+package repositories
+
+import (
+	"github.com/google/uuid"
+)
+
+// Implements PermissionsRepository
+type permissionsRepository struct {
+	embeddedContext
+}
+
+// GetPermission resolves the highest permission a person holds over an entity, permissions granted on a directory
+// apply to everything beneath it. Members of the group that owns an entity (or one of its ancestors) and admins
+// implicitly hold every permission
+func (rep permissionsRepository) GetPermission(UID int, entityID uuid.UUID) (Permission, error) {
+	var level string
+	err := rep.db.Query(rep.ctx, `WITH RECURSIVE ancestors AS (
+				SELECT EntityID, Parent, OwnedBy FROM filesystem WHERE EntityID = $1
+				UNION ALL
+				SELECT f.EntityID, f.Parent, f.OwnedBy FROM filesystem f
+					JOIN ancestors a ON f.EntityID = a.Parent
+			), memberships AS (
+				SELECT GroupID FROM group_membership WHERE UID = $2
+			)
+			SELECT COALESCE(MAX(level)::text, 'none') FROM (
+				SELECT p.Permission AS level FROM permissions p
+					JOIN ancestors a ON p.EntityID = a.EntityID
+					JOIN memberships m ON m.GroupID = p.GroupID
+				UNION ALL
+				SELECT 'delete'::permissions_enum FROM ancestors a
+					JOIN memberships m ON m.GroupID = a.OwnedBy
+				UNION ALL
+				SELECT 'delete'::permissions_enum FROM memberships m
+					WHERE m.GroupID = $3 AND EXISTS (SELECT 1 FROM ancestors)
+			) levels;`,
+		[]interface{}{entityID, UID, GROUPS_ADMIN}, &level)
+	if err != nil {
+		return PermissionNone, err
+	}
+
+	return Permission(level), nil
+}
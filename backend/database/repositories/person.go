@@ -18,7 +18,7 @@ type personRepository struct {
 
 func (rep personRepository) PersonExists(p Person) bool {
 	var result int
-	err := rep.ctx.Query("SELECT count(*) from person where email = $1 and frontendid = $2 and password = $3;", []interface{}{p.Email, rep.frontEndID, p.Password}, &result)
+	err := rep.db.Query(rep.ctx, "SELECT count(*) from person where email = $1 and frontendid = $2 and password = $3;", []interface{}{p.Email, rep.frontEndID, p.Password}, &result)
 	if err != nil {
 		log.Println("credentials match err", err.Error())
 	}
@@ -28,10 +28,32 @@ func (rep personRepository) PersonExists(p Person) bool {
 
 func (rep personRepository) GetPersonWithDetails(p Person) Person {
 	var result Person
-	err := rep.ctx.Query("SELECT * from person where email = $1 and frontendid = $2 and password = $3;", []interface{}{p.Email, rep.frontEndID, p.Password},
+	err := rep.db.Query(rep.ctx, "SELECT * from person where email = $1 and frontendid = $2 and password = $3;", []interface{}{p.Email, rep.frontEndID, p.Password},
 		&result.UID, &result.Email, &result.FirstName, &result.Password, &result.GroupID, &result.FrontEndID)
 	if err != nil {
 		log.Print("get permissions error", err.Error())
 	}
 	return result
 }
+
+// GetPersonWithEmail fetches a person purely by their email, unlike GetPersonWithDetails it does not require their password
+func (rep personRepository) GetPersonWithEmail(email string) (Person, error) {
+	var result Person
+	err := rep.db.Query(rep.ctx, "SELECT UID, Email, First_name, Password, Verified FROM person WHERE Email = $1;", []interface{}{email},
+		&result.UID, &result.Email, &result.FirstName, &result.Password, &result.Verified)
+	if err != nil {
+		return Person{}, err
+	}
+
+	return result, nil
+}
+
+// SetPassword updates a person's password, it expects the password to already be hashed
+func (rep personRepository) SetPassword(UID int, hashedPassword string) error {
+	return rep.db.Exec(rep.ctx, "UPDATE person SET Password = $1 WHERE UID = $2;", []interface{}{hashedPassword, UID})
+}
+
+// MarkAsVerified marks a person's email as verified
+func (rep personRepository) MarkAsVerified(UID int) error {
+	return rep.db.Exec(rep.ctx, "UPDATE person SET Verified = true WHERE UID = $1;", []interface{}{UID})
+}
@@ -32,6 +32,11 @@ type (
 		GetRoot() (FilesystemEntry, error)
 		GetEntryWithParentID(ID uuid.UUID) (FilesystemEntry, error)
 		GetIDWithPath(path string) (uuid.UUID, error)
+		// GetSubtree fetches an entity followed by its descendants up to depth levels beneath it,
+		// every entry comes after its parent
+		GetSubtree(ID uuid.UUID, depth int) ([]FilesystemEntry, error)
+		// GetBreadcrumbs fetches the entities along the path from the root down to (and including) an entity
+		GetBreadcrumbs(ID uuid.UUID) ([]FilesystemEntry, error)
 
 		CreateEntry(file FilesystemEntry) (FilesystemEntry, error)
 		DeleteEntryWithID(ID uuid.UUID) error
@@ -49,6 +54,9 @@ type (
 		CopyToVolume(src *os.File, filename string) (err error)
 		GetFromVolume(filename string) (fp *os.File, err error)
 		GetFromVolumeTruncated(filename string) (fp *os.File, err error)
+		// ReadFromVolume opens a file for reading without creating it, the error
+		// wraps os.ErrNotExist if the file doesn't exist
+		ReadFromVolume(filename string) (fp *os.File, err error)
 		DeleteFromVolume(filename string) (err error)
 	}
 
@@ -62,17 +70,93 @@ type (
 	PersonRepository interface {
 		PersonExists(Person) bool
 		GetPersonWithDetails(Person) Person
+		GetPersonWithEmail(email string) (Person, error)
+
+		SetPassword(UID int, hashedPassword string) error
+		MarkAsVerified(UID int) error
+	}
+
+	// repository interface for the single-use tokens we hand out to users
+	// (password resets, email verification, etc)
+	TokensRepository interface {
+		CreateToken(AuthToken) error
+		// RedeemToken marks a token as used, it fails if the token was already used, has expired
+		// or was issued for a different purpose
+		RedeemToken(tokenID uuid.UUID, purpose string) (AuthToken, error)
+		InvalidateTokensForPerson(UID int, purpose string) error
+	}
+
+	// repository interface for resolving the permissions people hold over filesystem entities
+	PermissionsRepository interface {
+		GetPermission(UID int, entityID uuid.UUID) (Permission, error)
+	}
+
+	// repository interface for TOTP 2FA enrolments and their recovery codes
+	TwoFactorRepository interface {
+		GetTwoFactor(UID int) (TwoFactor, error)
+		BeginEnrolment(UID int, secret string) error
+		Enable(UID int, step int64, recoveryCodeHashes []string) error
+		Disable(UID int) error
+
+		MarkStepUsed(UID int, step int64) (bool, error)
+		ConsumeRecoveryCode(UID int, codeHash string) (bool, error)
+	}
+
+	// repository interface for the audit log of login attempts
+	LoginAttemptsRepository interface {
+		RecordAttempt(LoginAttempt) error
+		// GetAttempts returns the attempts matching the filter, most recent first
+		GetAttempts(LoginAttemptFilter) ([]LoginAttempt, error)
+	}
+
+	// repository interface for the key/value metadata (eg: SEO title, author) attached to filesystem entities
+	MetadataRepository interface {
+		GetMetadata(entityID uuid.UUID) (map[string]string, error)
+		SetMetadata(entityID uuid.UUID, key string, value string) error
+		DeleteMetadata(entityID uuid.UUID, key string) error
+	}
+
+	// repository interface for the tag taxonomy and the tags attached to filesystem entities
+	TagsRepository interface {
+		GetTags() ([]Tag, error)
+		CreateTag(name string, parent int) (Tag, error)
+		DeleteTag(tagID int) error
+
+		GetEntityTags(entityID uuid.UUID) ([]Tag, error)
+		TagEntity(entityID uuid.UUID, tagID int) error
+		UntagEntity(entityID uuid.UUID, tagID int) error
+		// GetChildrenWithTag fetches the children of an entity tagged with a tag (or any tag nested within it)
+		GetChildrenWithTag(parentID uuid.UUID, tagID int) ([]uuid.UUID, error)
+	}
+
+	// repository interface for document types and the directories they are attached to
+	DocumentTypesRepository interface {
+		GetDocumentTypes() ([]DocumentType, error)
+		CreateDocumentType(DocumentType) (DocumentType, error)
+		DeleteDocumentType(typeID int) error
+
+		// GetDirectoryType fetches the type that documents within a directory must have, if the directory
+		// has no type attached to it the returned type's TypeID is NoDocumentType
+		GetDirectoryType(directoryID uuid.UUID) (DocumentType, error)
+		// SetDirectoryType attaches a type to a directory, attaching NoDocumentType detaches the directory's type
+		SetDirectoryType(directoryID uuid.UUID, typeID int) error
 	}
 
 	// repository interface for the groups table within the database
 	GroupsRepository interface {
 		// Only requires Groups.Name
 		GetGroupInfo(Groups) Groups
+		IsMemberOf(UID int, groupID int) (bool, error)
 	}
 
 	// repository interface for getting information from the frontend table
 	FrontendsRepository interface {
 		GetFrontendFromURL(url string) int
+		// RequiresAdminTwoFactor determines if admins must use 2FA to log into a frontend, if the frontend
+		// isn't known the strictest policy of any frontend applies
+		RequiresAdminTwoFactor(frontendID uuid.UUID) (bool, error)
+		// IsRegisteredURL determines if a URL (eg. an Origin header) belongs to a registered frontend
+		IsRegisteredURL(url string) (bool, error)
 	}
 )
 
@@ -85,8 +169,111 @@ type Person struct {
 	Password   string
 	GroupID    int
 	FrontEndID int
+	Verified   bool
 }
 
+// model of a single-use token issued to a user
+type AuthToken struct {
+	TokenID   uuid.UUID
+	UID       int
+	Purpose   string
+	ExpiresAt time.Time
+}
+
+// Permission is a level of access to a filesystem entity, in ascending order: read -> write -> delete
+type Permission string
+
+const (
+	PermissionNone   Permission = "none"
+	PermissionRead   Permission = "read"
+	PermissionWrite  Permission = "write"
+	PermissionDelete Permission = "delete"
+)
+
+// permissionLevels orders the permissions, a higher permission implies all the lower ones
+var permissionLevels = map[Permission]int{
+	PermissionNone:   0,
+	PermissionRead:   1,
+	PermissionWrite:  2,
+	PermissionDelete: 3,
+}
+
+// Allows determines if holding a permission also grants the required permission
+func (p Permission) Allows(required Permission) bool {
+	return permissionLevels[p] >= permissionLevels[required]
+}
+
+// model of a person's 2FA enrolment
+type TwoFactor struct {
+	UID     int
+	Secret  string
+	Enabled bool
+}
+
+// model of a single (successful or failed) login attempt
+type LoginAttempt struct {
+	AttemptID   int
+	Email       string
+	IPAddress   string
+	Successful  bool
+	Outcome     string
+	AttemptedAt time.Time
+}
+
+// filter for querying login attempts, zero values are ignored
+type LoginAttemptFilter struct {
+	Email        string
+	IPAddress    string
+	OnlyFailures bool
+	Since        time.Time
+	Limit        int
+}
+
+// model of a tag within the taxonomy
+type Tag struct {
+	TagID int
+	Name  string
+	// Parent is NoParentTag if the tag is at the top of the taxonomy
+	Parent int
+}
+
+// NoParentTag is the parent of the tags at the top of the taxonomy
+const NoParentTag = 0
+
+// model of a document type, every document within a directory the type is attached to must match it
+type DocumentType struct {
+	TypeID int
+	Name   string
+	Fields []DocumentField
+}
+
+// model of a single (top level) field of a document type
+type DocumentField struct {
+	Name     string
+	Kind     FieldKind
+	Required bool
+}
+
+// FieldKind is the kind of value a field of a document type holds
+type FieldKind string
+
+const (
+	FieldString  FieldKind = "string"
+	FieldNumber  FieldKind = "number"
+	FieldBoolean FieldKind = "boolean"
+	// FieldDate is either a date (YYYY-MM-DD) or an RFC 3339 timestamp
+	FieldDate FieldKind = "date"
+	// FieldImage is the entity ID of an image
+	FieldImage FieldKind = "image"
+	// FieldURL is an absolute http(s) URL
+	FieldURL FieldKind = "url"
+	// FieldBlocks is an array of editor blocks (eg: the body of a blog post)
+	FieldBlocks FieldKind = "blocks"
+)
+
+// NoDocumentType is the type of directories that don't have a document type attached to them
+const NoDocumentType = 0
+
 // model of the groups table within the database
 type Groups struct {
 	UID        int
@@ -0,0 +1,107 @@
+package repositories
+
+import (
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v4"
+)
+
+// Implements TagsRepository
+type tagsRepository struct {
+	embeddedContext
+}
+
+// GetTags fetches the entire taxonomy, a tag always comes after its parent
+func (rep tagsRepository) GetTags() ([]Tag, error) {
+	rows, err := rep.db.QueryRow(rep.ctx, "SELECT TagID, Name, COALESCE(Parent, 0) FROM tags ORDER BY TagID;", []interface{}{})
+	if err != nil {
+		return nil, err
+	}
+
+	return scanTags(rows)
+}
+
+// CreateTag creates a new tag nested within parent (or at the top of the taxonomy if parent is NoParentTag)
+func (rep tagsRepository) CreateTag(name string, parent int) (Tag, error) {
+	tag := Tag{Name: name, Parent: parent}
+	err := rep.db.Query(rep.ctx, "INSERT INTO tags (Name, Parent) VALUES ($1, NULLIF($2::int, 0)) RETURNING TagID;",
+		[]interface{}{name, parent}, &tag.TagID)
+	if err != nil {
+		return Tag{}, err
+	}
+
+	return tag, nil
+}
+
+// DeleteTag deletes a tag along with every tag nested within it
+func (rep tagsRepository) DeleteTag(tagID int) error {
+	return rep.db.Exec(rep.ctx, "DELETE FROM tags WHERE TagID = $1;", []interface{}{tagID})
+}
+
+// GetEntityTags fetches the tags an entity has been tagged with
+func (rep tagsRepository) GetEntityTags(entityID uuid.UUID) ([]Tag, error) {
+	rows, err := rep.db.QueryRow(rep.ctx, `SELECT t.TagID, t.Name, COALESCE(t.Parent, 0) FROM tags t
+			JOIN entity_tags e ON e.TagID = t.TagID
+			WHERE e.EntityID = $1 ORDER BY t.TagID;`, []interface{}{entityID})
+	if err != nil {
+		return nil, err
+	}
+
+	return scanTags(rows)
+}
+
+// TagEntity tags an entity, tagging an entity twice with the same tag has no effect
+func (rep tagsRepository) TagEntity(entityID uuid.UUID, tagID int) error {
+	return rep.db.Exec(rep.ctx, "INSERT INTO entity_tags (EntityID, TagID) VALUES ($1, $2) ON CONFLICT DO NOTHING;",
+		[]interface{}{entityID, tagID})
+}
+
+// UntagEntity removes a tag from an entity
+func (rep tagsRepository) UntagEntity(entityID uuid.UUID, tagID int) error {
+	return rep.db.Exec(rep.ctx, "DELETE FROM entity_tags WHERE EntityID = $1 AND TagID = $2;", []interface{}{entityID, tagID})
+}
+
+// GetChildrenWithTag fetches the children of an entity that are tagged with a tag or any tag nested within it
+func (rep tagsRepository) GetChildrenWithTag(parentID uuid.UUID, tagID int) ([]uuid.UUID, error) {
+	rows, err := rep.db.QueryRow(rep.ctx, `WITH RECURSIVE nested AS (
+				SELECT TagID FROM tags WHERE TagID = $2
+				UNION ALL
+				SELECT t.TagID FROM tags t JOIN nested n ON t.Parent = n.TagID
+			)
+			SELECT f.EntityID FROM filesystem f
+				WHERE f.Parent = $1 AND EXISTS (
+					SELECT 1 FROM entity_tags e JOIN nested n ON e.TagID = n.TagID WHERE e.EntityID = f.EntityID
+				)
+				ORDER BY f.CreatedAt;`, []interface{}{parentID, tagID})
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	children := []uuid.UUID{}
+	for rows.Next() {
+		var child uuid.UUID
+		if err := rows.Scan(&child); err != nil {
+			return nil, err
+		}
+
+		children = append(children, child)
+	}
+
+	return children, rows.Err()
+}
+
+func scanTags(rows pgx.Rows) ([]Tag, error) {
+	defer rows.Close()
+
+	tags := []Tag{}
+	for rows.Next() {
+		var tag Tag
+		if err := rows.Scan(&tag.TagID, &tag.Name, &tag.Parent); err != nil {
+			return nil, err
+		}
+
+		tags = append(tags, tag)
+	}
+
+	return tags, rows.Err()
+}
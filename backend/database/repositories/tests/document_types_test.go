@@ -0,0 +1,64 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+
+	"cms.csesoc.unsw.edu.au/database/repositories"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDocumentTypes(t *testing.T) {
+	assert := assert.New(t)
+
+	testContext.RunTest(func() {
+		// ==== Setup ====
+		fsRepo, err := repositories.NewFilesystemRepo(context.Background(), frontendLogicalName, frontendURL, testContext)
+		assert.Nil(err)
+		root, _ := fsRepo.GetRoot()
+		blog, _ := fsRepo.CreateEntry(repositories.FilesystemEntry{
+			LogicalName: "blog", ParentFileID: root.EntityID, OwnerUserId: repositories.GROUPS_ADMIN, IsDocument: false,
+		})
+		post, _ := fsRepo.CreateEntry(repositories.FilesystemEntry{
+			LogicalName: "post", ParentFileID: blog.EntityID, OwnerUserId: repositories.GROUPS_ADMIN, IsDocument: true,
+		})
+		repo := repositories.NewDocumentTypesRepo(context.Background(), testContext)
+
+		blogPost, err := repo.CreateDocumentType(repositories.DocumentType{
+			Name: "blog post",
+			Fields: []repositories.DocumentField{
+				{Name: "title", Kind: repositories.FieldString, Required: true},
+				{Name: "coverImage", Kind: repositories.FieldImage},
+			},
+		})
+		assert.Nil(err)
+		assert.True(testContext.WillFail(func() error {
+			_, err := repo.CreateDocumentType(repositories.DocumentType{Name: "blog post"})
+			return err
+		}))
+
+		// ==== Assertions ====
+		if documentTypes, err := repo.GetDocumentTypes(); assert.Nil(err) {
+			assert.Equal([]repositories.DocumentType{blogPost}, documentTypes)
+		}
+
+		if documentType, err := repo.GetDirectoryType(blog.EntityID); assert.Nil(err) {
+			assert.Equal(repositories.NoDocumentType, documentType.TypeID)
+		}
+
+		assert.Nil(repo.SetDirectoryType(blog.EntityID, blogPost.TypeID))
+		if documentType, err := repo.GetDirectoryType(blog.EntityID); assert.Nil(err) {
+			assert.Equal(blogPost, documentType)
+		}
+
+		// only directories can have a type
+		assert.True(testContext.WillFail(func() error { return repo.SetDirectoryType(post.EntityID, blogPost.TypeID) }))
+
+		// deleting a type detaches it
+		assert.Nil(repo.DeleteDocumentType(blogPost.TypeID))
+		if documentType, err := repo.GetDirectoryType(blog.EntityID); assert.Nil(err) {
+			assert.Equal(repositories.NoDocumentType, documentType.TypeID)
+		}
+	})
+}
@@ -1,6 +1,7 @@
 package repositories
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"os"
@@ -27,7 +28,7 @@ func TestMain(m *testing.M) {
 func TestRootRetrieval(t *testing.T) {
 	assert := assert.New(t)
 	testContext.RunTest(func() {
-		repo, err := repositories.NewFilesystemRepo(frontendLogicalName, frontendURL, testContext)
+		repo, err := repositories.NewFilesystemRepo(context.Background(), frontendLogicalName, frontendURL, testContext)
 		assert.Nil(err)
 		root, err := repo.GetRoot()
 		if assert.Nil(err) {
@@ -43,7 +44,7 @@ func TestRootInsert(t *testing.T) {
 
 	testContext.RunTest(func() {
 		// ==== Test setup ====
-		repo, err := repositories.NewFilesystemRepo(frontendLogicalName, frontendURL, testContext)
+		repo, err := repositories.NewFilesystemRepo(context.Background(), frontendLogicalName, frontendURL, testContext)
 		assert.Nil(err)
 		root, _ := repo.GetRoot()
 
@@ -61,20 +62,20 @@ func TestRootInsert(t *testing.T) {
 		var docCount int
 		var dirCount int
 
-		if assert.Nil(testContext.Query("SELECT COUNT(*) FROM filesystem WHERE EntityID = $1", []interface{}{newDir.EntityID}, &dirCount)) {
+		if assert.Nil(testContext.Query(context.Background(), "SELECT COUNT(*) FROM filesystem WHERE EntityID = $1", []interface{}{newDir.EntityID}, &dirCount)) {
 			assert.Equal(dirCount, 1)
 		}
 
-		if assert.Nil(testContext.Query("SELECT COUNT(*) FROM filesystem WHERE EntityID = $1", []interface{}{newDoc.EntityID}, &docCount)) {
+		if assert.Nil(testContext.Query(context.Background(), "SELECT COUNT(*) FROM filesystem WHERE EntityID = $1", []interface{}{newDoc.EntityID}, &docCount)) {
 			assert.Equal(docCount, 1)
 		}
 
-		if rows, err := testContext.QueryRow("SELECT EntityID FROM filesystem WHERE Parent = $1", []interface{}{root.EntityID}); assert.Nil(err) {
+		if rows, err := testContext.QueryRow(context.Background(), "SELECT EntityID FROM filesystem WHERE Parent = $1", []interface{}{root.EntityID}); assert.Nil(err) {
 			childrenArr := scanArray[uuid.UUID](rows)
 			assert.Contains(childrenArr, newDir.EntityID)
 		}
 
-		if rows, err := testContext.QueryRow("SELECT EntityID FROM filesystem WHERE Parent = $1", []interface{}{newDir.EntityID}); assert.Nil(err) {
+		if rows, err := testContext.QueryRow(context.Background(), "SELECT EntityID FROM filesystem WHERE Parent = $1", []interface{}{newDir.EntityID}); assert.Nil(err) {
 			childrenArr := scanArray[uuid.UUID](rows)
 			assert.Contains(childrenArr, newDoc.EntityID)
 		}
@@ -86,7 +87,7 @@ func TestDocumentInfoRetrieval(t *testing.T) {
 
 	testContext.RunTest(func() {
 		// ==== Setup ====
-		repo, err := repositories.NewFilesystemRepo(frontendLogicalName, frontendURL, testContext)
+		repo, err := repositories.NewFilesystemRepo(context.Background(), frontendLogicalName, frontendURL, testContext)
 		assert.Nil(err)
 		root, _ := repo.GetRoot()
 		newDoc, err := repo.CreateEntry(repositories.FilesystemEntry{
@@ -112,7 +113,7 @@ func TestEntityDeletion(t *testing.T) {
 
 	testContext.RunTest(func() {
 		// ====== Setup ======
-		repo, err := repositories.NewFilesystemRepo(frontendLogicalName, frontendURL, testContext)
+		repo, err := repositories.NewFilesystemRepo(context.Background(), frontendLogicalName, frontendURL, testContext)
 		assert.Nil(err)
 		root, _ := repo.GetRoot()
 
@@ -179,7 +180,7 @@ func TestEntityRename(t *testing.T) {
 
 	testContext.RunTest(func() {
 		// ===== Test setup =====
-		repo, err := repositories.NewFilesystemRepo(frontendLogicalName, frontendURL, testContext)
+		repo, err := repositories.NewFilesystemRepo(context.Background(), frontendLogicalName, frontendURL, testContext)
 		assert.Nil(err)
 		root, _ := repo.GetRoot()
 		newDir, _ := repo.CreateEntry(getEntity("cool_dir", repositories.GROUPS_ADMIN, root.EntityID, false))
@@ -210,7 +211,7 @@ func TestEntityChildren(t *testing.T) {
 
 	testContext.RunTest(func() {
 		// Test setup
-		repo, err := repositories.NewFilesystemRepo(frontendLogicalName, frontendURL, testContext)
+		repo, err := repositories.NewFilesystemRepo(context.Background(), frontendLogicalName, frontendURL, testContext)
 		assert.Nil(err)
 		root, _ := repo.GetRoot()
 		dir1, _ := repo.CreateEntry(getEntity("d1", repositories.GROUPS_ADMIN, false, root.EntityID))
@@ -259,7 +260,7 @@ func TestGetIDWithPath(t *testing.T) {
 
 	testContext.RunTest(func() {
 		// Test setup
-		repo, err := repositories.NewFilesystemRepo(frontendLogicalName, frontendURL, testContext)
+		repo, err := repositories.NewFilesystemRepo(context.Background(), frontendLogicalName, frontendURL, testContext)
 		assert.Nil(err)
 		root, _ := repo.GetRoot()
 		dir1, _ := repo.CreateEntry(getEntity("d1", repositories.GROUPS_ADMIN, false, root.EntityID))
@@ -276,13 +277,85 @@ func TestGetIDWithPath(t *testing.T) {
 		_, error1 := repo.GetIDWithPath("/d1/cool_doc2/cool_doc1")
 		_, error2 := repo.GetIDWithPath("/d1/cool_doc1/cool_doc2/cool_doc1")
 
+		rootID, error3 := repo.GetIDWithPath("/")
+
 		assert.True(error1 != nil)
 		assert.True(error2 != nil)
+		assert.Nil(error3)
+		assert.Equal(root.EntityID, rootID)
 		assert.True(child1.EntityID == child2.ParentFileID)
 		assert.True(dir1.EntityID == child1.ParentFileID)
 	})
 }
 
+func TestGetSubtree(t *testing.T) {
+	assert := assert.New(t)
+	getEntity := func(name string, isDocument bool, parent uuid.UUID) repositories.FilesystemEntry {
+		return repositories.FilesystemEntry{
+			LogicalName:  name,
+			OwnerUserId:  repositories.GROUPS_ADMIN,
+			ParentFileID: parent,
+			IsDocument:   isDocument,
+		}
+	}
+
+	testContext.RunTest(func() {
+		// ==== Setup ====
+		// root -> d1 -> d2 -> doc
+		repo, err := repositories.NewFilesystemRepo(context.Background(), frontendLogicalName, frontendURL, testContext)
+		assert.Nil(err)
+		root, _ := repo.GetRoot()
+		d1, _ := repo.CreateEntry(getEntity("d1", false, root.EntityID))
+		d2, _ := repo.CreateEntry(getEntity("d2", false, d1.EntityID))
+		doc, _ := repo.CreateEntry(getEntity("doc", true, d2.EntityID))
+
+		// ==== Assertions ====
+		if subtree, err := repo.GetSubtree(d1.EntityID, 1); assert.Nil(err) && assert.Len(subtree, 2) {
+			assert.Equal(d1.EntityID, subtree[0].EntityID)
+			assert.Equal([]uuid.UUID{d2.EntityID}, subtree[0].ChildrenIDs)
+
+			// the children of the deepest entries are still filled in
+			assert.Equal(d2.EntityID, subtree[1].EntityID)
+			assert.Equal([]uuid.UUID{doc.EntityID}, subtree[1].ChildrenIDs)
+		}
+
+		if subtree, err := repo.GetSubtree(d1.EntityID, 5); assert.Nil(err) && assert.Len(subtree, 3) {
+			assert.Equal(doc.EntityID, subtree[2].EntityID)
+			assert.Empty(subtree[2].ChildrenIDs)
+		}
+
+		_, err = repo.GetSubtree(uuid.New(), 1)
+		assert.NotNil(err)
+	})
+}
+
+func TestGetBreadcrumbs(t *testing.T) {
+	assert := assert.New(t)
+
+	testContext.RunTest(func() {
+		// ==== Setup ====
+		repo, err := repositories.NewFilesystemRepo(context.Background(), frontendLogicalName, frontendURL, testContext)
+		assert.Nil(err)
+		root, _ := repo.GetRoot()
+		dir, _ := repo.CreateEntry(repositories.FilesystemEntry{
+			LogicalName: "dir", ParentFileID: root.EntityID, OwnerUserId: repositories.GROUPS_ADMIN,
+		})
+		doc, _ := repo.CreateEntry(repositories.FilesystemEntry{
+			LogicalName: "doc", ParentFileID: dir.EntityID, OwnerUserId: repositories.GROUPS_ADMIN, IsDocument: true,
+		})
+
+		// ==== Assertions ====
+		if breadcrumbs, err := repo.GetBreadcrumbs(doc.EntityID); assert.Nil(err) && assert.Len(breadcrumbs, 3) {
+			assert.Equal(root.EntityID, breadcrumbs[0].EntityID)
+			assert.Equal("dir", breadcrumbs[1].LogicalName)
+			assert.Equal("doc", breadcrumbs[2].LogicalName)
+		}
+
+		_, err = repo.GetBreadcrumbs(uuid.New())
+		assert.NotNil(err)
+	})
+}
+
 func TestMultiApplications(t *testing.T) {
 	assert := assert.New(t)
 
@@ -299,14 +372,14 @@ func TestMultiApplications(t *testing.T) {
 		// ===== Test setup =====
 
 		// Application 1
-		repo1, err := repositories.NewFilesystemRepo(frontendLogicalName, frontendURL, testContext)
+		repo1, err := repositories.NewFilesystemRepo(context.Background(), frontendLogicalName, frontendURL, testContext)
 		assert.Nil(err)
 		root1, _ := repo1.GetRoot()
 		newDir1, _ := repo1.CreateEntry(getEntity("cool_dir", repositories.GROUPS_ADMIN, root1.EntityID, false))
 		newDoc1, _ := repo1.CreateEntry(getEntity("cool_doc", repositories.GROUPS_ADMIN, newDir1.EntityID, false))
 
 		// Application 2
-		repo2, err := repositories.NewFilesystemRepo("CSESoc Website", "http://localhost:3002", testContext)
+		repo2, err := repositories.NewFilesystemRepo(context.Background(), "CSESoc Website", "http://localhost:3002", testContext)
 		assert.Nil(err)
 		root2, _ := repo2.GetRoot()
 		newDir2, _ := repo2.CreateEntry(getEntity("c00l_dir", repositories.GROUPS_ADMIN, root2.EntityID, false))
@@ -0,0 +1,392 @@
+package repositories
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"cms.csesoc.unsw.edu.au/database/repositories"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// The in-memory repositories don't need Postgres so unlike the other repository tests they live in their own package
+
+var (
+	frontendLogicalName = "CSESoc Test"
+	frontendURL         = "http://localhost:3001"
+)
+
+func newSeededDatabase(t *testing.T) *repositories.MemoryDatabase {
+	db := repositories.NewMemoryDatabase()
+	if err := db.SeedDummyData(); err != nil {
+		t.Fatalf("failed to seed the in-memory database: %v", err)
+	}
+
+	return db
+}
+
+func getEntity(name string, parent uuid.UUID, isDocument bool) repositories.FilesystemEntry {
+	return repositories.FilesystemEntry{
+		LogicalName:  name,
+		OwnerUserId:  repositories.GROUPS_ADMIN,
+		ParentFileID: parent,
+		IsDocument:   isDocument,
+	}
+}
+
+func TestMemoryRootRetrieval(t *testing.T) {
+	assert := assert.New(t)
+	db := newSeededDatabase(t)
+
+	repo, err := repositories.NewMemoryFilesystemRepo(frontendLogicalName, frontendURL, db)
+	assert.Nil(err)
+	root, err := repo.GetRoot()
+	if assert.Nil(err) {
+		assert.Equal("CSESoc Test", root.LogicalName)
+		assert.False(root.IsDocument)
+		assert.Empty(root.ChildrenIDs)
+	}
+
+	// the frontend is reused rather than recreated
+	sameRepo, err := repositories.NewMemoryFilesystemRepo(frontendLogicalName, frontendURL, db)
+	assert.Nil(err)
+	sameRoot, _ := sameRepo.GetRoot()
+	assert.Equal(root.EntityID, sameRoot.EntityID)
+
+	// the seeded frontend has the dummy data within it
+	seededRepo, _ := repositories.NewMemoryFilesystemRepo("CSESoc Main Website", "http://localhost:3000", db)
+	if id, err := seededRepo.GetIDWithPath("/blog_documents/cool_document.txt"); assert.Nil(err) {
+		document, _ := seededRepo.GetEntryWithID(id)
+		assert.True(document.IsDocument)
+	}
+}
+
+func TestMemoryEntityCreation(t *testing.T) {
+	assert := assert.New(t)
+	repo, _ := repositories.NewMemoryFilesystemRepo(frontendLogicalName, frontendURL, newSeededDatabase(t))
+	root, _ := repo.GetRoot()
+
+	newDir, err := repo.CreateEntry(getEntity("test_directory", root.EntityID, false))
+	assert.Nil(err)
+	newDoc, err := repo.CreateEntry(getEntity("test_doc", newDir.EntityID, true))
+	assert.Nil(err)
+
+	root, _ = repo.GetRoot()
+	assert.Equal([]uuid.UUID{newDir.EntityID}, root.ChildrenIDs)
+	if info, err := repo.GetEntryWithID(newDir.EntityID); assert.Nil(err) {
+		assert.Equal([]uuid.UUID{newDoc.EntityID}, info.ChildrenIDs)
+	}
+
+	// names are unique within a directory (but a directory and document may share one)
+	_, err = repo.CreateEntry(getEntity("test_directory", root.EntityID, false))
+	assert.ErrorIs(err, repositories.ErrDuplicateName)
+	sharedName, err := repo.CreateEntry(getEntity("test_directory", root.EntityID, true))
+	assert.Nil(err)
+
+	// paths resolve to the oldest entry with the name, "/" is the root itself
+	if id, err := repo.GetIDWithPath("/test_directory"); assert.Nil(err) {
+		assert.Equal(newDir.EntityID, id)
+		assert.NotEqual(sharedName.EntityID, id)
+	}
+	if id, err := repo.GetIDWithPath("/"); assert.Nil(err) {
+		assert.Equal(root.EntityID, id)
+	}
+
+	// documents can't have children
+	_, err = repo.CreateEntry(getEntity("child", newDoc.EntityID, false))
+	assert.ErrorIs(err, repositories.ErrDocumentParent)
+
+	entity := getEntity("orphan", root.EntityID, false)
+	entity.OwnerUserId = 1000
+	_, err = repo.CreateEntry(entity)
+	assert.ErrorIs(err, repositories.ErrUnknownGroup)
+}
+
+func TestMemoryEntityDeletion(t *testing.T) {
+	assert := assert.New(t)
+	repo, _ := repositories.NewMemoryFilesystemRepo(frontendLogicalName, frontendURL, newSeededDatabase(t))
+	root, _ := repo.GetRoot()
+
+	newDir, _ := repo.CreateEntry(getEntity("cool_dir", root.EntityID, false))
+	newDoc, _ := repo.CreateEntry(getEntity("cool_doc", newDir.EntityID, true))
+
+	assert.ErrorIs(repo.DeleteEntryWithID(root.EntityID), repositories.ErrHasChildren)
+	assert.ErrorIs(repo.DeleteEntryWithID(newDir.EntityID), repositories.ErrHasChildren)
+
+	assert.Nil(repo.DeleteEntryWithID(newDoc.EntityID))
+	assert.Nil(repo.DeleteEntryWithID(newDir.EntityID))
+	assert.ErrorIs(repo.DeleteEntryWithID(root.EntityID), repositories.ErrDeleteRoot)
+
+	_, err := repo.GetEntryWithID(newDir.EntityID)
+	assert.NotNil(err)
+}
+
+func TestMemoryEntityRename(t *testing.T) {
+	assert := assert.New(t)
+	repo, _ := repositories.NewMemoryFilesystemRepo(frontendLogicalName, frontendURL, newSeededDatabase(t))
+	root, _ := repo.GetRoot()
+
+	newDir, _ := repo.CreateEntry(getEntity("cool_dir", root.EntityID, false))
+	newDoc, _ := repo.CreateEntry(getEntity("cool_doc", newDir.EntityID, false))
+	newDoc1, _ := repo.CreateEntry(getEntity("cool_doc1", newDir.EntityID, false))
+
+	assert.ErrorIs(repo.RenameEntity(newDoc.EntityID, "cool_doc1"), repositories.ErrDuplicateName)
+	assert.Nil(repo.RenameEntity(newDoc1.EntityID, "cool_doc1"))
+	assert.Nil(repo.RenameEntity(newDoc.EntityID, "yabba dabba doo"))
+
+	if info, err := repo.GetEntryWithID(newDoc.EntityID); assert.Nil(err) {
+		assert.Equal("yabba dabba doo", info.LogicalName)
+	}
+}
+
+func TestMemoryTreeQueries(t *testing.T) {
+	assert := assert.New(t)
+	repo, _ := repositories.NewMemoryFilesystemRepo(frontendLogicalName, frontendURL, newSeededDatabase(t))
+	root, _ := repo.GetRoot()
+
+	// root -> d1 -> d2 -> doc
+	d1, _ := repo.CreateEntry(getEntity("d1", root.EntityID, false))
+	d2, _ := repo.CreateEntry(getEntity("d2", d1.EntityID, false))
+	doc, _ := repo.CreateEntry(getEntity("doc", d2.EntityID, true))
+
+	if subtree, err := repo.GetSubtree(d1.EntityID, 1); assert.Nil(err) && assert.Len(subtree, 2) {
+		assert.Equal(d1.EntityID, subtree[0].EntityID)
+		assert.Equal([]uuid.UUID{doc.EntityID}, subtree[1].ChildrenIDs)
+	}
+
+	if subtree, err := repo.GetSubtree(root.EntityID, 10); assert.Nil(err) {
+		assert.Len(subtree, 4)
+	}
+
+	if breadcrumbs, err := repo.GetBreadcrumbs(doc.EntityID); assert.Nil(err) && assert.Len(breadcrumbs, 4) {
+		assert.Equal(root.EntityID, breadcrumbs[0].EntityID)
+		assert.Equal(doc.EntityID, breadcrumbs[3].EntityID)
+	}
+
+	if id, err := repo.GetIDWithPath("/d1/d2/doc"); assert.Nil(err) {
+		assert.Equal(doc.EntityID, id)
+	}
+
+	_, err := repo.GetIDWithPath("/d1/doc")
+	assert.NotNil(err)
+}
+
+func TestMemoryMetadataAndTags(t *testing.T) {
+	assert := assert.New(t)
+	db := newSeededDatabase(t)
+	fsRepo, _ := repositories.NewMemoryFilesystemRepo(frontendLogicalName, frontendURL, db)
+	metadataRepo := repositories.NewMemoryMetadataRepo(db)
+	tagsRepo := repositories.NewMemoryTagsRepo(db)
+	root, _ := fsRepo.GetRoot()
+
+	workshop, _ := fsRepo.CreateEntry(getEntity("workshop", root.EntityID, true))
+	social, _ := fsRepo.CreateEntry(getEntity("social", root.EntityID, true))
+
+	// metadata
+	assert.Nil(metadataRepo.SetMetadata(workshop.EntityID, "author", "jane"))
+	assert.ErrorIs(metadataRepo.SetMetadata(uuid.New(), "author", "jane"), repositories.ErrUnknownEntity)
+	if metadata, err := metadataRepo.GetMetadata(workshop.EntityID); assert.Nil(err) {
+		assert.Equal(map[string]string{"author": "jane"}, metadata)
+	}
+
+	// tags
+	events, _ := tagsRepo.CreateTag("events", repositories.NoParentTag)
+	workshops, _ := tagsRepo.CreateTag("workshops", events.TagID)
+	_, err := tagsRepo.CreateTag("workshops", events.TagID)
+	assert.ErrorIs(err, repositories.ErrDuplicateTag)
+	_, err = tagsRepo.CreateTag("orphan", 1000)
+	assert.ErrorIs(err, repositories.ErrUnknownTag)
+
+	assert.Nil(tagsRepo.TagEntity(workshop.EntityID, workshops.TagID))
+	assert.Nil(tagsRepo.TagEntity(social.EntityID, events.TagID))
+	if children, err := tagsRepo.GetChildrenWithTag(root.EntityID, events.TagID); assert.Nil(err) {
+		assert.Equal([]uuid.UUID{workshop.EntityID, social.EntityID}, children)
+	}
+
+	if children, err := tagsRepo.GetChildrenWithTag(root.EntityID, workshops.TagID); assert.Nil(err) {
+		assert.Equal([]uuid.UUID{workshop.EntityID}, children)
+	}
+
+	// deleting a tag cascades
+	assert.Nil(tagsRepo.DeleteTag(events.TagID))
+	if tags, err := tagsRepo.GetTags(); assert.Nil(err) {
+		assert.Empty(tags)
+	}
+
+	// as does deleting an entity
+	assert.Nil(fsRepo.DeleteEntryWithID(workshop.EntityID))
+	if metadata, err := metadataRepo.GetMetadata(workshop.EntityID); assert.Nil(err) {
+		assert.Empty(metadata)
+	}
+}
+
+func TestMemoryDocumentTypes(t *testing.T) {
+	assert := assert.New(t)
+	db := newSeededDatabase(t)
+	fsRepo, _ := repositories.NewMemoryFilesystemRepo(frontendLogicalName, frontendURL, db)
+	repo := repositories.NewMemoryDocumentTypesRepo(db)
+	root, _ := fsRepo.GetRoot()
+
+	blog, _ := fsRepo.CreateEntry(getEntity("blog", root.EntityID, false))
+	post, _ := fsRepo.CreateEntry(getEntity("post", blog.EntityID, true))
+
+	blogPost, err := repo.CreateDocumentType(repositories.DocumentType{
+		Name:   "blog post",
+		Fields: []repositories.DocumentField{{Name: "title", Kind: repositories.FieldString, Required: true}},
+	})
+	assert.Nil(err)
+	_, err = repo.CreateDocumentType(repositories.DocumentType{Name: "blog post"})
+	assert.ErrorIs(err, repositories.ErrDuplicateType)
+
+	assert.Nil(repo.SetDirectoryType(blog.EntityID, blogPost.TypeID))
+	assert.ErrorIs(repo.SetDirectoryType(blog.EntityID, 1000), repositories.ErrUnknownType)
+	assert.NotNil(repo.SetDirectoryType(post.EntityID, blogPost.TypeID))
+	if documentType, err := repo.GetDirectoryType(blog.EntityID); assert.Nil(err) {
+		assert.Equal(blogPost, documentType)
+	}
+
+	// deleting a type detaches it
+	assert.Nil(repo.DeleteDocumentType(blogPost.TypeID))
+	if documentType, err := repo.GetDirectoryType(blog.EntityID); assert.Nil(err) {
+		assert.Equal(repositories.NoDocumentType, documentType.TypeID)
+	}
+}
+
+func TestMemoryUnitOfWork(t *testing.T) {
+	assert := assert.New(t)
+	db := newSeededDatabase(t)
+	repo, _ := repositories.NewMemoryFilesystemRepo(frontendLogicalName, frontendURL, db)
+	root, _ := repo.GetRoot()
+
+	var committed, savepointed, restored repositories.FilesystemEntry
+	failure := errors.New("failed")
+	err := db.WithTx(func() error {
+		committed, _ = repo.CreateEntry(getEntity("committed", root.EntityID, false))
+		assert.ErrorIs(db.Savepoint(func() error {
+			savepointed, _ = repo.CreateEntry(getEntity("savepointed", root.EntityID, false))
+			return failure
+		}), failure)
+
+		restored, _ = repo.CreateEntry(getEntity("restored", root.EntityID, false))
+		return nil
+	})
+	assert.Nil(err)
+
+	assert.ErrorIs(db.WithTx(func() error {
+		repo.DeleteEntryWithID(restored.EntityID)
+		return failure
+	}), failure)
+
+	root, _ = repo.GetRoot()
+	assert.Equal([]uuid.UUID{committed.EntityID, restored.EntityID}, root.ChildrenIDs)
+	assert.NotContains(root.ChildrenIDs, savepointed.EntityID)
+}
+
+func TestMemoryPersonRepository(t *testing.T) {
+	assert := assert.New(t)
+	repo := repositories.NewMemoryPersonRepo(newSeededDatabase(t))
+
+	person, err := repo.GetPersonWithEmail("jane.doe@gmail.com")
+	if assert.Nil(err) {
+		assert.True(repo.PersonExists(repositories.Person{Email: person.Email, Password: person.Password}))
+		assert.False(repo.PersonExists(repositories.Person{Email: person.Email, Password: "wrong"}))
+	}
+
+	assert.Nil(repo.SetPassword(person.UID, "new password"))
+	assert.Equal(person.UID, repo.GetPersonWithDetails(repositories.Person{Email: person.Email, Password: "new password"}).UID)
+
+	_, err = repo.GetPersonWithEmail("nobody@gmail.com")
+	assert.NotNil(err)
+}
+
+func TestMemoryPermissions(t *testing.T) {
+	assert := assert.New(t)
+	db := newSeededDatabase(t)
+	fsRepo, _ := repositories.NewMemoryFilesystemRepo("CSESoc Main Website", "http://localhost:3000", db)
+	personRepo := repositories.NewMemoryPersonRepo(db)
+	permissionsRepo := repositories.NewMemoryPermissionsRepo(db)
+
+	admin, _ := personRepo.GetPersonWithEmail("z0000000@ad.unsw.edu.au")
+	member, _ := personRepo.GetPersonWithEmail("john.smith@gmail.com")
+	document, _ := fsRepo.GetIDWithPath("/blog_documents/cool_document.txt")
+
+	permission, err := permissionsRepo.GetPermission(admin.UID, document)
+	assert.Nil(err)
+	assert.Equal(repositories.PermissionDelete, permission)
+
+	permission, err = permissionsRepo.GetPermission(member.UID, document)
+	assert.Nil(err)
+	assert.Equal(repositories.PermissionNone, permission)
+}
+
+func TestMemoryTokens(t *testing.T) {
+	assert := assert.New(t)
+	repo := repositories.NewMemoryTokensRepo(newSeededDatabase(t))
+
+	valid := repositories.AuthToken{TokenID: uuid.New(), UID: 1, Purpose: "reset", ExpiresAt: time.Now().Add(time.Hour)}
+	expired := repositories.AuthToken{TokenID: uuid.New(), UID: 1, Purpose: "reset", ExpiresAt: time.Now().Add(-time.Hour)}
+	assert.Nil(repo.CreateToken(valid))
+	assert.Nil(repo.CreateToken(expired))
+
+	_, err := repo.RedeemToken(valid.TokenID, "verify")
+	assert.NotNil(err)
+	_, err = repo.RedeemToken(expired.TokenID, "reset")
+	assert.NotNil(err)
+
+	if token, err := repo.RedeemToken(valid.TokenID, "reset"); assert.Nil(err) {
+		assert.Equal(valid.UID, token.UID)
+	}
+
+	_, err = repo.RedeemToken(valid.TokenID, "reset")
+	assert.NotNil(err)
+}
+
+func TestMemoryLoginAttemptsFitTheirColumns(t *testing.T) {
+	assert := assert.New(t)
+	repo := repositories.NewMemoryLoginAttemptsRepo(newSeededDatabase(t))
+
+	// the client chooses the email (and potentially the address), attempts must still be recorded if they are too long
+	assert.Nil(repo.RecordAttempt(repositories.LoginAttempt{Email: strings.Repeat("a", 200) + "@gmail.com", IPAddress: strings.Repeat("1", 100), Outcome: "invalid_email"}))
+
+	attempts, err := repo.GetAttempts(repositories.LoginAttemptFilter{})
+	if assert.Nil(err) && assert.Len(attempts, 1) {
+		assert.Equal(strings.Repeat("a", 50), attempts[0].Email)
+		assert.Len(attempts[0].IPAddress, 45)
+	}
+}
+
+func TestLocalVolume(t *testing.T) {
+	assert := assert.New(t)
+	repo, err := repositories.NewLocalUnpublishedRepo(t.TempDir())
+	if !assert.Nil(err) {
+		return
+	}
+
+	file, err := repo.GetFromVolume("document")
+	if assert.Nil(err) {
+		file.WriteString("hello")
+		file.Close()
+	}
+
+	file, err = repo.GetFromVolumeTruncated("document")
+	if assert.Nil(err) {
+		file.Close()
+	}
+
+	file, err = repo.ReadFromVolume("document")
+	if assert.Nil(err) {
+		file.Close()
+	}
+
+	assert.Nil(repo.DeleteFromVolume("document"))
+	assert.NotNil(repo.DeleteFromVolume("document"))
+
+	// reading a file that doesn't exist doesn't create it
+	_, err = repo.ReadFromVolume("document")
+	assert.ErrorIs(err, os.ErrNotExist)
+	assert.NotNil(repo.DeleteFromVolume("document"))
+}
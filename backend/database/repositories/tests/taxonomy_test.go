@@ -0,0 +1,97 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+
+	"cms.csesoc.unsw.edu.au/database/repositories"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEntityMetadata(t *testing.T) {
+	assert := assert.New(t)
+
+	testContext.RunTest(func() {
+		// ==== Setup ====
+		fsRepo, err := repositories.NewFilesystemRepo(context.Background(), frontendLogicalName, frontendURL, testContext)
+		assert.Nil(err)
+		root, _ := fsRepo.GetRoot()
+		doc, _ := fsRepo.CreateEntry(repositories.FilesystemEntry{
+			LogicalName: "doc", ParentFileID: root.EntityID, OwnerUserId: repositories.GROUPS_ADMIN, IsDocument: true,
+		})
+		repo := repositories.NewMetadataRepo(context.Background(), testContext)
+
+		// ==== Assertions ====
+		if metadata, err := repo.GetMetadata(doc.EntityID); assert.Nil(err) {
+			assert.Empty(metadata)
+		}
+
+		assert.Nil(repo.SetMetadata(doc.EntityID, "author", "jane"))
+		assert.Nil(repo.SetMetadata(doc.EntityID, "seo_title", "Hello"))
+		assert.Nil(repo.SetMetadata(doc.EntityID, "author", "john"))
+		if metadata, err := repo.GetMetadata(doc.EntityID); assert.Nil(err) {
+			assert.Equal(map[string]string{"author": "john", "seo_title": "Hello"}, metadata)
+		}
+
+		assert.Nil(repo.DeleteMetadata(doc.EntityID, "author"))
+		if metadata, err := repo.GetMetadata(doc.EntityID); assert.Nil(err) {
+			assert.Equal(map[string]string{"seo_title": "Hello"}, metadata)
+		}
+
+		assert.True(testContext.WillFail(func() error { return repo.SetMetadata(uuid.New(), "author", "jane") }))
+	})
+}
+
+func TestEntityTags(t *testing.T) {
+	assert := assert.New(t)
+
+	testContext.RunTest(func() {
+		// ==== Setup ====
+		fsRepo, err := repositories.NewFilesystemRepo(context.Background(), frontendLogicalName, frontendURL, testContext)
+		assert.Nil(err)
+		root, _ := fsRepo.GetRoot()
+		newDoc := func(name string) repositories.FilesystemEntry {
+			doc, _ := fsRepo.CreateEntry(repositories.FilesystemEntry{
+				LogicalName: name, ParentFileID: root.EntityID, OwnerUserId: repositories.GROUPS_ADMIN, IsDocument: true,
+			})
+			return doc
+		}
+		workshop, social, untagged := newDoc("workshop"), newDoc("social"), newDoc("untagged")
+		repo := repositories.NewTagsRepo(context.Background(), testContext)
+
+		events, err := repo.CreateTag("events", repositories.NoParentTag)
+		assert.Nil(err)
+		workshops, err := repo.CreateTag("workshops", events.TagID)
+		assert.Nil(err)
+		socials, err := repo.CreateTag("socials", events.TagID)
+		assert.Nil(err)
+		assert.True(testContext.WillFail(func() error { _, err := repo.CreateTag("workshops", events.TagID); return err }))
+
+		assert.Nil(repo.TagEntity(workshop.EntityID, workshops.TagID))
+		assert.Nil(repo.TagEntity(workshop.EntityID, workshops.TagID))
+		assert.Nil(repo.TagEntity(social.EntityID, socials.TagID))
+
+		// ==== Assertions ====
+		if tags, err := repo.GetEntityTags(workshop.EntityID); assert.Nil(err) {
+			assert.Equal([]repositories.Tag{workshops}, tags)
+		}
+
+		// filtering by a tag includes the tags nested within it
+		if children, err := repo.GetChildrenWithTag(root.EntityID, events.TagID); assert.Nil(err) {
+			assert.ElementsMatch([]uuid.UUID{workshop.EntityID, social.EntityID}, children)
+			assert.NotContains(children, untagged.EntityID)
+		}
+
+		if children, err := repo.GetChildrenWithTag(root.EntityID, workshops.TagID); assert.Nil(err) {
+			assert.Equal([]uuid.UUID{workshop.EntityID}, children)
+		}
+
+		// deleting a tag deletes the tags nested within it
+		assert.Nil(repo.DeleteTag(events.TagID))
+		if tags, err := repo.GetEntityTags(workshop.EntityID); assert.Nil(err) {
+			assert.Empty(tags)
+		}
+	})
+}
@@ -0,0 +1,37 @@
+package repositories
+
+import (
+	"github.com/google/uuid"
+)
+
+// Implements TokensRepository
+type tokensRepository struct {
+	embeddedContext
+}
+
+// CreateToken records a newly issued token
+func (rep tokensRepository) CreateToken(token AuthToken) error {
+	return rep.db.Exec(rep.ctx, "INSERT INTO auth_tokens (TokenID, UID, Purpose, ExpiresAt) VALUES ($1, $2, $3, $4);",
+		[]interface{}{token.TokenID, token.UID, token.Purpose, token.ExpiresAt})
+}
+
+// RedeemToken atomically marks a token as used, the update only matches unused and unexpired tokens
+// so if the token cannot be redeemed the query returns no rows and an error is returned
+func (rep tokensRepository) RedeemToken(tokenID uuid.UUID, purpose string) (AuthToken, error) {
+	result := AuthToken{TokenID: tokenID, Purpose: purpose}
+	err := rep.db.Query(rep.ctx, `UPDATE auth_tokens SET UsedAt = NOW()
+			WHERE TokenID = $1 AND Purpose = $2 AND UsedAt IS NULL AND ExpiresAt > NOW()
+			RETURNING UID, ExpiresAt;`,
+		[]interface{}{tokenID, purpose}, &result.UID, &result.ExpiresAt)
+	if err != nil {
+		return AuthToken{}, err
+	}
+
+	return result, nil
+}
+
+// InvalidateTokensForPerson marks all outstanding tokens of a specific purpose for a person as used
+func (rep tokensRepository) InvalidateTokensForPerson(UID int, purpose string) error {
+	return rep.db.Exec(rep.ctx, "UPDATE auth_tokens SET UsedAt = NOW() WHERE UID = $1 AND Purpose = $2 AND UsedAt IS NULL;",
+		[]interface{}{UID, purpose})
+}
@@ -0,0 +1,77 @@
+package repositories
+
+import (
+	"errors"
+
+	"github.com/jackc/pgx/v4"
+)
+
+// Implements TwoFactorRepository
+type twoFactorRepository struct {
+	embeddedContext
+}
+
+// GetTwoFactor fetches the 2FA enrolment of a person, a person that has never enrolled has an empty (disabled) enrolment
+func (rep twoFactorRepository) GetTwoFactor(UID int) (TwoFactor, error) {
+	result := TwoFactor{UID: UID}
+	err := rep.db.Query(rep.ctx, "SELECT Secret, Enabled FROM two_factor WHERE UID = $1;", []interface{}{UID},
+		&result.Secret, &result.Enabled)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return TwoFactor{UID: UID}, nil
+	} else if err != nil {
+		return TwoFactor{}, err
+	}
+
+	return result, nil
+}
+
+// BeginEnrolment stores a new (unconfirmed) secret for a person, it has no effect if 2FA is already enabled
+func (rep twoFactorRepository) BeginEnrolment(UID int, secret string) error {
+	return rep.db.Exec(rep.ctx, `INSERT INTO two_factor (UID, Secret) VALUES ($1, $2)
+			ON CONFLICT (UID) DO UPDATE SET Secret = EXCLUDED.Secret, LastUsedStep = NULL
+			WHERE two_factor.Enabled = false;`, []interface{}{UID, secret})
+}
+
+// Enable confirms a person's enrolment and replaces their recovery codes, step is the time step of the code they confirmed with
+func (rep twoFactorRepository) Enable(UID int, step int64, recoveryCodeHashes []string) error {
+	// a single statement so that enabling and issuing the recovery codes happen atomically
+	return rep.db.Exec(rep.ctx, `WITH enabled AS (
+				UPDATE two_factor SET Enabled = true, EnabledAt = NOW(), LastUsedStep = $2 WHERE UID = $1
+			), cleared AS (
+				DELETE FROM recovery_codes WHERE UID = $1
+			)
+			INSERT INTO recovery_codes (UID, CodeHash) SELECT $1, unnest($3::text[]);`,
+		[]interface{}{UID, step, recoveryCodeHashes})
+}
+
+// Disable removes a person's enrolment along with their recovery codes
+func (rep twoFactorRepository) Disable(UID int) error {
+	return rep.db.Exec(rep.ctx, `WITH cleared AS (
+				DELETE FROM recovery_codes WHERE UID = $1
+			)
+			DELETE FROM two_factor WHERE UID = $1;`, []interface{}{UID})
+}
+
+// MarkStepUsed records that a code from a time step has been accepted, it returns false if a code from
+// that step (or a later one) was already used
+func (rep twoFactorRepository) MarkStepUsed(UID int, step int64) (bool, error) {
+	return rep.affectsRow(`UPDATE two_factor SET LastUsedStep = $2
+			WHERE UID = $1 AND (LastUsedStep IS NULL OR LastUsedStep < $2) RETURNING UID;`, []interface{}{UID, step})
+}
+
+// ConsumeRecoveryCode marks a recovery code as used, it returns false if the code doesn't exist or was already used
+func (rep twoFactorRepository) ConsumeRecoveryCode(UID int, codeHash string) (bool, error) {
+	return rep.affectsRow(`UPDATE recovery_codes SET UsedAt = NOW()
+			WHERE UID = $1 AND CodeHash = $2 AND UsedAt IS NULL RETURNING UID;`, []interface{}{UID, codeHash})
+}
+
+// affectsRow runs a query that returns a single UID and reports if it returned anything
+func (rep twoFactorRepository) affectsRow(query string, args []interface{}) (bool, error) {
+	var UID int
+	err := rep.db.Query(rep.ctx, query, args, &UID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+
+	return err == nil, err
+}
@@ -1,6 +1,8 @@
 package repositories
 
 import (
+	"context"
+
 	"cms.csesoc.unsw.edu.au/database/contexts"
 	"cms.csesoc.unsw.edu.au/environment"
 )
@@ -33,9 +35,11 @@ func (ni nullableID) Scan(src interface{}) error {
 }
 
 // small struct that can be embedded into repository implementations
-// contains some methods for exposing a test context
+// contains the database the repository queries and the context (usually that of the current request)
+// its queries are made under, as well as some methods for exposing a test context
 type embeddedContext struct {
-	ctx contexts.DatabaseContext
+	ctx context.Context
+	db  contexts.DatabaseContext
 }
 
 // utility function for testing
@@ -44,5 +48,5 @@ func (rep embeddedContext) GetContext() contexts.DatabaseContext {
 		panic("not in a testing environment")
 	}
 
-	return rep.ctx
+	return rep.db
 }
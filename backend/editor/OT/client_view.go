@@ -2,6 +2,7 @@ package editor
 
 import (
 	"cms.csesoc.unsw.edu.au/editor/OT/operations"
+	"cms.csesoc.unsw.edu.au/pkg/cmsjson"
 	"github.com/gorilla/websocket"
 )
 
@@ -14,16 +15,23 @@ import (
 type clientView struct {
 	socket *websocket.Conn
 
+	// configuration is the configuration of the document the client is editing,
+	// the client's operations are parsed with it
+	configuration cmsjson.Configuration
+
 	sendOp              chan operations.Operation
 	sendAcknowledgement chan empty
+	sendRejection       chan cmsjson.ValidationErrors
 	sendTerminateSignal chan empty
 }
 
-func newClient(socket *websocket.Conn) *clientView {
+func newClient(socket *websocket.Conn, configuration cmsjson.Configuration) *clientView {
 	return &clientView{
 		socket:              socket,
+		configuration:       configuration,
 		sendOp:              make(chan operations.Operation),
 		sendAcknowledgement: make(chan empty),
+		sendRejection:       make(chan cmsjson.ValidationErrors),
 		sendTerminateSignal: make(chan empty),
 	}
 }
@@ -45,6 +53,14 @@ func (c *clientView) run(serverPipe pipe, terminatePipe alertLeaving) {
 			// push the acknowledgement down the websocket
 			break
 
+		case invalid := <-c.sendRejection:
+			// tell the client which values its operation would have made invalid
+			c.socket.WriteJSON(struct {
+				Type   string   `json:"type"`
+				Reason string   `json:"reason"`
+				Errors []string `json:"errors"`
+			}{"rejected", "invalid", invalid.Messages()})
+
 		case <-c.sendTerminateSignal:
 			// looks like we've been told to terminate by the documentServer
 			// propagate this to the client and close this connection
@@ -54,7 +70,7 @@ func (c *clientView) run(serverPipe pipe, terminatePipe alertLeaving) {
 		default:
 			if _, msg, err := c.socket.ReadMessage(); err == nil {
 				// push the update to the documentServer
-				if request, err := operations.ParseOperation(string(msg)); err == nil {
+				if request, err := c.parseOperation(msg); err == nil {
 					serverPipe(request)
 				}
 			} else {
@@ -65,3 +81,9 @@ func (c *clientView) run(serverPipe pipe, terminatePipe alertLeaving) {
 		}
 	}
 }
+
+// parseOperation parses an operation sent by the client, the operation can carry any
+// component declared by the frontend the document belongs to
+func (c *clientView) parseOperation(msg []byte) (operations.Operation, error) {
+	return operations.ParseOperationWith(c.configuration, string(msg))
+}
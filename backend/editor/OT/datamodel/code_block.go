@@ -0,0 +1,20 @@
+package datamodel
+
+import "reflect"
+
+// @implements Component
+type CodeBlock struct {
+	CodeBlockID       string
+	CodeBlockLanguage string `validate:"maxLength=32"`
+	CodeBlockSource   string
+}
+
+// Get returns the reflect.Value corresponding to a specific field
+func (c CodeBlock) Get(field string) (reflect.Value, error) {
+	return getField(c, field)
+}
+
+// Set returns a copy of the CodeBlock with a specific field set to a reflect.Value
+func (c CodeBlock) Set(field string, value reflect.Value) (Component, error) {
+	return setField(c, field, value)
+}
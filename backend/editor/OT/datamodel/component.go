@@ -1,8 +1,57 @@
 package datamodel
 
-import "reflect"
+import (
+	"errors"
+	"fmt"
+	"reflect"
 
+	"cms.csesoc.unsw.edu.au/pkg/cmsjson"
+)
+
+// Component is a block within a document, components are values so Set returns a copy of the component with the
+// field updated rather than updating the component in place. The rules a field's values must satisfy are declared
+// with validate (and enum) struct tags, see cmsjson.Validate
 type Component interface {
 	Get(string) (reflect.Value, error)
-	Set(string, reflect.Value) error
+	Set(string, reflect.Value) (Component, error)
+}
+
+// Block is an element of a document's content, it has no methods so that the components frontends declare in a schema
+// (which are built at runtime and so can't have any) can be registered against it. The built in blocks are Components
+type Block interface{}
+
+// getField fetches the value of one of a component's fields
+func getField(component Component, field string) (reflect.Value, error) {
+	value := reflect.ValueOf(component).FieldByName(field)
+	if !value.IsValid() {
+		return reflect.Value{}, fmt.Errorf("%T has no field %s", component, field)
+	}
+
+	return value, nil
+}
+
+// setField copies a component and updates one of its fields, the new value must satisfy the field's rules
+func setField(component Component, field string, value reflect.Value) (Component, error) {
+	updated := reflect.New(reflect.TypeOf(component)).Elem()
+	updated.Set(reflect.ValueOf(component))
+
+	structField, ok := updated.Type().FieldByName(field)
+	switch {
+	case !ok:
+		return nil, fmt.Errorf("%T has no field %s", component, field)
+	case !value.IsValid() || !value.Type().AssignableTo(structField.Type):
+		return nil, fmt.Errorf("%s must be a %v", field, structField.Type)
+	}
+
+	var invalid cmsjson.ValidationErrors
+	if err := cmsjson.ValidateField(structField, value); err != nil {
+		if errors.As(err, &invalid) {
+			return nil, invalid.At("/" + cmsjson.EscapePointer(field))
+		}
+
+		return nil, err
+	}
+
+	updated.FieldByIndex(structField.Index).Set(value)
+	return updated.Interface().(Component), nil
 }
@@ -0,0 +1,52 @@
+package datamodel
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetReturnsUpdatedCopy(t *testing.T) {
+	assert := assert.New(t)
+
+	paragraph := Paragraph{ParagraphID: "1", ParagraphAlign: "left"}
+	updated, err := paragraph.Set("ParagraphAlign", reflect.ValueOf("center"))
+	if assert.Nil(err) {
+		assert.Equal(Paragraph{ParagraphID: "1", ParagraphAlign: "center"}, updated)
+		assert.Equal("left", paragraph.ParagraphAlign)
+
+		value, err := updated.Get("ParagraphAlign")
+		assert.Nil(err)
+		assert.Equal("center", value.String())
+	}
+
+	_, err = paragraph.Set("ParagraphAlign", reflect.ValueOf("justified"))
+	assert.Equal(`/ParagraphAlign: must be one of left, right, center but was "justified"`, err.Error())
+
+	_, err = paragraph.Set("ParagraphChildren", reflect.ValueOf([]Text{{Text: "click me", Link: "javascript:alert(1)"}}))
+	assert.Equal(`/ParagraphChildren/0/Link: links must use one of the schemes http, https, mailto but used "javascript"`, err.Error())
+
+	_, err = paragraph.Set("ParagraphAlign", reflect.ValueOf(3))
+	assert.NotNil(err)
+
+	_, err = paragraph.Set("ParagraphColour", reflect.ValueOf("red"))
+	assert.NotNil(err)
+}
+
+func TestSetValidatesEveryComponent(t *testing.T) {
+	assert := assert.New(t)
+
+	_, err := Image{ImageDocumentID: "1", ImageSource: "morb.png"}.Set("ImageSource", reflect.ValueOf(""))
+	assert.Equal("/ImageSource: required field is missing", err.Error())
+
+	_, err = Heading{HeadingLevel: 1}.Set("HeadingLevel", reflect.ValueOf(7))
+	assert.Equal("/HeadingLevel: must be at most 6", err.Error())
+
+	_, err = Embed{EmbedURL: "https://youtube.com/1"}.Set("EmbedURL", reflect.ValueOf("youtube.com/1"))
+	assert.Equal("/EmbedURL: must be an absolute URL", err.Error())
+
+	updated, err := Embed{EmbedURL: "https://youtube.com/1"}.Set("EmbedURL", reflect.ValueOf("https://vimeo.com/1"))
+	assert.Nil(err)
+	assert.Equal(Embed{EmbedURL: "https://vimeo.com/1"}, updated)
+}
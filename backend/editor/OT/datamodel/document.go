@@ -4,7 +4,7 @@ package datamodel
 type Document struct {
 	DocumentName string
 	DocumentId   string
-	Content      []Component
+	Content      []Block
 }
 
 // IsExposed is the required registration for our type
@@ -0,0 +1,21 @@
+package datamodel
+
+import "reflect"
+
+// @implements Component
+type Embed struct {
+	EmbedID  string
+	EmbedURL string `validate:"required,url,maxLength=2048,schemes=http|https"`
+	// EmbedProvider is the service hosting the embedded content (eg: youtube), it determines how the URL is rendered
+	EmbedProvider string `validate:"maxLength=64"`
+}
+
+// Get returns the reflect.Value corresponding to a specific field
+func (e Embed) Get(field string) (reflect.Value, error) {
+	return getField(e, field)
+}
+
+// Set returns a copy of the Embed with a specific field set to a reflect.Value
+func (e Embed) Set(field string, value reflect.Value) (Component, error) {
+	return setField(e, field, value)
+}
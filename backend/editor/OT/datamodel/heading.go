@@ -0,0 +1,20 @@
+package datamodel
+
+import "reflect"
+
+// @implements Component
+type Heading struct {
+	HeadingID       string
+	HeadingLevel    int `validate:"min=1,max=6"`
+	HeadingChildren []Text
+}
+
+// Get returns the reflect.Value corresponding to a specific field
+func (h Heading) Get(field string) (reflect.Value, error) {
+	return getField(h, field)
+}
+
+// Set returns a copy of the Heading with a specific field set to a reflect.Value
+func (h Heading) Set(field string, value reflect.Value) (Component, error) {
+	return setField(h, field, value)
+}
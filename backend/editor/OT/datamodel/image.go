@@ -1,28 +1,19 @@
 package datamodel
 
-import (
-	"errors"
-	"reflect"
-)
+import "reflect"
 
 // @implements the Component interface
 type Image struct {
-	ImageDocumentID string
-	ImageSource     string
+	ImageDocumentID string `validate:"required"`
+	ImageSource     string `validate:"required,maxLength=2048,schemes=http|https"`
 }
 
 // Get returns the reflect.Value corresponding to a specific field
 func (i Image) Get(field string) (reflect.Value, error) {
-	return reflect.ValueOf(i).FieldByName(field), nil
+	return getField(i, field)
 }
 
-// Set sets a reflect.Value given a specific field
-func (i Image) Set(field string, value reflect.Value) error {
-	reflectionField := reflect.ValueOf(i).FieldByName(field)
-	if reflectionField.IsValid() {
-		reflectionField.Set(value)
-		return nil
-	}
-
-	return errors.New("invalid field provided")
+// Set returns a copy of the Image with a specific field set to a reflect.Value
+func (i Image) Set(field string, value reflect.Value) (Component, error) {
+	return setField(i, field, value)
 }
@@ -0,0 +1,26 @@
+package datamodel
+
+import "reflect"
+
+// @implements Component
+type List struct {
+	ListID      string
+	ListOrdered bool
+	ListItems   []ListItem
+}
+
+// ListItem is a single item of a list, items can contain nested items of their own
+type ListItem struct {
+	ListItemChildren []Text
+	ListItemNested   []ListItem
+}
+
+// Get returns the reflect.Value corresponding to a specific field
+func (l List) Get(field string) (reflect.Value, error) {
+	return getField(l, field)
+}
+
+// Set returns a copy of the List with a specific field set to a reflect.Value
+func (l List) Set(field string, value reflect.Value) (Component, error) {
+	return setField(l, field, value)
+}
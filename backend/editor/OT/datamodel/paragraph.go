@@ -1,20 +1,17 @@
 package datamodel
 
-import (
-	"errors"
-	"reflect"
-)
+import "reflect"
 
 // @implements Component
 type Paragraph struct {
 	ParagraphID       string
-	ParagraphAlign    string
+	ParagraphAlign    string `enum:"left|right|center"`
 	ParagraphChildren []Text
 }
 
 type Text struct {
 	Text      string
-	Link      string
+	Link      string `validate:"maxLength=2048,schemes=http|https|mailto"`
 	Bold      bool
 	Italic    bool
 	Underline bool
@@ -22,21 +19,10 @@ type Text struct {
 
 // Get returns the reflect.Value corresponding to a specific field
 func (p Paragraph) Get(field string) (reflect.Value, error) {
-	return reflect.ValueOf(p).FieldByName(field), nil
+	return getField(p, field)
 }
 
-// Set sets a reflect.Value given a specific field
-func (p Paragraph) Set(field string, value reflect.Value) error {
-	inputType := value.Kind()
-	if field == "ParagraphAlign" {
-		fieldValue := value.String()
-		isValidAllignment := inputType == reflect.String &&
-			(fieldValue == "left" || fieldValue == "right" || fieldValue == "center")
-		if !isValidAllignment {
-			return errors.New("ParagraphAlign data must be a string of 'left', 'right' or 'center'")
-		}
-	}
-	reflectionField := reflect.ValueOf(p).FieldByName(field)
-	reflectionField.Set(value)
-	return nil
+// Set returns a copy of the Paragraph with a specific field set to a reflect.Value
+func (p Paragraph) Set(field string, value reflect.Value) (Component, error) {
+	return setField(p, field, value)
 }
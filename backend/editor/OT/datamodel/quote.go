@@ -0,0 +1,20 @@
+package datamodel
+
+import "reflect"
+
+// @implements Component
+type Quote struct {
+	QuoteID          string
+	QuoteChildren    []Text
+	QuoteAttribution string `validate:"maxLength=256"`
+}
+
+// Get returns the reflect.Value corresponding to a specific field
+func (q Quote) Get(field string) (reflect.Value, error) {
+	return getField(q, field)
+}
+
+// Set returns a copy of the Quote with a specific field set to a reflect.Value
+func (q Quote) Set(field string, value reflect.Value) (Component, error) {
+	return setField(q, field, value)
+}
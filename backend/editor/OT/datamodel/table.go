@@ -0,0 +1,29 @@
+package datamodel
+
+import "reflect"
+
+// @implements Component
+type Table struct {
+	TableID string
+	// TableHasHeader determines if the first row is a header row
+	TableHasHeader bool
+	TableRows      []TableRow
+}
+
+type TableRow struct {
+	TableCells []TableCell
+}
+
+type TableCell struct {
+	TableCellChildren []Text
+}
+
+// Get returns the reflect.Value corresponding to a specific field
+func (t Table) Get(field string) (reflect.Value, error) {
+	return getField(t, field)
+}
+
+// Set returns a copy of the Table with a specific field set to a reflect.Value
+func (t Table) Set(field string, value reflect.Value) (Component, error) {
+	return setField(t, field, value)
+}
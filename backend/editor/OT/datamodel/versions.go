@@ -0,0 +1,176 @@
+package datamodel
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// Stored documents record the version of the datamodel they were written with under VersionKey, documents
+// written before versioning was introduced have no version and are treated as version 0. Whenever the datamodel
+// changes in a way that breaks stored documents (eg: a field of Paragraph is renamed) the version is bumped by
+// registering an upgrade from the previous version in Upgrades, stored documents are then upgraded when they
+// are next read (or in bulk with the upgrade-documents command)
+
+// VersionKey is the top level key of a stored document holding its datamodel version
+const VersionKey = "SchemaVersion"
+
+// Upgrade upgrades a document from one version of the datamodel to the next, documents are provided as generic
+// JSON objects (with numbers as json.Number) since the Go types of older versions no longer exist
+type Upgrade func(document map[string]interface{}) error
+
+// UpgradeRegistry is the sequence of upgrades for a datamodel, the nth upgrade upgrades version n to n+1
+type UpgradeRegistry struct {
+	upgrades []Upgrade
+}
+
+// ErrNewerVersion is returned when upgrading a document written by a newer version of the datamodel
+var ErrNewerVersion = errors.New("document was written by a newer version of the datamodel")
+
+// NewUpgradeRegistry instantiates a registry from a sequence of upgrades, the nth upgrade upgrades version n to n+1
+func NewUpgradeRegistry(upgrades ...Upgrade) UpgradeRegistry {
+	return UpgradeRegistry{upgrades: upgrades}
+}
+
+// Upgrades is the registry of upgrades for the CMS datamodel, new upgrades must be appended to the end
+var Upgrades = NewUpgradeRegistry(
+	// 0 -> 1: documents written before versioning already match the first versioned datamodel
+	func(document map[string]interface{}) error { return nil },
+)
+
+// CurrentVersion is the version of the datamodel new documents are written with
+func (r UpgradeRegistry) CurrentVersion() int {
+	return len(r.upgrades)
+}
+
+// Version fetches the datamodel version of a stored document, ok is false if the contents are not a datamodel
+// document (ie. not a JSON object)
+func (r UpgradeRegistry) Version(contents []byte) (version int, ok bool, err error) {
+	document, ok := decodeDocument(contents)
+	if !ok {
+		return 0, false, nil
+	}
+
+	version, err = documentVersion(document)
+	return version, true, err
+}
+
+// Upgrade upgrades a stored document to the current version, changed is false (and contents are returned as is)
+// if the document is already up to date or isn't a datamodel document at all
+func (r UpgradeRegistry) Upgrade(contents []byte) (upgraded []byte, changed bool, err error) {
+	document, ok := decodeDocument(contents)
+	if !ok {
+		return contents, false, nil
+	}
+
+	version, err := documentVersion(document)
+	switch {
+	case err != nil:
+		return nil, false, err
+	case version > r.CurrentVersion():
+		return nil, false, fmt.Errorf("%w (version %d)", ErrNewerVersion, version)
+	case version == r.CurrentVersion():
+		return contents, false, nil
+	}
+
+	for ; version < r.CurrentVersion(); version++ {
+		if err := r.upgrades[version](document); err != nil {
+			return nil, false, fmt.Errorf("failed to upgrade from version %d to %d: %w", version, version+1, err)
+		}
+	}
+
+	document[VersionKey] = r.CurrentVersion()
+	upgraded, err = json.Marshal(document)
+	return upgraded, err == nil, err
+}
+
+// Stamp marks a document sent by a client as belonging to the current version, clients always work with the
+// current datamodel so unversioned contents are stamped rather than upgraded. Contents that already have a
+// version are upgraded as usual
+func (r UpgradeRegistry) Stamp(contents []byte) ([]byte, error) {
+	document, ok := decodeDocument(contents)
+	if !ok {
+		return contents, nil
+	} else if _, hasVersion := document[VersionKey]; hasVersion {
+		upgraded, _, err := r.Upgrade(contents)
+		return upgraded, err
+	}
+
+	document[VersionKey] = r.CurrentVersion()
+	return json.Marshal(document)
+}
+
+// RenameField builds an upgrade renaming a field of every component of the given type (eg: "paragraph")
+func RenameField(componentType string, from string, to string) Upgrade {
+	return func(document map[string]interface{}) error {
+		return forEachComponent(document, componentType, func(component map[string]interface{}) {
+			if value, ok := component[from]; ok {
+				component[to] = value
+				delete(component, from)
+			}
+		})
+	}
+}
+
+// AddField builds an upgrade adding a field with a default value to every component of the given type
+func AddField(componentType string, field string, defaultValue interface{}) Upgrade {
+	return func(document map[string]interface{}) error {
+		return forEachComponent(document, componentType, func(component map[string]interface{}) {
+			if _, ok := component[field]; !ok {
+				component[field] = defaultValue
+			}
+		})
+	}
+}
+
+// forEachComponent calls visit with every component in the content of a document that has the given type
+func forEachComponent(document map[string]interface{}, componentType string, visit func(map[string]interface{})) error {
+	content, ok := document["Content"]
+	if !ok || content == nil {
+		return nil
+	}
+
+	components, ok := content.([]interface{})
+	if !ok {
+		return errors.New("document content must be an array")
+	}
+
+	for _, element := range components {
+		if component, ok := element.(map[string]interface{}); ok && component["$type"] == componentType {
+			visit(component)
+		}
+	}
+
+	return nil
+}
+
+// decodeDocument decodes the contents of a stored document, ok is false if the contents aren't a JSON object
+func decodeDocument(contents []byte) (document map[string]interface{}, ok bool) {
+	decoder := json.NewDecoder(bytes.NewReader(contents))
+	decoder.UseNumber()
+	if err := decoder.Decode(&document); err != nil || document == nil {
+		return nil, false
+	}
+
+	return document, true
+}
+
+func documentVersion(document map[string]interface{}) (int, error) {
+	value, ok := document[VersionKey]
+	if !ok {
+		return 0, nil
+	}
+
+	number, ok := value.(json.Number)
+	if !ok {
+		return 0, fmt.Errorf("%s must be a number", VersionKey)
+	}
+
+	version, err := number.Int64()
+	if err != nil || version < 0 {
+		return 0, fmt.Errorf("%s must be a non-negative integer", VersionKey)
+	}
+
+	return int(version), nil
+}
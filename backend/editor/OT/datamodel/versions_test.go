@@ -0,0 +1,77 @@
+package datamodel
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// paragraphUpgrades simulates two breaking changes to the datamodel: ParagraphAlign being renamed to
+// ParagraphAlignment and paragraphs gaining a ParagraphIndent field
+var paragraphUpgrades = NewUpgradeRegistry(
+	func(document map[string]interface{}) error { return nil },
+	RenameField("paragraph", "ParagraphAlign", "ParagraphAlignment"),
+	AddField("paragraph", "ParagraphIndent", 0),
+)
+
+const legacyDocument = `{
+	"DocumentName": "legacy",
+	"Content": [
+		{"$type": "paragraph", "ParagraphAlign": "center", "ParagraphChildren": []},
+		{"$type": "image", "ImageSource": "morb.png"}
+	]
+}`
+
+func TestUpgradeAppliesEveryUpgrade(t *testing.T) {
+	assert := assert.New(t)
+
+	upgraded, changed, err := paragraphUpgrades.Upgrade([]byte(legacyDocument))
+	if assert.Nil(err) && assert.True(changed) {
+		assert.JSONEq(`{
+			"DocumentName": "legacy",
+			"SchemaVersion": 3,
+			"Content": [
+				{"$type": "paragraph", "ParagraphAlignment": "center", "ParagraphIndent": 0, "ParagraphChildren": []},
+				{"$type": "image", "ImageSource": "morb.png"}
+			]
+		}`, string(upgraded))
+	}
+
+	// upgrading only starts from the version of the document
+	upgraded, changed, err = paragraphUpgrades.Upgrade([]byte(`{"SchemaVersion": 2, "Content": [{"$type": "paragraph", "ParagraphAlign": "left"}]}`))
+	if assert.Nil(err) && assert.True(changed) {
+		assert.JSONEq(`{"SchemaVersion": 3, "Content": [{"$type": "paragraph", "ParagraphAlign": "left", "ParagraphIndent": 0}]}`, string(upgraded))
+	}
+}
+
+func TestUpgradeLeavesCurrentDocumentsAlone(t *testing.T) {
+	assert := assert.New(t)
+
+	for _, contents := range []string{`{"SchemaVersion": 3, "Content": []}`, `[]`, `hello world`, ``} {
+		upgraded, changed, err := paragraphUpgrades.Upgrade([]byte(contents))
+		assert.Nil(err)
+		assert.False(changed)
+		assert.Equal(contents, string(upgraded))
+	}
+
+	_, _, err := paragraphUpgrades.Upgrade([]byte(`{"SchemaVersion": 4}`))
+	assert.True(errors.Is(err, ErrNewerVersion))
+
+	_, _, err = paragraphUpgrades.Upgrade([]byte(`{"SchemaVersion": "one"}`))
+	assert.NotNil(err)
+}
+
+func TestStampMarksClientDocumentsAsCurrent(t *testing.T) {
+	assert := assert.New(t)
+
+	stamped, err := paragraphUpgrades.Stamp([]byte(`{"Content": [{"$type": "paragraph", "ParagraphAlignment": "left"}]}`))
+	if assert.Nil(err) {
+		assert.JSONEq(`{"SchemaVersion": 3, "Content": [{"$type": "paragraph", "ParagraphAlignment": "left"}]}`, string(stamped))
+	}
+
+	version, isDocument, err := paragraphUpgrades.Version([]byte(`{"SchemaVersion": 1}`))
+	assert.Nil(err)
+	assert.True(isDocument)
+	assert.Equal(1, version)
+}
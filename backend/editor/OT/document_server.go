@@ -1,6 +1,8 @@
 package editor
 
 import (
+	"errors"
+	"fmt"
 	"log"
 	"sync"
 
@@ -15,29 +17,112 @@ type documentServer struct {
 	// strategy or a more appropriate ds
 	// state management
 	ID        uuid.UUID
-	state     cmsjson.AstNode
+	state     cmsjson.PersistentAST
 	stateLock sync.Mutex
 
+	// configuration is the cmsjson configuration of the frontend the document belongs to (see operations.Registry),
+	// it determines which components the document and the operations applied to it can contain
+	configuration cmsjson.Configuration
+
+	// revisions holds the state of the document after each of the last maxRevisions operations in the operation
+	// history (revisions[0] is the state after firstRevision operations), states are persistent ASTs so each revision
+	// shares everything that wasn't modified with the revision before it. Readers only hold the revisionsLock while
+	// fetching a revision, not while reading it
+	revisions     []cmsjson.PersistentAST
+	firstRevision int
+	revisionsLock sync.RWMutex
+
 	clients     map[int]*clientState
 	clientsLock sync.Mutex
 
 	operationHistory []operations.Operation
 }
 
+// maxRevisions is the number of revisions a document server holds onto, older revisions are dropped so that long
+// editing sessions don't keep every state the document has been in alive
+const maxRevisions = 1024
+
 type clientState struct {
 	*clientView
 	canSendOps bool
 }
 
-// todo: newDocumentServer should take an initial state
-func newDocumentServer() *documentServer {
-	// ideally state shouldn't be a string due to its immutability
-	// any update requires the allocation + copy of a new string in memory
-	return &documentServer{
-		state:       nil,
-		stateLock:   sync.Mutex{},
-		clients:     make(map[int]*clientState),
-		clientsLock: sync.Mutex{},
+// newDocumentServer creates a server for a document, the initial state is the document before any operations
+// have been applied to it
+func newDocumentServer(configuration cmsjson.Configuration, initialState cmsjson.AstNode) (*documentServer, error) {
+	server := &documentServer{
+		stateLock:     sync.Mutex{},
+		configuration: configuration,
+		clients:       make(map[int]*clientState),
+		clientsLock:   sync.Mutex{},
+	}
+
+	if err := server.setInitialState(initialState); err != nil {
+		return nil, err
+	}
+
+	return server, nil
+}
+
+// setInitialState sets the state of the document before any operations have been applied to it
+func (s *documentServer) setInitialState(state cmsjson.AstNode) error {
+	persistentState, err := cmsjson.NewPersistentAST(state)
+	if err != nil {
+		return err
+	}
+
+	s.stateLock.Lock()
+	defer s.stateLock.Unlock()
+	s.revisionsLock.Lock()
+	defer s.revisionsLock.Unlock()
+
+	s.state = persistentState
+	s.revisions = []cmsjson.PersistentAST{persistentState}
+	s.firstRevision = 0
+	return nil
+}
+
+// snapshot fetches the state of the document once the given number of operations had been applied, the snapshot
+// is immutable so it can be read without any locks while the document continues to be edited. Only the last
+// maxRevisions revisions are available
+func (s *documentServer) snapshot(revision int) (cmsjson.AstNode, error) {
+	s.revisionsLock.RLock()
+	defer s.revisionsLock.RUnlock()
+
+	latest := s.firstRevision + len(s.revisions) - 1
+	if revision < 0 || revision > latest {
+		return nil, fmt.Errorf("revision %d does not exist, the document has %d revisions", revision, latest+1)
+	} else if revision < s.firstRevision {
+		return nil, fmt.Errorf("revision %d has been dropped, the oldest revision is %d", revision, s.firstRevision)
+	}
+
+	return s.revisions[revision-s.firstRevision], nil
+}
+
+// latestSnapshot fetches the current state of the document along with its revision number, see snapshot
+func (s *documentServer) latestSnapshot() (cmsjson.AstNode, int) {
+	s.revisionsLock.RLock()
+	defer s.revisionsLock.RUnlock()
+
+	if len(s.revisions) == 0 {
+		return nil, 0
+	}
+
+	return s.revisions[len(s.revisions)-1], s.firstRevision + len(s.revisions) - 1
+}
+
+// addRevision records the state of the document after an operation, the oldest revision is dropped once there
+// are more than maxRevisions of them
+func (s *documentServer) addRevision(state cmsjson.PersistentAST) {
+	s.revisionsLock.Lock()
+	defer s.revisionsLock.Unlock()
+
+	s.revisions = append(s.revisions, state)
+	if len(s.revisions) > maxRevisions {
+		// the slot is cleared so the dropped state can be collected before the backing array is reallocated
+		s.revisions[0] = nil
+		s.revisions = s.revisions[1:]
+		s.firstRevision++
 	}
 }
 
@@ -118,18 +203,29 @@ func (s *documentServer) buildClientPipe(clientID int, workerWorkHandle chan fun
 
 			// apply the operation locally and log the new operation
 			transformedOperation := s.transformOperation(op)
-			s.operationHistory = append(s.operationHistory, transformedOperation)
-
 			if !transformedOperation.IsNoOp {
 				newState, err := op.ApplyTo(s.state)
-				if err != nil {
+				var invalid cmsjson.ValidationErrors
+				switch {
+				case errors.As(err, &invalid):
+					// invalid edits are rejected, they never make it into the document so other clients never see them
+					s.stateLock.Unlock()
+					log.Printf("rejected invalid operation from client %d: %v", clientID, invalid)
+					go func() { thisClient.sendRejection <- invalid }()
+					return
+				case err != nil:
 					log.Fatal(err)
 					clientState.sendTerminateSignal <- empty{}
-				} else {
-					s.state = newState
+				default:
+					// operations applied to a persistent AST produce another one, so this doesn't copy anything
+					if s.state, err = cmsjson.NewPersistentAST(newState); err != nil {
+						log.Fatal(err)
+					}
 				}
 			}
 
+			s.operationHistory = append(s.operationHistory, transformedOperation)
+			s.addRevision(s.state)
 			s.stateLock.Unlock()
 
 			// propagate updates to all connected clients except this one
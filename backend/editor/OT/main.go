@@ -4,6 +4,7 @@ import (
 	"log"
 	"net/http"
 
+	"cms.csesoc.unsw.edu.au/editor/OT/operations"
 	"github.com/google/uuid"
 	"github.com/gorilla/websocket"
 )
@@ -26,13 +27,19 @@ func EditEndpoint(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	targetServer, err := GetDocumentServerFactoryInstance().FetchDocumentServer(uuid.MustParse(requestedDocument[0]), operations.CmsJsonConf)
+	if err != nil {
+		log.Println(err)
+		w.WriteHeader(500)
+		return
+	}
+
 	ws, err := Upgrader.Upgrade(w, r, nil)
 	if err != nil {
 		log.Println(err)
 	}
 
-	wsClient := newClient(ws)
-	targetServer := GetDocumentServerFactoryInstance().FetchDocumentServer(uuid.MustParse(requestedDocument[0]))
+	wsClient := newClient(ws, targetServer.configuration)
 	commPipe, terminatePipe := targetServer.connectClient(wsClient)
 
 	go wsClient.run(commPipe, terminatePipe)
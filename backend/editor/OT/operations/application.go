@@ -1,7 +1,10 @@
 package operations
 
 import (
+	"errors"
 	"fmt"
+	"strconv"
+	"strings"
 
 	"cms.csesoc.unsw.edu.au/pkg/cmsjson"
 )
@@ -18,6 +21,11 @@ func Traverse(document cmsjson.AstNode, subpaths []int) (cmsjson.AstNode, cmsjso
 		if node, _ := curr.JsonObject(); node != nil {
 			curr = node[pathValue]
 		} else if node, _ := curr.JsonArray(); node != nil {
+			if pathIndex == lastNode && pathValue == len(node) {
+				// appending to an array, the value doesn't exist yet
+				return prev, nil, nil
+			}
+
 			curr = node[pathValue]
 		} else if node, _ := curr.JsonPrimitive(); node != nil {
 			if pathIndex != lastNode {
@@ -29,6 +37,10 @@ func Traverse(document cmsjson.AstNode, subpaths []int) (cmsjson.AstNode, cmsjso
 	return prev, curr, nil
 }
 
+// ApplyTo applies an operation to a document and returns the updated document, operations that would leave an invalid
+// value in the document (see cmsjson.ValidateAST) are rejected with cmsjson.ValidationErrors locating the invalid
+// values within the document. Persistent documents (see cmsjson.PersistentAST) are left untouched, the operation is
+// applied to a new revision of the document instead
 func (op Operation) ApplyTo(document cmsjson.AstNode) (cmsjson.AstNode, error) {
 	parent, _, err := Traverse(document, op.Path)
 	if err != nil {
@@ -36,5 +48,49 @@ func (op Operation) ApplyTo(document cmsjson.AstNode) (cmsjson.AstNode, error) {
 	}
 
 	applicationIndex := op.Path[len(op.Path)-1]
-	return op.Operation.Apply(parent, applicationIndex, op.OperationType)
+	result := document
+	if persistent, isPersistent := document.(cmsjson.PersistentAST); isPersistent {
+		result, err = persistent.Update(op.Path, func(parent cmsjson.AstNode, index int) error {
+			_, err := op.Operation.Apply(parent, index, op.OperationType)
+			return err
+		})
+	} else {
+		_, err = op.Operation.Apply(parent, applicationIndex, op.OperationType)
+	}
+
+	var invalid cmsjson.ValidationErrors
+	switch {
+	case errors.As(err, &invalid):
+		return nil, invalid.At(Pointer(document, op.Path))
+	case err != nil:
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// Pointer converts a path into the JSON pointer (RFC 6901) of the value it points at
+func Pointer(document cmsjson.AstNode, subpaths []int) string {
+	pointer := strings.Builder{}
+	curr := document
+
+	for _, pathValue := range subpaths {
+		if node, _ := curr.JsonObject(); node != nil && pathValue < len(node) {
+			pointer.WriteString("/" + cmsjson.EscapePointer(node[pathValue].GetKey()))
+			curr = node[pathValue]
+		} else if node, _ := curr.JsonArray(); node != nil {
+			// array elements are identified by their index, the index may be one past the end when inserting
+			pointer.WriteString("/" + strconv.Itoa(pathValue))
+			if pathValue >= len(node) {
+				break
+			}
+
+			curr = node[pathValue]
+		} else {
+			// primitives are always at the end of the path
+			break
+		}
+	}
+
+	return pointer.String()
 }
@@ -11,6 +11,9 @@ import (
 // @implements OperationModel
 type ArrayOperation struct {
 	NewValue float64
+
+	// Splice inserts NewValue before the element at the index rather than replacing it
+	Splice bool
 }
 
 // TransformAgainst is the ArrayOperation implementation of the operationModel interface
@@ -26,7 +29,9 @@ func (arrOp ArrayOperation) Apply(parentNode cmsjson.AstNode, applicationIndex i
 			return nil, fmt.Errorf("invalid application index, index %d out of bounds for array of size %d", applicationIndex, len(children))
 		}
 
-		if applicationType == Insert {
+		if applicationType == Insert && arrOp.Splice {
+			err = parentNode.InsertArrayElement(applicationIndex, cmsjson.ASTFromValue(arrOp.NewValue))
+		} else if applicationType == Insert {
 			operandAsAst := cmsjson.ASTFromValue(arrOp.NewValue)
 			err = parentNode.UpdateOrAddArrayElement(applicationIndex, operandAsAst)
 		} else {
@@ -0,0 +1,289 @@
+package operations
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"cms.csesoc.unsw.edu.au/algorithms"
+	"cms.csesoc.unsw.edu.au/pkg/cmsjson"
+)
+
+// Diff computes the operations that turn one document into another (both of the same type), the operations are
+// applied in order to from. Strings are diffed character by character and arrays element by element (using the
+// LCS of the two arrays), elements that are changed in place are diffed recursively so an edit to a single
+// paragraph results in string operations rather than the paragraph being replaced. This allows a full document
+// saved by a client that doesn't speak OT to be merged into a live editing session
+func Diff(configuration cmsjson.Configuration, from, to cmsjson.AstNode) ([]Operation, error) {
+	differ := differ{configuration: configuration, operations: []Operation{}}
+	if err := differ.diff([]int{}, from, to); err != nil {
+		return nil, err
+	}
+
+	return differ.operations, nil
+}
+
+// differ accumulates the operations computed by Diff
+type differ struct {
+	configuration cmsjson.Configuration
+	operations    []Operation
+}
+
+func (d *differ) emit(path []int, operationType EditType, model OperationModel) {
+	d.operations = append(d.operations, Operation{
+		Path:          append([]int{}, path...),
+		OperationType: operationType,
+		Operation:     model,
+	})
+}
+
+// diff diffs two nodes of the same type located at path
+func (d *differ) diff(path []int, from, to cmsjson.AstNode) error {
+	fromValue, fromType := from.JsonPrimitive()
+	toValue, toType := to.JsonPrimitive()
+	if fromValue != nil || toValue != nil {
+		if fromType != toType {
+			return fmt.Errorf("%v can't be changed into a %v", fromType, toType)
+		}
+
+		return d.diffPrimitive(path, fromValue, toValue, fromType)
+	}
+
+	if fromFields, fromType := from.JsonObject(); fromFields != nil {
+		toFields, toType := to.JsonObject()
+		if fromType != toType || len(fromFields) != len(toFields) {
+			return fmt.Errorf("%v can't be changed into a %v", fromType, toType)
+		}
+
+		for i := range fromFields {
+			if err := d.diff(append(path, i), fromFields[i], toFields[i]); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	fromElements, elementType := from.JsonArray()
+	toElements, _ := to.JsonArray()
+	if toElements == nil {
+		return errors.New("an array can't be changed into a value that isn't an array")
+	}
+
+	return d.diffArray(path, fromElements, toElements, elementType)
+}
+
+// diffPrimitive diffs two primitives of the same type
+func (d *differ) diffPrimitive(path []int, from, to interface{}, primitiveType reflect.Type) error {
+	switch {
+	case reflect.DeepEqual(from, to):
+		return nil
+	case primitiveType.Kind() == reflect.Int && toFloat(from) == toFloat(to):
+		return nil
+	}
+
+	switch primitiveType.Kind() {
+	case reflect.String:
+		d.diffString(path, from.(string), to.(string))
+	case reflect.Int:
+		// primitive operations are applied to the primitive itself rather than its parent
+		d.emit(append(path, 0), Insert, IntegerOperation{NewValue: int(toFloat(to))})
+	case reflect.Bool:
+		d.emit(append(path, 0), Insert, BooleanOperation{NewValue: to.(bool)})
+	default:
+		return fmt.Errorf("%v fields can't be updated", primitiveType)
+	}
+
+	return nil
+}
+
+// diffString emits the string operations turning one string into another, each run of changed characters is a
+// single operation
+func (d *differ) diffString(path []int, from, to string) {
+	fromCharacters, toCharacters := strings.Split(from, ""), strings.Split(to, "")
+
+	// string operations index bytes while the diff is of characters, offsets[i] is the byte offset of the ith character
+	offsets := make([]int, len(fromCharacters)+1)
+	for i, character := range fromCharacters {
+		offsets[i+1] = offsets[i] + len(character)
+	}
+
+	shift := 0
+	for _, hunk := range hunks(algorithms.ComputeDiff(fromCharacters, toCharacters)) {
+		start := offsets[hunk.start] + shift
+		removed := offsets[hunk.start+hunk.removed] - offsets[hunk.start]
+		added := strings.Join(hunk.added, "")
+
+		// ranges are inclusive so an empty range ends just before it starts
+		if added == "" {
+			d.emit(path, Delete, StringOperation{RangeStart: start, RangeEnd: start + removed - 1})
+		} else {
+			d.emit(path, Insert, StringOperation{RangeStart: start, RangeEnd: start + removed - 1, NewValue: added})
+		}
+
+		shift += len(added) - removed
+	}
+}
+
+// diffArray emits the operations turning one array into another, elements that are only in from are removed and
+// elements only in to are inserted, when an element is swapped for another of the same type the element is diffed
+// instead
+func (d *differ) diffArray(path []int, from, to []cmsjson.AstNode, elementType reflect.Type) error {
+	// elements are compared by their marshalled value
+	fromKeys, toKeys := make([]string, len(from)), make([]string, len(to))
+	elements := map[string]cmsjson.AstNode{}
+	for i, element := range from {
+		fromKeys[i] = d.configuration.MarshallAST(element)
+	}
+	for i, element := range to {
+		toKeys[i] = d.configuration.MarshallAST(element)
+		elements[toKeys[i]] = element
+	}
+
+	shift := 0
+	for _, hunk := range hunks(algorithms.ComputeDiff(fromKeys, toKeys)) {
+		removed := from[hunk.start : hunk.start+hunk.removed]
+		added := make([]cmsjson.AstNode, len(hunk.added))
+		for i, key := range hunk.added {
+			added[i] = elements[key]
+		}
+
+		if err := d.diffHunk(path, hunk.start+shift, removed, added, elementType); err != nil {
+			return err
+		}
+
+		shift += len(added) - len(removed)
+	}
+
+	return nil
+}
+
+// diffHunk emits the operations replacing a run of elements starting at index with another run of elements, the
+// runs are aligned by the types of their elements (using their LCS) and aligned elements are diffed
+func (d *differ) diffHunk(path []int, index int, removed, added []cmsjson.AstNode, elementType reflect.Type) error {
+	typeOf := func(elements []cmsjson.AstNode) []string {
+		types := make([]string, len(elements))
+		for i, element := range elements {
+			if _, objectType := element.JsonObject(); objectType != nil {
+				types[i] = objectType.String()
+			}
+		}
+
+		return types
+	}
+
+	i, j := 0, 0
+	align := func(upTo int) error {
+		for ; i < upTo; i, j, index = i+1, j+1, index+1 {
+			if err := d.replaceElement(append(path, index), removed[i], added[j], elementType); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	for _, edit := range algorithms.ComputeDiff(typeOf(removed), typeOf(added)) {
+		if err := align(edit.Index); err != nil {
+			return err
+		}
+
+		if edit.Type == algorithms.Remove {
+			d.emit(append(path, index), Delete, removalOperation(elementType))
+			i++
+			continue
+		}
+
+		model, err := elementOperation(d.configuration, elementType, json.RawMessage(d.configuration.MarshallAST(added[j])), true)
+		if err != nil {
+			return err
+		}
+
+		d.emit(append(path, index), Insert, model)
+		j, index = j+1, index+1
+	}
+
+	return align(len(removed))
+}
+
+// replaceElement turns an element of an array into another, the elements are diffed if possible otherwise the
+// element is replaced as a whole
+func (d *differ) replaceElement(path []int, from, to cmsjson.AstNode, elementType reflect.Type) error {
+	_, fromType := from.JsonObject()
+	_, toType := to.JsonObject()
+	if fromType != nil && fromType == toType {
+		// only commit to the element's diff if the whole element can be diffed
+		nested := differ{configuration: d.configuration, operations: d.operations}
+		if err := nested.diff(path, from, to); err == nil {
+			d.operations = nested.operations
+			return nil
+		}
+	}
+
+	if value, _ := from.JsonPrimitive(); value != nil && elementType.Kind() == reflect.String {
+		return d.diff(path, from, to)
+	}
+
+	model, err := elementOperation(d.configuration, elementType, json.RawMessage(d.configuration.MarshallAST(to)), false)
+	if err != nil {
+		return err
+	}
+
+	d.emit(path, Insert, model)
+	return nil
+}
+
+// removalOperation is the operation removing an element from an array of the given type
+func removalOperation(elementType reflect.Type) OperationModel {
+	if elementType.Kind() == reflect.Struct || elementType.Kind() == reflect.Interface {
+		return ObjectOperation{}
+	}
+
+	return ArrayOperation{}
+}
+
+// hunk is a run of consecutive elements that were removed from a sequence and the elements added in their place
+type hunk struct {
+	start, removed int
+	added          []string
+}
+
+// hunks groups an edit script (see algorithms.ComputeDiff) into hunks
+func hunks(script []algorithms.Edit) []hunk {
+	grouped := []hunk{}
+	for _, edit := range script {
+		current := len(grouped) - 1
+		end := 0
+		if current >= 0 {
+			end = grouped[current].start + grouped[current].removed
+		}
+
+		if current < 0 || edit.Index > end || edit.Index < grouped[current].start {
+			grouped = append(grouped, hunk{start: edit.Index})
+			current++
+		}
+
+		if edit.Type == algorithms.Remove {
+			grouped[current].removed++
+		} else {
+			grouped[current].added = append(grouped[current].added, edit.Val)
+		}
+	}
+
+	return grouped
+}
+
+// toFloat converts an integer that may have been parsed as a float64 (numbers within an AST are float64s until
+// they are updated) into a float64
+func toFloat(value interface{}) float64 {
+	switch value := value.(type) {
+	case int:
+		return float64(value)
+	case float64:
+		return value
+	}
+
+	return 0
+}
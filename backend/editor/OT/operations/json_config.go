@@ -17,15 +17,27 @@ var CmsJsonConf = cmsjson.Configuration{
 	// Registration for cmsmodel, when the LP is finally merged with CSESoc Projects
 	// this will also contain the registration for their data models
 	RegisteredTypes: map[reflect.Type]map[string]reflect.Type{
-		// TODO: later allow this to be dynamically swapped out for different front-ends
-		reflect.TypeOf((*datamodel.Component)(nil)).Elem(): {
+		// frontends can declare additional components at runtime, see Registry
+		reflect.TypeOf((*datamodel.Block)(nil)).Elem(): {
 			"image":     reflect.TypeOf(datamodel.Image{}),
 			"paragraph": reflect.TypeOf(datamodel.Paragraph{}),
+			"heading":   reflect.TypeOf(datamodel.Heading{}),
+			"list":      reflect.TypeOf(datamodel.List{}),
+			"codeBlock": reflect.TypeOf(datamodel.CodeBlock{}),
+			"table":     reflect.TypeOf(datamodel.Table{}),
+			"quote":     reflect.TypeOf(datamodel.Quote{}),
+			"embed":     reflect.TypeOf(datamodel.Embed{}),
 		},
 
 		reflect.TypeOf((*datamodel.DataType)(nil)).Elem(): {
 			"image":     reflect.TypeOf(datamodel.Image{}),
 			"paragraph": reflect.TypeOf(datamodel.Paragraph{}),
+			"heading":   reflect.TypeOf(datamodel.Heading{}),
+			"list":      reflect.TypeOf(datamodel.List{}),
+			"codeBlock": reflect.TypeOf(datamodel.CodeBlock{}),
+			"table":     reflect.TypeOf(datamodel.Table{}),
+			"quote":     reflect.TypeOf(datamodel.Quote{}),
+			"embed":     reflect.TypeOf(datamodel.Embed{}),
 		},
 
 		// Type registrations for the OperationModel
@@ -39,3 +51,10 @@ var CmsJsonConf = cmsjson.Configuration{
 		},
 	},
 }
+
+// SchemaRoots are the types clients exchange with the editor, the JSON Schema and TypeScript declarations served
+// to frontends are generated from them (see cmsjson.Configuration.JSONSchema)
+var SchemaRoots = []reflect.Type{
+	reflect.TypeOf(datamodel.Document{}),
+	reflect.TypeOf(Operation{}),
+}
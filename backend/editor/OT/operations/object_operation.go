@@ -11,6 +11,9 @@ import (
 // ObjectOperation represents an operation we perform on an object
 type ObjectOperation struct {
 	NewValue datamodel.DataType
+
+	// Splice inserts NewValue before the element at the index rather than replacing it (only within arrays)
+	Splice bool
 }
 
 // TransformAgainst is the ArrayOperation implementation of the operationModel interface
@@ -35,5 +38,25 @@ func (objOp ObjectOperation) Apply(parentNode cmsjson.AstNode, applicationIndex
 		}
 		return parentNode, err
 	}
-	return nil, errors.New("invalid application of an object operation, expected parent node to be an object")
+
+	// within arrays (eg: the content of a document) objects are inserted/replaced and removed as a whole
+	if children, _ := parentNode.JsonArray(); children != nil {
+		if applicationIndex < 0 || applicationIndex > len(children) {
+			return nil, fmt.Errorf("invalid application index, index %d out of bounds for array of size %d", applicationIndex, len(children))
+		}
+
+		switch {
+		case applicationType == Insert && objOp.Splice:
+			err = parentNode.InsertArrayElement(applicationIndex, cmsjson.ASTFromValue(objOp.NewValue))
+		case applicationType == Insert:
+			err = parentNode.UpdateOrAddArrayElement(applicationIndex, cmsjson.ASTFromValue(objOp.NewValue))
+		case applicationType == Delete:
+			err = parentNode.RemoveArrayElement(applicationIndex)
+		default:
+			err = errors.New("invalid edit type")
+		}
+		return parentNode, err
+	}
+
+	return nil, errors.New("invalid application of an object operation, expected parent node to be an object or an array")
 }
@@ -1,6 +1,8 @@
 package operations
 
 import (
+	"strings"
+
 	"cms.csesoc.unsw.edu.au/pkg/cmsjson"
 )
 
@@ -37,8 +39,14 @@ var NoOperation = Operation{IsNoOp: true, Operation: Noop{}}
 // Parse is a utility function that takes a JSON stream and parses the input into
 // a Request object
 func ParseOperation(request string) (Operation, error) {
+	return ParseOperationWith(CmsJsonConf, request)
+}
+
+// ParseOperationWith parses an operation using a specific configuration (eg: one fetched from a Registry)
+// so that the operation can carry components declared by a frontend's schema
+func ParseOperationWith(configuration cmsjson.Configuration, request string) (Operation, error) {
 	var operation Operation
-	if err := cmsjson.Unmarshall[Operation](CmsJsonConf, &operation, []byte(request)); err != nil {
+	if err := cmsjson.UnmarshallFrom[Operation](configuration, &operation, strings.NewReader(request)); err != nil {
 		return Operation{}, err
 	} else {
 		return operation, nil
@@ -0,0 +1,348 @@
+package operations
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+
+	"cms.csesoc.unsw.edu.au/pkg/cmsjson"
+)
+
+// JSON Patch (RFC 6902) lets clients that don't speak OT (eg: scripts) edit documents, patches are converted into
+// operations so that they go through the same pipeline as edits from the editor. Since documents have a fixed
+// shape the operations can express a subset of JSON Patch:
+//   - replace: strings, integers, booleans, numbers within arrays and objects within arrays (eg: components)
+//   - add: inserting into an array, adding an existing field of an object is a replace
+//   - remove: array elements, fields can't be removed (deleting from a string is exported as a replace)
+//   - move and copy: are a remove and/or an add
+//   - test: checked when converting the patch, no operation is produced
+
+// PatchOperation is a single operation of a JSON Patch document
+type PatchOperation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	From  string          `json:"from,omitempty"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+// FromPatch converts a JSON Patch document into the operations that perform the patch when applied (in order)
+// to the document, the document isn't modified. Objects within the patch are parsed using the configuration
+// (see ParseOperationWith) so components must be annotated with their $type
+func FromPatch(configuration cmsjson.Configuration, document cmsjson.AstNode, patch []byte) ([]Operation, error) {
+	var patchOperations []PatchOperation
+	if err := json.Unmarshal(patch, &patchOperations); err != nil {
+		return nil, fmt.Errorf("invalid JSON patch: %w", err)
+	}
+
+	// operations are applied to a scratch copy as they are converted since each patch operation sees the result of the last
+	scratch, err := cmsjson.CloneAST(document)
+	if err != nil {
+		return nil, err
+	}
+
+	converter := patchConverter{configuration: configuration, document: scratch}
+	operations := []Operation{}
+	for i, patchOperation := range patchOperations {
+		converted, err := converter.convert(patchOperation)
+		if err != nil {
+			return nil, fmt.Errorf("patch operation %d (%s %s): %w", i, patchOperation.Op, patchOperation.Path, err)
+		}
+
+		operations = append(operations, converted...)
+	}
+
+	return operations, nil
+}
+
+// ToPatch converts a sequence of operations into an equivalent JSON Patch document, the document is the state
+// the operations are applied to and isn't modified
+func ToPatch(configuration cmsjson.Configuration, document cmsjson.AstNode, operations []Operation) ([]PatchOperation, error) {
+	scratch, err := cmsjson.CloneAST(document)
+	if err != nil {
+		return nil, err
+	}
+
+	patch := []PatchOperation{}
+	for i, operation := range operations {
+		if operation.IsNoOp {
+			continue
+		}
+
+		// primitive operations (integer, boolean) point one past the primitive they update
+		target := operation.Path
+		switch operation.Operation.(type) {
+		case IntegerOperation, BooleanOperation:
+			target = target[:len(target)-1]
+		}
+
+		parentPointer, err := cmsjson.PathToPointer(scratch, target[:len(target)-1])
+		if err != nil {
+			return nil, fmt.Errorf("operation %d: %w", i, err)
+		}
+
+		parent, _, _ := Traverse(scratch, target)
+		elements, _ := parent.JsonArray()
+		isAdd := elements != nil && (target[len(target)-1] == len(elements) || isSplice(operation.Operation))
+
+		if _, err := operation.ApplyTo(scratch); err != nil {
+			return nil, fmt.Errorf("operation %d: %w", i, err)
+		}
+
+		pointer, _ := cmsjson.PathToPointer(scratch, target)
+		switch {
+		case operation.OperationType == Delete && elements != nil:
+			pointer = parentPointer + "/" + strconv.Itoa(target[len(target)-1])
+			patch = append(patch, PatchOperation{Op: "remove", Path: pointer})
+		default:
+			// deleting from a string (or primitive) leaves the field in place so it's exported as a replace
+			op := "replace"
+			if isAdd {
+				op = "add"
+			}
+
+			_, updated, _ := Traverse(scratch, target)
+			patch = append(patch, PatchOperation{Op: op, Path: pointer, Value: json.RawMessage(configuration.MarshallAST(updated))})
+		}
+	}
+
+	return patch, nil
+}
+
+// isSplice determines if an operation inserts into an array rather than replacing an element
+func isSplice(model OperationModel) bool {
+	switch model := model.(type) {
+	case ArrayOperation:
+		return model.Splice
+	case ObjectOperation:
+		return model.Splice
+	}
+
+	return false
+}
+
+// patchConverter converts patch operations into operations against a document
+type patchConverter struct {
+	configuration cmsjson.Configuration
+	document      cmsjson.AstNode
+}
+
+// convert converts a patch operation and applies the result to the converter's document
+func (c patchConverter) convert(patchOperation PatchOperation) ([]Operation, error) {
+	switch patchOperation.Op {
+	case "add":
+		return c.apply(c.add(patchOperation.Path, patchOperation.Value))
+	case "replace":
+		return c.apply(c.replace(patchOperation.Path, patchOperation.Value))
+	case "remove":
+		return c.apply(c.remove(patchOperation.Path))
+	case "test":
+		return nil, c.test(patchOperation.Path, patchOperation.Value)
+	case "copy", "move":
+		value, err := c.valueAt(patchOperation.From)
+		if err != nil {
+			return nil, err
+		}
+
+		// the value is removed first so that the add sees the document without it
+		operations := []Operation{}
+		if patchOperation.Op == "move" {
+			if operations, err = c.apply(c.remove(patchOperation.From)); err != nil {
+				return nil, err
+			}
+		}
+
+		added, err := c.apply(c.add(patchOperation.Path, value))
+		return append(operations, added...), err
+	}
+
+	return nil, fmt.Errorf("unknown patch operation %q", patchOperation.Op)
+}
+
+// apply applies converted operations to the converter's document
+func (c patchConverter) apply(operations []Operation, err error) ([]Operation, error) {
+	if err != nil {
+		return nil, err
+	}
+
+	for _, operation := range operations {
+		if _, err := operation.ApplyTo(c.document); err != nil {
+			return nil, err
+		}
+	}
+
+	return operations, nil
+}
+
+// add converts an add, add either appends to an array or behaves like replace
+func (c patchConverter) add(pointer string, value json.RawMessage) ([]Operation, error) {
+	tokens, err := cmsjson.ParsePointer(pointer)
+	if err != nil {
+		return nil, err
+	} else if len(tokens) == 0 {
+		return nil, errors.New("the document itself can't be replaced")
+	}
+
+	parentPath, err := cmsjson.ResolvePointer(c.document, cmsjson.FormatPointer(tokens[:len(tokens)-1]))
+	if err != nil {
+		return nil, err
+	}
+
+	_, parent, _ := Traverse(c.document, parentPath)
+	elements, elementType := parent.JsonArray()
+	if elements == nil {
+		return c.replace(pointer, value)
+	}
+
+	index := len(elements)
+	if last := tokens[len(tokens)-1]; last != "-" {
+		if index, err = strconv.Atoi(last); err != nil || index < 0 || index > len(elements) {
+			return nil, fmt.Errorf("%q is not an index of the array", last)
+		}
+	}
+
+	model, err := elementOperation(c.configuration, elementType, value, true)
+	if err != nil {
+		return nil, err
+	}
+
+	return []Operation{{Path: append(parentPath, index), OperationType: Insert, Operation: model}}, nil
+}
+
+// replace converts a replace, the value must already exist
+func (c patchConverter) replace(pointer string, value json.RawMessage) ([]Operation, error) {
+	path, err := c.resolve(pointer)
+	if err != nil {
+		return nil, err
+	}
+
+	parent, target, _ := Traverse(c.document, path)
+	if elements, elementType := parent.JsonArray(); elements != nil {
+		model, err := elementOperation(c.configuration, elementType, value, false)
+		if err != nil {
+			return nil, err
+		}
+
+		return []Operation{{Path: path, OperationType: Insert, Operation: model}}, nil
+	}
+
+	current, fieldType := target.JsonPrimitive()
+	if current == nil {
+		return nil, errors.New("only primitive fields and array elements can be replaced")
+	}
+
+	switch fieldType.Kind() {
+	case reflect.String:
+		var replacement string
+		if err := json.Unmarshal(value, &replacement); err != nil {
+			return nil, errors.New("expected a string")
+		}
+
+		// string operations splice the (inclusive) range with the new value
+		model := StringOperation{RangeStart: 0, RangeEnd: len(current.(string)) - 1, NewValue: replacement}
+		return []Operation{{Path: path, OperationType: Insert, Operation: model}}, nil
+
+	case reflect.Int:
+		var replacement int
+		if err := json.Unmarshal(value, &replacement); err != nil {
+			return nil, errors.New("expected an integer")
+		}
+
+		return []Operation{{Path: append(path, 0), OperationType: Insert, Operation: IntegerOperation{NewValue: replacement}}}, nil
+
+	case reflect.Bool:
+		var replacement bool
+		if err := json.Unmarshal(value, &replacement); err != nil {
+			return nil, errors.New("expected a boolean")
+		}
+
+		return []Operation{{Path: append(path, 0), OperationType: Insert, Operation: BooleanOperation{NewValue: replacement}}}, nil
+	}
+
+	return nil, fmt.Errorf("%v fields can't be replaced", fieldType)
+}
+
+// remove converts a remove, only array elements can be removed
+func (c patchConverter) remove(pointer string) ([]Operation, error) {
+	path, err := c.resolve(pointer)
+	if err != nil {
+		return nil, err
+	}
+
+	parent, _, _ := Traverse(c.document, path)
+	elements, elementType := parent.JsonArray()
+	if elements == nil {
+		return nil, errors.New("only array elements can be removed")
+	}
+
+	return []Operation{{Path: path, OperationType: Delete, Operation: removalOperation(elementType)}}, nil
+}
+
+// test checks that the value at a pointer is equal to the expected value
+func (c patchConverter) test(pointer string, expected json.RawMessage) error {
+	actual, err := c.valueAt(pointer)
+	if err != nil {
+		return err
+	}
+
+	var actualValue, expectedValue interface{}
+	if err := json.Unmarshal(expected, &expectedValue); err != nil {
+		return errors.New("test requires a value")
+	}
+
+	json.Unmarshal(actual, &actualValue)
+	if !reflect.DeepEqual(actualValue, expectedValue) {
+		return fmt.Errorf("test failed, the value is %s", actual)
+	}
+
+	return nil
+}
+
+// elementOperation builds the operation placing a value into an array holding elements of the given type, splice
+// determines whether the value is inserted or replaces the existing element
+func elementOperation(configuration cmsjson.Configuration, elementType reflect.Type, value json.RawMessage, splice bool) (OperationModel, error) {
+	switch elementType.Kind() {
+	case reflect.Float32, reflect.Float64:
+		var number float64
+		if err := json.Unmarshal(value, &number); err != nil {
+			return nil, errors.New("expected a number")
+		}
+
+		return ArrayOperation{NewValue: number, Splice: splice}, nil
+
+	case reflect.Struct, reflect.Interface:
+		// the value is parsed just like the value of an object operation sent by the editor
+		operation, err := ParseOperationWith(configuration, fmt.Sprintf(`{"Path": [], "Operation": {"$type": "objectOperation", "NewValue": %s}}`, value))
+		if err != nil {
+			return nil, fmt.Errorf("invalid object: %w", err)
+		}
+
+		model := operation.Operation.(ObjectOperation)
+		model.Splice = splice
+		return model, nil
+	}
+
+	return nil, fmt.Errorf("arrays of %v can only have elements removed", elementType)
+}
+
+// resolve resolves a pointer to an existing value (other than the document itself)
+func (c patchConverter) resolve(pointer string) ([]int, error) {
+	path, err := cmsjson.ResolvePointer(c.document, pointer)
+	if err == nil && len(path) == 0 {
+		return nil, errors.New("the document itself can't be replaced")
+	}
+
+	return path, err
+}
+
+// valueAt marshalls the value referenced by a pointer
+func (c patchConverter) valueAt(pointer string) (json.RawMessage, error) {
+	path, err := cmsjson.ResolvePointer(c.document, pointer)
+	if err != nil {
+		return nil, err
+	}
+
+	_, value, _ := Traverse(c.document, path)
+	return json.RawMessage(c.configuration.MarshallAST(value)), nil
+}
@@ -0,0 +1,97 @@
+package operations
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+
+	"cms.csesoc.unsw.edu.au/editor/OT/datamodel"
+	"cms.csesoc.unsw.edu.au/pkg/cmsjson"
+	"github.com/google/uuid"
+)
+
+// Registry holds the cmsjson configuration of each frontend, a frontend's configuration contains the built in
+// components (see CmsJsonConf) alongside the components declared in the frontend's schema. Frontends without
+// a schema just use CmsJsonConf
+type Registry struct {
+	lock           sync.RWMutex
+	configurations map[uuid.UUID]cmsjson.Configuration
+}
+
+// NewRegistry instantiates a new registry where every frontend uses the built in components
+func NewRegistry() *Registry {
+	return &Registry{configurations: map[uuid.UUID]cmsjson.Configuration{}}
+}
+
+// LoadRegistry loads the component schemas within a directory, the schema of a frontend is in <frontend id>.json
+func LoadRegistry(directory string) (*Registry, error) {
+	registry := NewRegistry()
+	schemas, err := filepath.Glob(filepath.Join(directory, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, schemaPath := range schemas {
+		frontendID, err := uuid.Parse(strings.TrimSuffix(filepath.Base(schemaPath), ".json"))
+		if err != nil {
+			return nil, fmt.Errorf("%s is not named after a frontend ID", schemaPath)
+		}
+
+		contents, err := os.ReadFile(schemaPath)
+		if err != nil {
+			return nil, err
+		}
+
+		var schema cmsjson.Schema
+		if err := json.Unmarshal(contents, &schema); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", schemaPath, err)
+		}
+
+		if err := registry.Register(frontendID, schema); err != nil {
+			return nil, fmt.Errorf("invalid schema %s: %w", schemaPath, err)
+		}
+	}
+
+	return registry, nil
+}
+
+// Register builds the components declared by a frontend's schema and registers them alongside the built in
+// components, it replaces any components previously registered for the frontend
+func (r *Registry) Register(frontendID uuid.UUID, schema cmsjson.Schema) error {
+	components, err := schema.Build()
+	if err != nil {
+		return err
+	}
+
+	configuration := CmsJsonConf
+	for _, registeredInterface := range []reflect.Type{
+		reflect.TypeOf((*datamodel.Block)(nil)).Elem(),
+		reflect.TypeOf((*datamodel.DataType)(nil)).Elem(),
+	} {
+		if configuration, err = configuration.Extend(registeredInterface, components); err != nil {
+			return err
+		}
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.configurations[frontendID] = configuration
+	return nil
+}
+
+// ConfigurationFor fetches the configuration documents belonging to a frontend must be parsed with
+func (r *Registry) ConfigurationFor(frontendID uuid.UUID) cmsjson.Configuration {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	if configuration, ok := r.configurations[frontendID]; ok {
+		return configuration
+	}
+
+	return CmsJsonConf
+}
@@ -72,7 +72,10 @@ func (arrOp StringOperation) Apply(parentNode cmsjson.AstNode, applicationIndex
 		return nil, fmt.Errorf("invalid edit type")
 	}
 
-	children[applicationIndex].UpdateOrAddPrimitiveElement(cmsjson.ASTFromValue(resultText))
+	if err := children[applicationIndex].UpdateOrAddPrimitiveElement(cmsjson.ASTFromValue(resultText)); err != nil {
+		return nil, err
+	}
+
 	return parentNode, nil
 }
 
@@ -0,0 +1,277 @@
+package tests
+
+import (
+	"testing"
+
+	"cms.csesoc.unsw.edu.au/editor/OT/datamodel"
+	"cms.csesoc.unsw.edu.au/editor/OT/operations"
+	"cms.csesoc.unsw.edu.au/pkg/cmsjson"
+	"github.com/stretchr/testify/assert"
+)
+
+// componentsDocument contains one of each of the built in components
+const componentsDocument = `{
+	"DocumentName": "components",
+	"DocumentId": "1",
+	"Content": [
+		{
+			"$type": "heading",
+			"HeadingID": "heading",
+			"HeadingLevel": 2,
+			"HeadingChildren": [{"Text": "Getting started", "Link": "", "Bold": false, "Italic": false, "Underline": false}]
+		},
+		{
+			"$type": "list",
+			"ListID": "list",
+			"ListOrdered": true,
+			"ListItems": [
+				{
+					"ListItemChildren": [{"Text": "install go", "Link": "", "Bold": true, "Italic": false, "Underline": false}],
+					"ListItemNested": [
+						{
+							"ListItemChildren": [{"Text": "1.19 or later", "Link": "", "Bold": false, "Italic": true, "Underline": false}],
+							"ListItemNested": []
+						}
+					]
+				}
+			]
+		},
+		{
+			"$type": "codeBlock",
+			"CodeBlockID": "code",
+			"CodeBlockLanguage": "go",
+			"CodeBlockSource": "fmt.Println(\"hello\")"
+		},
+		{
+			"$type": "table",
+			"TableID": "table",
+			"TableHasHeader": true,
+			"TableRows": [
+				{"TableCells": [{"TableCellChildren": [{"Text": "name", "Link": "", "Bold": true, "Italic": false, "Underline": false}]}]},
+				{"TableCells": [{"TableCellChildren": [{"Text": "morb", "Link": "", "Bold": false, "Italic": false, "Underline": false}]}]}
+			]
+		},
+		{
+			"$type": "quote",
+			"QuoteID": "quote",
+			"QuoteChildren": [{"Text": "it's morbin time", "Link": "", "Bold": false, "Italic": false, "Underline": false}],
+			"QuoteAttribution": "morbius"
+		},
+		{
+			"$type": "embed",
+			"EmbedID": "embed",
+			"EmbedURL": "https://www.youtube.com/watch?v=dQw4w9WgXcQ",
+			"EmbedProvider": "youtube"
+		}
+	]
+}`
+
+var expectedComponents = datamodel.Document{
+	DocumentName: "components",
+	DocumentId:   "1",
+	Content: []datamodel.Block{
+		datamodel.Heading{
+			HeadingID:       "heading",
+			HeadingLevel:    2,
+			HeadingChildren: []datamodel.Text{{Text: "Getting started"}},
+		},
+		datamodel.List{
+			ListID:      "list",
+			ListOrdered: true,
+			ListItems: []datamodel.ListItem{
+				{
+					ListItemChildren: []datamodel.Text{{Text: "install go", Bold: true}},
+					ListItemNested: []datamodel.ListItem{
+						{ListItemChildren: []datamodel.Text{{Text: "1.19 or later", Italic: true}}, ListItemNested: []datamodel.ListItem{}},
+					},
+				},
+			},
+		},
+		datamodel.CodeBlock{
+			CodeBlockID:       "code",
+			CodeBlockLanguage: "go",
+			CodeBlockSource:   `fmt.Println("hello")`,
+		},
+		datamodel.Table{
+			TableID:        "table",
+			TableHasHeader: true,
+			TableRows: []datamodel.TableRow{
+				{TableCells: []datamodel.TableCell{{TableCellChildren: []datamodel.Text{{Text: "name", Bold: true}}}}},
+				{TableCells: []datamodel.TableCell{{TableCellChildren: []datamodel.Text{{Text: "morb"}}}}},
+			},
+		},
+		datamodel.Quote{
+			QuoteID:          "quote",
+			QuoteChildren:    []datamodel.Text{{Text: "it's morbin time"}},
+			QuoteAttribution: "morbius",
+		},
+		datamodel.Embed{
+			EmbedID:       "embed",
+			EmbedURL:      "https://www.youtube.com/watch?v=dQw4w9WgXcQ",
+			EmbedProvider: "youtube",
+		},
+	},
+}
+
+func TestUnmarshallComponents(t *testing.T) {
+	assert := assert.New(t)
+
+	var document datamodel.Document
+	if assert.Nil(cmsjson.Unmarshall[datamodel.Document](operations.CmsJsonConf, &document, []byte(componentsDocument))) {
+		assert.Equal(expectedComponents, document)
+	}
+
+	ast, err := cmsjson.UnmarshallAST[datamodel.Document](operations.CmsJsonConf, componentsDocument)
+	if assert.Nil(err) {
+		// Content/1/ListItems/0/ListItemNested/0/ListItemChildren/0/Text
+		_, result, err := operations.Traverse(ast, []int{2, 1, 2, 0, 1, 0, 0, 0, 0})
+		if assert.Nil(err) {
+			text, _ := result.JsonPrimitive()
+			assert.Equal("1.19 or later", text)
+		}
+	}
+}
+
+func TestMarshallComponents(t *testing.T) {
+	assert := assert.New(t)
+
+	marshalled := operations.CmsJsonConf.Marshall(expectedComponents)
+
+	var document datamodel.Document
+	if assert.Nil(cmsjson.Unmarshall[datamodel.Document](operations.CmsJsonConf, &document, []byte(marshalled))) {
+		assert.Equal(expectedComponents, document)
+	}
+}
+
+func TestApplyComponentOperations(t *testing.T) {
+	assert := assert.New(t)
+
+	document, err := cmsjson.UnmarshallAST[datamodel.Document](operations.CmsJsonConf, componentsDocument)
+	if !assert.Nil(err) {
+		return
+	}
+
+	apply := func(request string, path []int, index int) cmsjson.AstNode {
+		operation, err := operations.ParseOperation(request)
+		if !assert.Nil(err) {
+			return nil
+		}
+
+		parent, _, err := operations.Traverse(document, path)
+		if !assert.Nil(err) {
+			return nil
+		}
+
+		result, err := operation.Operation.Apply(parent, index, operations.Insert)
+		assert.Nil(err)
+		return result
+	}
+
+	// Content/2/CodeBlockSource
+	result := apply(`{
+		"Path": [2, 2, 2],
+		"OperationType": 0,
+		"AcknowledgedServerOps": 0,
+		"IsNoOp": false,
+		"Operation": {"$type": "stringOperation", "RangeStart": 0, "RangeEnd": 0, "NewValue": "// f"}
+	}`, []int{2, 2, 2}, 2)
+	if result != nil {
+		fields, _ := result.JsonObject()
+		source, _ := fields[2].JsonPrimitive()
+		assert.Equal(`// fmt.Println("hello")`, source)
+	}
+
+	// Content/0/HeadingLevel
+	result = apply(`{
+		"Path": [2, 0, 1, 0],
+		"OperationType": 0,
+		"AcknowledgedServerOps": 0,
+		"IsNoOp": false,
+		"Operation": {"$type": "integerOperation", "NewValue": 3}
+	}`, []int{2, 0, 1, 0}, 0)
+	if result != nil {
+		level, _ := result.JsonPrimitive()
+		assert.Equal(3, level)
+	}
+
+	// Content/1/ListOrdered
+	result = apply(`{
+		"Path": [2, 1, 1, 0],
+		"OperationType": 0,
+		"AcknowledgedServerOps": 0,
+		"IsNoOp": false,
+		"Operation": {"$type": "booleanOperation", "NewValue": false}
+	}`, []int{2, 1, 1, 0}, 0)
+	if result != nil {
+		ordered, _ := result.JsonPrimitive()
+		assert.Equal(false, ordered)
+	}
+
+	// Content/5/EmbedID
+	result = apply(`{
+		"Path": [2, 5, 0],
+		"OperationType": 0,
+		"AcknowledgedServerOps": 0,
+		"IsNoOp": false,
+		"Operation": {
+			"$type": "objectOperation",
+			"NewValue": {"$type": "embed", "EmbedID": "video", "EmbedURL": "https://vimeo.com/1", "EmbedProvider": "vimeo"}
+		}
+	}`, []int{2, 5, 0}, 0)
+	if result != nil {
+		fields, _ := result.JsonObject()
+		id, _ := fields[0].JsonPrimitive()
+		assert.Equal("video", id)
+	}
+}
+
+func TestApplyRejectsInvalidEdits(t *testing.T) {
+	assert := assert.New(t)
+
+	document, err := cmsjson.UnmarshallAST[datamodel.Document](operations.CmsJsonConf, componentsDocument)
+	if !assert.Nil(err) {
+		return
+	}
+
+	// Content/0/HeadingLevel
+	operation, err := operations.ParseOperation(`{
+		"Path": [2, 0, 1, 0],
+		"OperationType": 0,
+		"AcknowledgedServerOps": 0,
+		"IsNoOp": false,
+		"Operation": {"$type": "integerOperation", "NewValue": 9}
+	}`)
+	if assert.Nil(err) {
+		_, err = operation.ApplyTo(document)
+		if assert.IsType(cmsjson.ValidationErrors{}, err) {
+			assert.Equal([]string{"/Content/0/HeadingLevel: must be at most 6"}, err.(cmsjson.ValidationErrors).Messages())
+		}
+	}
+
+	// Content/5/EmbedURL
+	operation, err = operations.ParseOperation(`{
+		"Path": [2, 5, 1],
+		"OperationType": 1,
+		"AcknowledgedServerOps": 0,
+		"IsNoOp": false,
+		"Operation": {"$type": "stringOperation", "RangeStart": 0, "RangeEnd": 7, "NewValue": ""}
+	}`)
+	if assert.Nil(err) {
+		_, err = operation.ApplyTo(document)
+		if assert.IsType(cmsjson.ValidationErrors{}, err) {
+			assert.Equal([]string{"/Content/5/EmbedURL: must be an absolute URL"}, err.(cmsjson.ValidationErrors).Messages())
+		}
+	}
+
+	// rejected edits leave the document untouched
+	_, result, _ := operations.Traverse(document, []int{2, 5, 1})
+	url, _ := result.JsonPrimitive()
+	assert.Equal("https://www.youtube.com/watch?v=dQw4w9WgXcQ", url)
+
+	// invalid documents can't be loaded either
+	_, err = cmsjson.UnmarshallAST[datamodel.Document](operations.CmsJsonConf, `{
+		"DocumentName": "invalid", "DocumentId": "1",
+		"Content": [{"$type": "paragraph", "ParagraphID": "1", "ParagraphAlign": "justified", "ParagraphChildren": []}]
+	}`)
+	assert.NotNil(err)
+}
@@ -0,0 +1,146 @@
+package tests
+
+import (
+	"testing"
+
+	"cms.csesoc.unsw.edu.au/editor/OT/datamodel"
+	"cms.csesoc.unsw.edu.au/editor/OT/operations"
+	"cms.csesoc.unsw.edu.au/pkg/cmsjson"
+	"github.com/stretchr/testify/assert"
+)
+
+// editedComponents is componentsDocument after a client has: edited the heading, removed the table,
+// inserted a paragraph after the list, swapped the embed for an image and appended a code block
+const editedComponents = `{
+	"DocumentName": "components",
+	"DocumentId": "1",
+	"Content": [
+		{
+			"$type": "heading",
+			"HeadingID": "heading",
+			"HeadingLevel": 1,
+			"HeadingChildren": [{"Text": "Getting stärted quickly", "Link": "", "Bold": false, "Italic": false, "Underline": false}]
+		},
+		{
+			"$type": "list",
+			"ListID": "list",
+			"ListOrdered": true,
+			"ListItems": [
+				{
+					"ListItemChildren": [{"Text": "install go", "Link": "", "Bold": true, "Italic": false, "Underline": false}],
+					"ListItemNested": []
+				}
+			]
+		},
+		{
+			"$type": "paragraph",
+			"ParagraphID": "intro",
+			"ParagraphAlign": "left",
+			"ParagraphChildren": [{"Text": "then run", "Link": "", "Bold": false, "Italic": false, "Underline": false}]
+		},
+		{
+			"$type": "codeBlock",
+			"CodeBlockID": "code",
+			"CodeBlockLanguage": "go",
+			"CodeBlockSource": "fmt.Println(\"hello\")"
+		},
+		{
+			"$type": "quote",
+			"QuoteID": "quote",
+			"QuoteChildren": [{"Text": "it's morbin time", "Link": "", "Bold": false, "Italic": false, "Underline": false}],
+			"QuoteAttribution": "michael morbius"
+		},
+		{
+			"$type": "image",
+			"ImageDocumentID": "1",
+			"ImageSource": "https://example.com/morb.png"
+		},
+		{
+			"$type": "codeBlock",
+			"CodeBlockID": "shell",
+			"CodeBlockLanguage": "sh",
+			"CodeBlockSource": "go run ."
+		}
+	]
+}`
+
+func TestDiffTurnsOneDocumentIntoAnother(t *testing.T) {
+	assert := assert.New(t)
+
+	from, err := cmsjson.UnmarshallAST[datamodel.Document](operations.CmsJsonConf, componentsDocument)
+	if !assert.Nil(err) {
+		return
+	}
+
+	to, err := cmsjson.UnmarshallAST[datamodel.Document](operations.CmsJsonConf, editedComponents)
+	if !assert.Nil(err) {
+		return
+	}
+
+	diff, err := operations.Diff(operations.CmsJsonConf, from, to)
+	if !assert.Nil(err) {
+		return
+	}
+
+	for _, operation := range diff {
+		_, err := operation.ApplyTo(from)
+		assert.Nil(err)
+	}
+
+	assert.JSONEq(operations.CmsJsonConf.MarshallAST(to), operations.CmsJsonConf.MarshallAST(from))
+
+	// changed strings are edited in place rather than replaced
+	assert.Contains(diff, operations.Operation{
+		Path:          []int{2, 0, 2, 0, 0},
+		OperationType: operations.Insert,
+		Operation:     operations.StringOperation{RangeStart: 10, RangeEnd: 10, NewValue: "ä"},
+	})
+	assert.Contains(diff, operations.Operation{
+		Path:          []int{2, 4, 2},
+		OperationType: operations.Insert,
+		Operation:     operations.StringOperation{RangeStart: 1, RangeEnd: 0, NewValue: "ichael m"},
+	})
+}
+
+func TestDiffOfIdenticalDocumentsIsEmpty(t *testing.T) {
+	assert := assert.New(t)
+
+	from, _ := cmsjson.UnmarshallAST[datamodel.Document](operations.CmsJsonConf, componentsDocument)
+	to, _ := cmsjson.UnmarshallAST[datamodel.Document](operations.CmsJsonConf, componentsDocument)
+
+	diff, err := operations.Diff(operations.CmsJsonConf, from, to)
+	assert.Nil(err)
+	assert.Empty(diff)
+}
+
+type NumbersData struct {
+	Numbers []float64
+}
+
+func TestDiffOfArrays(t *testing.T) {
+	assert := assert.New(t)
+
+	from, err := cmsjson.UnmarshallAST[NumbersData](operations.CmsJsonConf, `{"Numbers": [1, 2, 3, 4]}`)
+	if !assert.Nil(err) {
+		return
+	}
+
+	to, _ := cmsjson.UnmarshallAST[NumbersData](operations.CmsJsonConf, `{"Numbers": [0, 2, 4, 5]}`)
+	diff, err := operations.Diff(operations.CmsJsonConf, from, to)
+	if !assert.Nil(err) {
+		return
+	}
+
+	assert.Equal([]operations.Operation{
+		{Path: []int{0, 0}, OperationType: operations.Insert, Operation: operations.ArrayOperation{NewValue: 0}},
+		{Path: []int{0, 2}, OperationType: operations.Delete, Operation: operations.ArrayOperation{}},
+		{Path: []int{0, 3}, OperationType: operations.Insert, Operation: operations.ArrayOperation{NewValue: 5, Splice: true}},
+	}, diff)
+
+	for _, operation := range diff {
+		_, err := operation.ApplyTo(from)
+		assert.Nil(err)
+	}
+
+	assert.JSONEq(`{"Numbers": [0, 2, 4, 5]}`, operations.CmsJsonConf.MarshallAST(from))
+}
@@ -0,0 +1,82 @@
+package tests
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"cms.csesoc.unsw.edu.au/editor/OT/datamodel"
+	"cms.csesoc.unsw.edu.au/editor/OT/operations"
+	"cms.csesoc.unsw.edu.au/pkg/cmsjson"
+)
+
+// largeDocument builds a document containing the given number of paragraphs, each with a few runs of text
+func largeDocument(paragraphs int) datamodel.Document {
+	document := datamodel.Document{DocumentName: "large", DocumentId: "1"}
+	for i := 0; i < paragraphs; i++ {
+		document.Content = append(document.Content, datamodel.Paragraph{
+			ParagraphID:    fmt.Sprintf("paragraph-%d", i),
+			ParagraphAlign: "left",
+			ParagraphChildren: []datamodel.Text{
+				{Text: "the quick brown fox jumps over the lazy dog, "},
+				{Text: "it's \"morbin\" time", Bold: true},
+				{Text: "csesoc", Link: "https://csesoc.unsw.edu.au", Underline: true},
+			},
+		})
+	}
+
+	return document
+}
+
+func BenchmarkMarshall(b *testing.B) {
+	document := largeDocument(5000)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := operations.CmsJsonConf.MarshallTo(io.Discard, document); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkMarshallAST(b *testing.B) {
+	ast, err := cmsjson.UnmarshallAST[datamodel.Document](operations.CmsJsonConf, operations.CmsJsonConf.Marshall(largeDocument(5000)))
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := operations.CmsJsonConf.MarshallASTTo(io.Discard, ast); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkUnmarshall(b *testing.B) {
+	source := operations.CmsJsonConf.Marshall(largeDocument(5000))
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var document datamodel.Document
+		if err := cmsjson.UnmarshallFrom[datamodel.Document](operations.CmsJsonConf, &document, strings.NewReader(source)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkUnmarshallAST(b *testing.B) {
+	source := operations.CmsJsonConf.Marshall(largeDocument(5000))
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := cmsjson.UnmarshallASTFrom[datamodel.Document](operations.CmsJsonConf, strings.NewReader(source)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
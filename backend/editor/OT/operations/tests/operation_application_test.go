@@ -23,8 +23,8 @@ func (a ArraysData) Get(field string) (reflect.Value, error) {
 	return reflect.Value{}, nil
 }
 
-func (a ArraysData) Set(field string, value reflect.Value) error {
-	return nil
+func (a ArraysData) Set(field string, value reflect.Value) (datamodel.Component, error) {
+	return a, nil
 }
 
 var (
@@ -67,7 +67,7 @@ func setupDocument() cmsjson.AstNode {
 
 	config := cmsjson.Configuration{
 		RegisteredTypes: map[reflect.Type]map[string]reflect.Type{
-			reflect.TypeOf((*datamodel.Component)(nil)).Elem(): {
+			reflect.TypeOf((*datamodel.Block)(nil)).Elem(): {
 				"Image":      reflect.TypeOf(datamodel.Image{}),
 				"Paragraph":  reflect.TypeOf(datamodel.Paragraph{}),
 				"ArraysData": reflect.TypeOf(ArraysData{}),
@@ -419,3 +419,62 @@ func TestOutOfBoundsArrayIndex(t *testing.T) {
 	expectedErrorMsg := "Invalid"
 	assert.EqualErrorf(t, err, expectedErrorMsg, "Error should be: %v, got: %v", expectedErrorMsg, err)
 }
+
+func TestApplyToPersistentDocument(t *testing.T) {
+	assert := assert.New(t)
+
+	document, err := cmsjson.NewPersistentAST(setupDocument())
+	if !assert.Nil(err) {
+		return
+	}
+
+	// Content/0/ImageSource
+	stringOperation, err := operations.ParseOperation(`{
+		"Path": [2, 0, 1],
+		"OperationType": 0,
+		"AcknowledgedServerOps": 0,
+		"IsNoOp": false,
+		"Operation": {"$type": "stringOperation", "RangeStart": 5, "RangeEnd": 5, "NewValue": "0"}
+	}`)
+	if !assert.Nil(err) {
+		return
+	}
+
+	// Content/2/IntField
+	integerOperation, err := operations.ParseOperation(`{
+		"Path": [2, 2, 1, 0],
+		"OperationType": 0,
+		"AcknowledgedServerOps": 0,
+		"IsNoOp": false,
+		"Operation": {"$type": "integerOperation", "NewValue": 3}
+	}`)
+	if !assert.Nil(err) {
+		return
+	}
+
+	firstRevision, err := stringOperation.ApplyTo(document)
+	if !assert.Nil(err) {
+		return
+	}
+
+	secondRevision, err := integerOperation.ApplyTo(firstRevision)
+	if !assert.Nil(err) {
+		return
+	}
+
+	valueAt := func(document cmsjson.AstNode, path []int) interface{} {
+		_, node, err := operations.Traverse(document, path)
+		assert.Nil(err)
+		value, _ := node.JsonPrimitive()
+		return value
+	}
+
+	// each revision is left as it was when it was produced
+	assert.Equal("big_morb.png", valueAt(document, []int{2, 0, 1}))
+	assert.Equal("big_m0rb.png", valueAt(firstRevision, []int{2, 0, 1}))
+	assert.Equal("big_m0rb.png", valueAt(secondRevision, []int{2, 0, 1}))
+
+	assert.Equal(float64(7), valueAt(document, []int{2, 2, 1}))
+	assert.Equal(float64(7), valueAt(firstRevision, []int{2, 2, 1}))
+	assert.Equal(3, valueAt(secondRevision, []int{2, 2, 1}))
+}
@@ -0,0 +1,176 @@
+package tests
+
+import (
+	"encoding/json"
+	"testing"
+
+	"cms.csesoc.unsw.edu.au/editor/OT/datamodel"
+	"cms.csesoc.unsw.edu.au/editor/OT/operations"
+	"cms.csesoc.unsw.edu.au/pkg/cmsjson"
+	"github.com/stretchr/testify/assert"
+)
+
+const componentsPatch = `[
+	{"op": "test", "path": "/Content/0/HeadingLevel", "value": 2},
+	{"op": "replace", "path": "/Content/0/HeadingLevel", "value": 3},
+	{"op": "replace", "path": "/Content/1/ListOrdered", "value": false},
+	{"op": "replace", "path": "/Content/2/CodeBlockSource", "value": "fmt.Println(\"bye\")"},
+	{"op": "remove", "path": "/Content/3"},
+	{"op": "add", "path": "/Content/-", "value": {"$type": "codeBlock", "CodeBlockID": "shell", "CodeBlockLanguage": "sh", "CodeBlockSource": "go run ."}},
+	{"op": "move", "from": "/Content/3", "path": "/Content/-"}
+]`
+
+func TestFromPatch(t *testing.T) {
+	assert := assert.New(t)
+
+	document, err := cmsjson.UnmarshallAST[datamodel.Document](operations.CmsJsonConf, componentsDocument)
+	if !assert.Nil(err) {
+		return
+	}
+
+	converted, err := operations.FromPatch(operations.CmsJsonConf, document, []byte(componentsPatch))
+	if !assert.Nil(err) {
+		return
+	}
+
+	// converting the patch doesn't touch the document
+	_, level, _ := operations.Traverse(document, []int{2, 0, 1})
+	value, _ := level.JsonPrimitive()
+	assert.Equal(float64(2), value)
+
+	for _, operation := range converted {
+		_, err := operation.ApplyTo(document)
+		assert.Nil(err)
+	}
+
+	var patched datamodel.Document
+	if assert.Nil(cmsjson.Unmarshall[datamodel.Document](operations.CmsJsonConf, &patched, []byte(operations.CmsJsonConf.MarshallAST(document)))) {
+		assert.Len(patched.Content, 6)
+		assert.Equal(3, patched.Content[0].(datamodel.Heading).HeadingLevel)
+		assert.False(patched.Content[1].(datamodel.List).ListOrdered)
+		assert.Equal(`fmt.Println("bye")`, patched.Content[2].(datamodel.CodeBlock).CodeBlockSource)
+		assert.IsType(datamodel.Embed{}, patched.Content[3])
+		assert.Equal("shell", patched.Content[4].(datamodel.CodeBlock).CodeBlockID)
+		assert.IsType(datamodel.Quote{}, patched.Content[5])
+	}
+}
+
+func TestFromPatchRejectsUnsupportedPatches(t *testing.T) {
+	assert := assert.New(t)
+
+	document, err := cmsjson.UnmarshallAST[datamodel.Document](operations.CmsJsonConf, componentsDocument)
+	if !assert.Nil(err) {
+		return
+	}
+
+	for _, patch := range []string{
+		`[{"op": "test", "path": "/Content/0/HeadingLevel", "value": 4}]`,
+		`[{"op": "remove", "path": "/DocumentName"}]`,
+		`[{"op": "add", "path": "/Content/7", "value": {"$type": "embed", "EmbedID": "", "EmbedURL": "https://vimeo.com/1", "EmbedProvider": ""}}]`,
+		`[{"op": "add", "path": "/Content/0", "value": {"$type": "embed", "EmbedID": "", "EmbedURL": "", "EmbedProvider": ""}}]`,
+		`[{"op": "replace", "path": "/Content/0/HeadingLevel", "value": "three"}]`,
+		`[{"op": "replace", "path": "/Content/0/HeadingLevel", "value": 7}]`,
+		`[{"op": "replace", "path": "/Content/9/HeadingLevel", "value": 1}]`,
+		`[{"op": "shuffle", "path": "/Content"}]`,
+	} {
+		_, err := operations.FromPatch(operations.CmsJsonConf, document, []byte(patch))
+		assert.NotNil(err, patch)
+	}
+}
+
+func TestToPatch(t *testing.T) {
+	assert := assert.New(t)
+
+	document, err := cmsjson.UnmarshallAST[datamodel.Document](operations.CmsJsonConf, componentsDocument)
+	if !assert.Nil(err) {
+		return
+	}
+
+	converted, err := operations.FromPatch(operations.CmsJsonConf, document, []byte(componentsPatch))
+	if !assert.Nil(err) {
+		return
+	}
+
+	patch, err := operations.ToPatch(operations.CmsJsonConf, document, converted)
+	if !assert.Nil(err) {
+		return
+	}
+
+	marshalled, _ := json.Marshal(patch)
+	assert.JSONEq(`[
+		{"op": "replace", "path": "/Content/0/HeadingLevel", "value": 3},
+		{"op": "replace", "path": "/Content/1/ListOrdered", "value": false},
+		{"op": "replace", "path": "/Content/2/CodeBlockSource", "value": "fmt.Println(\"bye\")"},
+		{"op": "remove", "path": "/Content/3"},
+		{"op": "add", "path": "/Content/5", "value": {"$type": "codeBlock", "CodeBlockID": "shell", "CodeBlockLanguage": "sh", "CodeBlockSource": "go run ."}},
+		{"op": "remove", "path": "/Content/3"},
+		{"op": "add", "path": "/Content/5", "value": {"$type": "quote", "QuoteID": "quote", "QuoteAttribution": "morbius",
+			"QuoteChildren": [{"Text": "it's morbin time", "Link": "", "Bold": false, "Italic": false, "Underline": false}]}}
+	]`, string(marshalled))
+
+	// applying the exported patch gives the same operations
+	reconverted, err := operations.FromPatch(operations.CmsJsonConf, document, marshalled)
+	if assert.Nil(err) {
+		assert.Equal(len(converted), len(reconverted))
+	}
+}
+
+func TestToPatchOfDeletionsFromStrings(t *testing.T) {
+	assert := assert.New(t)
+
+	document, err := cmsjson.UnmarshallAST[datamodel.Document](operations.CmsJsonConf, componentsDocument)
+	if !assert.Nil(err) {
+		return
+	}
+
+	deletion, err := operations.ParseOperation(`{"Path": [2, 0, 2, 0, 0], "OperationType": 1, "Operation": {"$type": "stringOperation", "RangeStart": 0, "RangeEnd": 1, "NewValue": ""}}`)
+	if !assert.Nil(err) {
+		return
+	}
+
+	// the string can't be removed so the patch replaces it with what's left
+	patch, err := operations.ToPatch(operations.CmsJsonConf, document, []operations.Operation{deletion})
+	if assert.Nil(err) && assert.Len(patch, 1) {
+		assert.Equal("replace", patch[0].Op)
+		assert.Equal("/Content/0/HeadingChildren/0/Text", patch[0].Path)
+		assert.JSONEq(`"tting started"`, string(patch[0].Value))
+	}
+}
+
+// Test that a patch exported from a diff reproduces the diff when it's applied
+func TestDiffRoundTripsThroughPatches(t *testing.T) {
+	assert := assert.New(t)
+
+	from, err := cmsjson.UnmarshallAST[datamodel.Document](operations.CmsJsonConf, componentsDocument)
+	if !assert.Nil(err) {
+		return
+	}
+
+	to, err := cmsjson.UnmarshallAST[datamodel.Document](operations.CmsJsonConf, editedComponents)
+	if !assert.Nil(err) {
+		return
+	}
+
+	diff, err := operations.Diff(operations.CmsJsonConf, from, to)
+	if !assert.Nil(err) {
+		return
+	}
+
+	patch, err := operations.ToPatch(operations.CmsJsonConf, from, diff)
+	if !assert.Nil(err) {
+		return
+	}
+
+	marshalled, _ := json.Marshal(patch)
+	converted, err := operations.FromPatch(operations.CmsJsonConf, from, marshalled)
+	if !assert.Nil(err) {
+		return
+	}
+
+	for _, operation := range converted {
+		_, err := operation.ApplyTo(from)
+		assert.Nil(err)
+	}
+
+	assert.JSONEq(operations.CmsJsonConf.MarshallAST(to), operations.CmsJsonConf.MarshallAST(from))
+}
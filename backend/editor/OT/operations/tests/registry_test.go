@@ -0,0 +1,110 @@
+package tests
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"cms.csesoc.unsw.edu.au/editor/OT/datamodel"
+	"cms.csesoc.unsw.edu.au/editor/OT/operations"
+	"cms.csesoc.unsw.edu.au/pkg/cmsjson"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// calloutSchema declares a "callout" component: a coloured box containing some text
+const calloutSchema = `{
+	"types": {
+		"Text": {"fields": [{"name": "Text", "type": "string"}, {"name": "Bold", "type": "bool"}]}
+	},
+	"components": {
+		"callout": {"fields": [
+			{"name": "CalloutColour", "type": "string", "enum": ["red", "blue"]},
+			{"name": "CalloutChildren", "type": "array", "items": {"type": "Text"}}
+		]}
+	}
+}`
+
+const calloutDocument = `{
+	"DocumentName": "callouts",
+	"DocumentId": "1",
+	"Content": [
+		{
+			"$type": "callout",
+			"CalloutColour": "%s",
+			"CalloutChildren": [{"Text": "careful!", "Bold": true}]
+		}
+	]
+}`
+
+func TestRegistryIsPerFrontend(t *testing.T) {
+	assert := assert.New(t)
+
+	// ==== Setup ====
+	directory := t.TempDir()
+	frontendID := uuid.New()
+	assert.Nil(os.WriteFile(filepath.Join(directory, frontendID.String()+".json"), []byte(calloutSchema), 0o644))
+
+	registry, err := operations.LoadRegistry(directory)
+	if !assert.Nil(err) {
+		return
+	}
+
+	// ==== Assertions ====
+	configuration := registry.ConfigurationFor(frontendID)
+	document, err := cmsjson.UnmarshallAST[datamodel.Document](configuration, fmt.Sprintf(calloutDocument, "red"))
+	if assert.Nil(err) {
+		content, _ := document.JsonObject()
+		components, _ := content[2].JsonArray()
+		callout, calloutType := components[0].JsonObject()
+		assert.Equal("CalloutChildren", callout[1].GetKey())
+		assert.Equal(2, calloutType.NumField())
+	}
+
+	// enums are enforced
+	_, err = cmsjson.UnmarshallAST[datamodel.Document](configuration, fmt.Sprintf(calloutDocument, "green"))
+	assert.NotNil(err)
+
+	// other frontends only have the built in components
+	_, err = cmsjson.UnmarshallAST[datamodel.Document](registry.ConfigurationFor(uuid.New()), fmt.Sprintf(calloutDocument, "red"))
+	assert.NotNil(err)
+
+	// declared components can be carried by operations
+	operation, err := operations.ParseOperationWith(configuration, `{
+		"Path": [2, 0],
+		"OperationType": 0,
+		"AcknowledgedServerOps": 0,
+		"IsNoOp": false,
+		"Operation": {
+			"$type": "objectOperation",
+			"NewValue": {"$type": "callout", "CalloutColour": "blue", "CalloutChildren": []}
+		}
+	}`)
+	if assert.Nil(err) {
+		assert.IsType(operations.ObjectOperation{}, operation.Operation)
+	}
+}
+
+func TestRegistryRejectsInvalidSchemas(t *testing.T) {
+	assert := assert.New(t)
+	registry := operations.NewRegistry()
+
+	// components can't replace the built in ones
+	assert.NotNil(registry.Register(uuid.New(), cmsjson.Schema{
+		Components: map[string]cmsjson.TypeSchema{"paragraph": {Fields: []cmsjson.FieldSchema{{Name: "Text", Type: "string"}}}},
+	}))
+
+	// field names must be exported identifiers
+	assert.NotNil(registry.Register(uuid.New(), cmsjson.Schema{
+		Components: map[string]cmsjson.TypeSchema{"quote": {Fields: []cmsjson.FieldSchema{{Name: "text", Type: "string"}}}},
+	}))
+
+	// types can't contain themselves
+	assert.NotNil(registry.Register(uuid.New(), cmsjson.Schema{
+		Types: map[string]cmsjson.TypeSchema{"Node": {Fields: []cmsjson.FieldSchema{
+			{Name: "Children", Type: "array", Items: &cmsjson.FieldSchema{Type: "Node"}},
+		}}},
+		Components: map[string]cmsjson.TypeSchema{"tree": {Fields: []cmsjson.FieldSchema{{Name: "Root", Type: "Node"}}}},
+	}))
+}
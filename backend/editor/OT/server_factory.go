@@ -1,11 +1,17 @@
 package editor
 
 import (
+	"fmt"
 	"sync"
 
+	"cms.csesoc.unsw.edu.au/editor/OT/datamodel"
+	"cms.csesoc.unsw.edu.au/pkg/cmsjson"
 	"github.com/google/uuid"
 )
 
+// emptyDocument is the state of a document that hasn't been written to yet
+const emptyDocument = `{"DocumentName": "", "DocumentId": "%s", "Content": []}`
+
 // perhaps "factory" isnt appropriate but documentServerFactory just manages
 // instances of active documents that are being edited
 type documentServerFactory struct {
@@ -32,26 +38,31 @@ func GetDocumentServerFactoryInstance() *documentServerFactory {
 	return globalServerManager
 }
 
-// starts or fetches a server instance for a client to connect to
-func (sf *documentServerFactory) FetchDocumentServer(serverID uuid.UUID) *documentServer {
-	// todo: resolve the serverID to a document once
-	// everyone's week 7 tickets are done
-	var s *documentServer
-
+// starts or fetches a server instance for a client to connect to, the configuration
+// is only used if the server has to be started (a running server keeps its configuration)
+func (sf *documentServerFactory) FetchDocumentServer(serverID uuid.UUID, configuration cmsjson.Configuration) (*documentServer, error) {
 	sf.lock.Lock()
+	defer sf.lock.Unlock()
 
-	if locatedServer, ok := sf.activeServers[serverID]; !ok {
-		// setup the new server's state with the document contents
-		sf.activeServers[serverID] = newDocumentServer()
-		s = sf.activeServers[serverID]
-		s.ID = serverID
-	} else {
-		s = locatedServer
+	if locatedServer, ok := sf.activeServers[serverID]; ok {
+		return locatedServer, nil
 	}
 
-	sf.lock.Unlock()
+	// todo: resolve the serverID to a document once
+	// everyone's week 7 tickets are done, until then documents start out empty
+	initialState, err := cmsjson.UnmarshallAST[datamodel.Document](configuration, fmt.Sprintf(emptyDocument, serverID))
+	if err != nil {
+		return nil, err
+	}
+
+	s, err := newDocumentServer(configuration, initialState)
+	if err != nil {
+		return nil, err
+	}
 
-	return s
+	s.ID = serverID
+	sf.activeServers[serverID] = s
+	return s, nil
 }
 
 // closeDocumentServer terminates a documentServer, note that this method is only called by
@@ -32,6 +32,19 @@ func (tC TestingClient) WasAcknowledged() bool {
 	return false
 }
 
+// SendOperation sends an operation to the server as if it had come up the client's websocket,
+// it blocks until the server has acknowledged the operation
+func (tC TestingClient) SendOperation(msg string) error {
+	operation, err := tC.underlyingClient.parseOperation([]byte(msg))
+	if err != nil {
+		return err
+	}
+
+	tC.operationPipe(operation)
+	<-tC.underlyingClient.sendAcknowledgement
+	return nil
+}
+
 func (tC TestingClient) GetReceivedOp() operations.Operation {
 	if len(tC.underlyingClient.sendOp) == 0 {
 		panic("testing client failure: expected a non-zero amount of received operations")
@@ -46,33 +59,59 @@ func GetServerState(serverId uuid.UUID) string {
 		panic("method can only be called within the context of a test!")
 	}
 
-	connectedServer := GetDocumentServerFactoryInstance().FetchDocumentServer(serverId)
-	connectedServer.stateLock.Lock()
-	defer connectedServer.stateLock.Unlock()
+	connectedServer, err := GetDocumentServerFactoryInstance().FetchDocumentServer(serverId, operations.CmsJsonConf)
+	if err != nil {
+		panic(err)
+	}
+	state, _ := connectedServer.latestSnapshot()
+	return connectedServer.configuration.MarshallAST(state)
+}
 
-	return operations.CmsJsonConf.MarshallAST(connectedServer.state)
+// GetServerSnapshot returns the state the server saw once the given number of operations had been applied (as a string)
+func GetServerSnapshot(serverId uuid.UUID, revision int) string {
+	if !environment.IsTestingEnvironment() {
+		panic("method can only be called within the context of a test!")
+	}
+
+	connectedServer, err := GetDocumentServerFactoryInstance().FetchDocumentServer(serverId, operations.CmsJsonConf)
+	if err != nil {
+		panic(err)
+	}
+	state, err := connectedServer.snapshot(revision)
+	if err != nil {
+		panic(err)
+	}
+
+	return connectedServer.configuration.MarshallAST(state)
 }
 
 // CreateServer constructs a server with an initial state, it registers the server under the document manager
 // and returns the server's ID
 func CreateTestingServer(initState string) uuid.UUID {
+	return CreateTestingServerWith(operations.CmsJsonConf, initState)
+}
+
+// CreateTestingServerWith is CreateTestingServer for a document belonging to a frontend
+// with its own configuration (eg. one fetched from an operations.Registry)
+func CreateTestingServerWith(configuration cmsjson.Configuration, initState string) uuid.UUID {
 	if !environment.IsTestingEnvironment() {
 		panic("method can only be called within the context of a test!")
 	}
 
-	var err error
 	factory := GetDocumentServerFactoryInstance()
 	serverId := uuid.New()
 
 	factory.lock.Lock()
 	defer factory.lock.Unlock()
-	factory.activeServers[serverId] = newDocumentServer()
-
-	factory.activeServers[serverId].state, err = cmsjson.UnmarshallAST[datamodel.Document](operations.CmsJsonConf, initState)
+	initialState, err := cmsjson.UnmarshallAST[datamodel.Document](configuration, initState)
 	if err != nil {
 		panic(err)
 	}
 
+	if factory.activeServers[serverId], err = newDocumentServer(configuration, initialState); err != nil {
+		panic(err)
+	}
+
 	return serverId
 }
 
@@ -82,11 +121,14 @@ func BuildTestingClient(serverId uuid.UUID, numClients int) []TestingClient {
 		panic("method can only be called within the context of a test!")
 	}
 
-	connectedServer := GetDocumentServerFactoryInstance().FetchDocumentServer(serverId)
+	connectedServer, err := GetDocumentServerFactoryInstance().FetchDocumentServer(serverId, operations.CmsJsonConf)
+	if err != nil {
+		panic(err)
+	}
 
 	clients := make([]TestingClient, numClients)
 	for clientId := range clients {
-		internalView := newClient(&websocket.Conn{})
+		internalView := newClient(&websocket.Conn{}, connectedServer.configuration)
 		operationPipe, terminationPipe := connectedServer.connectClient(internalView)
 
 		clients[clientId] = TestingClient{
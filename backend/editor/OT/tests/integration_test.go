@@ -2,3 +2,97 @@ package tests
 
 // This test suite performs full integration tests on the entire concurrent editor
 //	the test suite is probably super fragile (as is the nature of a lot of integration tests :( ) but should give you assurance during any refactoring job you do
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	editor "cms.csesoc.unsw.edu.au/editor/OT"
+	"cms.csesoc.unsw.edu.au/editor/OT/operations"
+	"cms.csesoc.unsw.edu.au/pkg/cmsjson"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// calloutSchema declares a "callout" component: a coloured box containing some text
+const calloutSchema = `{
+	"components": {
+		"callout": {"fields": [{"name": "CalloutColour", "type": "string", "enum": ["red", "blue"]}]}
+	}
+}`
+
+const insertCallout = `{
+	"Path": [2, 0],
+	"OperationType": 0,
+	"AcknowledgedServerOps": 0,
+	"IsNoOp": false,
+	"Operation": {
+		"$type": "objectOperation",
+		"NewValue": {"$type": "callout", "CalloutColour": "blue"}
+	}
+}`
+
+func TestEditorAcceptsDeclaredComponents(t *testing.T) {
+	assert := assert.New(t)
+
+	// ==== Setup ====
+	var schema cmsjson.Schema
+	assert.Nil(json.Unmarshal([]byte(calloutSchema), &schema))
+	frontendID := uuid.New()
+	registry := operations.NewRegistry()
+	assert.Nil(registry.Register(frontendID, schema))
+
+	emptyDocument := `{"DocumentName": "callouts", "DocumentId": "1", "Content": []}`
+	server := editor.CreateTestingServerWith(registry.ConfigurationFor(frontendID), emptyDocument)
+	client := editor.BuildTestingClient(server, 1)[0]
+
+	// ==== Assertions ====
+	// the operation is parsed by the client with the frontend's configuration and applied by the server
+	assert.Nil(client.SendOperation(insertCallout))
+	assert.Contains(editor.GetServerState(server), `"CalloutColour": "blue"`)
+
+	// documents belonging to other frontends only have the built in components
+	otherServer := editor.CreateTestingServerWith(registry.ConfigurationFor(uuid.New()), emptyDocument)
+	otherClient := editor.BuildTestingClient(otherServer, 1)[0]
+	assert.NotNil(otherClient.SendOperation(insertCallout))
+}
+
+const insertImage = `{
+	"Path": [2, 0],
+	"OperationType": 0,
+	"AcknowledgedServerOps": %d,
+	"IsNoOp": false,
+	"Operation": {
+		"$type": "objectOperation",
+		"NewValue": {"$type": "image", "ImageDocumentID": "1", "ImageSource": "https://example.com/morb.png"}
+	}
+}`
+
+func TestEditorStartsDocumentsThatAreNotRunning(t *testing.T) {
+	assert := assert.New(t)
+
+	// the server is started by the client connecting to it rather than being created with a state
+	server := uuid.New()
+	client := editor.BuildTestingClient(server, 1)[0]
+
+	assert.Nil(client.SendOperation(fmt.Sprintf(insertImage, 0)))
+	assert.Contains(editor.GetServerState(server), `"ImageSource": "https://example.com/morb.png"`)
+}
+
+func TestEditorOnlyKeepsRecentRevisions(t *testing.T) {
+	assert := assert.New(t)
+
+	server := editor.CreateTestingServer(`{"DocumentName": "images", "DocumentId": "1", "Content": []}`)
+	client := editor.BuildTestingClient(server, 1)[0]
+
+	const edits = 1100
+	for i := 0; i < edits; i++ {
+		assert.Nil(client.SendOperation(fmt.Sprintf(insertImage, i)))
+	}
+
+	// the latest revisions are still available but the oldest have been dropped
+	assert.Contains(editor.GetServerSnapshot(server, edits), `"ImageSource"`)
+	assert.Contains(editor.GetServerSnapshot(server, edits-100), `"ImageSource"`)
+	assert.Panics(func() { editor.GetServerSnapshot(server, 0) })
+}
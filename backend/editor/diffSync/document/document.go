@@ -2,10 +2,10 @@ package document
 
 import (
 	"errors"
+	"log"
 	"sync"
 
 	"github.com/google/uuid"
-	"github.com/sergi/go-diff/diffmatchpatch"
 )
 
 type Document struct {
@@ -20,19 +20,31 @@ type Document struct {
 	// events to react to
 	syncEvent               chan syncPayload
 	terminateExtensionEvent chan terminatePayload
+	reportEvent             chan reportPayload
 	stopSpinningEvent       chan bool
 
-	dmp *diffmatchpatch.DiffMatchPatch
+	// stopped is set (while holding readingExtensions) once the document has
+	// destroyed its extensions, no more extensions can be added after that.
+	// done is closed when the document stops spinning
+	stopped bool
+	done    chan struct{}
+
+	// the manager maintaining the document, it is told when the document stops
+	manager *Manager
 }
 
+// reportBacklog is the number of reported problems that can be waiting for
+// the document before further reports are dropped
+const reportBacklog = 16
+
 // NewDocument returns a new instance of a document allocated on the heap
-func newDocument(documentID uuid.UUID, documentName string, baseText string) *Document {
+func newDocument(documentID uuid.UUID, documentName string, baseText string, manager *Manager) *Document {
 	return &Document{
 		id:           documentID,
 		documentName: documentName,
 		documentState: documentState{
 			baseText:            baseText,
-			shadows:             make(map[uuid.UUID]*string),
+			shadows:             make(map[uuid.UUID]*Shadow),
 			connectedExtensions: make(map[uuid.UUID]*Extension),
 			readingExtensions:   sync.RWMutex{},
 		},
@@ -41,9 +53,13 @@ func newDocument(documentID uuid.UUID, documentName string, baseText string) *Do
 
 		syncEvent:               make(chan syncPayload),
 		terminateExtensionEvent: make(chan terminatePayload),
-		stopSpinningEvent:       make(chan bool),
+		reportEvent:             make(chan reportPayload, reportBacklog),
+		// the document may stop itself just as the manager asks it to, the
+		// buffer means the manager isn't left waiting on a stopped document
+		stopSpinningEvent: make(chan bool, 1),
+		done:              make(chan struct{}),
 
-		dmp: diffmatchpatch.New(),
+		manager: manager,
 	}
 }
 
@@ -52,20 +68,31 @@ func newDocument(documentID uuid.UUID, documentName string, baseText string) *Do
 // and track a shadow for this document
 func (doc *Document) addExtension(ext *Extension) error {
 	doc.readingExtensions.Lock()
-	defer doc.readingExtensions.Unlock()
+	if doc.stopped {
+		doc.readingExtensions.Unlock()
+		return ErrDocumentClosed
+	}
 
 	doc.connectedExtensions[ext.getID()] = ext
-	doc.shadows[ext.getID()] = new(string)
-	*doc.shadows[ext.getID()] = doc.baseText
+	doc.shadows[ext.getID()] = NewDocumentShadow("")
 
 	// initialise the extension and pass
 	// it the the channel that it can use to send updates
-	ext.init(doc.syncEvent, doc.terminateExtensionEvent, &doc.baseText)
+	ext.init(doc.syncEvent, doc.terminateExtensionEvent, doc.reportEvent, &doc.baseText)
 	if ext.isService() {
 		go ext.spin()
 	}
-
-	return nil
+	doc.readingExtensions.Unlock()
+
+	// the link starts out reset, this sends the extension the contents of the document. The
+	// document may stop before it gets to the reset (eg. its last client disconnects) in which
+	// case it has already destroyed the extension
+	select {
+	case doc.syncEvent <- syncPayload{message: Message{Reset: true}, signature: ext.getID()}:
+		return nil
+	case <-doc.done:
+		return ErrDocumentClosed
+	}
 }
 
 // Spin is the main entrypoint in the document
@@ -73,6 +100,7 @@ func (doc *Document) addExtension(ext *Extension) error {
 // as its own independent goroutine and interfaced with via the appropriate methods
 func (doc *Document) spin() {
 	doc.isSpinning = true
+	defer close(doc.done)
 
 	for {
 		select {
@@ -80,60 +108,122 @@ func (doc *Document) spin() {
 		case _ = <-doc.stopSpinningEvent:
 			doc.isSpinning = false
 			// Stop extensions
-			for _, ext := range doc.connectedExtensions {
-				ext.destroy(&doc.baseText)
-			}
+			doc.destroyExtensions()
 			return
 
 		// an extension is trying to terminate itself
 		case payload := <-doc.terminateExtensionEvent:
+			doc.readingExtensions.Lock()
 			delete(doc.shadows, payload.signature)
 			delete(doc.connectedExtensions, payload.signature)
+			doc.readingExtensions.Unlock()
 
-			// if there are no more connected extensions just die off
-			if len(doc.connectedExtensions) == 0 {
+			// if only background services (or nothing) are left just die off,
+			// the background services get to see the final state of the document
+			if doc.onlyBackgroundServices() {
+				doc.destroyExtensions()
 				doc.stop()
 				return
 			}
 
 			break
 
+		// an extension wants everyone to know something went wrong
+		case payload := <-doc.reportEvent:
+			doc.readingExtensions.RLock()
+			for extID, ext := range doc.connectedExtensions {
+				if extID != payload.signature {
+					ext.reportProblem(payload.problem)
+				}
+			}
+			doc.readingExtensions.RUnlock()
+
 		// an extension is trying to synrhconise the document state
 		case payload := <-doc.syncEvent:
-			// parse the patches into the diffmatchpatch library
-			if len(payload.patches) == 0 {
-				continue
-			}
+			doc.synchronise(payload)
+		}
+	}
+}
+
+// synchronise applies a message from an extension to the document and responds with
+// the document's edits, the other extensions are notified if the document changed
+func (doc *Document) synchronise(payload syncPayload) {
+	doc.readingExtensions.RLock()
+	defer doc.readingExtensions.RUnlock()
 
-			// Apply the patch to the document text
-			// it is assumed that the patches have already been applied
-			// to the extension shadow
-			sig := payload.signature
-			newText, _ := doc.dmp.PatchApply(payload.patches, doc.baseText)
-			newShadow, _ := doc.dmp.PatchApply(payload.patches, *doc.shadows[sig])
+	ext, ok := doc.connectedExtensions[payload.signature]
+	if !ok {
+		// the extension terminated while the message was in flight
+		return
+	}
 
+	shadow := doc.shadows[payload.signature]
+	reset := payload.message.Reset
+	changed := false
+
+	if !reset {
+		newText, conflicts, err := shadow.Receive(payload.message, doc.baseText)
+		switch {
+		case errors.Is(err, ErrStaleMessage):
+			return
+		case err != nil:
+			// whatever the extension has that the document doesn't is lost
+			log.Printf("resetting extension %s of document %s: %v\n", payload.signature, doc.id, err)
+			ext.reportProblem(errors.New("the document lost track of your latest changes and has been reloaded"))
+			reset = true
+		case conflicts != 0:
+			ext.reportProblem(errors.New("some of your changes conflicted with someone else's and were discarded"))
+			fallthrough
+		default:
+			changed = newText != doc.baseText
 			doc.baseText = newText
-			*doc.shadows[sig] = newShadow
+		}
+	}
 
-			for extID, ext := range doc.connectedExtensions {
-				// generate a new list of patches to apply to the extension
-				// and send them off
-				extensionShadow := doc.shadows[extID]
-				patches := doc.dmp.PatchMake(*extensionShadow, doc.baseText)
-				*extensionShadow = doc.baseText
-
-				// propogate edits to extension
-				if len(patches) != 0 {
-					ext.Synchronise(patches)
-				}
+	if reset {
+		shadow.Reset()
+	}
+
+	response := shadow.Diff(doc.baseText)
+	response.Reset = reset
+	ext.Synchronise(response)
+
+	if changed {
+		for extID, other := range doc.connectedExtensions {
+			if extID != payload.signature {
+				other.Notify()
 			}
-			break
-		default:
-			break
 		}
 	}
 }
 
+// destroyExtensions destroys every extension connected to the document as it
+// stops, extensions can't be added once they have been destroyed
+func (doc *Document) destroyExtensions() {
+	doc.readingExtensions.Lock()
+	defer doc.readingExtensions.Unlock()
+
+	doc.stopped = true
+	for _, ext := range doc.connectedExtensions {
+		ext.destroy(&doc.baseText)
+	}
+}
+
+// onlyBackgroundServices determines if every extension still connected to
+// the document is a background service
+func (doc *Document) onlyBackgroundServices() bool {
+	doc.readingExtensions.RLock()
+	defer doc.readingExtensions.RUnlock()
+
+	for _, ext := range doc.connectedExtensions {
+		if !ext.isBackground() {
+			return false
+		}
+	}
+
+	return true
+}
+
 // Stop terminates the spinning of a document
 // if it is spinning, otherwise it throws and error
 func (doc *Document) stop() error {
@@ -141,14 +231,16 @@ func (doc *Document) stop() error {
 		return errors.New("document is not spinning, nothing to stop")
 	}
 
+	doc.readingExtensions.RLock()
 	for _, ext := range doc.connectedExtensions {
 		if ext.isSpinning() {
 			ext.stop()
 		}
 	}
+	doc.readingExtensions.RUnlock()
 
-	// finally tell the singleton manager to
+	// finally tell the manager to
 	// delete us for goooood :)
-	GetManagerInstance().closeDocument(doc.id)
+	doc.manager.closeDocument(doc.id)
 	return nil
 }
@@ -4,14 +4,13 @@ import (
 	"sync"
 
 	"github.com/google/uuid"
-	"github.com/sergi/go-diff/diffmatchpatch"
 )
 
 type documentState struct {
-	// Shadows maps an extension ID to an shadow
+	// Shadows maps an extension ID to the document's end of its link
 	// Connected extensions maps an extension ID to an extension
 	baseText            string
-	shadows             map[uuid.UUID]*string
+	shadows             map[uuid.UUID]*Shadow
 	connectedExtensions map[uuid.UUID]*Extension
 
 	readingExtensions sync.RWMutex
@@ -22,7 +21,7 @@ type documentState struct {
 // a document, the signature refers to the ID of the extension sending
 // this payload
 type syncPayload struct {
-	patches   []diffmatchpatch.Patch
+	message   Message
 	signature uuid.UUID
 }
 
@@ -31,3 +30,10 @@ type syncPayload struct {
 type terminatePayload struct {
 	signature uuid.UUID
 }
+
+// reportPayload is a problem an extension wants the people editing the
+// document to know about, the signature refers to the extension reporting it
+type reportPayload struct {
+	problem   error
+	signature uuid.UUID
+}
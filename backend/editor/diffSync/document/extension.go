@@ -1,8 +1,9 @@
 package document
 
 import (
+	"log"
+
 	"github.com/google/uuid"
-	"github.com/sergi/go-diff/diffmatchpatch"
 )
 
 // Extension is an actual extension that embeds a HeadlessExtension
@@ -12,6 +13,7 @@ type Extension struct {
 	ID                       uuid.UUID
 	attachedChannel          chan syncPayload
 	attachedTerminateChannel chan terminatePayload
+	attachedReportChannel    chan reportPayload
 
 	spinning bool
 
@@ -53,21 +55,31 @@ func (ext *Extension) stop() {
 	ext.ExtensionHead.Stop()
 }
 
-func (ext *Extension) init(commChannel chan syncPayload, terminateChannel chan terminatePayload, documentState *string) {
+func (ext *Extension) isBackground() bool {
+	background, ok := ext.ExtensionHead.(BackgroundService)
+	return ok && background.IsBackground()
+}
+
+func (ext *Extension) init(commChannel chan syncPayload, terminateChannel chan terminatePayload, reportChannel chan reportPayload, documentState *string) {
 	ext.attachedChannel = commChannel
 	ext.attachedTerminateChannel = terminateChannel
-	ext.ExtensionHead.Init(ext.propogatePatches, ext.terminate, documentState)
+	ext.attachedReportChannel = reportChannel
+	if reporter, ok := ext.ExtensionHead.(ProblemReporter); ok {
+		reporter.AttachReporter(ext.report)
+	}
+
+	ext.ExtensionHead.Init(ext.propogateMessage, ext.terminate, documentState)
 }
 
 func (ext *Extension) destroy(docState *string) {
 	ext.ExtensionHead.Destroy(docState)
 }
 
-// propogatePatches allows the extension to send information to the document
+// propogateMessage allows the extension to send information to the document
 // that it is attached to
-func (ext *Extension) propogatePatches(patches []diffmatchpatch.Patch) {
+func (ext *Extension) propogateMessage(message Message) {
 	ext.attachedChannel <- syncPayload{
-		patches:   patches,
+		message:   message,
 		signature: ext.ID,
 	}
 }
@@ -79,3 +91,22 @@ func (ext *Extension) terminate() {
 		signature: ext.ID,
 	}
 }
+
+// report allows the extension to tell the other extensions attached to the
+// document about a problem, reports are dropped rather than blocking the
+// extension if the document can't keep up (or has stopped)
+func (ext *Extension) report(problem error) {
+	select {
+	case ext.attachedReportChannel <- reportPayload{problem: problem, signature: ext.ID}:
+	default:
+		log.Printf("dropped a problem reported by extension %s: %v\n", ext.ID, problem)
+	}
+}
+
+// reportProblem passes a problem reported by another extension on to
+// this extension (if it can do something with it)
+func (ext *Extension) reportProblem(problem error) {
+	if listener, ok := ext.ExtensionHead.(ProblemListener); ok {
+		listener.ReportProblem(problem)
+	}
+}
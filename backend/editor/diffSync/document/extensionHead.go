@@ -1,12 +1,14 @@
 package document
 
-import "github.com/sergi/go-diff/diffmatchpatch"
-
 // Defines an exteion interface, all extensions must satisfy this set of required
 // functions to be useable and considered an interface
 type ExtensionHead interface {
 	// Synchronisation mechanisms
-	Synchronise([]diffmatchpatch.Patch)
+	// the extension end of a link starts every exchange (see shadow.go), Synchronise is given the
+	// document's response to a message the extension sent and Notify is called whenever the document
+	// changes, the extension should send the document a message to fetch the changes
+	Synchronise(Message)
+	Notify()
 
 	// LifeCycle operations
 	// Just note that init is passed a method that it can use
@@ -14,8 +16,9 @@ type ExtensionHead interface {
 	// it is also given a method the extension head must call to signal
 	// to the document that it wishes to die (how tragic)
 	// the idea is that the ExtensionHead has no idea wat it is attached to
-	// it only knows how to communicate with it
-	Init(func([]diffmatchpatch.Patch), func(), *string)
+	// it only knows how to communicate with it, once the extension is initialised
+	// the document resets the link and sends the extension its contents
+	Init(func(Message), func(), *string)
 	Destroy(*string) // destroy is given the current state of the document
 
 	// Special functions regarding the service
@@ -23,3 +26,24 @@ type ExtensionHead interface {
 	Spin()
 	Stop()
 }
+
+// Optional interfaces an ExtensionHead can implement, the document checks for them when the extension is loaded
+type (
+	// ProblemReporter is implemented by extension heads that need to tell the people editing a document about
+	// problems (eg. the document failing to save), the report function is attached before Init is called
+	ProblemReporter interface {
+		AttachReporter(report func(error))
+	}
+
+	// ProblemListener is implemented by extension heads that can pass the problems reported by other extensions
+	// on to the people editing the document
+	ProblemListener interface {
+		ReportProblem(error)
+	}
+
+	// BackgroundService is implemented by extension heads that only work on behalf of the other extensions (eg.
+	// autosaving), they don't keep a document open so a document closes once only background services are left
+	BackgroundService interface {
+		IsBackground() bool
+	}
+)
@@ -2,42 +2,36 @@ package document
 
 import (
 	"errors"
+	"fmt"
+	"io"
 	"sync"
 
-	"cms.csesoc.unsw.edu.au/database/contexts"
 	"cms.csesoc.unsw.edu.au/database/repositories"
-	"cms.csesoc.unsw.edu.au/internal/storage"
 	"github.com/google/uuid"
 )
 
-// Manager is a singleton "class" thats in charge
-// of maintaining a global state for all open documents
-// and their underlying document connection
+// Manager is in charge of maintaining the state for all open documents
+// of a frontend and their underlying document connection
 type Manager struct {
 	// openDocuments maps document IDs (in the database) to document states
 	openDocuments    map[uuid.UUID]*Document
 	readingDocuments *sync.RWMutex
-}
 
-var (
-	managerInstance *Manager
-	lock            = &sync.Mutex{}
-	repo, _         = repositories.NewFilesystemRepo("test", "test1", contexts.GetDatabaseContext())
-)
+	// documents are looked up in the frontend's filesystem and their contents
+	// are read from the unpublished volume
+	filesystem  repositories.FilesystemRepository
+	unpublished repositories.UnpublishedVolumeRepository
+}
 
-// implementation of the singleton pattern :)
-func GetManagerInstance() *Manager {
-	if managerInstance == nil {
-		lock.Lock()
-		defer lock.Unlock()
+// NewManager creates a manager for the documents within a frontend's filesystem
+func NewManager(filesystem repositories.FilesystemRepository, unpublished repositories.UnpublishedVolumeRepository) *Manager {
+	return &Manager{
+		openDocuments:    make(map[uuid.UUID]*Document),
+		readingDocuments: &sync.RWMutex{},
 
-		managerInstance = &Manager{
-			openDocuments:    make(map[uuid.UUID]*Document),
-			readingDocuments: &sync.RWMutex{},
-		}
+		filesystem:  filesystem,
+		unpublished: unpublished,
 	}
-
-	return managerInstance
 }
 
 // openDocument opens a document with and ID representing the text's internal
@@ -45,30 +39,56 @@ func GetManagerInstance() *Manager {
 // note that on failure it returns an error indicating that the document
 // could not be opened
 func (docManager *Manager) OpenDocument(documentID uuid.UUID) error {
-	if _, ok := docManager.openDocuments[documentID]; ok {
-		return errors.New("document already open")
+	// Get the name of the document
+	docInfo, err := docManager.filesystem.GetEntryWithID(documentID)
+	if err != nil {
+		return fmt.Errorf("invalid document id: %w", err)
+	} else if !docInfo.IsDocument {
+		return errors.New("only documents can be opened")
 	}
 
-	// Get the name of the document
-	docInfo, err := repo.GetEntryWithID(documentID)
+	text, err := docManager.readDocument(documentID)
 	if err != nil {
-		return errors.New("invalid document id")
+		return err
 	}
-	newDoc := newDocument(documentID, docInfo.LogicalName, storage.Read(documentID.String(), "data"))
+
 	docManager.readingDocuments.Lock()
 	defer docManager.readingDocuments.Unlock()
 
+	if _, ok := docManager.openDocuments[documentID]; ok {
+		return errors.New("document already open")
+	}
+
+	newDoc := newDocument(documentID, docInfo.LogicalName, text, docManager)
 	docManager.openDocuments[documentID] = newDoc
 
 	go newDoc.spin()
 	return nil
 }
 
+// readDocument reads the contents of a document from the unpublished volume
+func (docManager *Manager) readDocument(documentID uuid.UUID) (string, error) {
+	file, err := docManager.unpublished.GetFromVolume(documentID.String())
+	if err != nil {
+		return "", fmt.Errorf("failed to open the contents of document %s: %w", documentID, err)
+	}
+	defer file.Close()
+
+	contents, err := io.ReadAll(file)
+	if err != nil {
+		return "", fmt.Errorf("failed to read the contents of document %s: %w", documentID, err)
+	}
+
+	return string(contents), nil
+}
+
 // CloseAndStopDocument closes a document with a specific ID and
 // sends it a shutdown signal
 func (docManager *Manager) CloseAndStopDocument(documentID uuid.UUID) error {
 	if doc := docManager.closeDocument(documentID); doc != nil {
-		return doc.stop()
+		// the document destroys its extensions as it stops
+		doc.stopSpinningEvent <- true
+		return nil
 	}
 	return errors.New("no such document exists within the manager")
 }
@@ -89,15 +109,22 @@ func (docManager *Manager) closeDocument(documentID uuid.UUID) *Document {
 
 // LoadExtension adds an extension to a document with the specified ID
 func (docManager *Manager) LoadExtension(documentID uuid.UUID, ext *Extension) error {
-	if _, ok := docManager.openDocuments[documentID]; !ok {
+	docManager.readingDocuments.RLock()
+	doc, ok := docManager.openDocuments[documentID]
+	docManager.readingDocuments.RUnlock()
+
+	if !ok {
 		return errors.New("document not open")
 	}
 
-	return docManager.openDocuments[documentID].addExtension(ext)
+	return doc.addExtension(ext)
 }
 
 // IsDocOpen determines if we have a document open and spinning :)
 func (docManager *Manager) IsDocOpen(documentID uuid.UUID) bool {
+	docManager.readingDocuments.RLock()
+	defer docManager.readingDocuments.RUnlock()
+
 	_, ok := docManager.openDocuments[documentID]
 	return ok
 }
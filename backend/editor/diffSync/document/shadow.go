@@ -0,0 +1,171 @@
+package document
+
+import (
+	"errors"
+
+	"github.com/sergi/go-diff/diffmatchpatch"
+)
+
+// This file implements the guaranteed delivery variant of differential synchronisation
+// (https://neil.fraser.name/writing/sync/), every link between the document and an extension
+// has a shadow on either end. The extension end initiates every exchange by sending a message
+// (its edits, possibly none) and the document end always responds with a message of its own,
+// since the document never sends edits unprompted there is at most one message in flight on a link.
+// Messages carry every edit the receiver hasn't acknowledged yet so a lost message is recovered from
+// by the next one and edits that have already been received are recognised by their version and skipped.
+
+var (
+	// ErrStaleMessage is returned when a message has been superseded by one sent after it, it can safely be ignored
+	ErrStaleMessage = errors.New("the message has been superseded by a newer one")
+
+	// ErrDocumentClosed is returned when an extension is added to a document that has stopped
+	ErrDocumentClosed = errors.New("the document has been closed")
+
+	// ErrOutOfSync is returned when a message can't be applied to the shadow (eg. some messages were lost
+	// and the edits they contained are no longer being resent), the link has to be reset to recover
+	ErrOutOfSync = errors.New("the shadows on either end of the link are out of sync")
+)
+
+type (
+	// Edit is a set of patches made by one end of a link, the version is the version of the sender's shadow
+	// the patches were diffed against
+	Edit struct {
+		Version int
+		Patches []diffmatchpatch.Patch
+	}
+
+	// Message is what the ends of a link exchange, Ack is the number of the receiver's edits the sender has
+	// applied to its shadow and Edits are all the edits the sender has made that the receiver hasn't acknowledged.
+	// A message that resets the link tells the receiver to throw away its shadow, the edits are then relative to
+	// an empty shadow and both versions start from 0
+	Message struct {
+		Reset bool
+		Ack   int
+		Edits []Edit
+	}
+
+	// Shadow is one end of a link, it tracks what the other end of the link believes the text to be
+	Shadow struct {
+		text string
+		// version is the number of edits made by this end of the link
+		// and peerVersion the number of edits received from the other end
+		version, peerVersion int
+
+		// the responding end of the link backs up its shadow whenever it receives a message,
+		// if its response is lost the next message it receives is relative to the backup
+		responder     bool
+		backup        string
+		backupVersion int
+
+		// edits that haven't been acknowledged yet, they are resent with every message
+		stack []Edit
+
+		dmp *diffmatchpatch.DiffMatchPatch
+	}
+)
+
+// NewDocumentShadow creates the shadow for the responding (document) end of a link
+func NewDocumentShadow(text string) *Shadow {
+	shadow := newShadow(text)
+	shadow.responder = true
+	return shadow
+}
+
+// NewExtensionShadow creates the shadow for the initiating (extension) end of a link
+func NewExtensionShadow(text string) *Shadow {
+	return newShadow(text)
+}
+
+func newShadow(text string) *Shadow {
+	return &Shadow{
+		text:   text,
+		backup: text,
+		stack:  []Edit{},
+		dmp:    diffmatchpatch.New(),
+	}
+}
+
+// Reset empties the shadow and forgets every edit, both ends of a link must be reset together
+func (s *Shadow) Reset() {
+	s.text, s.version, s.peerVersion = "", 0, 0
+	s.backup, s.backupVersion = "", 0
+	s.stack = []Edit{}
+}
+
+// Diff computes the edit turning the shadow into text and returns the message to send to the other end of the
+// link, the message contains every edit the other end hasn't acknowledged
+func (s *Shadow) Diff(text string) Message {
+	if patches := s.dmp.PatchMake(s.text, text); len(patches) != 0 {
+		s.stack = append(s.stack, Edit{Version: s.version, Patches: patches})
+		s.version++
+		s.text = text
+	}
+
+	return Message{
+		Ack:   s.peerVersion,
+		Edits: append([]Edit{}, s.stack...),
+	}
+}
+
+// Receive applies a message from the other end of the link to the shadow and the text, the updated text is
+// returned along with the number of patches that couldn't be applied to it (the edits conflicted with changes the
+// other end hasn't seen yet, they are dropped). When an error is returned the shadow may have been partially
+// updated, ErrStaleMessage can be ignored but any other error means the link needs to be reset
+func (s *Shadow) Receive(message Message, text string) (string, int, error) {
+	switch {
+	case message.Ack > s.version:
+		return text, 0, ErrOutOfSync
+	case message.Ack < s.version && s.responder && message.Ack == s.backupVersion:
+		// our last response never made it so the edits in this message are relative
+		// to the backup, the edits in the lost response are recomputed by the next Diff
+		s.text, s.version = s.backup, s.backupVersion
+		s.stack = []Edit{}
+	case message.Ack < s.version:
+		// a response to a message we've already given up on (or a repeated message)
+		return text, 0, ErrStaleMessage
+	}
+
+	// the other end has everything before the acknowledged version
+	unacknowledged := []Edit{}
+	for _, edit := range s.stack {
+		if edit.Version >= message.Ack {
+			unacknowledged = append(unacknowledged, edit)
+		}
+	}
+	s.stack = unacknowledged
+
+	updated, conflicts := text, 0
+	for _, edit := range message.Edits {
+		if edit.Version < s.peerVersion {
+			// we've seen it before
+			continue
+		} else if edit.Version > s.peerVersion {
+			// the edits before it were lost
+			return text, 0, ErrOutOfSync
+		}
+
+		// the edit was diffed against this exact shadow so every patch must apply cleanly
+		shadow, applied := s.dmp.PatchApply(edit.Patches, s.text)
+		for _, ok := range applied {
+			if !ok {
+				return text, 0, ErrOutOfSync
+			}
+		}
+
+		s.text = shadow
+		s.peerVersion++
+
+		updated, applied = s.dmp.PatchApply(edit.Patches, updated)
+		for _, ok := range applied {
+			if !ok {
+				conflicts++
+			}
+		}
+	}
+
+	if s.responder {
+		s.backup, s.backupVersion = s.text, s.version
+	}
+
+	return updated, conflicts, nil
+}
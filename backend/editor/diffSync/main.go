@@ -11,7 +11,6 @@ import (
 
 // This file just defines some of the endpoints for the editor
 // and ties togher its various disparate components
-var broker = service.NewBroker()
 
 var Upgrader = websocket.Upgrader{
 	ReadBufferSize:  1024,
@@ -21,8 +20,16 @@ var Upgrader = websocket.Upgrader{
 	},
 }
 
+// NewEditEndpoint creates the edit endpoint for a frontend, the broker
+// determines which frontend's documents can be edited
+func NewEditEndpoint(broker *service.Broker) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		editDocument(broker, w, r)
+	}
+}
+
 // Actual edit endpoint
-func EditEndpoint(w http.ResponseWriter, r *http.Request) {
+func editDocument(broker *service.Broker, w http.ResponseWriter, r *http.Request) {
 	requestedDocument, ok := r.URL.Query()["document"]
 	if !ok || len(requestedDocument[0]) < 1 {
 		w.WriteHeader(400)
@@ -33,6 +40,7 @@ func EditEndpoint(w http.ResponseWriter, r *http.Request) {
 	ws, err = Upgrader.Upgrade(w, r, nil)
 	if err != nil {
 		log.Println(err)
+		return
 	}
 
 	var documentID uuid.UUID
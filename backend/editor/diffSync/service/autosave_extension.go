@@ -1,61 +1,162 @@
 package service
 
 import (
-	"github.com/sergi/go-diff/diffmatchpatch"
+	"crypto/sha256"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"cms.csesoc.unsw.edu.au/database/repositories"
+	"cms.csesoc.unsw.edu.au/editor/diffSync/document"
+	"github.com/google/uuid"
 )
 
 const AUTOSAVE_EXTENSION_NAME = "AutosaveExtension"
 
+// AutosaveHead is a background service that writes the document to the unpublished volume once it has gone
+// idleDelay without any edits and one last time when the document is closed
 type AutosaveHead struct {
+	documentID uuid.UUID
+	volume     repositories.UnpublishedVolumeRepository
+	idleDelay  time.Duration
+
+	// edited is signalled whenever the document changes, it restarts the idle timer
+	edited       chan struct{}
 	stopSpinning chan bool
+	stopOnce     sync.Once
+
+	// lastSaved is the hash of the contents last written to the volume (or loaded from it), saving identical
+	// contents again is skipped
+	lastSaved [sha256.Size]byte
+	saveLock  sync.Mutex
+
+	report func(error)
 	ExtensionStub
 }
 
 // Creates a new autosave extension
-func NewAutosaveHead() *AutosaveHead {
+func NewAutosaveHead(documentID uuid.UUID, volume repositories.UnpublishedVolumeRepository, idleDelay time.Duration) *AutosaveHead {
 	return &AutosaveHead{
+		documentID:   documentID,
+		volume:       volume,
+		idleDelay:    idleDelay,
+		edited:       make(chan struct{}, 1),
 		stopSpinning: make(chan bool),
+		report:       func(error) {},
 		ExtensionStub: ExtensionStub{
-			serverShadow: new(string),
-			dmp:          diffmatchpatch.New(),
-			baseText:     "",
+			shadow:   document.NewExtensionShadow(""),
+			baseText: "",
 		},
 	}
 }
 
-// Methods for the new ClientHead to implement the ExtensionHead interface
-// we just need to tell the connected socket that they're now attached to a document :)
-func (c *AutosaveHead) Init(commMethod func([]diffmatchpatch.Patch), terminate func(), documentState *string) {
+// Methods for the new AutosaveHead to implement the ExtensionHead interface
+// the contents the document was opened with are already saved
+func (c *AutosaveHead) Init(commMethod func(document.Message), terminate func(), documentState *string) {
 	c.sendToDoc = commMethod
-	c.ExtensionStub.baseText = *documentState
-	*c.ExtensionStub.serverShadow = *documentState
+	c.lastSaved = sha256.Sum256([]byte(*documentState))
 }
 
-// Just tell the client that their connection is now closed
+// AttachReporter implements document.ProblemReporter, failed saves are reported to the people editing the document
+func (c *AutosaveHead) AttachReporter(report func(error)) {
+	c.report = report
+}
+
+// The document is closing, whatever state it was left in is saved
 func (c *AutosaveHead) Destroy(state *string) {
-	close(c.stopSpinning)
+	c.halt()
+
+	// there's no one left to report to
+	if err := c.save(*state); err != nil {
+		log.Printf("failed to save document %s when it was closed: %v\n", c.documentID, err)
+	}
 }
 
-// The following methods are more so "stubs" as this extension does not run as a service
+// Synchronise applies the document's edits to the extension's copy of the document and restarts the idle timer
+func (c *AutosaveHead) Synchronise(message document.Message) {
+	if !c.receive(message) {
+		return
+	}
+
+	select {
+	case c.edited <- struct{}{}:
+	default:
+		// the timer is already going to be restarted
+	}
+}
+
+// Autosaving is a service but it only works on behalf of the other extensions
 func (c *AutosaveHead) IsService() bool {
 	return true
 }
 
-// Spin in a loop listenining for updates on our
-// websocket connection
+// IsBackground implements document.BackgroundService, the document shouldn't stay open just to be autosaved
+func (c *AutosaveHead) IsBackground() bool {
+	return true
+}
+
+// Spin waits for the document to go idle and then saves it
 func (c *AutosaveHead) Spin() {
+	idle := time.NewTimer(c.idleDelay)
+	stopTimer(idle)
+
 	for {
-		switch {
+		select {
 		case <-c.stopSpinning:
+			stopTimer(idle)
 			return
-		default:
-			// TODO: write to file system every n seconds
-			// @Jacky
-			break
+		case <-c.edited:
+			stopTimer(idle)
+			idle.Reset(c.idleDelay)
+		case <-idle.C:
+			if err := c.save(c.Text()); err != nil {
+				log.Printf("failed to autosave document %s: %v\n", c.documentID, err)
+				c.report(fmt.Errorf("your changes couldn't be saved: %w", err))
+			}
 		}
 	}
 }
 
 func (c *AutosaveHead) Stop() {
-	c.stopSpinning <- true
+	c.halt()
+}
+
+// halt stops the service, it is safe to call more than once
+func (c *AutosaveHead) halt() {
+	c.stopOnce.Do(func() { close(c.stopSpinning) })
+}
+
+// save writes contents to the unpublished volume unless they are what was last saved
+func (c *AutosaveHead) save(contents string) error {
+	c.saveLock.Lock()
+	defer c.saveLock.Unlock()
+
+	hash := sha256.Sum256([]byte(contents))
+	if hash == c.lastSaved {
+		return nil
+	}
+
+	file, err := c.volume.GetFromVolumeTruncated(c.documentID.String())
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if _, err := file.WriteString(contents); err != nil {
+		return err
+	}
+
+	c.lastSaved = hash
+	return nil
+}
+
+// stopTimer stops a timer and drains its channel so that it can be safely reset
+func stopTimer(timer *time.Timer) {
+	if !timer.Stop() {
+		select {
+		case <-timer.C:
+		default:
+		}
+	}
 }
@@ -1,13 +1,16 @@
 package service
 
 import (
+	"time"
+
+	"cms.csesoc.unsw.edu.au/database/repositories"
 	"cms.csesoc.unsw.edu.au/editor/diffSync/document"
 	"github.com/google/uuid"
 	"github.com/gorilla/websocket"
 )
 
 var (
-	newDoc  = []string{CLIENT_EXTENSION_NAME}
+	newDoc  = []string{CLIENT_EXTENSION_NAME, AUTOSAVE_EXTENSION_NAME}
 	newConn = []string{CLIENT_EXTENSION_NAME}
 )
 
@@ -17,11 +20,19 @@ var (
 // and attaches new
 type Broker struct {
 	manager *document.Manager
+
+	// documents are autosaved to the unpublished volume once they've gone autosaveDelay without edits
+	unpublished   repositories.UnpublishedVolumeRepository
+	autosaveDelay time.Duration
 }
 
-func NewBroker() *Broker {
+// NewBroker creates a broker for the documents of a single frontend, documents are looked up in its filesystem
+// and read from (and autosaved to) the unpublished volume
+func NewBroker(filesystem repositories.FilesystemRepository, unpublished repositories.UnpublishedVolumeRepository, autosaveDelay time.Duration) *Broker {
 	return &Broker{
-		manager: document.GetManagerInstance(),
+		manager:       document.NewManager(filesystem, unpublished),
+		unpublished:   unpublished,
+		autosaveDelay: autosaveDelay,
 	}
 }
 
@@ -46,22 +57,25 @@ func (b *Broker) ConnectOrOpenDocument(documentID uuid.UUID, conn *websocket.Con
 	}
 
 	for _, extName := range requiredExtensions {
-		b.manager.LoadExtension(documentID, extensionFactory(
-			extName, conn,
+		err := b.manager.LoadExtension(documentID, b.extensionFactory(
+			extName, documentID, conn,
 		))
+		if err != nil {
+			return err
+		}
 	}
 	return nil
 }
 
 // small factory for generating extensions based on a name :)
-func extensionFactory(extName string, conn *websocket.Conn) *document.Extension {
+func (b *Broker) extensionFactory(extName string, documentID uuid.UUID, conn *websocket.Conn) *document.Extension {
 	var head document.ExtensionHead
 	switch extName {
 	case CLIENT_EXTENSION_NAME:
 		head = NewClientHead(conn)
 		break
 	case AUTOSAVE_EXTENSION_NAME:
-		head = NewAutosaveHead()
+		head = NewAutosaveHead(documentID, b.unpublished, b.autosaveDelay)
 		break
 	}
 
@@ -2,17 +2,40 @@ package service
 
 import (
 	"log"
+	"sync"
 
+	"cms.csesoc.unsw.edu.au/editor/diffSync/document"
 	"github.com/gorilla/websocket"
 	"github.com/sergi/go-diff/diffmatchpatch"
 )
 
-// defines the json structure of a response
-type response struct {
-	Status  string            `json:"status"`
-	Errors  []string          `json:"errors"`
-	Payload map[string]string `json:"payload"`
-}
+type (
+	// defines the json structure of a response
+	response struct {
+		Status  string      `json:"status"`
+		Errors  []string    `json:"errors"`
+		Payload interface{} `json:"payload"`
+	}
+
+	// defines the json structure of a request, the payload is always a sync message
+	request struct {
+		Status  string      `json:"status"`
+		Errors  []string    `json:"errors"`
+		Payload syncMessage `json:"payload"`
+	}
+
+	// syncMessage is how a document.Message is sent over the wire, patches are in GNU diff format
+	syncMessage struct {
+		Reset bool       `json:"reset"`
+		Ack   int        `json:"ack"`
+		Edits []syncEdit `json:"edits"`
+	}
+
+	syncEdit struct {
+		Version int    `json:"version"`
+		Patches string `json:"patches"`
+	}
+)
 
 // This file defines the headless extensions for a client facing extension
 // that is everything can be propogated to some client somewhere
@@ -22,10 +45,11 @@ type ClientHead struct {
 	Socket *websocket.Conn
 	dmp    *diffmatchpatch.DiffMatchPatch
 
-	sendToDoc func([]diffmatchpatch.Patch)
+	sendToDoc func(document.Message)
 	terminate func()
 
 	stopSpinning chan bool
+	stopOnce     sync.Once
 }
 
 // Create a new client
@@ -38,21 +62,10 @@ func NewClientHead(conn *websocket.Conn) *ClientHead {
 }
 
 // Methods for the new ClientHead to implement the ExtensionHead interface
-// we just need to tell the connected socket that they're now attached to a document :)
-func (c *ClientHead) Init(commMethod func([]diffmatchpatch.Patch), terminate func(), documentState *string) {
+// the document sends the client its contents once it has been initialised
+func (c *ClientHead) Init(commMethod func(document.Message), terminate func(), documentState *string) {
 	c.sendToDoc = commMethod
 	c.terminate = terminate
-	// we need to transport the current state of the document to the client
-	// this can be done by diffing the document via an empty string
-	patches := c.dmp.PatchMake("", *documentState)
-
-	c.Socket.WriteJSON(response{
-		Status: "connected",
-		Errors: []string{},
-		Payload: map[string]string{
-			"patches": c.dmp.PatchToText(patches),
-		},
-	})
 }
 
 // Just tell the client that their connection is now closed
@@ -62,7 +75,7 @@ func (c *ClientHead) Destroy(docState *string) {
 		Errors:  []string{},
 		Payload: map[string]string{},
 	})
-	c.Socket.Close()
+	c.halt()
 }
 
 // The following methods are more so "stubs" as this extension does not run as a service
@@ -70,17 +83,38 @@ func (c *ClientHead) IsService() bool {
 	return true
 }
 
+// ReportProblem implements document.ProblemListener, problems are sent to the client alongside an empty payload
+func (c *ClientHead) ReportProblem(problem error) {
+	c.Socket.WriteJSON(response{
+		Status:  "connected",
+		Errors:  []string{problem.Error()},
+		Payload: map[string]string{},
+	})
+}
+
 // Finally the big beefy Synchronise function :)
-func (c *ClientHead) Synchronise(patches []diffmatchpatch.Patch) {
+func (c *ClientHead) Synchronise(message document.Message) {
 	// We assume that the connected client symmetrically implements the diff sync algorithm
-	// hence we just pass our patches onto them
-	parsedPatches := c.dmp.PatchToText(patches)
+	// hence we just pass the document's response onto them
+	payload := syncMessage{Reset: message.Reset, Ack: message.Ack, Edits: make([]syncEdit, len(message.Edits))}
+	for i, edit := range message.Edits {
+		payload.Edits[i] = syncEdit{Version: edit.Version, Patches: c.dmp.PatchToText(edit.Patches)}
+	}
+
+	c.Socket.WriteJSON(response{
+		Status:  "connected",
+		Errors:  []string{},
+		Payload: payload,
+	})
+}
+
+// Notify tells the client that the document has changed, the client then sends
+// the document a message to fetch the changes
+func (c *ClientHead) Notify() {
 	c.Socket.WriteJSON(response{
-		Status: "connected",
-		Errors: []string{},
-		Payload: map[string]string{
-			"patches": parsedPatches,
-		},
+		Status:  "changed",
+		Errors:  []string{},
+		Payload: map[string]string{},
 	})
 }
 
@@ -88,35 +122,58 @@ func (c *ClientHead) Synchronise(patches []diffmatchpatch.Patch) {
 // websocket connection
 func (c *ClientHead) Spin() {
 	for {
-		select {
-		case <-c.stopSpinning:
-			return
-		default:
-			// todo: this code assumes that if our request is invalid then no request was sent
-			// do a bit of research into the gorilla sockets API and refactor that assumption out :)
-			var req response
-			err := c.Socket.ReadJSON(&req)
-			if err != nil {
+		var req request
+		if err := c.Socket.ReadJSON(&req); err != nil {
+			select {
+			case <-c.stopSpinning:
+				// we closed the connection ourselves
+			default:
 				if !websocket.IsCloseError(err, websocket.CloseGoingAway, websocket.CloseNoStatusReceived) {
 					log.Printf("something went horribly wrong, terminating connection: %v\n", err)
 				}
 
-				c.Stop()
+				c.halt()
+				c.terminate()
 			}
 
-			parsedPatches, err := c.dmp.PatchFromText(req.Payload["patches"])
-			if err != nil {
-				log.Printf("something went horribly wrong when parsing diff: %v\n", err)
-				c.Stop()
-			}
+			return
+		}
 
-			c.sendToDoc(parsedPatches)
-			break
+		message, err := c.parseMessage(req.Payload)
+		if err != nil {
+			// the client resends its edits with its next message
+			log.Printf("something went horribly wrong when parsing diff: %v\n", err)
+			continue
 		}
+
+		c.sendToDoc(message)
+	}
+}
+
+// parseMessage converts a message from the client into a document.Message
+func (c *ClientHead) parseMessage(payload syncMessage) (document.Message, error) {
+	message := document.Message{Reset: payload.Reset, Ack: payload.Ack, Edits: make([]document.Edit, len(payload.Edits))}
+	for i, edit := range payload.Edits {
+		patches, err := c.dmp.PatchFromText(edit.Patches)
+		if err != nil {
+			return document.Message{}, err
+		}
+
+		message.Edits[i] = document.Edit{Version: edit.Version, Patches: patches}
 	}
+
+	return message, nil
 }
 
+// Stop is called by the document (from within its event loop) so it mustn't wait on the document
 func (c *ClientHead) Stop() {
-	c.terminate()
-	c.stopSpinning <- true
+	c.halt()
+}
+
+// halt stops listening to the client and closes the connection, it is safe to call more than once
+func (c *ClientHead) halt() {
+	c.stopOnce.Do(func() {
+		close(c.stopSpinning)
+		c.Socket.Close()
+	})
 }
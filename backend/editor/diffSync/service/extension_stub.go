@@ -1,28 +1,87 @@
 package service
 
-import "github.com/sergi/go-diff/diffmatchpatch"
+import (
+	"errors"
+	"sync"
+
+	"cms.csesoc.unsw.edu.au/editor/diffSync/document"
+)
 
 // Defines some basic methods for synchronising with a document
-// and maintaining an internal shadow
+// and maintaining an internal shadow, the stub is the extension
+// end of the link between the extension and the document
 type ExtensionStub struct {
-	serverShadow *string
-	dmp          *diffmatchpatch.DiffMatchPatch
-	baseText     string
-	sendToDoc    func([]diffmatchpatch.Patch)
+	shadow    *document.Shadow
+	baseText  string
+	sendToDoc func(document.Message)
+
+	// awaiting is set while the document hasn't responded to our last message
+	awaiting bool
+
+	// stateLock guards the base text and shadow, services read them from their own goroutine
+	stateLock sync.Mutex
+}
+
+// Synchronise applies the document's response to our last message
+func (stub *ExtensionStub) Synchronise(message document.Message) {
+	stub.receive(message)
+}
+
+// Notify fetches the changes made to the document, it is called from within the
+// document's event loop so the message is sent from another goroutine
+func (stub *ExtensionStub) Notify() {
+	go stub.sync()
 }
 
-// Diff match patch implementation
-func (stub ExtensionStub) Synchronise(patches []diffmatchpatch.Patch) {
-	newText, _ := stub.dmp.PatchApply(patches, stub.baseText)
-	newShadow, _ := stub.dmp.PatchApply(patches, *stub.serverShadow)
+// receive applies a message from the document to the shadow and base text, it returns
+// whether the base text changed
+func (stub *ExtensionStub) receive(message document.Message) bool {
+	stub.stateLock.Lock()
+	defer stub.stateLock.Unlock()
 
+	text := stub.baseText
+	if message.Reset {
+		stub.shadow.Reset()
+		text = ""
+	}
+
+	newText, _, err := stub.shadow.Receive(message, text)
+	switch {
+	case errors.Is(err, document.ErrStaleMessage):
+		return false
+	case err != nil:
+		// start over, the document responds with its contents
+		stub.awaiting = true
+		go stub.sendToDoc(document.Message{Reset: true})
+		return false
+	}
+
+	stub.awaiting = false
+	changed := newText != stub.baseText
 	stub.baseText = newText
-	*stub.serverShadow = newShadow
+	return changed
+}
+
+// sync sends the document our edits (if any) unless we're still waiting on a response,
+// the response to that message will contain the document's latest changes anyway
+func (stub *ExtensionStub) sync() {
+	stub.stateLock.Lock()
+	if stub.awaiting {
+		stub.stateLock.Unlock()
+		return
+	}
+
+	message := stub.shadow.Diff(stub.baseText)
+	stub.awaiting = true
+	stub.stateLock.Unlock()
+
+	stub.sendToDoc(message)
+}
 
-	// compute the difference between the base text and the shadow
-	// and propogate the diff to the document
-	diff := stub.dmp.PatchMake(*stub.serverShadow, stub.baseText)
-	*stub.serverShadow = stub.baseText
+// Text returns the extension's copy of the document's text
+func (stub *ExtensionStub) Text() string {
+	stub.stateLock.Lock()
+	defer stub.stateLock.Unlock()
 
-	stub.sendToDoc(diff)
+	return stub.baseText
 }
@@ -0,0 +1,174 @@
+package tests
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	repMocks "cms.csesoc.unsw.edu.au/database/repositories/mocks"
+	"cms.csesoc.unsw.edu.au/editor/diffSync/document"
+	"cms.csesoc.unsw.edu.au/editor/diffSync/service"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+const idleDelay = 20 * time.Millisecond
+
+// fakeDocument stands in for the document end of an extension's link
+type fakeDocument struct {
+	lock   sync.Mutex
+	text   string
+	shadow *document.Shadow
+	head   document.ExtensionHead
+}
+
+// attach initialises an extension and sends it the document's contents
+func (d *fakeDocument) attach(head document.ExtensionHead) {
+	d.head = head
+	d.shadow = document.NewDocumentShadow("")
+	head.Init(d.respond, func() {}, &d.text)
+
+	d.respond(document.Message{Reset: true})
+}
+
+// respond applies a message from the extension and sends it the document's edits
+func (d *fakeDocument) respond(message document.Message) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	if message.Reset {
+		d.shadow.Reset()
+	} else {
+		d.text, _, _ = d.shadow.Receive(message, d.text)
+	}
+
+	response := d.shadow.Diff(d.text)
+	response.Reset = message.Reset
+	d.head.Synchronise(response)
+}
+
+// edit changes the document and notifies the extension
+func (d *fakeDocument) edit(text string) {
+	d.lock.Lock()
+	d.text = text
+	d.lock.Unlock()
+
+	d.head.Notify()
+}
+
+// openAutosave starts an autosave extension on a document containing text
+func openAutosave(t *testing.T, volume *repMocks.MockUnpublishedVolumeRepository, documentID uuid.UUID, text string) (*service.AutosaveHead, *fakeDocument) {
+	head := service.NewAutosaveHead(documentID, volume, idleDelay)
+	doc := &fakeDocument{text: text}
+	doc.attach(head)
+	go head.Spin()
+
+	t.Cleanup(head.Stop)
+	return head, doc
+}
+
+func expectSaves(volume *repMocks.MockUnpublishedVolumeRepository, documentID uuid.UUID, path string) *gomock.Call {
+	return volume.EXPECT().GetFromVolumeTruncated(documentID.String()).DoAndReturn(func(string) (*os.File, error) {
+		return os.Create(path)
+	})
+}
+
+func TestAutosaveAfterIdle(t *testing.T) {
+	assert := assert.New(t)
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	documentID := uuid.New()
+	path := filepath.Join(t.TempDir(), documentID.String())
+	volume := repMocks.NewMockUnpublishedVolumeRepository(controller)
+	expectSaves(volume, documentID, path).Times(1)
+
+	head, doc := openAutosave(t, volume, documentID, "hello world")
+	doc.edit("hello there world")
+
+	assert.Eventually(func() bool {
+		contents, err := os.ReadFile(path)
+		return err == nil && string(contents) == "hello there world"
+	}, time.Second, idleDelay)
+
+	// closing the document with the contents that were just saved doesn't write them again
+	state := head.Text()
+	head.Destroy(&state)
+}
+
+func TestAutosaveSkipsUnchangedContents(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	// nothing is ever written since the document ends up as it was opened
+	volume := repMocks.NewMockUnpublishedVolumeRepository(controller)
+	head, doc := openAutosave(t, volume, uuid.New(), "hello world")
+	doc.edit("goodbye world")
+	doc.edit("hello world")
+
+	time.Sleep(5 * idleDelay)
+	state := "hello world"
+	head.Destroy(&state)
+}
+
+func TestAutosaveOnDestroy(t *testing.T) {
+	assert := assert.New(t)
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	documentID := uuid.New()
+	path := filepath.Join(t.TempDir(), documentID.String())
+	volume := repMocks.NewMockUnpublishedVolumeRepository(controller)
+	expectSaves(volume, documentID, path).Times(1)
+
+	head := service.NewAutosaveHead(documentID, volume, time.Hour)
+	(&fakeDocument{text: "hello world"}).attach(head)
+	go head.Spin()
+
+	state := "hello there world"
+	head.Destroy(&state)
+
+	contents, err := os.ReadFile(path)
+	assert.Nil(err)
+	assert.Equal("hello there world", string(contents))
+
+	// stopping an extension that's been destroyed is harmless
+	head.Stop()
+}
+
+func TestAutosaveReportsFailures(t *testing.T) {
+	assert := assert.New(t)
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	documentID := uuid.New()
+	volume := repMocks.NewMockUnpublishedVolumeRepository(controller)
+	volume.EXPECT().GetFromVolumeTruncated(documentID.String()).Return(nil, errors.New("volume is full")).MinTimes(1)
+
+	reported := make(chan error, 1)
+	head := service.NewAutosaveHead(documentID, volume, idleDelay)
+	head.AttachReporter(func(problem error) {
+		select {
+		case reported <- problem:
+		default:
+		}
+	})
+
+	doc := &fakeDocument{text: "hello world"}
+	doc.attach(head)
+	go head.Spin()
+	defer head.Stop()
+
+	doc.edit("hello there world")
+
+	select {
+	case problem := <-reported:
+		assert.ErrorContains(problem, "volume is full")
+	case <-time.After(time.Second):
+		assert.Fail("the failed save was never reported")
+	}
+}
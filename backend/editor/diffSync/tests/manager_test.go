@@ -0,0 +1,219 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"cms.csesoc.unsw.edu.au/database/repositories"
+	"cms.csesoc.unsw.edu.au/editor/diffSync/document"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// recordingHead is an extension head that remembers the document state it was initialised with
+type recordingHead struct {
+	initialState string
+}
+
+func (r *recordingHead) Init(_ func(document.Message), _ func(), documentState *string) {
+	r.initialState = *documentState
+}
+func (r *recordingHead) Synchronise(document.Message) {}
+func (r *recordingHead) Notify()                      {}
+func (r *recordingHead) Destroy(*string)              {}
+func (r *recordingHead) IsService() bool              { return false }
+func (r *recordingHead) Spin()                        {}
+func (r *recordingHead) Stop()                        {}
+
+// leavingHead is an extension head that can disconnect itself like a client closing its connection
+type leavingHead struct {
+	recordingHead
+	leave func()
+}
+
+func (l *leavingHead) Init(propagate func(document.Message), terminate func(), documentState *string) {
+	l.recordingHead.Init(propagate, terminate, documentState)
+	l.leave = terminate
+}
+
+// stallingHead is a background service that takes until it is released to be destroyed
+type stallingHead struct {
+	recordingHead
+	destroying, release chan struct{}
+}
+
+func (s *stallingHead) IsBackground() bool { return true }
+func (s *stallingHead) Destroy(*string) {
+	close(s.destroying)
+	<-s.release
+}
+
+// frontend is the repositories a manager is constructed with
+type frontend struct {
+	filesystem  repositories.FilesystemRepository
+	unpublished repositories.UnpublishedVolumeRepository
+}
+
+func newFrontend(t *testing.T, db *repositories.MemoryDatabase, logicalName, URL string) frontend {
+	filesystem, err := repositories.NewMemoryFilesystemRepo(logicalName, URL, db)
+	if err != nil {
+		t.Fatalf("failed to create the filesystem: %v", err)
+	}
+
+	unpublished, err := repositories.NewLocalUnpublishedRepo(t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to create the unpublished volume: %v", err)
+	}
+
+	return frontend{filesystem, unpublished}
+}
+
+func newSeededDatabase(t *testing.T) *repositories.MemoryDatabase {
+	db := repositories.NewMemoryDatabase()
+	if err := db.SeedDummyData(); err != nil {
+		t.Fatalf("failed to seed the in-memory database: %v", err)
+	}
+
+	return db
+}
+
+// createEntry creates an entry in the root of the frontend, documents are given contents unless contents is nil
+func (f frontend) createEntry(t *testing.T, name string, isDocument bool, contents *string) uuid.UUID {
+	root, err := f.filesystem.GetRoot()
+	if err != nil {
+		t.Fatalf("failed to get the root: %v", err)
+	}
+
+	entry, err := f.filesystem.CreateEntry(repositories.FilesystemEntry{
+		LogicalName:  name,
+		OwnerUserId:  repositories.GROUPS_ADMIN,
+		ParentFileID: root.EntityID,
+		IsDocument:   isDocument,
+	})
+	if err != nil {
+		t.Fatalf("failed to create %s: %v", name, err)
+	}
+
+	if contents != nil {
+		file, err := f.unpublished.GetFromVolumeTruncated(entry.EntityID.String())
+		if err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+		defer file.Close()
+
+		file.WriteString(*contents)
+	}
+
+	return entry.EntityID
+}
+
+func TestManagerOpensDocuments(t *testing.T) {
+	assert := assert.New(t)
+
+	contents := "hello world"
+	frontend := newFrontend(t, newSeededDatabase(t), "CSESoc Test", "http://localhost:3001")
+	documentID := frontend.createEntry(t, "document", true, &contents)
+
+	manager := document.NewManager(frontend.filesystem, frontend.unpublished)
+	if !assert.Nil(manager.OpenDocument(documentID)) {
+		return
+	}
+	assert.True(manager.IsDocOpen(documentID))
+	assert.NotNil(manager.OpenDocument(documentID))
+
+	// extensions are loaded with the contents of the document
+	head := &recordingHead{}
+	if assert.Nil(manager.LoadExtension(documentID, document.NewExtensionWithHead(head))) {
+		assert.Equal("hello world", head.initialState)
+	}
+
+	assert.Nil(manager.CloseAndStopDocument(documentID))
+	assert.False(manager.IsDocOpen(documentID))
+	assert.NotNil(manager.LoadExtension(documentID, document.NewExtensionWithHead(&recordingHead{})))
+}
+
+func TestExtensionsCantJoinAStoppedDocument(t *testing.T) {
+	assert := assert.New(t)
+
+	contents := "hello world"
+	frontend := newFrontend(t, newSeededDatabase(t), "CSESoc Test", "http://localhost:3001")
+	documentID := frontend.createEntry(t, "document", true, &contents)
+
+	manager := document.NewManager(frontend.filesystem, frontend.unpublished)
+	if !assert.Nil(manager.OpenDocument(documentID)) {
+		return
+	}
+
+	service := &stallingHead{destroying: make(chan struct{}), release: make(chan struct{})}
+	client := &leavingHead{}
+	if !assert.Nil(manager.LoadExtension(documentID, document.NewExtensionWithHead(service))) ||
+		!assert.Nil(manager.LoadExtension(documentID, document.NewExtensionWithHead(client))) {
+		return
+	}
+
+	// the only client leaves so the document stops, another extension is loaded while it is stopping
+	client.leave()
+	<-service.destroying
+
+	loaded := make(chan error)
+	go func() { loaded <- manager.LoadExtension(documentID, document.NewExtensionWithHead(&recordingHead{})) }()
+	// give the load a chance to find the document before it is closed
+	time.Sleep(50 * time.Millisecond)
+	close(service.release)
+
+	select {
+	case err := <-loaded:
+		assert.ErrorIs(err, document.ErrDocumentClosed)
+	case <-time.After(time.Second):
+		assert.Fail("loading an extension into a stopped document blocked")
+	}
+}
+
+func TestManagerRejectsInvalidDocuments(t *testing.T) {
+	assert := assert.New(t)
+
+	frontend := newFrontend(t, newSeededDatabase(t), "CSESoc Test", "http://localhost:3001")
+	manager := document.NewManager(frontend.filesystem, frontend.unpublished)
+
+	// neither of these can be opened but neither of them panic either
+	directory := frontend.createEntry(t, "directory", false, nil)
+	for _, documentID := range []uuid.UUID{uuid.New(), directory} {
+		assert.NotNil(manager.OpenDocument(documentID))
+		assert.False(manager.IsDocOpen(documentID))
+	}
+
+	// documents that haven't been written to yet are empty
+	documentID := frontend.createEntry(t, "document", true, nil)
+	head := &recordingHead{}
+	if assert.Nil(manager.OpenDocument(documentID)) && assert.Nil(manager.LoadExtension(documentID, document.NewExtensionWithHead(head))) {
+		assert.Equal("", head.initialState)
+		assert.Nil(manager.CloseAndStopDocument(documentID))
+	}
+}
+
+func TestManagersArePerFrontend(t *testing.T) {
+	assert := assert.New(t)
+
+	db := newSeededDatabase(t)
+	contents := "hello world"
+	first := newFrontend(t, db, "first", "http://localhost:3001")
+	second := newFrontend(t, db, "second", "http://localhost:3002")
+	documentID := first.createEntry(t, "document", true, &contents)
+
+	firstManager := document.NewManager(first.filesystem, first.unpublished)
+	secondManager := document.NewManager(second.filesystem, second.unpublished)
+	if !assert.Nil(firstManager.OpenDocument(documentID)) {
+		return
+	}
+	assert.False(secondManager.IsDocOpen(documentID))
+
+	// the contents are read from the first frontend's volume
+	head := &recordingHead{}
+	if assert.Nil(firstManager.LoadExtension(documentID, document.NewExtensionWithHead(head))) {
+		assert.Equal("hello world", head.initialState)
+	}
+	assert.NotNil(secondManager.LoadExtension(documentID, document.NewExtensionWithHead(&recordingHead{})))
+
+	assert.Nil(firstManager.CloseAndStopDocument(documentID))
+	assert.NotNil(secondManager.CloseAndStopDocument(documentID))
+}
@@ -0,0 +1,247 @@
+package tests
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"cms.csesoc.unsw.edu.au/editor/diffSync/document"
+	"github.com/stretchr/testify/assert"
+)
+
+// peer is both ends of a link, the extension end initiates every exchange
+type peer struct {
+	extensionText, documentText string
+	extension, document         *document.Shadow
+}
+
+func newPeer(text string) *peer {
+	return &peer{
+		extensionText: text, documentText: text,
+		extension: document.NewExtensionShadow(text), document: document.NewDocumentShadow(text),
+	}
+}
+
+// send diffs the extension's text and returns its message
+func (p *peer) send() document.Message { return p.extension.Diff(p.extensionText) }
+
+// respond applies a message to the document and returns its response
+func (p *peer) respond(t *testing.T, message document.Message) document.Message {
+	text, _, err := p.document.Receive(message, p.documentText)
+	assert.Nil(t, err)
+	p.documentText = text
+
+	return p.document.Diff(p.documentText)
+}
+
+// accept applies a response to the extension
+func (p *peer) accept(t *testing.T, message document.Message) {
+	text, _, err := p.extension.Receive(message, p.extensionText)
+	assert.Nil(t, err)
+	p.extensionText = text
+}
+
+func TestShadowExchange(t *testing.T) {
+	assert := assert.New(t)
+
+	link := newPeer("the quick brown fox")
+	link.extensionText = "the quick red fox"
+	link.documentText = "the quick brown fox jumps"
+
+	link.accept(t, link.respond(t, link.send()))
+	assert.Equal("the quick red fox jumps", link.documentText)
+	assert.Equal("the quick red fox jumps", link.extensionText)
+}
+
+func TestShadowRecoversFromLostMessages(t *testing.T) {
+	assert := assert.New(t)
+
+	// the extension's message is lost, its edits are resent with its next message
+	link := newPeer("the quick brown fox")
+	link.extensionText = "the quick red fox"
+	link.send()
+	link.extensionText = "the quick red fox jumps"
+	link.accept(t, link.respond(t, link.send()))
+	assert.Equal("the quick red fox jumps", link.documentText)
+	assert.Equal("the quick red fox jumps", link.extensionText)
+
+	// the document's response is lost, the document falls back to its backup shadow
+	link.documentText = "a quick red fox jumps"
+	link.respond(t, link.send())
+	link.extensionText = "a very quick red fox jumps"
+	link.documentText = "a quick red fox jumps!"
+	link.accept(t, link.respond(t, link.send()))
+	assert.Equal("a very quick red fox jumps!", link.documentText)
+	assert.Equal("a very quick red fox jumps!", link.extensionText)
+}
+
+func TestShadowIgnoresRepeatedMessages(t *testing.T) {
+	assert := assert.New(t)
+
+	link := newPeer("the quick brown fox")
+	link.extensionText = "the quick brown fox jumps"
+	message := link.send()
+
+	response := link.respond(t, message)
+	link.respond(t, message)
+	link.accept(t, response)
+	link.accept(t, response)
+	assert.Equal("the quick brown fox jumps", link.documentText)
+	assert.Equal("the quick brown fox jumps", link.extensionText)
+
+	// a response that has been superseded is ignored
+	link.extensionText = "the quick brown fox jumps over"
+	link.respond(t, link.send())
+	_, _, err := link.extension.Receive(response, link.extensionText)
+	assert.ErrorIs(err, document.ErrStaleMessage)
+}
+
+func TestShadowDetectsLostEdits(t *testing.T) {
+	assert := assert.New(t)
+
+	// the extension forgets an edit it never got acknowledged
+	link := newPeer("the quick brown fox")
+	link.extensionText = "the quick red fox"
+	message := link.send()
+	message.Edits[0].Version++
+
+	_, _, err := link.document.Receive(message, link.documentText)
+	assert.ErrorIs(err, document.ErrOutOfSync)
+
+	// resetting the link brings both ends back in sync
+	link.extension.Reset()
+	link.document.Reset()
+	response := link.document.Diff(link.documentText)
+	response.Reset = true
+	link.extensionText = ""
+	link.accept(t, response)
+	assert.Equal("the quick brown fox", link.extensionText)
+}
+
+func TestShadowReportsConflicts(t *testing.T) {
+	assert := assert.New(t)
+
+	link := newPeer("the quick brown fox")
+	link.extensionText = "the quick red fox"
+	link.documentText = "something else entirely"
+
+	_, conflicts, err := link.document.Receive(link.send(), link.documentText)
+	assert.Nil(err)
+	assert.Equal(1, conflicts)
+}
+
+// editorHead is an extension that edits the document the way a client would, it can lose and repeat messages
+type editorHead struct {
+	lock      sync.Mutex
+	shadow    *document.Shadow
+	text      string
+	send      func(document.Message)
+	responses chan struct{}
+
+	// dropResponse loses the next response from the document
+	dropResponse bool
+}
+
+func newEditorHead() *editorHead {
+	return &editorHead{shadow: document.NewExtensionShadow(""), responses: make(chan struct{}, 16)}
+}
+
+func (e *editorHead) Init(send func(document.Message), _ func(), _ *string) { e.send = send }
+func (e *editorHead) Notify()                                               {}
+func (e *editorHead) Destroy(*string)                                       {}
+func (e *editorHead) IsService() bool                                       { return false }
+func (e *editorHead) Spin()                                                 {}
+func (e *editorHead) Stop()                                                 {}
+
+func (e *editorHead) Synchronise(message document.Message) {
+	e.lock.Lock()
+	defer func() {
+		e.lock.Unlock()
+		e.responses <- struct{}{}
+	}()
+
+	if e.dropResponse {
+		e.dropResponse = false
+		return
+	}
+
+	if message.Reset {
+		e.shadow.Reset()
+		e.text = ""
+	}
+	e.text, _, _ = e.shadow.Receive(message, e.text)
+}
+
+// sync sends the document a message (twice if repeat is set) and waits for the responses
+func (e *editorHead) sync(t *testing.T, repeat bool) {
+	e.lock.Lock()
+	message := e.shadow.Diff(e.text)
+	e.lock.Unlock()
+
+	e.send(message)
+	e.await(t)
+	if repeat {
+		e.send(message)
+		e.await(t)
+	}
+}
+
+func (e *editorHead) await(t *testing.T) {
+	select {
+	case <-e.responses:
+	case <-time.After(time.Second):
+		t.Fatal("the document never responded")
+	}
+}
+
+func (e *editorHead) edit(text string) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	e.text = text
+}
+
+func (e *editorHead) current() string {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	return e.text
+}
+
+func TestDocumentSynchronisesUnreliableClients(t *testing.T) {
+	assert := assert.New(t)
+
+	contents := "the quick brown fox"
+	frontend := newFrontend(t, newSeededDatabase(t), "CSESoc Test", "http://localhost:3001")
+	documentID := frontend.createEntry(t, "document", true, &contents)
+	manager := document.NewManager(frontend.filesystem, frontend.unpublished)
+	if !assert.Nil(manager.OpenDocument(documentID)) {
+		return
+	}
+	defer manager.CloseAndStopDocument(documentID)
+
+	first, second := newEditorHead(), newEditorHead()
+	for _, head := range []*editorHead{first, second} {
+		if !assert.Nil(manager.LoadExtension(documentID, document.NewExtensionWithHead(head))) {
+			return
+		}
+		head.await(t)
+		assert.Equal("the quick brown fox", head.current())
+	}
+
+	// the response to the first edit is lost so the edit is resent
+	first.edit("the quick red fox")
+	first.dropResponse = true
+	first.sync(t, false)
+	first.edit("the quick red fox jumps")
+	first.sync(t, false)
+	assert.Equal("the quick red fox jumps", first.current())
+
+	// repeated messages are only applied once
+	second.sync(t, false)
+	assert.Equal("the quick red fox jumps", second.current())
+	second.edit("the quick red fox jumps!")
+	second.sync(t, true)
+	assert.Equal("the quick red fox jumps!", second.current())
+
+	first.sync(t, false)
+	assert.Equal("the quick red fox jumps!", first.current())
+}
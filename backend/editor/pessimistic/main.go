@@ -2,24 +2,34 @@ package editor
 
 import (
 	"bytes"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"log"
+	"os"
 
 	"cms.csesoc.unsw.edu.au/database/repositories"
+	"cms.csesoc.unsw.edu.au/editor/OT/datamodel"
+	"cms.csesoc.unsw.edu.au/pkg/cmsjson"
 	"github.com/google/uuid"
 	"github.com/gorilla/websocket"
 )
 
-// This is the main loop that the editor client will run
-func EditorClientLoop(requestedDocument uuid.UUID, fs repositories.UnpublishedVolumeRepository, ws *websocket.Conn) error {
-	manager := getGlobalManagerInstance()
-	err := manager.startDocumentServer(requestedDocument)
-	if err != nil {
-		terminateWs(ws, "locked")
-		return errors.New("unable to open request document, cannot start document server")
+// Validator checks the new contents of a document before they are saved, saves are rejected if it returns an error
+type Validator func(contents []byte) error
+
+// This is the main loop that the editor client will run, read only clients are sent the current
+// state of the document but cannot make changes (and don't lock the document)
+func EditorClientLoop(requestedDocument uuid.UUID, fs repositories.UnpublishedVolumeRepository, ws *websocket.Conn, readOnly bool, validate Validator) error {
+	if !readOnly {
+		manager := getGlobalManagerInstance()
+		err := manager.startDocumentServer(requestedDocument)
+		if err != nil {
+			terminateWs(ws, "locked")
+			return errors.New("unable to open request document, cannot start document server")
+		}
+		defer manager.closeDocumentServer(requestedDocument)
 	}
-	defer manager.closeDocumentServer(requestedDocument)
 
 	file, err := fs.GetFromVolume(requestedDocument.String())
 	if err != nil {
@@ -37,6 +47,7 @@ func EditorClientLoop(requestedDocument uuid.UUID, fs repositories.UnpublishedVo
 	// 		-> client continues
 	//		-> client sends updated
 	//		-> we apply updated and send acknowledgement
+	//		   (or reject the update if the client is read only or the update is invalid)
 
 	// send the current state of the document
 	buf := &bytes.Buffer{}
@@ -52,7 +63,16 @@ func EditorClientLoop(requestedDocument uuid.UUID, fs repositories.UnpublishedVo
 		buf.WriteString("[]")
 	}
 
-	ws.WriteMessage(websocket.TextMessage, []byte(fmt.Sprintf(`{"type": "init", "contents": %s}`, buf.String())))
+	// documents written with an older datamodel are upgraded before anyone sees them
+	contents, upgraded, err := datamodel.Upgrades.Upgrade(buf.Bytes())
+	if err != nil {
+		terminateWs(ws, "error")
+		return fmt.Errorf("unable to upgrade request document: %w", err)
+	} else if upgraded && !readOnly {
+		overwrite(file, contents)
+	}
+
+	ws.WriteMessage(websocket.TextMessage, []byte(fmt.Sprintf(`{"type": "init", "readOnly": %t, "contents": %s}`, readOnly, contents)))
 
 	for {
 		_, buf, err := ws.ReadMessage()
@@ -65,9 +85,23 @@ func EditorClientLoop(requestedDocument uuid.UUID, fs repositories.UnpublishedVo
 			break
 		}
 
-		file.Truncate(0)
-		file.Seek(0, 0)
-		file.Write(buf)
+		if readOnly {
+			ws.WriteMessage(websocket.TextMessage, []byte(`{"type": "rejected", "reason": "read only"}`))
+			continue
+		}
+
+		if err := validate(buf); err != nil {
+			ws.WriteMessage(websocket.TextMessage, rejectInvalid(err))
+			continue
+		}
+
+		stamped, err := datamodel.Upgrades.Stamp(buf)
+		if err != nil {
+			ws.WriteMessage(websocket.TextMessage, rejectInvalid(err))
+			continue
+		}
+
+		overwrite(file, stamped)
 
 		// send an acknowledgement to the client
 		ws.WriteMessage(websocket.TextMessage, []byte(`{"type": "acknowledged"}`))
@@ -76,6 +110,30 @@ func EditorClientLoop(requestedDocument uuid.UUID, fs repositories.UnpublishedVo
 	return nil
 }
 
+// rejectInvalid builds the message rejecting an update that failed validation, it lists every problem with the update
+func rejectInvalid(err error) []byte {
+	problems := []string{err.Error()}
+	var invalid cmsjson.ValidationErrors
+	if errors.As(err, &invalid) {
+		problems = invalid.Messages()
+	}
+
+	message, _ := json.Marshal(struct {
+		Type   string   `json:"type"`
+		Reason string   `json:"reason"`
+		Errors []string `json:"errors"`
+	}{"rejected", "invalid", problems})
+
+	return message
+}
+
+// overwrite replaces the contents of a document
+func overwrite(file *os.File, contents []byte) {
+	file.Truncate(0)
+	file.Seek(0, 0)
+	file.Write(contents)
+}
+
 // terminateWs is just a small util function thats called on termination
 func terminateWs(ws *websocket.Conn, reason string) {
 	ws.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseGoingAway, fmt.Sprintf(`"%s"`, reason)))
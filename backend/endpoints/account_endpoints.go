@@ -0,0 +1,158 @@
+package endpoints
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"cms.csesoc.unsw.edu.au/database/repositories"
+	. "cms.csesoc.unsw.edu.au/endpoints/models"
+	"cms.csesoc.unsw.edu.au/environment"
+	"cms.csesoc.unsw.edu.au/internal/mailer"
+	"cms.csesoc.unsw.edu.au/internal/tokens"
+)
+
+// How long the tokens we email out remain valid for
+const (
+	passwordResetTokenTTL     = 1 * time.Hour
+	emailVerificationTokenTTL = 48 * time.Hour
+)
+
+// RequestPasswordReset emails a password reset link to the requested account, note that it always succeeds (as long as the request is well formed)
+// regardless of whether the account exists, this prevents the endpoint from being used to enumerate accounts
+func RequestPasswordReset(form ValidAccountEmailRequest, df DependencyFactory) handlerResponse[empty] {
+	log := df.GetLogger()
+	person, err := df.GetPersonsRepo().GetPersonWithEmail(form.Email)
+	if err != nil {
+		log.Write("password reset requested for an unknown account")
+		return handlerResponse[empty]{Status: http.StatusOK}
+	}
+
+	err = issueAndSendToken(df, person, tokens.PasswordReset, passwordResetTokenTTL, func(token string) mailer.Message {
+		return mailer.Message{
+			To:      person.Email,
+			Subject: "Reset your CSESoc CMS password",
+			Body: fmt.Sprintf("Hi %s,\n\nSomeone (hopefully you) requested a password reset for your account, "+
+				"you can reset your password by visiting:\n\n%s\n\nThis link expires in an hour, if you didn't request this you can ignore this email.",
+				person.FirstName, buildFrontendLink("reset-password", token)),
+		}
+	})
+
+	if err != nil {
+		log.Write(fmt.Sprintf("failed to send password reset email: %v", err))
+		return handlerResponse[empty]{Status: http.StatusInternalServerError}
+	}
+
+	return handlerResponse[empty]{Status: http.StatusOK}
+}
+
+// ResetPassword redeems a password reset token and updates the password of the account it was issued for
+func ResetPassword(form ValidPasswordResetRequest, df DependencyFactory) handlerResponse[empty] {
+	log := df.GetLogger()
+	if !form.IsValidPassword() {
+		return handlerResponse[empty]{Status: http.StatusBadRequest}
+	}
+
+	claims, status := redeemToken(df, form.Token, tokens.PasswordReset)
+	if status != http.StatusOK {
+		return handlerResponse[empty]{Status: status}
+	}
+
+	if err := df.GetPersonsRepo().SetPassword(claims.UID, form.HashPassword()); err != nil {
+		log.Write(fmt.Sprintf("failed to update password: %v", err))
+		return handlerResponse[empty]{Status: http.StatusInternalServerError}
+	}
+
+	// any other reset links floating around are now stale
+	if err := df.GetTokensRepo().InvalidateTokensForPerson(claims.UID, string(tokens.PasswordReset)); err != nil {
+		log.Write(fmt.Sprintf("failed to invalidate outstanding reset tokens: %v", err))
+	}
+
+	log.Write(fmt.Sprintf("reset password for user %d", claims.UID))
+	return handlerResponse[empty]{Status: http.StatusOK}
+}
+
+// RequestEmailVerification (re)sends the verification email for an account, like RequestPasswordReset it does
+// not reveal whether the account exists or has already been verified
+func RequestEmailVerification(form ValidAccountEmailRequest, df DependencyFactory) handlerResponse[empty] {
+	log := df.GetLogger()
+	person, err := df.GetPersonsRepo().GetPersonWithEmail(form.Email)
+	if err != nil || person.Verified {
+		return handlerResponse[empty]{Status: http.StatusOK}
+	}
+
+	err = issueAndSendToken(df, person, tokens.EmailVerification, emailVerificationTokenTTL, func(token string) mailer.Message {
+		return mailer.Message{
+			To:      person.Email,
+			Subject: "Verify your CSESoc CMS account",
+			Body: fmt.Sprintf("Hi %s,\n\nPlease confirm your email address by visiting:\n\n%s\n\nThis link expires in 48 hours.",
+				person.FirstName, buildFrontendLink("verify-email", token)),
+		}
+	})
+
+	if err != nil {
+		log.Write(fmt.Sprintf("failed to send verification email: %v", err))
+		return handlerResponse[empty]{Status: http.StatusInternalServerError}
+	}
+
+	return handlerResponse[empty]{Status: http.StatusOK}
+}
+
+// VerifyEmail redeems an email verification token and marks the account it was issued for as verified
+func VerifyEmail(form ValidEmailVerificationRequest, df DependencyFactory) handlerResponse[empty] {
+	log := df.GetLogger()
+	claims, status := redeemToken(df, form.Token, tokens.EmailVerification)
+	if status != http.StatusOK {
+		return handlerResponse[empty]{Status: status}
+	}
+
+	if err := df.GetPersonsRepo().MarkAsVerified(claims.UID); err != nil {
+		log.Write(fmt.Sprintf("failed to mark user as verified: %v", err))
+		return handlerResponse[empty]{Status: http.StatusInternalServerError}
+	}
+
+	log.Write(fmt.Sprintf("verified email for user %d", claims.UID))
+	return handlerResponse[empty]{Status: http.StatusOK}
+}
+
+// issueAndSendToken issues a new token for a person, records it and emails it to them
+func issueAndSendToken(df DependencyFactory, person repositories.Person, purpose tokens.Purpose, ttl time.Duration, buildMessage func(token string) mailer.Message) error {
+	claims := tokens.NewClaims(person.UID, purpose, ttl)
+	err := df.GetTokensRepo().CreateToken(repositories.AuthToken{
+		TokenID:   claims.TokenID,
+		UID:       claims.UID,
+		Purpose:   string(claims.Purpose),
+		ExpiresAt: claims.ExpiresAt,
+	})
+
+	if err != nil {
+		return fmt.Errorf("failed to record token: %w", err)
+	}
+
+	return df.GetMailer().Send(buildMessage(tokens.Sign(claims)))
+}
+
+// redeemToken verifies a token's signature and marks it as used, it returns the claims within the token
+// and the HTTP status the handler should respond with if the token could not be redeemed
+func redeemToken(df DependencyFactory, token string, purpose tokens.Purpose) (tokens.Claims, int) {
+	log := df.GetLogger()
+	claims, err := tokens.Verify(token, purpose)
+	if err != nil {
+		log.Write(fmt.Sprintf("rejected token: %v", err))
+		return tokens.Claims{}, http.StatusUnauthorized
+	}
+
+	// the database is the source of truth regarding whether or not a token has been used
+	if _, err := df.GetTokensRepo().RedeemToken(claims.TokenID, string(purpose)); err != nil {
+		log.Write(fmt.Sprintf("token %s could not be redeemed: %v", claims.TokenID, err))
+		return tokens.Claims{}, http.StatusUnauthorized
+	}
+
+	return claims, http.StatusOK
+}
+
+// buildFrontendLink builds a link to a page on the frontend that receives a token
+func buildFrontendLink(page string, token string) string {
+	return fmt.Sprintf("%s/%s?token=%s", environment.GetFrontendURI(), page, url.QueryEscape(token))
+}
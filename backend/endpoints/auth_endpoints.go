@@ -11,29 +11,192 @@ package endpoints
 
 import (
 	"fmt"
+	"math"
+	"net"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
+	"cms.csesoc.unsw.edu.au/database/repositories"
 	. "cms.csesoc.unsw.edu.au/endpoints/models"
 	"cms.csesoc.unsw.edu.au/environment"
 	"cms.csesoc.unsw.edu.au/internal/session"
+	"cms.csesoc.unsw.edu.au/internal/throttle"
+	"github.com/google/uuid"
 )
 
-// LoginHandler is a HTTP login handler
-func LoginHandler(form User, w http.ResponseWriter, r *http.Request, df DependencyFactory) handlerResponse[empty] {
-	if !form.IsValidEmail() || !form.UserExists(df.GetPersonsRepo()) {
-		return handlerResponse[empty]{
+// Throttling scopes for the login endpoint, failures are tracked per account, per IP address and per account
+// from each IP address. The per IP policy is a lot more lenient as many legitimate users can share an IP (eg.
+// everyone on the uni network).
+//
+// Failures are counted against an email whether or not an account exists for it (otherwise the throttle would reveal
+// which emails have accounts), so anyone can make failed attempts against anyone else's email. To stop that from
+// locking the owner out only the account+address scope has a lockout, the account scope alone just backs off. This
+// still slows down an attacker spreading their guesses over many addresses but it does mean an attacker can keep
+// an account's owner waiting for up to its MaxDelay between attempts
+const (
+	accountScope        throttle.Scope = "account"
+	addressScope        throttle.Scope = "ip"
+	accountAddressScope throttle.Scope = "account_ip"
+)
+
+// Outcomes recorded in the login audit log
+const (
+	loginSucceeded          = "success"
+	loginInvalidEmail       = "invalid_email"
+	loginInvalidCredentials = "invalid_credentials"
+	loginUnverified         = "unverified"
+	loginThrottled          = "throttled"
+	loginLockedOut          = "locked_out"
+	loginTwoFactorRequired  = "two_factor_required"
+	loginEnrolmentRequired  = "two_factor_enrolment_required"
+	loginInvalidSecondStep  = "invalid_two_factor_code"
+)
+
+// NewLoginThrottler constructs the throttler used by the login endpoint on top of the provided store
+func NewLoginThrottler(store throttle.Store) *throttle.Throttler {
+	return throttle.New(store, map[throttle.Scope]throttle.Policy{
+		accountScope: {
+			FreeAttempts: 3,
+			BaseDelay:    time.Second,
+			MaxDelay:     time.Minute,
+			Window:       time.Hour,
+		},
+		accountAddressScope: {
+			FreeAttempts:     3,
+			BaseDelay:        time.Second,
+			MaxDelay:         5 * time.Minute,
+			LockoutThreshold: 10,
+			LockoutDuration:  15 * time.Minute,
+			Window:           time.Hour,
+		},
+		addressScope: {
+			FreeAttempts:     20,
+			BaseDelay:        time.Second,
+			MaxDelay:         time.Minute,
+			LockoutThreshold: 100,
+			LockoutDuration:  30 * time.Minute,
+			Window:           time.Hour,
+		},
+	})
+}
+
+// LoginHandler is a HTTP login handler, repeated failures against either an account or from an IP address
+// are throttled with an exponential backoff (and eventually a lockout), every attempt is recorded in the audit log.
+// If the user has 2FA enabled (or must enrol in it) then logging in is a two step process, LoginHandler responds
+// with a LoginResponse describing the next step and VerifyTwoFactorLogin completes the login
+func LoginHandler(form User, w http.ResponseWriter, r *http.Request, df DependencyFactory) handlerResponse[LoginResponse] {
+	log := df.GetLogger()
+	throttler := df.GetLoginThrottler()
+	attempt := repositories.LoginAttempt{Email: form.Email, IPAddress: getClientIP(r)}
+	accountKeys, addressKey := getLoginKeys(attempt)
+
+	// the attempt is counted as a failure up front and released if it turns out to be successful, checking
+	// and failing separately would allow concurrent guesses to all get through before any of them failed
+	decision, err := throttler.Reserve(append(accountKeys, addressKey)...)
+	if err != nil {
+		log.Write(fmt.Sprintf("failed to check login throttle: %v", err))
+		return handlerResponse[LoginResponse]{Status: http.StatusInternalServerError}
+	}
+
+	if !decision.Allowed {
+		attempt.Outcome = loginThrottled
+		if decision.LockedOut {
+			attempt.Outcome = loginLockedOut
+		}
+
+		recordLoginAttempt(df, attempt)
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(decision.RetryAfter.Seconds()))))
+		return handlerResponse[LoginResponse]{Status: http.StatusTooManyRequests}
+	}
+
+	personRepo := df.GetPersonsRepo()
+	if !form.IsValidEmail() || !form.UserExists(personRepo) {
+		attempt.Outcome = loginInvalidCredentials
+		if !form.IsValidEmail() {
+			// there is no account to throttle so just count it against the address
+			attempt.Outcome = loginInvalidEmail
+			releaseLoginAttempt(df, accountKeys...)
+		}
+
+		recordLoginAttempt(df, attempt)
+		return handlerResponse[LoginResponse]{
 			Status: http.StatusUnauthorized,
 		}
 	}
 
-	session.CreateSession(w, r, form.Email)
+	// the password was correct so the attempt didn't fail
+	releaseLoginAttempt(df, append(accountKeys, addressKey)...)
+
+	// users have to confirm their email before they can log in
+	person, err := personRepo.GetPersonWithEmail(form.Email)
+	if err != nil || !person.Verified {
+		attempt.Outcome = loginUnverified
+		recordLoginAttempt(df, attempt)
+		return handlerResponse[LoginResponse]{
+			Status: http.StatusForbidden,
+		}
+	}
+
+	// the password was correct but the user may still need to complete a second step, note that the throttle
+	// is not reset until the user has completed every step otherwise the second factor could be brute forced
+	twoFactor, err := df.GetTwoFactorRepo().GetTwoFactor(person.UID)
+	if err != nil {
+		log.Write(fmt.Sprintf("failed to fetch 2FA enrolment: %v", err))
+		return handlerResponse[LoginResponse]{Status: http.StatusInternalServerError}
+	}
+
+	if twoFactor.Enabled {
+		attempt.Outcome = loginTwoFactorRequired
+		recordLoginAttempt(df, attempt)
+		session.CreatePendingSession(w, r, person.Email, form.FrontendID, false)
+
+		return handlerResponse[LoginResponse]{
+			Status:   http.StatusOK,
+			Response: LoginResponse{TwoFactorRequired: true},
+		}
+	}
+
+	if required, err := requiresTwoFactor(df, person, form.FrontendID); err != nil || required {
+		if err != nil {
+			log.Write(fmt.Sprintf("failed to determine 2FA policy: %v", err))
+			return handlerResponse[LoginResponse]{Status: http.StatusInternalServerError}
+		}
+
+		attempt.Outcome = loginEnrolmentRequired
+		recordLoginAttempt(df, attempt)
+		session.CreatePendingSession(w, r, person.Email, form.FrontendID, true)
+
+		return handlerResponse[LoginResponse]{
+			Status:   http.StatusOK,
+			Response: LoginResponse{EnrolmentRequired: true},
+		}
+	}
+
+	completeLogin(w, r, df, attempt, form.FrontendID)
 	http.Redirect(w, r, fmt.Sprintf("%s/%s", environment.GetFrontendURI(), "dashboard"), http.StatusMovedPermanently)
 
-	return handlerResponse[empty]{
+	return handlerResponse[LoginResponse]{
 		Status: http.StatusMovedPermanently,
 	}
 }
 
+// completeLogin establishes a session for a user that has completed every step of logging into a frontend
+func completeLogin(w http.ResponseWriter, r *http.Request, df DependencyFactory, attempt repositories.LoginAttempt, frontendID uuid.UUID) {
+	// only the account is cleared, otherwise an attacker could reset their address by logging into their own account
+	accountKeys, _ := getLoginKeys(attempt)
+	if err := df.GetLoginThrottler().Succeed(accountKeys...); err != nil {
+		df.GetLogger().Write(fmt.Sprintf("failed to reset login throttle: %v", err))
+	}
+
+	attempt.Successful = true
+	attempt.Outcome = loginSucceeded
+	recordLoginAttempt(df, attempt)
+
+	session.CreateSession(w, r, attempt.Email, frontendID)
+}
+
 // LogoutHandler is just logs the user out of their current session
 func LogoutHandler(form empty, w http.ResponseWriter, r *http.Request, df DependencyFactory) handlerResponse[empty] {
 	w.Header().Set("Access-Control-Allow-Origin", environment.GetFrontendURI())
@@ -44,3 +207,123 @@ func LogoutHandler(form empty, w http.ResponseWriter, r *http.Request, df Depend
 		Status: http.StatusOK,
 	}
 }
+
+// GetCSRFToken fetches the CSRF token of the client's session (creating the session if need be), the token must
+// be sent in the X-CSRF-Token header of every state changing request made with the session cookie
+func GetCSRFToken(form empty, w http.ResponseWriter, r *http.Request, df DependencyFactory) handlerResponse[CSRFTokenResponse] {
+	token, err := session.GetCSRFToken(w, r)
+	if err != nil {
+		df.GetLogger().Write(fmt.Sprintf("failed to issue CSRF token: %v", err))
+		return handlerResponse[CSRFTokenResponse]{Status: http.StatusInternalServerError}
+	}
+
+	return handlerResponse[CSRFTokenResponse]{
+		Status:   http.StatusOK,
+		Response: CSRFTokenResponse{Token: token},
+	}
+}
+
+// GetLoginAttempts allows admins to query the login audit log
+func GetLoginAttempts(form ValidLoginAttemptsRequest, w http.ResponseWriter, r *http.Request, df DependencyFactory) handlerResponse[LoginAttemptsResponse] {
+	if status := requireAdmin(r, df); status != http.StatusOK {
+		return handlerResponse[LoginAttemptsResponse]{Status: status}
+	}
+
+	attempts, err := df.GetLoginAttemptsRepo().GetAttempts(repositories.LoginAttemptFilter{
+		Email:        form.Email,
+		IPAddress:    form.IPAddress,
+		OnlyFailures: form.OnlyFailures,
+		Limit:        form.Limit,
+	})
+
+	if err != nil {
+		df.GetLogger().Write(fmt.Sprintf("failed to fetch login attempts: %v", err))
+		return handlerResponse[LoginAttemptsResponse]{Status: http.StatusInternalServerError}
+	}
+
+	return handlerResponse[LoginAttemptsResponse]{
+		Status:   http.StatusOK,
+		Response: LoginAttemptsResponse{Attempts: attempts},
+	}
+}
+
+// requireAdmin determines if the client making the request is logged in as an admin, it returns the status
+// the handler should respond with if they aren't
+func requireAdmin(r *http.Request, df DependencyFactory) int {
+	person, _, status := getSessionPerson(r, df, false)
+	if status != http.StatusOK {
+		return status
+	}
+
+	if isAdmin, err := df.GetGroupsRepo().IsMemberOf(person.UID, repositories.GROUPS_ADMIN); err != nil || !isAdmin {
+		return http.StatusForbidden
+	}
+
+	return http.StatusOK
+}
+
+// requirePermission determines if the client making the request is logged in as someone holding (at least) the
+// required permission over an entity, it returns the status the handler should respond with if they aren't
+func requirePermission(r *http.Request, df DependencyFactory, entityID uuid.UUID, required repositories.Permission) int {
+	person, _, status := getSessionPerson(r, df, false)
+	if status != http.StatusOK {
+		return status
+	}
+
+	permission, err := df.GetPermissionsRepo().GetPermission(person.UID, entityID)
+	if err != nil {
+		df.GetLogger().Write(fmt.Sprintf("failed to resolve permissions: %v", err))
+		return http.StatusInternalServerError
+	} else if !permission.Allows(required) {
+		df.GetLogger().Write(fmt.Sprintf("user %d doesn't have %s access to %s", person.UID, required, entityID))
+		return http.StatusForbidden
+	}
+
+	return http.StatusOK
+}
+
+// getLoginKeys determines the keys a login attempt is throttled by, the keys belonging to the account are
+// separated from the key belonging to the address since successfully logging in only clears the former
+func getLoginKeys(attempt repositories.LoginAttempt) ([]throttle.Key, throttle.Key) {
+	email := strings.ToLower(attempt.Email)
+	return []throttle.Key{
+		{Scope: accountScope, Value: email},
+		{Scope: accountAddressScope, Value: fmt.Sprintf("%s|%s", email, attempt.IPAddress)},
+	}, throttle.Key{Scope: addressScope, Value: attempt.IPAddress}
+}
+
+// releaseLoginAttempt undoes the failures reserved for a login attempt against the provided keys
+func releaseLoginAttempt(df DependencyFactory, keys ...throttle.Key) {
+	if err := df.GetLoginThrottler().Release(keys...); err != nil {
+		df.GetLogger().Write(fmt.Sprintf("failed to release login attempt: %v", err))
+	}
+}
+
+// recordLoginAttempt writes an attempt to the audit log, failing to do so shouldn't prevent the user from logging in
+func recordLoginAttempt(df DependencyFactory, attempt repositories.LoginAttempt) {
+	if err := df.GetLoginAttemptsRepo().RecordAttempt(attempt); err != nil {
+		df.GetLogger().Write(fmt.Sprintf("failed to record login attempt: %v", err))
+	}
+}
+
+// getClientIP determines the IP address of the client making a request, the X-Forwarded-For header is only respected
+// if we have been configured to trust it. Every proxy appends the address it received the request from to the header,
+// so only the entries appended by our own proxies can be trusted (the client can send anything before them) and the
+// client's address is the one appended by the outermost of them
+func getClientIP(r *http.Request) string {
+	if hops := environment.GetTrustedProxyHops(); hops > 0 {
+		forwarded := strings.Split(strings.Join(r.Header.Values("X-Forwarded-For"), ","), ",")
+		if len(forwarded) >= hops {
+			if ip := net.ParseIP(strings.TrimSpace(forwarded[len(forwarded)-hops])); ip != nil {
+				return ip.String()
+			}
+		}
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+
+	return host
+}
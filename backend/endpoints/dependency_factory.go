@@ -3,11 +3,18 @@ package endpoints
 //go:generate mockgen -source=dependency_factory.go -destination=mocks/dependency_factory_mock.go -package=mocks
 
 import (
+	"context"
 	"fmt"
+	"path/filepath"
+	"sync"
 
 	"cms.csesoc.unsw.edu.au/database/contexts"
 	repos "cms.csesoc.unsw.edu.au/database/repositories"
+	"cms.csesoc.unsw.edu.au/editor/OT/operations"
+	"cms.csesoc.unsw.edu.au/environment"
 	"cms.csesoc.unsw.edu.au/internal/logger"
+	"cms.csesoc.unsw.edu.au/internal/mailer"
+	"cms.csesoc.unsw.edu.au/internal/throttle"
 	"github.com/google/uuid"
 )
 
@@ -19,11 +26,26 @@ type (
 		GetGroupsRepo() repos.GroupsRepository
 		GetFrontendsRepo() repos.FrontendsRepository
 		GetPersonsRepo() repos.PersonRepository
+		GetTokensRepo() repos.TokensRepository
+		GetLoginAttemptsRepo() repos.LoginAttemptsRepository
+		GetTwoFactorRepo() repos.TwoFactorRepository
+		GetPermissionsRepo() repos.PermissionsRepository
+		GetMetadataRepo() repos.MetadataRepository
+		GetTagsRepo() repos.TagsRepository
+		GetDocumentTypesRepo() repos.DocumentTypesRepository
 
 		GetUnpublishedVolumeRepo() repos.UnpublishedVolumeRepository
 		GetPublishedVolumeRepo() repos.PublishedVolumeRepository
 
+		GetComponentRegistry() (*operations.Registry, error)
+
 		GetLogger() *logger.Log
+		GetMailer() mailer.Mailer
+		GetLoginThrottler() *throttle.Throttler
+
+		// WithTx runs fn as a single unit of work, every repository fetched from the dependency factory
+		// provided to fn shares one database transaction which is rolled back if fn returns an error
+		WithTx(fn func(tx DependencyFactory) error) error
 	}
 
 	// DependencyProvider is a simple implementation of the dependency factory that supports the injection of "dynamic" dependencies
@@ -32,12 +54,52 @@ type (
 		FrontEndID  uuid.UUID
 		LogicalName string
 		URL         string
+
+		// Context is the context of the request the dependencies are serving, all database queries are made under it
+		Context context.Context
+		// Database is the database repositories are instantiated with, if unset the database for the current environment is used
+		Database contexts.DatabaseContext
+
+		// inDevTx is set when the provider is handed to a unit of work in dev mode, nested units of work become savepoints
+		inDevTx bool
 	}
 )
 
+// devModeDependencies are the in-memory database and local volumes every DependencyProvider uses in dev mode
+type devModeDependencies struct {
+	database    *repos.MemoryDatabase
+	unpublished repos.UnpublishedVolumeRepository
+	published   repos.PublishedVolumeRepository
+}
+
+// devDependencies is only set once dev mode has been enabled
+var devDependencies *devModeDependencies
+
+// EnableDevMode makes every DependencyProvider use the in-memory repositories backed by db (rather than Postgres)
+// and volumes stored beneath volumeDirectory (rather than the docker volumes)
+func EnableDevMode(db *repos.MemoryDatabase, volumeDirectory string) error {
+	unpublished, err := repos.NewLocalUnpublishedRepo(filepath.Join(volumeDirectory, "unpublished"))
+	if err != nil {
+		return fmt.Errorf("Error creating unpublished volume: %w", err)
+	}
+
+	published, err := repos.NewLocalPublishedRepo(filepath.Join(volumeDirectory, "published"))
+	if err != nil {
+		return fmt.Errorf("Error creating published volume: %w", err)
+	}
+
+	devDependencies = &devModeDependencies{db, unpublished, published}
+
+	return nil
+}
+
 // GetFilesystemRepo is the constructor for FS repos
 func (dp DependencyProvider) GetFilesystemRepo() (repos.FilesystemRepository, error) {
-	fsRepo, err := repos.NewFilesystemRepo(dp.LogicalName, dp.URL, contexts.GetDatabaseContext())
+	if devDependencies != nil {
+		return repos.NewMemoryFilesystemRepo(dp.LogicalName, dp.URL, devDependencies.database)
+	}
+
+	fsRepo, err := repos.NewFilesystemRepo(dp.context(), dp.LogicalName, dp.URL, dp.database())
 	if err != nil {
 		return fsRepo, fmt.Errorf("Error getting FSRepo: %w", err)
 	}
@@ -46,29 +108,190 @@ func (dp DependencyProvider) GetFilesystemRepo() (repos.FilesystemRepository, er
 
 // GetGroupsRepo instantiates a new groups repository
 func (dp DependencyProvider) GetGroupsRepo() repos.GroupsRepository {
-	return repos.NewGroupsRepo(contexts.GetDatabaseContext())
+	if devDependencies != nil {
+		return repos.NewMemoryGroupsRepo(devDependencies.database)
+	}
+
+	return repos.NewGroupsRepo(dp.context(), dp.database())
 }
 
 // GetFrontendsRepo instantiates a new frontend repository
 func (dp DependencyProvider) GetFrontendsRepo() repos.FrontendsRepository {
-	return repos.NewFrontendsRepo(contexts.GetDatabaseContext())
+	if devDependencies != nil {
+		return repos.NewMemoryFrontendsRepo(devDependencies.database)
+	}
+
+	return repos.NewFrontendsRepo(dp.context(), dp.database())
 }
 
 // GetPersonsRepo instantiates a new person repository
 func (dp DependencyProvider) GetPersonsRepo() repos.PersonRepository {
-	return repos.NewPersonRepo(dp.FrontEndID)
+	if devDependencies != nil {
+		return repos.NewMemoryPersonRepo(devDependencies.database)
+	}
+
+	return repos.NewPersonRepo(dp.context(), dp.FrontEndID, dp.database())
+}
+
+// GetTokensRepo instantiates a new tokens repository
+func (dp DependencyProvider) GetTokensRepo() repos.TokensRepository {
+	if devDependencies != nil {
+		return repos.NewMemoryTokensRepo(devDependencies.database)
+	}
+
+	return repos.NewTokensRepo(dp.context(), dp.database())
+}
+
+// GetLoginAttemptsRepo instantiates a new login attempts repository
+func (dp DependencyProvider) GetLoginAttemptsRepo() repos.LoginAttemptsRepository {
+	if devDependencies != nil {
+		return repos.NewMemoryLoginAttemptsRepo(devDependencies.database)
+	}
+
+	return repos.NewLoginAttemptsRepo(dp.context(), dp.database())
+}
+
+// GetTwoFactorRepo instantiates a new 2FA repository
+func (dp DependencyProvider) GetTwoFactorRepo() repos.TwoFactorRepository {
+	if devDependencies != nil {
+		return repos.NewMemoryTwoFactorRepo(devDependencies.database)
+	}
+
+	return repos.NewTwoFactorRepo(dp.context(), dp.database())
+}
+
+// GetPermissionsRepo instantiates a new permissions repository
+func (dp DependencyProvider) GetPermissionsRepo() repos.PermissionsRepository {
+	if devDependencies != nil {
+		return repos.NewMemoryPermissionsRepo(devDependencies.database)
+	}
+
+	return repos.NewPermissionsRepo(dp.context(), dp.database())
+}
+
+// GetMetadataRepo instantiates a new metadata repository
+func (dp DependencyProvider) GetMetadataRepo() repos.MetadataRepository {
+	if devDependencies != nil {
+		return repos.NewMemoryMetadataRepo(devDependencies.database)
+	}
+
+	return repos.NewMetadataRepo(dp.context(), dp.database())
+}
+
+// GetTagsRepo instantiates a new tags repository
+func (dp DependencyProvider) GetTagsRepo() repos.TagsRepository {
+	if devDependencies != nil {
+		return repos.NewMemoryTagsRepo(devDependencies.database)
+	}
+
+	return repos.NewTagsRepo(dp.context(), dp.database())
+}
+
+// GetDocumentTypesRepo instantiates a new document types repository
+func (dp DependencyProvider) GetDocumentTypesRepo() repos.DocumentTypesRepository {
+	if devDependencies != nil {
+		return repos.NewMemoryDocumentTypesRepo(devDependencies.database)
+	}
+
+	return repos.NewDocumentTypesRepo(dp.context(), dp.database())
 }
 
 // GetUnpublishedVolumeRepo instantiates a new instance of the unpublished volume repository
 func (dp DependencyProvider) GetUnpublishedVolumeRepo() repos.UnpublishedVolumeRepository {
+	if devDependencies != nil {
+		return devDependencies.unpublished
+	}
+
 	return repos.NewUnpublishedRepo()
 }
 
 // PublishedVolumeRepo instantiates an instance of the published volume repository
 func (dp DependencyProvider) GetPublishedVolumeRepo() repos.PublishedVolumeRepository {
+	if devDependencies != nil {
+		return devDependencies.published
+	}
+
 	return repos.NewPublishedRepo()
 }
 
+// the component registry is loaded from disk so it is shared between requests rather than loaded for every request
+var (
+	componentRegistry     *operations.Registry
+	componentRegistryErr  error
+	componentRegistryOnce sync.Once
+)
+
+// GetComponentRegistry fetches the registry holding the components declared by each frontend, the schemas are
+// loaded from the component schema directory (if there is one) the first time the registry is fetched
+func (dp DependencyProvider) GetComponentRegistry() (*operations.Registry, error) {
+	componentRegistryOnce.Do(func() {
+		if directory := environment.GetComponentSchemaDirectory(); directory != "" {
+			componentRegistry, componentRegistryErr = operations.LoadRegistry(directory)
+		} else {
+			componentRegistry = operations.NewRegistry()
+		}
+	})
+
+	return componentRegistry, componentRegistryErr
+}
+
 func (dp DependencyProvider) GetLogger() *logger.Log {
 	return dp.Log
 }
+
+// GetMailer fetches the mailer for the current environment
+func (dp DependencyProvider) GetMailer() mailer.Mailer {
+	return mailer.GetMailer()
+}
+
+// the login throttler has to outlive individual requests so unlike the other dependencies it is shared
+var (
+	loginThrottler     *throttle.Throttler
+	loginThrottlerOnce sync.Once
+)
+
+// GetLoginThrottler fetches the throttler protecting the login endpoint
+func (dp DependencyProvider) GetLoginThrottler() *throttle.Throttler {
+	loginThrottlerOnce.Do(func() {
+		loginThrottler = NewLoginThrottler(throttle.NewMemoryStore())
+	})
+
+	return loginThrottler
+}
+
+// WithTx runs fn within a database transaction, the transaction is committed if fn succeeds
+func (dp DependencyProvider) WithTx(fn func(tx DependencyFactory) error) error {
+	if devDependencies != nil {
+		txProvider := dp
+		txProvider.inDevTx = true
+		if dp.inDevTx {
+			return devDependencies.database.Savepoint(func() error { return fn(txProvider) })
+		}
+
+		return devDependencies.database.WithTx(func() error { return fn(txProvider) })
+	}
+
+	return dp.database().WithTx(dp.context(), func(tx contexts.DatabaseContext) error {
+		txProvider := dp
+		txProvider.Database = tx
+		return fn(txProvider)
+	})
+}
+
+// context fetches the context queries should be made under
+func (dp DependencyProvider) context() context.Context {
+	if dp.Context == nil {
+		return context.Background()
+	}
+
+	return dp.Context
+}
+
+// database fetches the database repositories should be instantiated with
+func (dp DependencyProvider) database() contexts.DatabaseContext {
+	if dp.Database == nil {
+		return contexts.GetDatabaseContext()
+	}
+
+	return dp.Database
+}
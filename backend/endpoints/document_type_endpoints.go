@@ -0,0 +1,101 @@
+package endpoints
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"cms.csesoc.unsw.edu.au/database/repositories"
+	. "cms.csesoc.unsw.edu.au/endpoints/models"
+	"cms.csesoc.unsw.edu.au/internal/doctypes"
+	"cms.csesoc.unsw.edu.au/pkg/cmsjson"
+	"github.com/google/uuid"
+)
+
+// validateDocument validates the contents of a document against the type attached to the directory it lives in,
+// it returns the status the handler should respond with alongside a description of every problem found
+func validateDocument(df DependencyFactory, directoryID uuid.UUID, content []byte) (int, []string) {
+	documentType, err := df.GetDocumentTypesRepo().GetDirectoryType(directoryID)
+	if err != nil {
+		df.GetLogger().Write(fmt.Sprintf("failed to fetch the document type of %s: %v", directoryID, err))
+		return http.StatusInternalServerError, nil
+	}
+
+	var invalid cmsjson.ValidationErrors
+	if err := doctypes.Validate(documentType, content); errors.As(err, &invalid) {
+		df.GetLogger().Write(fmt.Sprintf("document is not a valid %s: %v", documentType.Name, invalid))
+		return http.StatusUnprocessableEntity, invalid.Messages()
+	}
+
+	return http.StatusOK, nil
+}
+
+// GetDocumentTypes is the handler for fetching every document type
+func GetDocumentTypes(form empty, df DependencyFactory) handlerResponse[DocumentTypesResponse] {
+	documentTypes, err := df.GetDocumentTypesRepo().GetDocumentTypes()
+	if err != nil {
+		return handlerResponse[DocumentTypesResponse]{Status: http.StatusInternalServerError}
+	}
+
+	return handlerResponse[DocumentTypesResponse]{
+		Status:   http.StatusOK,
+		Response: DocumentTypesResponse{DocumentTypes: DocumentTypesToResponse(documentTypes)},
+	}
+}
+
+// CreateDocumentType is the handler for defining a new document type, document types are shared by every frontend
+// so only admins can define them
+func CreateDocumentType(form ValidDocumentTypeCreationRequest, w http.ResponseWriter, r *http.Request, df DependencyFactory) handlerResponse[NewDocumentTypeResponse] {
+	if status := requireAdmin(r, df); status != http.StatusOK {
+		return handlerResponse[NewDocumentTypeResponse]{Status: status}
+	}
+
+	documentType := CreationReqToDocumentType(form)
+
+	var invalid cmsjson.ValidationErrors
+	if err := doctypes.ValidateDefinition(documentType); errors.As(err, &invalid) {
+		return handlerResponse[NewDocumentTypeResponse]{Status: http.StatusUnprocessableEntity, Errors: invalid.Messages()}
+	}
+
+	documentType, err := df.GetDocumentTypesRepo().CreateDocumentType(documentType)
+	if err != nil {
+		return handlerResponse[NewDocumentTypeResponse]{Status: http.StatusNotAcceptable}
+	}
+
+	df.GetLogger().Write(fmt.Sprintf("created document type %v", documentType))
+	return handlerResponse[NewDocumentTypeResponse]{
+		Status:   http.StatusOK,
+		Response: NewDocumentTypeResponse{NewID: documentType.TypeID},
+	}
+}
+
+// DeleteDocumentType is the handler for deleting a document type, the type is detached from every directory so only
+// admins can delete them
+func DeleteDocumentType(form ValidDocumentTypeRequest, w http.ResponseWriter, r *http.Request, df DependencyFactory) handlerResponse[empty] {
+	if status := requireAdmin(r, df); status != http.StatusOK {
+		return handlerResponse[empty]{Status: status}
+	}
+
+	if err := df.GetDocumentTypesRepo().DeleteDocumentType(form.TypeID); err != nil {
+		return handlerResponse[empty]{Status: http.StatusInternalServerError}
+	}
+
+	df.GetLogger().Write(fmt.Sprintf("deleted document type %d", form.TypeID))
+	return handlerResponse[empty]{Status: http.StatusOK}
+}
+
+// SetDirectoryType is the handler for attaching a document type to (or detaching it from) a directory, note that
+// documents already within the directory are only validated against the new type when they are next saved. The type
+// decides what can be saved within the directory so only its owners (and admins) can change it
+func SetDirectoryType(form ValidDirectoryTypeRequest, w http.ResponseWriter, r *http.Request, df DependencyFactory) handlerResponse[empty] {
+	if status := requirePermission(r, df, form.DirectoryID, repositories.PermissionDelete); status != http.StatusOK {
+		return handlerResponse[empty]{Status: status}
+	}
+
+	if err := df.GetDocumentTypesRepo().SetDirectoryType(form.DirectoryID, form.TypeID); err != nil {
+		return handlerResponse[empty]{Status: http.StatusNotAcceptable}
+	}
+
+	df.GetLogger().Write(fmt.Sprintf("set the document type of %s to %d", form.DirectoryID, form.TypeID))
+	return handlerResponse[empty]{Status: http.StatusOK}
+}
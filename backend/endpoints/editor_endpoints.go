@@ -3,38 +3,81 @@ package endpoints
 import (
 	"fmt"
 	"net/http"
+	"strings"
 
+	"cms.csesoc.unsw.edu.au/database/repositories"
 	editor "cms.csesoc.unsw.edu.au/editor/pessimistic"
 	. "cms.csesoc.unsw.edu.au/endpoints/models"
+	"cms.csesoc.unsw.edu.au/internal/doctypes"
+	"github.com/google/uuid"
 	"github.com/gorilla/websocket"
 )
 
-// Upgrader is a websocket upgrader
-var Upgrader = websocket.Upgrader{
-	ReadBufferSize:  1024,
-	WriteBufferSize: 1024,
-	CheckOrigin: func(r *http.Request) bool {
-		return true
-	},
+// newUpgrader constructs a websocket upgrader that only accepts connections originating from a registered frontend,
+// without this check any site could open an editor connection using a visitor's session cookie
+func newUpgrader(df DependencyFactory) websocket.Upgrader {
+	return websocket.Upgrader{
+		ReadBufferSize:  1024,
+		WriteBufferSize: 1024,
+		CheckOrigin: func(r *http.Request) bool {
+			origin := r.Header.Get("Origin")
+			if origin == "" {
+				return false
+			}
+
+			registered, err := df.GetFrontendsRepo().IsRegisteredURL(strings.TrimSuffix(origin, "/"))
+			return err == nil && registered
+		},
+	}
 }
 
 // EditHandler is the HTTP handler responsible for dealing with incoming requests to edit a document
-// for the most part this is passed over to the editor package
+// for the most part this is passed over to the editor package, users with write access get a normal
+// editing session while users that can only read the document get a read only session
 func EditHandler(form ValidEditRequest, w http.ResponseWriter, r *http.Request, df DependencyFactory) handlerResponse[empty] {
 	unpublishedVol := df.GetUnpublishedVolumeRepo()
 	log := df.GetLogger()
 
-	ws, err := Upgrader.Upgrade(w, r, nil)
+	// the connection hasn't been upgraded yet so failures are reported as regular HTTP errors
+	person, _, status := getSessionPerson(r, df, false)
+	if status != http.StatusOK {
+		http.Error(w, getMessageFromStatus(status), status)
+		return handlerResponse[empty]{Status: status}
+	}
+
+	permission, err := df.GetPermissionsRepo().GetPermission(person.UID, form.DocumentID)
 	if err != nil {
-		log.Write("failed to upgrade websocket connection")
+		log.Write(fmt.Sprintf("failed to resolve permissions: %v", err))
+		http.Error(w, getMessageFromStatus(http.StatusInternalServerError), http.StatusInternalServerError)
+		return handlerResponse[empty]{Status: http.StatusInternalServerError}
+	} else if !permission.Allows(repositories.PermissionRead) {
+		log.Write(fmt.Sprintf("user %d has no access to %s", person.UID, form.DocumentID))
+		http.Error(w, getMessageFromStatus(http.StatusForbidden), http.StatusForbidden)
+		return handlerResponse[empty]{Status: http.StatusForbidden}
+	}
+
+	// saves are validated against the type of the directory the document lives in
+	validate, err := getDocumentValidator(df, form.DocumentID)
+	if err != nil {
+		log.Write(fmt.Sprintf("failed to fetch the document type of %s: %v", form.DocumentID, err))
+		http.Error(w, getMessageFromStatus(http.StatusNotFound), http.StatusNotFound)
+		return handlerResponse[empty]{Status: http.StatusNotFound}
+	}
+
+	upgrader := newUpgrader(df)
+	ws, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// note that Upgrade has already responded to the client
+		log.Write(fmt.Sprintf("failed to upgrade websocket connection: %v", err))
 		return handlerResponse[empty]{
 			Status: http.StatusInternalServerError,
 		}
 	}
 
 	// note: this blocks until completion
-	log.Write("starting editor loop")
-	err = editor.EditorClientLoop(form.DocumentID, unpublishedVol, ws)
+	readOnly := !permission.Allows(repositories.PermissionWrite)
+	log.Write(fmt.Sprintf("starting editor loop (read only: %t)", readOnly))
+	err = editor.EditorClientLoop(form.DocumentID, unpublishedVol, ws, readOnly, validate)
 	if err != nil {
 		log.Write(fmt.Sprintf("ending editor loop, message: %v", err.Error()))
 		return handlerResponse[empty]{
@@ -42,6 +85,25 @@ func EditHandler(form ValidEditRequest, w http.ResponseWriter, r *http.Request,
 		}
 	}
 
-	fmt.Print("hello!: D")
 	return handlerResponse[empty]{Status: http.StatusOK}
 }
+
+// getDocumentValidator builds the validator the editor checks a document's saves with
+func getDocumentValidator(df DependencyFactory, documentID uuid.UUID) (editor.Validator, error) {
+	fsRepo, err := df.GetFilesystemRepo()
+	if err != nil {
+		return nil, err
+	}
+
+	entity, err := fsRepo.GetEntryWithID(documentID)
+	if err != nil {
+		return nil, err
+	}
+
+	documentType, err := df.GetDocumentTypesRepo().GetDirectoryType(entity.ParentFileID)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(contents []byte) error { return doctypes.Validate(documentType, contents) }, nil
+}
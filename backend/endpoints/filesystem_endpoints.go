@@ -4,8 +4,8 @@ import (
 	"fmt"
 	"net/http"
 
+	"cms.csesoc.unsw.edu.au/database/repositories"
 	. "cms.csesoc.unsw.edu.au/endpoints/models"
-	"github.com/google/uuid"
 )
 
 // Defines endpoints consumable via the API
@@ -19,8 +19,8 @@ func GetEntityInfo(form ValidInfoRequest, df DependencyFactory) handlerResponse[
 		}
 	}
 
-	// Query the repository for an existing entity with the given ID
-	entity, err := fsRepo.GetEntryWithID(form.EntityID)
+	// Query the repository for an existing entity with the given ID (along with its children)
+	subtree, err := fsRepo.GetSubtree(form.EntityID, 1)
 	if err != nil {
 		return handlerResponse[EntityInfoResponse]{
 			Status:   http.StatusNotFound,
@@ -28,36 +28,114 @@ func GetEntityInfo(form ValidInfoRequest, df DependencyFactory) handlerResponse[
 		}
 	}
 
-	log.Write(fmt.Sprintf("retrieved entity: %v.", entity))
+	log.Write(fmt.Sprintf("retrieved entity: %v.", subtree[0]))
+
+	info, err := withMetadataAndTags(SubtreeToEntityInfo(subtree), df)
+	if err != nil {
+		return handlerResponse[EntityInfoResponse]{Status: http.StatusInternalServerError}
+	}
 
 	return handlerResponse[EntityInfoResponse]{
 		Status:   http.StatusOK,
-		Response: FsEntryToEntityInfo(entity, fsRepo, true),
+		Response: info,
 	}
 }
 
-// CreateNewEntity is the public handler for constructing and creating new entities
-func CreateNewEntity(form ValidEntityCreationRequest, df DependencyFactory) handlerResponse[NewEntityResponse] {
+// the deepest subtree that can be fetched in a single request
+const maxSubtreeDepth = 10
+
+// GetEntitySubtree is the handler for fetching an entity along with all its descendants up to a given depth
+func GetEntitySubtree(form ValidSubtreeRequest, df DependencyFactory) handlerResponse[EntityInfoResponse] {
 	log := df.GetLogger()
+	if form.Depth < 0 || form.Depth > maxSubtreeDepth {
+		return handlerResponse[EntityInfoResponse]{Status: http.StatusBadRequest}
+	}
+
 	fsRepo, err := df.GetFilesystemRepo()
 	if err != nil {
-		return handlerResponse[NewEntityResponse]{
-			Status:   http.StatusInternalServerError,
-			Response: NewEntityResponse{},
-		}
+		return handlerResponse[EntityInfoResponse]{Status: http.StatusInternalServerError}
+	}
+
+	subtree, err := fsRepo.GetSubtree(form.EntityID, form.Depth)
+	if err != nil {
+		return handlerResponse[EntityInfoResponse]{Status: http.StatusNotFound}
+	}
+
+	log.Write(fmt.Sprintf("retrieved subtree of %s (%d entities).", form.EntityID, len(subtree)))
+
+	info, err := withMetadataAndTags(SubtreeToEntityInfo(subtree), df)
+	if err != nil {
+		return handlerResponse[EntityInfoResponse]{Status: http.StatusInternalServerError}
+	}
+
+	return handlerResponse[EntityInfoResponse]{
+		Status:   http.StatusOK,
+		Response: info,
+	}
+}
+
+// GetBreadcrumbs is the handler for fetching the path from the root down to an entity
+func GetBreadcrumbs(form ValidInfoRequest, df DependencyFactory) handlerResponse[BreadcrumbsResponse] {
+	log := df.GetLogger()
+	fsRepo, err := df.GetFilesystemRepo()
+	if err != nil {
+		return handlerResponse[BreadcrumbsResponse]{Status: http.StatusInternalServerError}
 	}
-	pubRepo := df.GetUnpublishedVolumeRepo()
 
+	breadcrumbs, err := fsRepo.GetBreadcrumbs(form.EntityID)
+	if err != nil {
+		return handlerResponse[BreadcrumbsResponse]{Status: http.StatusNotFound}
+	}
+
+	log.Write(fmt.Sprintf("retrieved breadcrumbs for %s.", form.EntityID))
+	return handlerResponse[BreadcrumbsResponse]{
+		Status:   http.StatusOK,
+		Response: EntriesToBreadcrumbs(breadcrumbs),
+	}
+}
+
+// CreateNewEntity is the public handler for constructing and creating new entities, the entity's row is
+// only committed once its file has been created within the unpublished volume
+func CreateNewEntity(form ValidEntityCreationRequest, df DependencyFactory) handlerResponse[NewEntityResponse] {
+	log := df.GetLogger()
 	entityToCreate := CreationReqToFsEntry(form)
-	newEntity, err := fsRepo.CreateEntry(entityToCreate)
+
+	var newEntity repositories.FilesystemEntry
+	status, addedToVolume := http.StatusInternalServerError, false
+	err := df.WithTx(func(tx DependencyFactory) error {
+		fsRepo, err := tx.GetFilesystemRepo()
+		if err != nil {
+			return err
+		}
+
+		if newEntity, err = fsRepo.CreateEntry(entityToCreate); err != nil {
+			status = http.StatusNotAcceptable
+			return err
+		}
+
+		// opening the entity's file within the volume creates it
+		file, err := tx.GetUnpublishedVolumeRepo().GetFromVolume(newEntity.EntityID.String())
+		if err != nil {
+			return err
+		}
+
+		addedToVolume = true
+		return file.Close()
+	})
+
 	if err != nil {
+		// the transaction failed to commit, so the file we created no longer belongs to anything
+		if addedToVolume {
+			df.GetUnpublishedVolumeRepo().DeleteFromVolume(newEntity.EntityID.String())
+		}
+
+		log.Write(fmt.Sprintf("failed to create entity %v: %v", entityToCreate, err))
 		return handlerResponse[NewEntityResponse]{
-			Status: http.StatusNotAcceptable,
+			Status: status,
 		}
 	}
 
 	log.Write(fmt.Sprintf("created new entity %v.", entityToCreate))
-	pubRepo.AddToVolume(newEntity.EntityID.String())
 	return handlerResponse[NewEntityResponse]{
 		Status:   http.StatusOK,
 		Response: NewEntityResponse{NewID: newEntity.EntityID},
@@ -89,8 +167,8 @@ func DeleteFilesystemEntity(form ValidInfoRequest, df DependencyFactory) handler
 	}
 }
 
-// Handler for retrieving children
-func GetChildren(form ValidInfoRequest, df DependencyFactory) handlerResponse[ChildrenRequestResponse] {
+// Handler for retrieving children, optionally only those with a given tag
+func GetChildren(form ValidChildrenRequest, df DependencyFactory) handlerResponse[ChildrenRequestResponse] {
 	log := df.GetLogger()
 	fsRepo, err := df.GetFilesystemRepo()
 
@@ -108,36 +186,46 @@ func GetChildren(form ValidInfoRequest, df DependencyFactory) handlerResponse[Ch
 		}
 	}
 
-	log.Write(fmt.Sprintf("fetched children for %s, got %v.", form.EntityID, fileInfo.ChildrenIDs))
+	children := fileInfo.ChildrenIDs
+	if form.Tag != repositories.NoParentTag {
+		if children, err = df.GetTagsRepo().GetChildrenWithTag(form.EntityID, form.Tag); err != nil {
+			return handlerResponse[ChildrenRequestResponse]{
+				Status: http.StatusInternalServerError,
+			}
+		}
+	}
+
+	log.Write(fmt.Sprintf("fetched children for %s, got %v.", form.EntityID, children))
 	return handlerResponse[ChildrenRequestResponse]{
 		Status: http.StatusOK,
 		Response: ChildrenRequestResponse{
-			Children: fileInfo.ChildrenIDs,
+			Children: children,
 		},
 	}
 }
 
-func GetIDWithPath(form ValidPathRequest, df DependencyFactory) handlerResponse[uuid.UUID] {
+// GetIDWithPath is the handler for resolving a path (eg. /a/b/c) to the ID of the entity it refers to
+func GetIDWithPath(form ValidPathRequest, df DependencyFactory) handlerResponse[PathResolutionResponse] {
 	log := df.GetLogger()
 	repository, err := df.GetFilesystemRepo()
 
 	if err != nil {
-		return handlerResponse[uuid.UUID]{
+		return handlerResponse[PathResolutionResponse]{
 			Status:   http.StatusNotFound,
-			Response: uuid.UUID{},
+			Response: PathResolutionResponse{},
 		}
 	}
 
 	entityID, err := repository.GetIDWithPath(form.Path)
 	if err != nil {
-		return handlerResponse[uuid.UUID]{
+		return handlerResponse[PathResolutionResponse]{
 			Status: http.StatusNotFound,
 		}
 	}
 
 	log.Write(fmt.Sprintf("got ID %s for %s", entityID, form.Path))
-	return handlerResponse[uuid.UUID]{
-		Status: http.StatusOK, Response: entityID,
+	return handlerResponse[PathResolutionResponse]{
+		Status: http.StatusOK, Response: PathResolutionResponse{EntityID: entityID},
 	}
 }
 
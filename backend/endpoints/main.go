@@ -4,9 +4,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strings"
 
-	"cms.csesoc.unsw.edu.au/database/contexts"
-	"cms.csesoc.unsw.edu.au/database/repositories"
 	"cms.csesoc.unsw.edu.au/internal/logger"
 	"cms.csesoc.unsw.edu.au/internal/session"
 	"github.com/google/uuid"
@@ -21,6 +20,8 @@ type (
 		Status      int
 		Response    V
 		ContentType string
+		// Errors details why a request failed (eg: which fields of a document are invalid)
+		Errors []string
 	}
 
 	// APIResponse is the public response type that is marshalled and presented to consumers of the API
@@ -28,6 +29,7 @@ type (
 		Status   int
 		Message  string
 		Response V
+		Errors   []string `json:",omitempty"`
 	}
 )
 
@@ -56,6 +58,17 @@ type (
 
 // ServeHTTP is an overloaded implementation of method on the http.HttpHandler interface
 func (fn handler[T, V]) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	// reject forged cross-site requests before doing anything else
+	ignoreSessionOfBearerRequests(r)
+	if !passesCSRFCheck(r) {
+		writeResponse(w, handlerResponse[empty]{
+			Status:   http.StatusForbidden,
+			Response: empty{},
+		})
+
+		return
+	}
+
 	// Determine what type of form parser to use first
 	parser := getParser(fn)
 	parsedForm := new(T)
@@ -82,7 +95,7 @@ func (fn handler[T, V]) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 
 	// construct a dependency factory for this request, which implies instantiating a logger
 	logger := buildLogger(r.Method, r.URL.Path)
-	dependencyFactory := DependencyProvider{Log: logger, FrontEndID: frontendId}
+	dependencyFactory := DependencyProvider{Log: logger, FrontEndID: frontendId, Context: r.Context()}
 	response := fn.Handler(*parsedForm, dependencyFactory)
 
 	// Record and write out any useful information
@@ -97,6 +110,7 @@ func (fn handler[T, V]) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 // ServeHTTP is an overloaded implementation of method on the http.HttpHandler interface, the constraint for the authenticateHandler
 // is that it wraps the target handler up in an authentication check
 func (fn authenticatedHandler[T, V]) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ignoreSessionOfBearerRequests(r)
 	if ok, err := session.IsAuthenticated(w, r); !ok || err != nil {
 		writeResponse(w, handlerResponse[empty]{
 			Status:   http.StatusUnauthorized,
@@ -116,16 +130,45 @@ func (fn rawHandler[T, V]) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return fn.Handler(form, w, r, dependencyFactory)
 	}
 
+	wrapped := handler[T, V]{Handler: handlerWrapper, FormType: fn.FormType, IsMultipart: fn.IsMultipart, IsWebsocket: fn.IsWebsocket}
 	if fn.NeedsAuth {
-		authenticatedHandler[T, V]{Handler: handlerWrapper, FormType: fn.FormType, IsMultipart: false}.ServeHTTP(w, r)
+		authenticatedHandler[T, V](wrapped).ServeHTTP(w, r)
 	} else {
-		handler[T, V]{Handler: handlerWrapper, FormType: fn.FormType, IsMultipart: false}.ServeHTTP(w, r)
+		wrapped.ServeHTTP(w, r)
+	}
+}
+
+// CSRFHeader is the header clients must echo their CSRF token back in
+const CSRFHeader = "X-CSRF-Token"
+
+// passesCSRFCheck determines if a request is safe from cross-site request forgery, only requests that could change
+// state and are implicitly authenticated by the browser (ie. carry the session cookie) need to present a CSRF token
+func passesCSRFCheck(r *http.Request) bool {
+	switch r.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	}
+
+	if !session.HasSession(r) {
+		return true
+	}
+
+	return session.ValidCSRFToken(r, r.Header.Get(CSRFHeader))
+}
+
+// ignoreSessionOfBearerRequests removes the session cookie from requests that present a bearer token, so they are
+// exempt from the CSRF check (browsers never attach bearer tokens automatically) but can only ever be authenticated
+// by their token. Otherwise a forged request could attach any token to skip the check and still be authenticated by
+// the victim's cookie. Note that nothing authenticates bearer tokens yet so these requests are always anonymous
+func ignoreSessionOfBearerRequests(r *http.Request) {
+	if strings.HasPrefix(r.Header.Get("Authorization"), "Bearer ") {
+		session.RemoveSessionCookie(r)
 	}
 }
 
 // getFrontendID gets the frontend id for an incoming http request
 func getFrontendId(r *http.Request) int {
-	frontendRepo := repositories.NewFrontendsRepo(contexts.GetDatabaseContext())
+	frontendRepo := DependencyProvider{Context: r.Context()}.GetFrontendsRepo()
 	return frontendRepo.GetFrontendFromURL(r.URL.Host)
 }
 
@@ -133,9 +176,13 @@ func getFrontendId(r *http.Request) int {
 func getMessageFromStatus(statusCode int) string {
 	statusMappings := map[int]string{
 		http.StatusBadRequest:          "missing parameters (check documentation)",
+		http.StatusUnauthorized:        "invalid credentials",
+		http.StatusForbidden:           "you are not allowed to do that",
 		http.StatusMethodNotAllowed:    "invalid method",
 		http.StatusNotFound:            "unable to find requested object",
 		http.StatusNotAcceptable:       "unable to preform requested operation",
+		http.StatusTooManyRequests:     "too many attempts, try again later",
+		http.StatusUnprocessableEntity: "invalid content (see errors)",
 		http.StatusInternalServerError: "somethings wrong I can feel it",
 		http.StatusOK:                  "ok",
 	}
@@ -177,6 +224,7 @@ func getErrorResponse[V any](response handlerResponse[V]) APIResponse[empty] {
 		Status:   response.Status,
 		Response: empty{},
 		Message:  getMessageFromStatus(response.Status),
+		Errors:   response.Errors,
 	}
 }
 
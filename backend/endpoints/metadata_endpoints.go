@@ -0,0 +1,136 @@
+package endpoints
+
+import (
+	"fmt"
+	"net/http"
+
+	"cms.csesoc.unsw.edu.au/database/repositories"
+	. "cms.csesoc.unsw.edu.au/endpoints/models"
+)
+
+// the longest metadata key an entity can have
+const maxMetadataKeyLength = 64
+
+// withMetadataAndTags attaches an entity's metadata and tags to its info
+func withMetadataAndTags(info EntityInfoResponse, df DependencyFactory) (EntityInfoResponse, error) {
+	metadata, err := df.GetMetadataRepo().GetMetadata(info.EntityID)
+	if err != nil {
+		return EntityInfoResponse{}, err
+	}
+
+	tags, err := df.GetTagsRepo().GetEntityTags(info.EntityID)
+	if err != nil {
+		return EntityInfoResponse{}, err
+	}
+
+	info.Metadata = metadata
+	info.Tags = TagsToResponse(tags)
+	return info, nil
+}
+
+// SetMetadata is the handler for setting (or overwriting) a single key within an entity's metadata, the client must
+// be able to write to the entity
+func SetMetadata(form ValidMetadataRequest, w http.ResponseWriter, r *http.Request, df DependencyFactory) handlerResponse[empty] {
+	if status := requirePermission(r, df, form.EntityID, repositories.PermissionWrite); status != http.StatusOK {
+		return handlerResponse[empty]{Status: status}
+	} else if len(form.Key) > maxMetadataKeyLength {
+		return handlerResponse[empty]{Status: http.StatusBadRequest}
+	}
+
+	if err := df.GetMetadataRepo().SetMetadata(form.EntityID, form.Key, form.Value); err != nil {
+		return handlerResponse[empty]{Status: http.StatusNotAcceptable}
+	}
+
+	df.GetLogger().Write(fmt.Sprintf("set metadata %s of %s", form.Key, form.EntityID))
+	return handlerResponse[empty]{Status: http.StatusOK}
+}
+
+// DeleteMetadata is the handler for removing a single key from an entity's metadata, the client must be able to
+// write to the entity
+func DeleteMetadata(form ValidMetadataDeletionRequest, w http.ResponseWriter, r *http.Request, df DependencyFactory) handlerResponse[empty] {
+	if status := requirePermission(r, df, form.EntityID, repositories.PermissionWrite); status != http.StatusOK {
+		return handlerResponse[empty]{Status: status}
+	}
+
+	if err := df.GetMetadataRepo().DeleteMetadata(form.EntityID, form.Key); err != nil {
+		return handlerResponse[empty]{Status: http.StatusInternalServerError}
+	}
+
+	df.GetLogger().Write(fmt.Sprintf("deleted metadata %s of %s", form.Key, form.EntityID))
+	return handlerResponse[empty]{Status: http.StatusOK}
+}
+
+// GetTags is the handler for fetching the entire tag taxonomy
+func GetTags(form empty, df DependencyFactory) handlerResponse[TagsResponse] {
+	tags, err := df.GetTagsRepo().GetTags()
+	if err != nil {
+		return handlerResponse[TagsResponse]{Status: http.StatusInternalServerError}
+	}
+
+	return handlerResponse[TagsResponse]{
+		Status:   http.StatusOK,
+		Response: TagsResponse{Tags: TagsToResponse(tags)},
+	}
+}
+
+// CreateTag is the handler for adding a new tag to the taxonomy, the taxonomy is shared by every frontend
+// so only admins can change it
+func CreateTag(form ValidTagCreationRequest, w http.ResponseWriter, r *http.Request, df DependencyFactory) handlerResponse[NewTagResponse] {
+	if status := requireAdmin(r, df); status != http.StatusOK {
+		return handlerResponse[NewTagResponse]{Status: status}
+	}
+
+	tag, err := df.GetTagsRepo().CreateTag(form.Name, form.Parent)
+	if err != nil {
+		return handlerResponse[NewTagResponse]{Status: http.StatusNotAcceptable}
+	}
+
+	df.GetLogger().Write(fmt.Sprintf("created tag %v", tag))
+	return handlerResponse[NewTagResponse]{
+		Status:   http.StatusOK,
+		Response: NewTagResponse{NewID: tag.TagID},
+	}
+}
+
+// DeleteTag is the handler for removing a tag (and every tag nested within it) from the taxonomy, only admins
+// can change the taxonomy
+func DeleteTag(form ValidTagRequest, w http.ResponseWriter, r *http.Request, df DependencyFactory) handlerResponse[empty] {
+	if status := requireAdmin(r, df); status != http.StatusOK {
+		return handlerResponse[empty]{Status: status}
+	}
+
+	if err := df.GetTagsRepo().DeleteTag(form.TagID); err != nil {
+		return handlerResponse[empty]{Status: http.StatusInternalServerError}
+	}
+
+	df.GetLogger().Write(fmt.Sprintf("deleted tag %d", form.TagID))
+	return handlerResponse[empty]{Status: http.StatusOK}
+}
+
+// TagEntity is the handler for tagging an entity, the client must be able to write to the entity
+func TagEntity(form ValidEntityTagRequest, w http.ResponseWriter, r *http.Request, df DependencyFactory) handlerResponse[empty] {
+	if status := requirePermission(r, df, form.EntityID, repositories.PermissionWrite); status != http.StatusOK {
+		return handlerResponse[empty]{Status: status}
+	}
+
+	if err := df.GetTagsRepo().TagEntity(form.EntityID, form.TagID); err != nil {
+		return handlerResponse[empty]{Status: http.StatusNotAcceptable}
+	}
+
+	df.GetLogger().Write(fmt.Sprintf("tagged %s with %d", form.EntityID, form.TagID))
+	return handlerResponse[empty]{Status: http.StatusOK}
+}
+
+// UntagEntity is the handler for removing a tag from an entity, the client must be able to write to the entity
+func UntagEntity(form ValidEntityTagRequest, w http.ResponseWriter, r *http.Request, df DependencyFactory) handlerResponse[empty] {
+	if status := requirePermission(r, df, form.EntityID, repositories.PermissionWrite); status != http.StatusOK {
+		return handlerResponse[empty]{Status: status}
+	}
+
+	if err := df.GetTagsRepo().UntagEntity(form.EntityID, form.TagID); err != nil {
+		return handlerResponse[empty]{Status: http.StatusInternalServerError}
+	}
+
+	df.GetLogger().Write(fmt.Sprintf("untagged %s from %d", form.EntityID, form.TagID))
+	return handlerResponse[empty]{Status: http.StatusOK}
+}
@@ -8,7 +8,11 @@ import (
 	reflect "reflect"
 
 	repositories "cms.csesoc.unsw.edu.au/database/repositories"
+	operations "cms.csesoc.unsw.edu.au/editor/OT/operations"
+	endpoints "cms.csesoc.unsw.edu.au/endpoints"
 	logger "cms.csesoc.unsw.edu.au/internal/logger"
+	mailer "cms.csesoc.unsw.edu.au/internal/mailer"
+	throttle "cms.csesoc.unsw.edu.au/internal/throttle"
 	gomock "github.com/golang/mock/gomock"
 )
 
@@ -35,6 +39,35 @@ func (m *MockDependencyFactory) EXPECT() *MockDependencyFactoryMockRecorder {
 	return m.recorder
 }
 
+// GetComponentRegistry mocks base method.
+func (m *MockDependencyFactory) GetComponentRegistry() (*operations.Registry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetComponentRegistry")
+	ret0, _ := ret[0].(*operations.Registry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetComponentRegistry indicates an expected call of GetComponentRegistry.
+func (mr *MockDependencyFactoryMockRecorder) GetComponentRegistry() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetComponentRegistry", reflect.TypeOf((*MockDependencyFactory)(nil).GetComponentRegistry))
+}
+
+// GetDocumentTypesRepo mocks base method.
+func (m *MockDependencyFactory) GetDocumentTypesRepo() repositories.DocumentTypesRepository {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDocumentTypesRepo")
+	ret0, _ := ret[0].(repositories.DocumentTypesRepository)
+	return ret0
+}
+
+// GetDocumentTypesRepo indicates an expected call of GetDocumentTypesRepo.
+func (mr *MockDependencyFactoryMockRecorder) GetDocumentTypesRepo() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDocumentTypesRepo", reflect.TypeOf((*MockDependencyFactory)(nil).GetDocumentTypesRepo))
+}
+
 // GetFilesystemRepo mocks base method.
 func (m *MockDependencyFactory) GetFilesystemRepo() (repositories.FilesystemRepository, error) {
 	m.ctrl.T.Helper()
@@ -92,6 +125,76 @@ func (mr *MockDependencyFactoryMockRecorder) GetLogger() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLogger", reflect.TypeOf((*MockDependencyFactory)(nil).GetLogger))
 }
 
+// GetLoginAttemptsRepo mocks base method.
+func (m *MockDependencyFactory) GetLoginAttemptsRepo() repositories.LoginAttemptsRepository {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLoginAttemptsRepo")
+	ret0, _ := ret[0].(repositories.LoginAttemptsRepository)
+	return ret0
+}
+
+// GetLoginAttemptsRepo indicates an expected call of GetLoginAttemptsRepo.
+func (mr *MockDependencyFactoryMockRecorder) GetLoginAttemptsRepo() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLoginAttemptsRepo", reflect.TypeOf((*MockDependencyFactory)(nil).GetLoginAttemptsRepo))
+}
+
+// GetLoginThrottler mocks base method.
+func (m *MockDependencyFactory) GetLoginThrottler() *throttle.Throttler {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLoginThrottler")
+	ret0, _ := ret[0].(*throttle.Throttler)
+	return ret0
+}
+
+// GetLoginThrottler indicates an expected call of GetLoginThrottler.
+func (mr *MockDependencyFactoryMockRecorder) GetLoginThrottler() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLoginThrottler", reflect.TypeOf((*MockDependencyFactory)(nil).GetLoginThrottler))
+}
+
+// GetMailer mocks base method.
+func (m *MockDependencyFactory) GetMailer() mailer.Mailer {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMailer")
+	ret0, _ := ret[0].(mailer.Mailer)
+	return ret0
+}
+
+// GetMailer indicates an expected call of GetMailer.
+func (mr *MockDependencyFactoryMockRecorder) GetMailer() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMailer", reflect.TypeOf((*MockDependencyFactory)(nil).GetMailer))
+}
+
+// GetMetadataRepo mocks base method.
+func (m *MockDependencyFactory) GetMetadataRepo() repositories.MetadataRepository {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMetadataRepo")
+	ret0, _ := ret[0].(repositories.MetadataRepository)
+	return ret0
+}
+
+// GetMetadataRepo indicates an expected call of GetMetadataRepo.
+func (mr *MockDependencyFactoryMockRecorder) GetMetadataRepo() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMetadataRepo", reflect.TypeOf((*MockDependencyFactory)(nil).GetMetadataRepo))
+}
+
+// GetPermissionsRepo mocks base method.
+func (m *MockDependencyFactory) GetPermissionsRepo() repositories.PermissionsRepository {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPermissionsRepo")
+	ret0, _ := ret[0].(repositories.PermissionsRepository)
+	return ret0
+}
+
+// GetPermissionsRepo indicates an expected call of GetPermissionsRepo.
+func (mr *MockDependencyFactoryMockRecorder) GetPermissionsRepo() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPermissionsRepo", reflect.TypeOf((*MockDependencyFactory)(nil).GetPermissionsRepo))
+}
+
 // GetPersonsRepo mocks base method.
 func (m *MockDependencyFactory) GetPersonsRepo() repositories.PersonRepository {
 	m.ctrl.T.Helper()
@@ -120,6 +223,48 @@ func (mr *MockDependencyFactoryMockRecorder) GetPublishedVolumeRepo() *gomock.Ca
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPublishedVolumeRepo", reflect.TypeOf((*MockDependencyFactory)(nil).GetPublishedVolumeRepo))
 }
 
+// GetTagsRepo mocks base method.
+func (m *MockDependencyFactory) GetTagsRepo() repositories.TagsRepository {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTagsRepo")
+	ret0, _ := ret[0].(repositories.TagsRepository)
+	return ret0
+}
+
+// GetTagsRepo indicates an expected call of GetTagsRepo.
+func (mr *MockDependencyFactoryMockRecorder) GetTagsRepo() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTagsRepo", reflect.TypeOf((*MockDependencyFactory)(nil).GetTagsRepo))
+}
+
+// GetTokensRepo mocks base method.
+func (m *MockDependencyFactory) GetTokensRepo() repositories.TokensRepository {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTokensRepo")
+	ret0, _ := ret[0].(repositories.TokensRepository)
+	return ret0
+}
+
+// GetTokensRepo indicates an expected call of GetTokensRepo.
+func (mr *MockDependencyFactoryMockRecorder) GetTokensRepo() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTokensRepo", reflect.TypeOf((*MockDependencyFactory)(nil).GetTokensRepo))
+}
+
+// GetTwoFactorRepo mocks base method.
+func (m *MockDependencyFactory) GetTwoFactorRepo() repositories.TwoFactorRepository {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTwoFactorRepo")
+	ret0, _ := ret[0].(repositories.TwoFactorRepository)
+	return ret0
+}
+
+// GetTwoFactorRepo indicates an expected call of GetTwoFactorRepo.
+func (mr *MockDependencyFactoryMockRecorder) GetTwoFactorRepo() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTwoFactorRepo", reflect.TypeOf((*MockDependencyFactory)(nil).GetTwoFactorRepo))
+}
+
 // GetUnpublishedVolumeRepo mocks base method.
 func (m *MockDependencyFactory) GetUnpublishedVolumeRepo() repositories.UnpublishedVolumeRepository {
 	m.ctrl.T.Helper()
@@ -133,3 +278,17 @@ func (mr *MockDependencyFactoryMockRecorder) GetUnpublishedVolumeRepo() *gomock.
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUnpublishedVolumeRepo", reflect.TypeOf((*MockDependencyFactory)(nil).GetUnpublishedVolumeRepo))
 }
+
+// WithTx mocks base method.
+func (m *MockDependencyFactory) WithTx(fn func(endpoints.DependencyFactory) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WithTx", fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// WithTx indicates an expected call of WithTx.
+func (mr *MockDependencyFactoryMockRecorder) WithTx(fn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithTx", reflect.TypeOf((*MockDependencyFactory)(nil).WithTx), fn)
+}
@@ -7,4 +7,11 @@ type (
 	ValidEditRequest struct {
 		DocumentID uuid.UUID
 	}
+
+	// ValidSchemaRequest is the request model for fetching the types documents of a frontend are made of, Format
+	// is either json (a JSON Schema, the default) or typescript
+	ValidSchemaRequest struct {
+		FrontendID uuid.UUID
+		Format     string
+	}
 )
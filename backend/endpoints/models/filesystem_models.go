@@ -20,6 +20,19 @@ type (
 		IsDocument  bool   `schema:"IsDocument,required"`
 	}
 
+	// ValidChildrenRequest is the model accepted by handlers that return the children of an entity, if a tag is
+	// provided only the children tagged with it (or a tag nested within it) are returned
+	ValidChildrenRequest struct {
+		EntityID uuid.UUID `schema:"EntityID"`
+		Tag      int       `schema:"Tag"`
+	}
+
+	// ValidSubtreeRequest is the model accepted by handlers that return an entity along with its descendants
+	ValidSubtreeRequest struct {
+		EntityID uuid.UUID `schema:"EntityID,required"`
+		Depth    int       `schema:"Depth"`
+	}
+
 	// ValidPathRequest is a special request model that is accepted by a handler that takes a path to an entity as an argument
 	ValidPathRequest struct {
 		Path string `schema:"Path,required"`
@@ -30,6 +43,56 @@ type (
 		EntityID uuid.UUID `schema:"EntityID,required"`
 		NewName  string    `schema:"NewName,required"`
 	}
+
+	// ValidMetadataRequest is the request model accepted by handlers that set an entity's metadata
+	ValidMetadataRequest struct {
+		EntityID uuid.UUID `schema:"EntityID,required"`
+		Key      string    `schema:"Key,required"`
+		Value    string    `schema:"Value"`
+	}
+
+	// ValidMetadataDeletionRequest is the request model accepted by handlers that delete an entity's metadata
+	ValidMetadataDeletionRequest struct {
+		EntityID uuid.UUID `schema:"EntityID,required"`
+		Key      string    `schema:"Key,required"`
+	}
+
+	// ValidTagCreationRequest is the request model accepted by handlers that create tags, tags without
+	// a parent are created at the top of the taxonomy
+	ValidTagCreationRequest struct {
+		Name   string `schema:"Name,required"`
+		Parent int    `schema:"Parent"`
+	}
+
+	// ValidTagRequest is the request model accepted by handlers that act on a single tag
+	ValidTagRequest struct {
+		TagID int `schema:"TagID,required"`
+	}
+
+	// ValidEntityTagRequest is the request model accepted by handlers that tag (or untag) entities
+	ValidEntityTagRequest struct {
+		EntityID uuid.UUID `schema:"EntityID,required"`
+		TagID    int       `schema:"TagID,required"`
+	}
+
+	// ValidDocumentTypeCreationRequest is the request model accepted by handlers that create document types,
+	// fields are provided as Fields.0.Name, Fields.0.Kind, Fields.0.Required, Fields.1.Name, ...
+	ValidDocumentTypeCreationRequest struct {
+		Name   string              `schema:"Name,required"`
+		Fields []DocumentTypeField `schema:"Fields"`
+	}
+
+	// ValidDocumentTypeRequest is the request model accepted by handlers that act on a single document type
+	ValidDocumentTypeRequest struct {
+		TypeID int `schema:"TypeID,required"`
+	}
+
+	// ValidDirectoryTypeRequest is the request model accepted by handlers that attach document types to directories,
+	// a TypeID of 0 detaches the directory's type
+	ValidDirectoryTypeRequest struct {
+		DirectoryID uuid.UUID `schema:"DirectoryID,required"`
+		TypeID      int       `schema:"TypeID"`
+	}
 )
 
 // Response models outline the general format a HTTP handler response follows
@@ -44,34 +107,155 @@ type (
 		Children []uuid.UUID
 	}
 
-	// EntityInfoResponse is the response model of any handler that returns information regarding an entity
+	// EntityInfoResponse is the response model of any handler that returns information regarding an entity,
+	// the metadata and tags of an entity's children are not included
 	EntityInfoResponse struct {
 		EntityID   uuid.UUID
 		EntityName string
 		IsDocument bool
 		Parent     uuid.UUID
 		Children   []EntityInfoResponse
+
+		Metadata map[string]string
+		Tags     []TagResponse
+	}
+
+	// TagResponse is the response model of a single tag within the taxonomy
+	TagResponse struct {
+		TagID  int
+		Name   string
+		Parent int
+	}
+
+	// TagsResponse is the response model for any handler that returns a collection of tags
+	TagsResponse struct {
+		Tags []TagResponse
+	}
+
+	// NewTagResponse is the response model for any handler that creates a tag
+	NewTagResponse struct {
+		NewID int
+	}
+
+	// DocumentTypeResponse is the response model of a single document type
+	DocumentTypeResponse struct {
+		TypeID int
+		Name   string
+		Fields []DocumentTypeField
+	}
+
+	// DocumentTypesResponse is the response model for any handler that returns a collection of document types
+	DocumentTypesResponse struct {
+		DocumentTypes []DocumentTypeResponse
+	}
+
+	// NewDocumentTypeResponse is the response model for any handler that creates a document type
+	NewDocumentTypeResponse struct {
+		NewID int
+	}
+
+	// BreadcrumbsResponse is the response model for any handler that returns the path from the root to an entity
+	BreadcrumbsResponse struct {
+		Breadcrumbs []Breadcrumb
+	}
+
+	// Breadcrumb is a single entity along the path to an entity
+	Breadcrumb struct {
+		EntityID   uuid.UUID
+		EntityName string
+		IsDocument bool
+	}
+
+	// PathResolutionResponse is the response model for any handler that resolves a path to an entity
+	PathResolutionResponse struct {
+		EntityID uuid.UUID
 	}
 )
 
-// FsEntryToEntityInfo just converts an instance of an FS entry to an instance of an entityInfo object
-// entityInfo objects are what is actually displayed to the end user
-func FsEntryToEntityInfo(entity repositories.FilesystemEntry, fsRepo repositories.FilesystemRepository, expandChildren bool) EntityInfoResponse {
-	children := []EntityInfoResponse{}
-	if expandChildren {
-		for _, childId := range entity.ChildrenIDs {
-			child, _ := fsRepo.GetEntryWithID(childId)
-			children = append(children, FsEntryToEntityInfo(child, fsRepo, false))
+// SubtreeToEntityInfo converts a subtree (as returned by FilesystemRepository.GetSubtree) to an instance of an entityInfo
+// object, entityInfo objects are what is actually displayed to the end user. Entities at the bottom of the subtree are
+// returned without their children
+func SubtreeToEntityInfo(subtree []repositories.FilesystemEntry) EntityInfoResponse {
+	entries := map[uuid.UUID]repositories.FilesystemEntry{}
+	for _, entry := range subtree {
+		entries[entry.EntityID] = entry
+	}
+
+	var toEntityInfo func(entity repositories.FilesystemEntry) EntityInfoResponse
+	toEntityInfo = func(entity repositories.FilesystemEntry) EntityInfoResponse {
+		children := []EntityInfoResponse{}
+		for _, childID := range entity.ChildrenIDs {
+			if child, ok := entries[childID]; ok {
+				children = append(children, toEntityInfo(child))
+			}
+		}
+
+		return EntityInfoResponse{
+			EntityID:   entity.EntityID,
+			EntityName: entity.LogicalName,
+			IsDocument: entity.IsDocument,
+			Parent:     entity.ParentFileID,
+			Children:   children,
 		}
 	}
 
-	return EntityInfoResponse{
-		EntityID:   entity.EntityID,
-		EntityName: entity.LogicalName,
-		IsDocument: entity.IsDocument,
-		Parent:     entity.ParentFileID,
-		Children:   children,
+	return toEntityInfo(subtree[0])
+}
+
+// EntriesToBreadcrumbs converts the entities along a path to the breadcrumbs displayed to the end user
+func EntriesToBreadcrumbs(entries []repositories.FilesystemEntry) BreadcrumbsResponse {
+	breadcrumbs := []Breadcrumb{}
+	for _, entry := range entries {
+		breadcrumbs = append(breadcrumbs, Breadcrumb{
+			EntityID:   entry.EntityID,
+			EntityName: entry.LogicalName,
+			IsDocument: entry.IsDocument,
+		})
 	}
+
+	return BreadcrumbsResponse{Breadcrumbs: breadcrumbs}
+}
+
+// TagsToResponse converts tags to the response model displayed to the end user
+func TagsToResponse(tags []repositories.Tag) []TagResponse {
+	response := []TagResponse{}
+	for _, tag := range tags {
+		response = append(response, TagResponse{TagID: tag.TagID, Name: tag.Name, Parent: tag.Parent})
+	}
+
+	return response
+}
+
+// DocumentTypeField is a single field of a document type, Kind is one of the repositories.FieldKind constants
+type DocumentTypeField struct {
+	Name     string `schema:"Name"`
+	Kind     string `schema:"Kind"`
+	Required bool   `schema:"Required"`
+}
+
+// DocumentTypesToResponse converts document types to the response model displayed to the end user
+func DocumentTypesToResponse(documentTypes []repositories.DocumentType) []DocumentTypeResponse {
+	response := []DocumentTypeResponse{}
+	for _, documentType := range documentTypes {
+		fields := []DocumentTypeField{}
+		for _, field := range documentType.Fields {
+			fields = append(fields, DocumentTypeField{Name: field.Name, Kind: string(field.Kind), Required: field.Required})
+		}
+
+		response = append(response, DocumentTypeResponse{TypeID: documentType.TypeID, Name: documentType.Name, Fields: fields})
+	}
+
+	return response
+}
+
+// CreationReqToDocumentType converts a document type creation request into a document type
+func CreationReqToDocumentType(form ValidDocumentTypeCreationRequest) repositories.DocumentType {
+	fields := []repositories.DocumentField{}
+	for _, field := range form.Fields {
+		fields = append(fields, repositories.DocumentField{Name: field.Name, Kind: repositories.FieldKind(field.Kind), Required: field.Required})
+	}
+
+	return repositories.DocumentType{Name: form.Name, Fields: fields}
 }
 
 // CreationReqToFsEntry converts a creation request into a proper filesystem entity
@@ -2,18 +2,88 @@ package models
 
 import (
 	"crypto/sha256"
+	"encoding/hex"
 	"regexp"
 
 	"cms.csesoc.unsw.edu.au/database/repositories"
+	"github.com/google/uuid"
 )
 
 type (
 	User struct {
 		Email    string `schema:"Email"`
 		Password string `schema:"Password"`
+		// FrontendID is the frontend being logged into, its policy determines if admins must use 2FA
+		FrontendID uuid.UUID `schema:"FrontendID"`
+	}
+
+	// ValidAccountEmailRequest is the request model for handlers that send an email to an account (password resets, verification)
+	ValidAccountEmailRequest struct {
+		Email string `schema:"Email,required"`
+	}
+
+	// ValidPasswordResetRequest is the request model for the handler that redeems a password reset token
+	ValidPasswordResetRequest struct {
+		Token       string `schema:"Token,required"`
+		NewPassword string `schema:"NewPassword,required"`
+	}
+
+	// LoginResponse is returned by the login handler when the user has to complete a second step to log in,
+	// either providing a 2FA code or enrolling in 2FA (as required by the frontend's policy)
+	LoginResponse struct {
+		TwoFactorRequired bool
+		EnrolmentRequired bool
+	}
+
+	// ValidTwoFactorLoginRequest is the request model for the second step of logging in, exactly one of the fields must be provided
+	ValidTwoFactorLoginRequest struct {
+		Code         string `schema:"Code"`
+		RecoveryCode string `schema:"RecoveryCode"`
+	}
+
+	// ValidTwoFactorCodeRequest is the request model for handlers that require the user to prove they hold their 2FA secret
+	ValidTwoFactorCodeRequest struct {
+		Code string `schema:"Code,required"`
+	}
+
+	// TwoFactorEnrolmentResponse is the response model for beginning 2FA enrolment, the provisioning URI should be rendered as a QR code
+	TwoFactorEnrolmentResponse struct {
+		Secret          string
+		ProvisioningURI string
+	}
+
+	// RecoveryCodesResponse contains the recovery codes issued when 2FA is enabled, this is the only time they are ever revealed
+	RecoveryCodesResponse struct {
+		RecoveryCodes []string
+	}
+
+	// CSRFTokenResponse is the response model for fetching a session's CSRF token
+	CSRFTokenResponse struct {
+		Token string
+	}
+
+	// ValidLoginAttemptsRequest is the request model for querying the login audit log
+	ValidLoginAttemptsRequest struct {
+		Email        string `schema:"Email"`
+		IPAddress    string `schema:"IPAddress"`
+		OnlyFailures bool   `schema:"OnlyFailures"`
+		Limit        int    `schema:"Limit"`
+	}
+
+	// LoginAttemptsResponse is the response model for the login audit log
+	LoginAttemptsResponse struct {
+		Attempts []repositories.LoginAttempt
+	}
+
+	// ValidEmailVerificationRequest is the request model for the handler that redeems an email verification token
+	ValidEmailVerificationRequest struct {
+		Token string `schema:"Token,required"`
 	}
 )
 
+// minimumPasswordLength is the shortest password we will accept when a user resets their password
+const minimumPasswordLength = 8
+
 // ValidEmail checks to see if the username is valid
 //  - email must be > 2 characters before the domain
 //  - white lists a few domains which are allowed
@@ -40,8 +110,18 @@ func (u *User) UserExists(personRepo repositories.PersonRepository) bool {
 	})
 }
 
-// HashPassword hashes a user's password
+// HashPassword hashes a user's password, the hex encoding matches the hashing done by create_normal_user
 func (u *User) HashPassword() string {
 	hashedBytes := sha256.Sum256([]byte(u.Password))
-	return string(hashedBytes[:])
+	return hex.EncodeToString(hashedBytes[:])
+}
+
+// IsValidPassword checks that a new password satisfies our (rather minimal) password requirements
+func (r *ValidPasswordResetRequest) IsValidPassword() bool {
+	return len(r.NewPassword) >= minimumPasswordLength
+}
+
+// HashPassword hashes the new password in the reset request
+func (r *ValidPasswordResetRequest) HashPassword() string {
+	return (&User{Password: r.NewPassword}).HashPassword()
 }
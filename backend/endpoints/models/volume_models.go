@@ -33,4 +33,13 @@ type (
 	ValidGetPublishedDocumentRequest struct {
 		DocumentID uuid.UUID `schema:"DocumentID,required"`
 	}
+
+	// ValidDocumentDiffRequest is the request model for comparing the published and unpublished versions of a
+	// document, Format is either unified (the default) or html and Context is the number of unchanged lines shown
+	// around each change (3 if not provided)
+	ValidDocumentDiffRequest struct {
+		DocumentID uuid.UUID `schema:"DocumentID,required"`
+		Format     string    `schema:"Format"`
+		Context    *int      `schema:"Context"`
+	}
 )
@@ -9,21 +9,52 @@ func RegisterFilesystemEndpoints(mux *http.ServeMux) {
 	mux.Handle("/api/filesystem/delete", newHandler("POST", DeleteFilesystemEntity, false)) // auth
 	mux.Handle("/api/filesystem/rename", newHandler("POST", RenameFilesystemEntity, false)) // auth
 	mux.Handle("/api/filesystem/children", newHandler("GET", GetChildren, false))
+	mux.Handle("/api/filesystem/subtree", newHandler("GET", GetEntitySubtree, false))
+	mux.Handle("/api/filesystem/breadcrumbs", newHandler("GET", GetBreadcrumbs, false))
+	mux.Handle("/api/filesystem/resolve-path", newHandler("GET", GetIDWithPath, false))
 	mux.Handle("/api/filesystem/upload-image", newHandler("POST", UploadImage, true))           // auth
 	mux.Handle("/api/filesystem/upload-document", newHandler("POST", UploadDocument, false))    // auth
 	mux.Handle("/api/filesystem/publish-document", newHandler("POST", PublishDocument, false))  // auth
 	mux.Handle("/api/filesystem/get/published", newHandler("GET", GetPublishedDocument, false)) // auth
+	mux.Handle("/api/filesystem/diff", newHandler("GET", DiffDocument, false))                  // auth
+
+	mux.Handle("/api/filesystem/metadata/set", newRawHandler("POST", SetMetadata, false, true, false))       // auth
+	mux.Handle("/api/filesystem/metadata/delete", newRawHandler("POST", DeleteMetadata, false, true, false)) // auth
+	mux.Handle("/api/filesystem/tags", newHandler("GET", GetTags, false))
+	mux.Handle("/api/filesystem/tags/create", newRawHandler("POST", CreateTag, false, true, false))   // auth
+	mux.Handle("/api/filesystem/tags/delete", newRawHandler("POST", DeleteTag, false, true, false))   // auth
+	mux.Handle("/api/filesystem/tags/add", newRawHandler("POST", TagEntity, false, true, false))      // auth
+	mux.Handle("/api/filesystem/tags/remove", newRawHandler("POST", UntagEntity, false, true, false)) // auth
+
+	mux.Handle("/api/filesystem/document-types", newHandler("GET", GetDocumentTypes, false))
+	mux.Handle("/api/filesystem/document-types/create", newRawHandler("POST", CreateDocumentType, false, true, false)) // auth
+	mux.Handle("/api/filesystem/document-types/delete", newRawHandler("POST", DeleteDocumentType, false, true, false)) // auth
+	mux.Handle("/api/filesystem/document-types/attach", newRawHandler("POST", SetDirectoryType, false, true, false))   // auth
 }
 
 // Registers the authentication based endpoints
 func RegisterAuthenticationEndpoints(mux *http.ServeMux) {
+	mux.Handle("/api/auth/csrf", newRawHandler("GET", GetCSRFToken, false, false, false))
 	mux.Handle("/login", newRawHandler("POST", LoginHandler, false, false, false))
 	mux.Handle("/logout", newRawHandler("POST", LogoutHandler, false, false, false)) // auth
+	mux.Handle("/login/2fa", newRawHandler("POST", VerifyTwoFactorLogin, false, false, false))
+
+	mux.Handle("/api/auth/2fa/enrol", newRawHandler("POST", BeginTwoFactorEnrolment, false, false, false))     // auth
+	mux.Handle("/api/auth/2fa/confirm", newRawHandler("POST", ConfirmTwoFactorEnrolment, false, false, false)) // auth
+	mux.Handle("/api/auth/2fa/disable", newRawHandler("POST", DisableTwoFactor, false, true, false))           // auth
+
+	mux.Handle("/api/auth/forgot-password", newHandler("POST", RequestPasswordReset, false))
+	mux.Handle("/api/auth/reset-password", newHandler("POST", ResetPassword, false))
+	mux.Handle("/api/auth/request-verification", newHandler("POST", RequestEmailVerification, false))
+	mux.Handle("/api/auth/verify-email", newHandler("POST", VerifyEmail, false))
+
+	mux.Handle("/api/admin/login-attempts", newRawHandler("GET", GetLoginAttempts, false, true, false)) // auth
 }
 
 // Registers the editor related endpoints
 func RegisterEditorEndpoints(mux *http.ServeMux) {
-	mux.Handle("/editor", newRawHandler("GET", EditHandler, false, false, true)) // auth
+	mux.Handle("/editor", newRawHandler("GET", EditHandler, false, true, true)) // auth
+	mux.Handle("/api/schema", newHandler("GET", GetSchema, false))
 }
 
 // newHandler is just a small wrapper around a handler that returns an instance of a handler struct
@@ -50,6 +81,7 @@ func newRawHandler[T, V any](formType string, handler func(form T, w http.Respon
 		FormType:    formType,
 		Handler:     handler,
 		IsMultipart: isMultipart,
+		NeedsAuth:   needsAuth,
 		IsWebsocket: isWebsocket,
 	}
 }
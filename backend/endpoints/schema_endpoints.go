@@ -0,0 +1,63 @@
+package endpoints
+
+import (
+	"fmt"
+	"net/http"
+
+	"cms.csesoc.unsw.edu.au/editor/OT/operations"
+	. "cms.csesoc.unsw.edu.au/endpoints/models"
+	"github.com/google/uuid"
+)
+
+// schemaContentTypes maps the formats the schema can be generated in to the content type it is served with
+var schemaContentTypes = map[string]string{
+	"json":       "application/schema+json",
+	"typescript": "application/typescript",
+}
+
+// GetSchema is the handler for fetching a description of the documents (and editor operations) a frontend works
+// with, the description is generated from the frontend's cmsjson configuration so it includes the components the
+// frontend has declared alongside the built in ones
+func GetSchema(form ValidSchemaRequest, df DependencyFactory) handlerResponse[[]byte] {
+	format := form.Format
+	if format == "" {
+		format = "json"
+	}
+
+	contentType, ok := schemaContentTypes[format]
+	if !ok {
+		return handlerResponse[[]byte]{
+			Status: http.StatusBadRequest,
+			Errors: []string{fmt.Sprintf("unknown format %q, expected json or typescript", form.Format)},
+		}
+	}
+
+	registry, err := df.GetComponentRegistry()
+	if err != nil {
+		df.GetLogger().Write(fmt.Sprintf("failed to load the component registry: %v", err))
+		return handlerResponse[[]byte]{Status: http.StatusInternalServerError}
+	}
+
+	schema, err := GenerateSchema(registry, form.FrontendID, format)
+	if err != nil {
+		df.GetLogger().Write(fmt.Sprintf("failed to generate the schema of %s: %v", form.FrontendID, err))
+		return handlerResponse[[]byte]{Status: http.StatusInternalServerError}
+	}
+
+	return handlerResponse[[]byte]{
+		Status:      http.StatusOK,
+		Response:    schema,
+		ContentType: contentType,
+	}
+}
+
+// GenerateSchema generates the schema of a frontend's documents in the given format (json or typescript)
+func GenerateSchema(registry *operations.Registry, frontendID uuid.UUID, format string) ([]byte, error) {
+	configuration := registry.ConfigurationFor(frontendID)
+	if format == "typescript" {
+		declarations, err := configuration.TypeScript(operations.SchemaRoots...)
+		return []byte(declarations), err
+	}
+
+	return configuration.JSONSchema(operations.SchemaRoots...)
+}
@@ -0,0 +1,179 @@
+package tests
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"cms.csesoc.unsw.edu.au/database/repositories"
+	repMocks "cms.csesoc.unsw.edu.au/database/repositories/mocks"
+	"cms.csesoc.unsw.edu.au/endpoints"
+	mock_endpoints "cms.csesoc.unsw.edu.au/endpoints/mocks"
+	"cms.csesoc.unsw.edu.au/endpoints/models"
+	"cms.csesoc.unsw.edu.au/internal/logger"
+	"cms.csesoc.unsw.edu.au/internal/mailer"
+	mock_mailer "cms.csesoc.unsw.edu.au/internal/mailer/mocks"
+	"cms.csesoc.unsw.edu.au/internal/tokens"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPasswordResetEmailsToken(t *testing.T) {
+	controller := gomock.NewController(t)
+	assert := assert.New(t)
+	defer controller.Finish()
+
+	// ==== test setup =====
+	person := repositories.Person{UID: 1, Email: TEST_EMAIL, FirstName: "Thomas", Verified: true}
+	mockPersonRepo := repMocks.NewMockPersonRepository(controller)
+	mockPersonRepo.EXPECT().GetPersonWithEmail(TEST_EMAIL).Return(person, nil).Times(1)
+
+	mockTokensRepo := repMocks.NewMockTokensRepository(controller)
+	mockTokensRepo.EXPECT().CreateToken(gomock.Any()).DoAndReturn(func(token repositories.AuthToken) error {
+		assert.Equal(person.UID, token.UID)
+		assert.Equal(string(tokens.PasswordReset), token.Purpose)
+		return nil
+	}).Times(1)
+
+	var sentMail mailer.Message
+	mockMailer := mock_mailer.NewMockMailer(controller)
+	mockMailer.EXPECT().Send(gomock.Any()).DoAndReturn(func(message mailer.Message) error {
+		sentMail = message
+		return nil
+	}).Times(1)
+
+	mockDepFactory := createMockAccountDependencyFactory(controller, mockPersonRepo, mockTokensRepo)
+	mockDepFactory.EXPECT().GetMailer().Return(mockMailer).AnyTimes()
+
+	// ==== test execution =====
+	response := endpoints.RequestPasswordReset(models.ValidAccountEmailRequest{Email: TEST_EMAIL}, mockDepFactory)
+	assert.Equal(http.StatusOK, response.Status)
+	assert.Equal(TEST_EMAIL, sentMail.To)
+
+	// the emailed token should be redeemable for a password reset
+	claims, err := tokens.Verify(extractToken(t, sentMail.Body), tokens.PasswordReset)
+	assert.Nil(err)
+	assert.Equal(person.UID, claims.UID)
+}
+
+func TestPasswordResetForUnknownAccount(t *testing.T) {
+	controller := gomock.NewController(t)
+	assert := assert.New(t)
+	defer controller.Finish()
+
+	// ==== test setup =====
+	mockPersonRepo := repMocks.NewMockPersonRepository(controller)
+	mockPersonRepo.EXPECT().GetPersonWithEmail(TEST_EMAIL).Return(repositories.Person{}, errors.New("no rows")).Times(1)
+	mockTokensRepo := repMocks.NewMockTokensRepository(controller)
+
+	// no mailer is provided as nothing should be sent
+	mockDepFactory := createMockAccountDependencyFactory(controller, mockPersonRepo, mockTokensRepo)
+
+	// ==== test execution =====
+	response := endpoints.RequestPasswordReset(models.ValidAccountEmailRequest{Email: TEST_EMAIL}, mockDepFactory)
+	assert.Equal(http.StatusOK, response.Status)
+}
+
+func TestValidPasswordReset(t *testing.T) {
+	controller := gomock.NewController(t)
+	assert := assert.New(t)
+	defer controller.Finish()
+
+	// ==== test setup =====
+	claims := tokens.NewClaims(1, tokens.PasswordReset, time.Hour)
+	form := models.ValidPasswordResetRequest{Token: tokens.Sign(claims), NewPassword: TEST_PASSWORD}
+
+	mockPersonRepo := repMocks.NewMockPersonRepository(controller)
+	mockPersonRepo.EXPECT().SetPassword(1, form.HashPassword()).Return(nil).Times(1)
+
+	mockTokensRepo := repMocks.NewMockTokensRepository(controller)
+	mockTokensRepo.EXPECT().RedeemToken(claims.TokenID, string(tokens.PasswordReset)).Return(repositories.AuthToken{}, nil).Times(1)
+	mockTokensRepo.EXPECT().InvalidateTokensForPerson(1, string(tokens.PasswordReset)).Return(nil).Times(1)
+
+	mockDepFactory := createMockAccountDependencyFactory(controller, mockPersonRepo, mockTokensRepo)
+
+	// ==== test execution =====
+	response := endpoints.ResetPassword(form, mockDepFactory)
+	assert.Equal(http.StatusOK, response.Status)
+}
+
+func TestInvalidPasswordReset(t *testing.T) {
+	controller := gomock.NewController(t)
+	assert := assert.New(t)
+	defer controller.Finish()
+
+	// ==== test setup =====
+	claims := tokens.NewClaims(1, tokens.PasswordReset, time.Hour)
+	mockPersonRepo := repMocks.NewMockPersonRepository(controller)
+	mockTokensRepo := repMocks.NewMockTokensRepository(controller)
+	// the token has already been used
+	mockTokensRepo.EXPECT().RedeemToken(claims.TokenID, string(tokens.PasswordReset)).Return(repositories.AuthToken{}, errors.New("no rows")).Times(1)
+
+	mockDepFactory := createMockAccountDependencyFactory(controller, mockPersonRepo, mockTokensRepo)
+
+	// ==== test execution =====
+	// CASE: weak password
+	response := endpoints.ResetPassword(models.ValidPasswordResetRequest{Token: tokens.Sign(claims), NewPassword: "short"}, mockDepFactory)
+	assert.Equal(http.StatusBadRequest, response.Status)
+
+	// CASE: token issued for a different purpose
+	verificationToken := tokens.Sign(tokens.NewClaims(1, tokens.EmailVerification, time.Hour))
+	response = endpoints.ResetPassword(models.ValidPasswordResetRequest{Token: verificationToken, NewPassword: TEST_PASSWORD}, mockDepFactory)
+	assert.Equal(http.StatusUnauthorized, response.Status)
+
+	// CASE: tampered token
+	response = endpoints.ResetPassword(models.ValidPasswordResetRequest{Token: tokens.Sign(claims) + "a", NewPassword: TEST_PASSWORD}, mockDepFactory)
+	assert.Equal(http.StatusUnauthorized, response.Status)
+
+	// CASE: reused token
+	response = endpoints.ResetPassword(models.ValidPasswordResetRequest{Token: tokens.Sign(claims), NewPassword: TEST_PASSWORD}, mockDepFactory)
+	assert.Equal(http.StatusUnauthorized, response.Status)
+}
+
+func TestValidEmailVerification(t *testing.T) {
+	controller := gomock.NewController(t)
+	assert := assert.New(t)
+	defer controller.Finish()
+
+	// ==== test setup =====
+	claims := tokens.NewClaims(1, tokens.EmailVerification, time.Hour)
+	mockPersonRepo := repMocks.NewMockPersonRepository(controller)
+	mockPersonRepo.EXPECT().MarkAsVerified(1).Return(nil).Times(1)
+
+	mockTokensRepo := repMocks.NewMockTokensRepository(controller)
+	mockTokensRepo.EXPECT().RedeemToken(claims.TokenID, string(tokens.EmailVerification)).Return(repositories.AuthToken{}, nil).Times(1)
+
+	mockDepFactory := createMockAccountDependencyFactory(controller, mockPersonRepo, mockTokensRepo)
+
+	// ==== test execution =====
+	response := endpoints.VerifyEmail(models.ValidEmailVerificationRequest{Token: tokens.Sign(claims)}, mockDepFactory)
+	assert.Equal(http.StatusOK, response.Status)
+}
+
+func createMockAccountDependencyFactory(controller *gomock.Controller, personRepo repositories.PersonRepository, tokensRepo repositories.TokensRepository) *mock_endpoints.MockDependencyFactory {
+	mockDepFactory := mock_endpoints.NewMockDependencyFactory(controller)
+	mockDepFactory.EXPECT().GetPersonsRepo().Return(personRepo).AnyTimes()
+	mockDepFactory.EXPECT().GetTokensRepo().Return(tokensRepo).AnyTimes()
+	mockDepFactory.EXPECT().GetLogger().Return(logger.OpenLog("account log")).AnyTimes()
+
+	return mockDepFactory
+}
+
+// extractToken pulls the token out of the link within an email body
+func extractToken(t *testing.T, body string) string {
+	start := strings.Index(body, "?token=")
+	if start == -1 {
+		t.Fatalf("no token in email body: %s", body)
+	}
+
+	encoded := strings.Fields(body[start+len("?token="):])[0]
+	token, err := url.QueryUnescape(encoded)
+	if err != nil {
+		t.Fatalf("malformed token in email body: %v", err)
+	}
+
+	return token
+}
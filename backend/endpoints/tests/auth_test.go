@@ -10,6 +10,8 @@ import (
 
 	"cms.csesoc.unsw.edu.au/endpoints"
 	"cms.csesoc.unsw.edu.au/endpoints/models"
+	"cms.csesoc.unsw.edu.au/internal/logger"
+	"cms.csesoc.unsw.edu.au/internal/throttle"
 
 	"cms.csesoc.unsw.edu.au/database/repositories"
 	. "cms.csesoc.unsw.edu.au/database/repositories/mocks"
@@ -51,9 +53,9 @@ func TestLogout(t *testing.T) {
 	mockDependencyFactory := setUpMockRepositories(controller)
 
 	// First, we login.
-	loginResponse := endpoints.LoginHandler(form, loginResponseRecorder, loginRequest, mockDependencyFactory)
-	// A bit of a hack. Since empty is unexported, it is difficult to get a value of type empty to pass to LogoutHandler.
-	empty := loginResponse.Response
+	endpoints.LoginHandler(form, loginResponseRecorder, loginRequest, mockDependencyFactory)
+	// Since empty is unexported, we have to let the compiler infer the form type of LogoutHandler.
+	empty := zeroFormOf(endpoints.LogoutHandler)
 
 	logoutResponseRecorder := httptest.NewRecorder()
 	logoutRequest := httptest.NewRequest("POST", "/logout", nil)
@@ -77,17 +79,46 @@ func TestLogout(t *testing.T) {
 func setUpMockRepositories(controller *gomock.Controller) endpoints.DependencyFactory {
 	form := newTestUser()
 	// LoginHandler queries the person repository of the dependency factory it is given for the user details.
-	mockPersonRepository := NewMockIPersonRepository(controller)
+	mockPersonRepository := NewMockPersonRepository(controller)
 	// Fake a repository entry.
 	person := repositories.Person {Email: form.Email, Password: form.HashPassword()}
 	mockPersonRepository.EXPECT().PersonExists(person).Return(true)
+	// Only verified users can log in.
+	mockPersonRepository.EXPECT().GetPersonWithEmail(form.Email).Return(repositories.Person{Email: form.Email, Verified: true}, nil)
 	mockDependencyFactory := NewMockDependencyFactory(controller)
 	mockDependencyFactory.EXPECT().GetPersonsRepo().Return(mockPersonRepository)
+	addLoginThrottling(controller, mockDependencyFactory)
+
+	// The user hasn't enrolled in 2FA and isn't an admin, so logging in is a single step.
+	mockTwoFactorRepository := NewMockTwoFactorRepository(controller)
+	mockTwoFactorRepository.EXPECT().GetTwoFactor(gomock.Any()).Return(repositories.TwoFactor{}, nil).AnyTimes()
+	mockGroupsRepository := NewMockGroupsRepository(controller)
+	mockGroupsRepository.EXPECT().IsMemberOf(gomock.Any(), repositories.GROUPS_ADMIN).Return(false, nil).AnyTimes()
+	mockDependencyFactory.EXPECT().GetTwoFactorRepo().Return(mockTwoFactorRepository).AnyTimes()
+	mockDependencyFactory.EXPECT().GetGroupsRepo().Return(mockGroupsRepository).AnyTimes()
 
 	return mockDependencyFactory
 }
 
+// Attach a fresh login throttler and an audit log to a mock dependency factory.
+func addLoginThrottling(controller *gomock.Controller, mockDependencyFactory *MockDependencyFactory) *MockLoginAttemptsRepository {
+	mockLoginAttemptsRepository := NewMockLoginAttemptsRepository(controller)
+	mockLoginAttemptsRepository.EXPECT().RecordAttempt(gomock.Any()).Return(nil).AnyTimes()
+
+	mockDependencyFactory.EXPECT().GetLoginThrottler().Return(endpoints.NewLoginThrottler(throttle.NewMemoryStore())).AnyTimes()
+	mockDependencyFactory.EXPECT().GetLoginAttemptsRepo().Return(mockLoginAttemptsRepository).AnyTimes()
+	mockDependencyFactory.EXPECT().GetLogger().Return(logger.OpenLog("login log")).AnyTimes()
+
+	return mockLoginAttemptsRepository
+}
+
 // Create a [User] with the details in [TEST_EMAIL] and [TEST_PASSWORD].
 func newTestUser() models.User {
 	return models.User {Email: TEST_EMAIL, Password: TEST_PASSWORD}
 }
+
+// Get the zero value of a raw handler's form type, useful when the type is unexported.
+func zeroFormOf[T, V any](handler func(T, http.ResponseWriter, *http.Request, endpoints.DependencyFactory) V) T {
+	var form T
+	return form
+}
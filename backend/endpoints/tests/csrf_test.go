@@ -0,0 +1,142 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+
+	"cms.csesoc.unsw.edu.au/endpoints"
+	"cms.csesoc.unsw.edu.au/endpoints/models"
+	"cms.csesoc.unsw.edu.au/internal/session"
+)
+
+// Test that cookie authenticated POSTs must present the session's CSRF token.
+func TestCSRFProtection(t *testing.T) {
+	assert := assert.New(t)
+	mux := http.NewServeMux()
+	endpoints.RegisterAuthenticationEndpoints(mux)
+
+	// Fetch a token, this also issues the session cookie.
+	tokenRecorder := httptest.NewRecorder()
+	mux.ServeHTTP(tokenRecorder, httptest.NewRequest("GET", "/api/auth/csrf", nil))
+	assert.Equal(http.StatusOK, tokenRecorder.Code)
+
+	var tokenResponse endpoints.APIResponse[models.CSRFTokenResponse]
+	assert.Nil(json.Unmarshal(tokenRecorder.Body.Bytes(), &tokenResponse))
+	token := tokenResponse.Response.Token
+	assert.NotEmpty(token)
+
+	logout := func(csrfToken string, authorization string) int {
+		request := httptest.NewRequest("POST", "/logout", nil)
+		for _, cookie := range tokenRecorder.Result().Cookies() {
+			request.AddCookie(cookie)
+		}
+
+		if csrfToken != "" {
+			request.Header.Set(endpoints.CSRFHeader, csrfToken)
+		}
+		if authorization != "" {
+			request.Header.Set("Authorization", authorization)
+		}
+
+		recorder := httptest.NewRecorder()
+		mux.ServeHTTP(recorder, request)
+		return getResponseStatus(t, recorder)
+	}
+
+	// CASE: missing token
+	assert.Equal(http.StatusForbidden, logout("", ""))
+	// CASE: wrong token
+	assert.Equal(http.StatusForbidden, logout(token+"a", ""))
+	// CASE: requests presenting a bearer token are exempt as their cookie is ignored
+	assert.Equal(http.StatusOK, logout("", "Bearer abc"))
+	// CASE: correct token
+	assert.Equal(http.StatusOK, logout(token, ""))
+
+	// CASE: requests without a session cookie are never implicitly authenticated
+	recorder := httptest.NewRecorder()
+	mux.ServeHTTP(recorder, httptest.NewRequest("POST", "/logout", nil))
+	assert.Equal(http.StatusOK, getResponseStatus(t, recorder))
+}
+
+// Test that attaching a bearer token to a request doesn't let it skip the CSRF check while the session cookie still
+// authenticates it.
+func TestBearerRequestsIgnoreTheSessionCookie(t *testing.T) {
+	assert := assert.New(t)
+	mux := http.NewServeMux()
+	endpoints.RegisterAuthenticationEndpoints(mux)
+
+	loginRecorder := httptest.NewRecorder()
+	session.CreateSession(loginRecorder, httptest.NewRequest("POST", "/login", nil), TEST_EMAIL, uuid.Nil)
+
+	disable := func(authorization string) int {
+		request := httptest.NewRequest("POST", "/api/auth/2fa/disable?Code=000000", nil)
+		for _, cookie := range loginRecorder.Result().Cookies() {
+			request.AddCookie(cookie)
+		}
+
+		if authorization != "" {
+			request.Header.Set("Authorization", authorization)
+		}
+
+		recorder := httptest.NewRecorder()
+		mux.ServeHTTP(recorder, request)
+		return getResponseStatus(t, recorder)
+	}
+
+	// CASE: the cookie authenticates the request but it has no CSRF token
+	assert.Equal(http.StatusForbidden, disable(""))
+	// CASE: the junk token isn't authenticated and the cookie is ignored
+	assert.Equal(http.StatusUnauthorized, disable("Bearer junk"))
+}
+
+// Test that logging in issues the session a new CSRF token.
+func TestLoggingInRotatesTheCSRFToken(t *testing.T) {
+	assert := assert.New(t)
+	mux := http.NewServeMux()
+	endpoints.RegisterAuthenticationEndpoints(mux)
+
+	fetchToken := func(cookies []*http.Cookie) (string, []*http.Cookie) {
+		request := httptest.NewRequest("GET", "/api/auth/csrf", nil)
+		for _, cookie := range cookies {
+			request.AddCookie(cookie)
+		}
+
+		recorder := httptest.NewRecorder()
+		mux.ServeHTTP(recorder, request)
+
+		var response endpoints.APIResponse[models.CSRFTokenResponse]
+		assert.Nil(json.Unmarshal(recorder.Body.Bytes(), &response))
+		return response.Response.Token, recorder.Result().Cookies()
+	}
+
+	before, cookies := fetchToken(nil)
+	assert.NotEmpty(before)
+
+	// log in within the session the token was issued to
+	loginRequest := httptest.NewRequest("POST", "/login", nil)
+	for _, cookie := range cookies {
+		loginRequest.AddCookie(cookie)
+	}
+
+	loginRecorder := httptest.NewRecorder()
+	session.CreateSession(loginRecorder, loginRequest, TEST_EMAIL, uuid.Nil)
+
+	after, _ := fetchToken(loginRecorder.Result().Cookies())
+	assert.NotEmpty(after)
+	assert.NotEqual(before, after)
+}
+
+// Errors are reported within the body of the response, so fetch the status from there.
+func getResponseStatus(t *testing.T, recorder *httptest.ResponseRecorder) int {
+	var response endpoints.APIResponse[any]
+	if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil {
+		t.Fatalf("malformed response: %v", err)
+	}
+
+	return response.Status
+}
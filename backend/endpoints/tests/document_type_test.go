@@ -0,0 +1,152 @@
+package tests
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"cms.csesoc.unsw.edu.au/database/repositories"
+	repMocks "cms.csesoc.unsw.edu.au/database/repositories/mocks"
+	"cms.csesoc.unsw.edu.au/endpoints"
+	"cms.csesoc.unsw.edu.au/endpoints/models"
+	"cms.csesoc.unsw.edu.au/internal/logger"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// the document type used by the tests below
+var blogPostType = repositories.DocumentType{
+	TypeID: 1,
+	Name:   "blog post",
+	Fields: []repositories.DocumentField{
+		{Name: "title", Kind: repositories.FieldString, Required: true},
+		{Name: "date", Kind: repositories.FieldDate, Required: true},
+	},
+}
+
+func TestCreateDocumentType(t *testing.T) {
+	controller := gomock.NewController(t)
+	assert := assert.New(t)
+	defer controller.Finish()
+
+	// ==== test setup =====
+	mockDocumentTypesRepo := repMocks.NewMockDocumentTypesRepository(controller)
+	mockDocumentTypesRepo.EXPECT().CreateDocumentType(repositories.DocumentType{
+		Name:   "blog post",
+		Fields: blogPostType.Fields,
+	}).Return(blogPostType, nil).Times(1)
+
+	mockDepFactory := createMockDependencyFactory(controller, nil, true)
+	mockDepFactory.EXPECT().GetDocumentTypesRepo().Return(mockDocumentTypesRepo)
+	expectAdmin(controller, mockDepFactory, true)
+
+	// ==== test execution =====
+	request := newAuthenticatedRequest("POST", "/api/filesystem/document-types/create")
+	response := endpoints.CreateDocumentType(models.ValidDocumentTypeCreationRequest{
+		Name: "blog post",
+		Fields: []models.DocumentTypeField{
+			{Name: "title", Kind: "string", Required: true},
+			{Name: "date", Kind: "date", Required: true},
+		},
+	}, httptest.NewRecorder(), request, mockDepFactory)
+	assert.Equal(http.StatusOK, response.Status)
+	assert.Equal(models.NewDocumentTypeResponse{NewID: 1}, response.Response)
+
+	// malformed definitions never reach the repository
+	response = endpoints.CreateDocumentType(models.ValidDocumentTypeCreationRequest{
+		Name:   "event",
+		Fields: []models.DocumentTypeField{{Name: "start", Kind: "timestamp"}},
+	}, httptest.NewRecorder(), request, mockDepFactory)
+	assert.Equal(http.StatusUnprocessableEntity, response.Status)
+	assert.Equal([]string{`/Fields/0/Kind: unknown kind "timestamp"`}, response.Errors)
+}
+
+func TestOnlyAdminsCanManageDocumentTypes(t *testing.T) {
+	controller := gomock.NewController(t)
+	assert := assert.New(t)
+	defer controller.Finish()
+
+	// ==== test setup =====
+	mockDepFactory := createMockDependencyFactory(controller, nil, false)
+	expectAdmin(controller, mockDepFactory, false)
+
+	// ==== test execution =====
+	request := newAuthenticatedRequest("POST", "/api/filesystem/document-types/create")
+	response := endpoints.CreateDocumentType(models.ValidDocumentTypeCreationRequest{Name: "blog post"}, httptest.NewRecorder(), request, mockDepFactory)
+	assert.Equal(http.StatusForbidden, response.Status)
+	assert.Equal(http.StatusForbidden, endpoints.DeleteDocumentType(models.ValidDocumentTypeRequest{TypeID: 1}, httptest.NewRecorder(), request, mockDepFactory).Status)
+}
+
+func TestSetDirectoryTypeRequiresOwnership(t *testing.T) {
+	controller := gomock.NewController(t)
+	assert := assert.New(t)
+	defer controller.Finish()
+
+	// ==== test setup =====
+	ownedDirectory, writableDirectory := uuid.New(), uuid.New()
+	mockDocumentTypesRepo := repMocks.NewMockDocumentTypesRepository(controller)
+	mockDocumentTypesRepo.EXPECT().SetDirectoryType(ownedDirectory, blogPostType.TypeID).Return(nil).Times(1)
+
+	mockDepFactory := createMockDependencyFactory(controller, nil, false)
+	mockDepFactory.EXPECT().GetLogger().Return(logger.OpenLog("attach log")).AnyTimes()
+	mockDepFactory.EXPECT().GetDocumentTypesRepo().Return(mockDocumentTypesRepo).Times(1)
+	mockPermissionsRepo := expectPermission(controller, mockDepFactory, ownedDirectory, repositories.PermissionDelete)
+	mockPermissionsRepo.EXPECT().GetPermission(1, writableDirectory).Return(repositories.PermissionWrite, nil)
+
+	// ==== test execution =====
+	request := newAuthenticatedRequest("POST", "/api/filesystem/document-types/attach")
+	response := endpoints.SetDirectoryType(models.ValidDirectoryTypeRequest{DirectoryID: ownedDirectory, TypeID: blogPostType.TypeID}, httptest.NewRecorder(), request, mockDepFactory)
+	assert.Equal(http.StatusOK, response.Status)
+
+	// being able to write to the directory isn't enough
+	response = endpoints.SetDirectoryType(models.ValidDirectoryTypeRequest{DirectoryID: writableDirectory, TypeID: repositories.NoDocumentType}, httptest.NewRecorder(), request, mockDepFactory)
+	assert.Equal(http.StatusForbidden, response.Status)
+}
+
+func TestPublishDocumentValidatesType(t *testing.T) {
+	controller := gomock.NewController(t)
+	assert := assert.New(t)
+	defer controller.Finish()
+
+	// ==== test setup =====
+	documentID := uuid.New()
+	directoryID := uuid.New()
+	documentPath := filepath.Join(t.TempDir(), documentID.String())
+
+	mockFileRepo := repMocks.NewMockFilesystemRepository(controller)
+	mockFileRepo.EXPECT().GetEntryWithID(documentID).Return(repositories.FilesystemEntry{
+		EntityID: documentID, IsDocument: true, ParentFileID: directoryID,
+	}, nil).Times(2)
+
+	mockUnpublishedVolume := repMocks.NewMockUnpublishedVolumeRepository(controller)
+	mockUnpublishedVolume.EXPECT().GetFromVolume(documentID.String()).DoAndReturn(func(string) (*os.File, error) {
+		// like the docker volume the file is opened for writing (upgrades are saved when publishing)
+		return os.OpenFile(documentPath, os.O_RDWR, 0)
+	}).Times(2)
+
+	mockPublishedVolume := repMocks.NewMockPublishedVolumeRepository(controller)
+	mockPublishedVolume.EXPECT().CopyToVolume(gomock.Any(), documentID.String()).Return(nil).Times(1)
+
+	mockDocumentTypesRepo := repMocks.NewMockDocumentTypesRepository(controller)
+	mockDocumentTypesRepo.EXPECT().GetDirectoryType(directoryID).Return(blogPostType, nil).Times(2)
+
+	depFactory := createMockDependencyFactory(controller, nil, false)
+	depFactory.EXPECT().GetLogger().Return(logger.OpenLog("publish log")).AnyTimes()
+	depFactory.EXPECT().GetFilesystemRepo().Return(mockFileRepo, nil).Times(2)
+	depFactory.EXPECT().GetUnpublishedVolumeRepo().Return(mockUnpublishedVolume).Times(2)
+	depFactory.EXPECT().GetPublishedVolumeRepo().Return(mockPublishedVolume).Times(2)
+	depFactory.EXPECT().GetDocumentTypesRepo().Return(mockDocumentTypesRepo).Times(2)
+
+	// ==== test execution =====
+	os.WriteFile(documentPath, []byte(`{"title": "Hello"}`), 0o644)
+	response := endpoints.PublishDocument(models.ValidPublishDocumentRequest{DocumentID: documentID}, depFactory)
+	assert.Equal(http.StatusUnprocessableEntity, response.Status)
+	assert.Equal([]string{"/date: required field is missing"}, response.Errors)
+
+	os.WriteFile(documentPath, []byte(`{"title": "Hello", "date": "2022-07-01"}`), 0o644)
+	response = endpoints.PublishDocument(models.ValidPublishDocumentRequest{DocumentID: documentID}, depFactory)
+	assert.Equal(http.StatusOK, response.Status)
+}
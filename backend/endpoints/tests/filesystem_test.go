@@ -1,7 +1,10 @@
 package tests
 
 import (
+	"errors"
 	"net/http"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"cms.csesoc.unsw.edu.au/database/repositories"
@@ -23,15 +26,26 @@ func TestValidEntityInfo(t *testing.T) {
 	// ==== test setup =====
 	entityID := uuid.New()
 	mockFileRepo := repMocks.NewMockIFilesystemRepository(controller)
-	mockFileRepo.EXPECT().GetEntryWithID(entityID).Return(repositories.FilesystemEntry{
-		EntityID:     entityID,
-		LogicalName:  "random name",
-		IsDocument:   false,
-		ParentFileID: parentID,
-		ChildrenIDs:  []uuid.UUID{},
+	childID := uuid.New()
+	mockFileRepo.EXPECT().GetSubtree(entityID, 1).Return([]repositories.FilesystemEntry{
+		{
+			EntityID:     entityID,
+			LogicalName:  "random name",
+			IsDocument:   false,
+			ParentFileID: parentID,
+			ChildrenIDs:  []uuid.UUID{childID},
+		},
+		{
+			EntityID:     childID,
+			LogicalName:  "child",
+			IsDocument:   true,
+			ParentFileID: entityID,
+			ChildrenIDs:  []uuid.UUID{},
+		},
 	}, nil).Times(1)
 
 	mockDepFactory := createMockDependencyFactory(controller, mockFileRepo, true)
+	expectMetadataAndTags(controller, mockDepFactory, entityID, map[string]string{"author": "jane"}, []repositories.Tag{{TagID: 1, Name: "news"}})
 
 	// ==== test execution =====
 	form := models.ValidInfoRequest{EntityID: entityID}
@@ -43,10 +57,99 @@ func TestValidEntityInfo(t *testing.T) {
 		EntityName: "random name",
 		IsDocument: false,
 		Parent:     parentID,
-		Children:   []models.EntityInfoResponse{},
+		Children: []models.EntityInfoResponse{
+			{EntityID: childID, EntityName: "child", IsDocument: true, Parent: entityID, Children: []models.EntityInfoResponse{}},
+		},
+		Metadata: map[string]string{"author": "jane"},
+		Tags:     []models.TagResponse{{TagID: 1, Name: "news"}},
 	})
 }
 
+func TestValidEntitySubtree(t *testing.T) {
+	controller := gomock.NewController(t)
+	assert := assert.New(t)
+	defer controller.Finish()
+
+	// ==== test setup =====
+	// root -> directory -> document, the document's sibling is beyond the requested depth
+	rootID, directoryID, documentID := uuid.New(), uuid.New(), uuid.New()
+	mockFileRepo := repMocks.NewMockIFilesystemRepository(controller)
+	mockFileRepo.EXPECT().GetSubtree(rootID, 2).Return([]repositories.FilesystemEntry{
+		{EntityID: rootID, LogicalName: "root", ChildrenIDs: []uuid.UUID{directoryID}},
+		{EntityID: directoryID, LogicalName: "directory", ParentFileID: rootID, ChildrenIDs: []uuid.UUID{documentID, uuid.New()}},
+		{EntityID: documentID, LogicalName: "document", IsDocument: true, ParentFileID: directoryID, ChildrenIDs: []uuid.UUID{}},
+	}, nil).Times(1)
+
+	mockDepFactory := createMockDependencyFactory(controller, mockFileRepo, true)
+	expectMetadataAndTags(controller, mockDepFactory, rootID, map[string]string{}, []repositories.Tag{})
+
+	// ==== test execution =====
+	response := endpoints.GetEntitySubtree(models.ValidSubtreeRequest{EntityID: rootID, Depth: 2}, mockDepFactory)
+	assert.Equal(http.StatusOK, response.Status)
+	assert.Equal(models.EntityInfoResponse{
+		EntityID: rootID, EntityName: "root",
+		Children: []models.EntityInfoResponse{{
+			EntityID: directoryID, EntityName: "directory", Parent: rootID,
+			Children: []models.EntityInfoResponse{
+				{EntityID: documentID, EntityName: "document", IsDocument: true, Parent: directoryID, Children: []models.EntityInfoResponse{}},
+			},
+		}},
+		Metadata: map[string]string{},
+		Tags:     []models.TagResponse{},
+	}, response.Response)
+
+	// the depth is bounded
+	mockDepFactory = createMockDependencyFactory(controller, nil, true)
+	response = endpoints.GetEntitySubtree(models.ValidSubtreeRequest{EntityID: rootID, Depth: 1000}, mockDepFactory)
+	assert.Equal(http.StatusBadRequest, response.Status)
+}
+
+func TestValidBreadcrumbs(t *testing.T) {
+	controller := gomock.NewController(t)
+	assert := assert.New(t)
+	defer controller.Finish()
+
+	// ==== test setup =====
+	rootID, documentID := uuid.New(), uuid.New()
+	mockFileRepo := repMocks.NewMockIFilesystemRepository(controller)
+	mockFileRepo.EXPECT().GetBreadcrumbs(documentID).Return([]repositories.FilesystemEntry{
+		{EntityID: rootID, LogicalName: "root"},
+		{EntityID: documentID, LogicalName: "document", IsDocument: true, ParentFileID: rootID},
+	}, nil).Times(1)
+
+	mockDepFactory := createMockDependencyFactory(controller, mockFileRepo, true)
+
+	// ==== test execution =====
+	response := endpoints.GetBreadcrumbs(models.ValidInfoRequest{EntityID: documentID}, mockDepFactory)
+	assert.Equal(http.StatusOK, response.Status)
+	assert.Equal(models.BreadcrumbsResponse{
+		Breadcrumbs: []models.Breadcrumb{
+			{EntityID: rootID, EntityName: "root"},
+			{EntityID: documentID, EntityName: "document", IsDocument: true},
+		},
+	}, response.Response)
+}
+
+func TestGetIDWithPath(t *testing.T) {
+	controller := gomock.NewController(t)
+	assert := assert.New(t)
+	defer controller.Finish()
+
+	// ==== test setup =====
+	entityID := uuid.New()
+	mockFileRepo := repMocks.NewMockIFilesystemRepository(controller)
+	mockFileRepo.EXPECT().GetIDWithPath("/a/b/c").Return(entityID, nil).Times(1)
+	mockFileRepo.EXPECT().GetIDWithPath("/a/b/d").Return(uuid.Nil, errors.New("no rows in result set")).Times(1)
+
+	// ==== test execution =====
+	response := endpoints.GetIDWithPath(models.ValidPathRequest{Path: "/a/b/c"}, createMockDependencyFactory(controller, mockFileRepo, true))
+	assert.Equal(http.StatusOK, response.Status)
+	assert.Equal(models.PathResolutionResponse{EntityID: entityID}, response.Response)
+
+	response = endpoints.GetIDWithPath(models.ValidPathRequest{Path: "/a/b/d"}, createMockDependencyFactory(controller, mockFileRepo, true))
+	assert.Equal(http.StatusNotFound, response.Status)
+}
+
 func TestValidCreateNewEntity(t *testing.T) {
 	controller := gomock.NewController(t)
 	assert := assert.New(t)
@@ -72,10 +175,13 @@ func TestValidCreateNewEntity(t *testing.T) {
 	}, nil).Times(1)
 
 	mockDockerFileSystemRepo := repMocks.NewMockIUnpublishedVolumeRepository(controller)
-	mockDockerFileSystemRepo.EXPECT().AddToVolume(entityID.String()).Return(nil).Times(1)
+	mockDockerFileSystemRepo.EXPECT().GetFromVolume(entityID.String()).DoAndReturn(func(filename string) (*os.File, error) {
+		return os.Create(filepath.Join(t.TempDir(), filename))
+	}).Times(1)
 
 	mockDepFactory := createMockDependencyFactory(controller, mockFileRepo, true)
 	mockDepFactory.EXPECT().GetUnpublishedVolumeRepo().Return(mockDockerFileSystemRepo)
+	expectUnitOfWork(mockDepFactory, nil)
 
 	form := models.ValidEntityCreationRequest{
 		LogicalName: "random name",
@@ -92,6 +198,32 @@ func TestValidCreateNewEntity(t *testing.T) {
 	})
 }
 
+func TestCreateNewEntityRollsBack(t *testing.T) {
+	controller := gomock.NewController(t)
+	assert := assert.New(t)
+	defer controller.Finish()
+
+	// ==== test setup =====
+	entityID := uuid.New()
+	mockFileRepo := repMocks.NewMockIFilesystemRepository(controller)
+	mockFileRepo.EXPECT().CreateEntry(gomock.Any()).Return(repositories.FilesystemEntry{EntityID: entityID}, nil).Times(1)
+
+	// the document cannot be created so the entity must not be committed
+	mockDockerFileSystemRepo := repMocks.NewMockIUnpublishedVolumeRepository(controller)
+	mockDockerFileSystemRepo.EXPECT().GetFromVolume(entityID.String()).Return(nil, errors.New("volume is full")).Times(1)
+
+	mockDepFactory := createMockDependencyFactory(controller, mockFileRepo, true)
+	mockDepFactory.EXPECT().GetUnpublishedVolumeRepo().Return(mockDockerFileSystemRepo)
+
+	committed := true
+	expectUnitOfWork(mockDepFactory, &committed)
+
+	// ==== test execution =====
+	response := endpoints.CreateNewEntity(models.ValidEntityCreationRequest{LogicalName: "random name", OwnerGroup: 1}, mockDepFactory)
+	assert.Equal(http.StatusInternalServerError, response.Status)
+	assert.False(committed)
+}
+
 func TestValidDeleteFilesystemEntity(t *testing.T) {
 	controller := gomock.NewController(t)
 	assert := assert.New(t)
@@ -131,7 +263,7 @@ func TestValidGetChildren(t *testing.T) {
 
 	mockDepFactory := createMockDependencyFactory(controller, mockFileRepo, true)
 
-	form := models.ValidInfoRequest{
+	form := models.ValidChildrenRequest{
 		EntityID: entityID,
 	}
 
@@ -143,7 +275,56 @@ func TestValidGetChildren(t *testing.T) {
 	})
 }
 
+func TestGetChildrenWithTag(t *testing.T) {
+	controller := gomock.NewController(t)
+	assert := assert.New(t)
+	defer controller.Finish()
+
+	// ==== test setup =====
+	entityID, taggedID := uuid.New(), uuid.New()
+	mockFileRepo := repMocks.NewMockIFilesystemRepository(controller)
+	mockFileRepo.EXPECT().GetEntryWithID(entityID).Return(repositories.FilesystemEntry{
+		EntityID:    entityID,
+		ChildrenIDs: []uuid.UUID{taggedID, uuid.New()},
+	}, nil).Times(1)
+
+	mockTagsRepo := repMocks.NewMockTagsRepository(controller)
+	mockTagsRepo.EXPECT().GetChildrenWithTag(entityID, 3).Return([]uuid.UUID{taggedID}, nil).Times(1)
+
+	mockDepFactory := createMockDependencyFactory(controller, mockFileRepo, true)
+	mockDepFactory.EXPECT().GetTagsRepo().Return(mockTagsRepo)
+
+	// ==== test execution =====
+	response := endpoints.GetChildren(models.ValidChildrenRequest{EntityID: entityID, Tag: 3}, mockDepFactory)
+	assert.Equal(http.StatusOK, response.Status)
+	assert.Equal(models.ChildrenRequestResponse{Children: []uuid.UUID{taggedID}}, response.Response)
+}
+
+// expectMetadataAndTags makes the mock dependency factory return an entity's metadata and tags
+func expectMetadataAndTags(controller *gomock.Controller, mockDepFactory *mock_endpoints.MockDependencyFactory, entityID uuid.UUID, metadata map[string]string, tags []repositories.Tag) {
+	mockMetadataRepo := repMocks.NewMockMetadataRepository(controller)
+	mockMetadataRepo.EXPECT().GetMetadata(entityID).Return(metadata, nil)
+	mockDepFactory.EXPECT().GetMetadataRepo().Return(mockMetadataRepo)
+
+	mockTagsRepo := repMocks.NewMockTagsRepository(controller)
+	mockTagsRepo.EXPECT().GetEntityTags(entityID).Return(tags, nil)
+	mockDepFactory.EXPECT().GetTagsRepo().Return(mockTagsRepo)
+}
+
 // createMockDependencyFactory just constructs an instance of a dependency factory mock
+// expectUnitOfWork makes the mock dependency factory run units of work against itself, if committed
+// is provided it records if the unit of work succeeded (and would have been committed)
+func expectUnitOfWork(mockDepFactory *mock_endpoints.MockDependencyFactory, committed *bool) {
+	mockDepFactory.EXPECT().WithTx(gomock.Any()).DoAndReturn(func(fn func(endpoints.DependencyFactory) error) error {
+		err := fn(mockDepFactory)
+		if committed != nil {
+			*committed = err == nil
+		}
+
+		return err
+	})
+}
+
 func createMockDependencyFactory(controller *gomock.Controller, mockFileRepo *repMocks.MockIFilesystemRepository, needsLogger bool) *mock_endpoints.MockDependencyFactory {
 	mockDepFactory := mock_endpoints.NewMockDependencyFactory(controller)
 
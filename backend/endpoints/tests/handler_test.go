@@ -1,21 +1,30 @@
 package tests
 
 import (
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
+	"cms.csesoc.unsw.edu.au/database/repositories"
 	repMocks "cms.csesoc.unsw.edu.au/database/repositories/mocks"
 	"cms.csesoc.unsw.edu.au/endpoints"
 	mock_endpoints "cms.csesoc.unsw.edu.au/endpoints/mocks"
 	"cms.csesoc.unsw.edu.au/endpoints/models"
 	"cms.csesoc.unsw.edu.au/internal/logger"
+	"cms.csesoc.unsw.edu.au/internal/session"
 	"github.com/golang/mock/gomock"
 	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
 	"github.com/stretchr/testify/assert"
 )
 
-// Currently failing, because the websocket upgrade thing fails and will send back a 500 error.
+const TEST_FRONTEND = "http://localhost:3000"
+
+// Connections without a session are rejected before the websocket is upgraded.
 func TestEditHandler(t *testing.T) {
 	controller := gomock.NewController(t)
 	assert := assert.New(t)
@@ -28,7 +37,7 @@ func TestEditHandler(t *testing.T) {
 	request := httptest.NewRequest("GET", "/editor", nil)
 
 	//Make unpublishedvolumes
-	mockUnpublishedVolume := repMocks.NewMockIUnpublishedVolumeRepository(controller)
+	mockUnpublishedVolume := repMocks.NewMockUnpublishedVolumeRepository(controller)
 
 	// Make logger
 	log := logger.OpenLog("New Handler Log")
@@ -38,5 +47,161 @@ func TestEditHandler(t *testing.T) {
 
 	// Test execution
 	response := endpoints.EditHandler(form, responseRecorder, request, mockDepFactory)
-	assert.Equal(response.Status, http.StatusInternalServerError)
+	assert.Equal(http.StatusUnauthorized, response.Status)
+	assert.Equal(http.StatusUnauthorized, responseRecorder.Code)
+}
+
+// Users with write access get a normal editing session.
+func TestEditHandlerWithWriteAccess(t *testing.T) {
+	assert := assert.New(t)
+	ws := dialEditor(t, repositories.PermissionWrite, TEST_FRONTEND)
+	defer ws.Close()
+
+	init := readEditorMessage(t, ws)
+	assert.Equal("init", init["type"])
+	assert.Equal(false, init["readOnly"])
+
+	assert.Nil(ws.WriteMessage(websocket.TextMessage, []byte(`[{"type": "paragraph"}]`)))
+	assert.Equal("acknowledged", readEditorMessage(t, ws)["type"])
+}
+
+// Users that can only read a document get a read only session.
+func TestEditHandlerWithReadAccess(t *testing.T) {
+	assert := assert.New(t)
+	ws := dialEditor(t, repositories.PermissionRead, TEST_FRONTEND)
+	defer ws.Close()
+
+	init := readEditorMessage(t, ws)
+	assert.Equal("init", init["type"])
+	assert.Equal(true, init["readOnly"])
+
+	assert.Nil(ws.WriteMessage(websocket.TextMessage, []byte(`[{"type": "paragraph"}]`)))
+	assert.Equal("rejected", readEditorMessage(t, ws)["type"])
+}
+
+// Saves that don't match the type of the document's directory are rejected with the problems found.
+func TestEditHandlerRejectsInvalidDocuments(t *testing.T) {
+	assert := assert.New(t)
+	ws, _, err := tryDialTypedEditor(t, repositories.PermissionWrite, TEST_FRONTEND, repositories.DocumentType{
+		TypeID: 1,
+		Name:   "blog post",
+		Fields: []repositories.DocumentField{{Name: "title", Kind: repositories.FieldString, Required: true}},
+	})
+	if !assert.Nil(err) {
+		return
+	}
+	defer ws.Close()
+
+	assert.Equal("init", readEditorMessage(t, ws)["type"])
+
+	assert.Nil(ws.WriteMessage(websocket.TextMessage, []byte(`{"title": 3}`)))
+	rejection := readEditorMessage(t, ws)
+	assert.Equal("rejected", rejection["type"])
+	assert.Equal([]interface{}{"/title: expected a string"}, rejection["errors"])
+
+	assert.Nil(ws.WriteMessage(websocket.TextMessage, []byte(`{"title": "Hello"}`)))
+	assert.Equal("acknowledged", readEditorMessage(t, ws)["type"])
+}
+
+// Users without access and foreign origins are refused.
+func TestEditHandlerRejectsConnections(t *testing.T) {
+	assert := assert.New(t)
+
+	_, response, err := tryDialEditor(t, repositories.PermissionNone, TEST_FRONTEND)
+	assert.NotNil(err)
+	assert.Equal(http.StatusForbidden, response.StatusCode)
+
+	_, response, err = tryDialEditor(t, repositories.PermissionWrite, "http://evil.com")
+	assert.NotNil(err)
+	assert.Equal(http.StatusForbidden, response.StatusCode)
+}
+
+// dialEditor opens an editor connection as a logged in user holding the provided permission over the document
+func dialEditor(t *testing.T, permission repositories.Permission, origin string) *websocket.Conn {
+	ws, _, err := tryDialEditor(t, permission, origin)
+	if err != nil {
+		t.Fatalf("failed to dial editor: %v", err)
+	}
+
+	return ws
+}
+
+func tryDialEditor(t *testing.T, permission repositories.Permission, origin string) (*websocket.Conn, *http.Response, error) {
+	return tryDialTypedEditor(t, permission, origin, repositories.DocumentType{TypeID: repositories.NoDocumentType})
+}
+
+// tryDialTypedEditor opens an editor connection to a document within a directory of the provided type
+func tryDialTypedEditor(t *testing.T, permission repositories.Permission, origin string, documentType repositories.DocumentType) (*websocket.Conn, *http.Response, error) {
+	controller := gomock.NewController(t)
+	t.Cleanup(controller.Finish)
+	documentID := uuid.New()
+	directoryID := uuid.New()
+
+	// the document lives in a temporary directory
+	documentPath := filepath.Join(t.TempDir(), documentID.String())
+	if err := os.WriteFile(documentPath, []byte("[]"), 0o644); err != nil {
+		t.Fatalf("failed to create document: %v", err)
+	}
+
+	mockUnpublishedVolume := repMocks.NewMockUnpublishedVolumeRepository(controller)
+	mockUnpublishedVolume.EXPECT().GetFromVolume(documentID.String()).DoAndReturn(func(string) (*os.File, error) {
+		return os.OpenFile(documentPath, os.O_RDWR, 0o644)
+	}).AnyTimes()
+
+	mockPersonRepo := repMocks.NewMockPersonRepository(controller)
+	mockPersonRepo.EXPECT().GetPersonWithEmail(TEST_EMAIL).Return(repositories.Person{UID: 1, Email: TEST_EMAIL}, nil).AnyTimes()
+	mockPermissionsRepo := repMocks.NewMockPermissionsRepository(controller)
+	mockPermissionsRepo.EXPECT().GetPermission(1, documentID).Return(permission, nil).AnyTimes()
+	mockFrontendsRepo := repMocks.NewMockFrontendsRepository(controller)
+	mockFrontendsRepo.EXPECT().IsRegisteredURL(gomock.Any()).DoAndReturn(func(url string) (bool, error) {
+		return url == TEST_FRONTEND, nil
+	}).AnyTimes()
+
+	mockFileRepo := repMocks.NewMockFilesystemRepository(controller)
+	mockFileRepo.EXPECT().GetEntryWithID(documentID).Return(repositories.FilesystemEntry{
+		EntityID: documentID, IsDocument: true, ParentFileID: directoryID,
+	}, nil).AnyTimes()
+	mockDocumentTypesRepo := repMocks.NewMockDocumentTypesRepository(controller)
+	mockDocumentTypesRepo.EXPECT().GetDirectoryType(directoryID).Return(documentType, nil).AnyTimes()
+
+	mockDepFactory := mock_endpoints.NewMockDependencyFactory(controller)
+	mockDepFactory.EXPECT().GetLogger().Return(logger.OpenLog("editor log")).AnyTimes()
+	mockDepFactory.EXPECT().GetFilesystemRepo().Return(mockFileRepo, nil).AnyTimes()
+	mockDepFactory.EXPECT().GetDocumentTypesRepo().Return(mockDocumentTypesRepo).AnyTimes()
+	mockDepFactory.EXPECT().GetUnpublishedVolumeRepo().Return(mockUnpublishedVolume).AnyTimes()
+	mockDepFactory.EXPECT().GetPersonsRepo().Return(mockPersonRepo).AnyTimes()
+	mockDepFactory.EXPECT().GetPermissionsRepo().Return(mockPermissionsRepo).AnyTimes()
+	mockDepFactory.EXPECT().GetFrontendsRepo().Return(mockFrontendsRepo).AnyTimes()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		endpoints.EditHandler(models.ValidEditRequest{DocumentID: documentID}, w, r, mockDepFactory)
+	}))
+	t.Cleanup(server.Close)
+
+	// log in to acquire a session cookie
+	loginRecorder := httptest.NewRecorder()
+	session.CreateSession(loginRecorder, httptest.NewRequest("POST", "/login", nil), TEST_EMAIL, uuid.Nil)
+
+	header := http.Header{}
+	header.Set("Origin", origin)
+	for _, cookie := range loginRecorder.Result().Cookies() {
+		header.Add("Cookie", cookie.String())
+	}
+
+	return websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), header)
+}
+
+// readEditorMessage reads and decodes a single message from the editor
+func readEditorMessage(t *testing.T, ws *websocket.Conn) map[string]interface{} {
+	_, data, err := ws.ReadMessage()
+	if err != nil {
+		t.Fatalf("failed to read from editor: %v", err)
+	}
+
+	message := map[string]interface{}{}
+	if err := json.Unmarshal(data, &message); err != nil {
+		t.Fatalf("malformed editor message %s: %v", data, err)
+	}
+
+	return message
 }
@@ -0,0 +1,178 @@
+package tests
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"cms.csesoc.unsw.edu.au/database/repositories"
+	repMocks "cms.csesoc.unsw.edu.au/database/repositories/mocks"
+	"cms.csesoc.unsw.edu.au/endpoints"
+	mock_endpoints "cms.csesoc.unsw.edu.au/endpoints/mocks"
+	"cms.csesoc.unsw.edu.au/endpoints/models"
+	"cms.csesoc.unsw.edu.au/internal/logger"
+	"cms.csesoc.unsw.edu.au/internal/session"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetMetadata(t *testing.T) {
+	controller := gomock.NewController(t)
+	assert := assert.New(t)
+	defer controller.Finish()
+
+	// ==== test setup =====
+	entityID := uuid.New()
+	mockMetadataRepo := repMocks.NewMockMetadataRepository(controller)
+	mockMetadataRepo.EXPECT().SetMetadata(entityID, "seo_title", "Hello").Return(nil).Times(1)
+
+	mockDepFactory := createMockDependencyFactory(controller, nil, true)
+	mockDepFactory.EXPECT().GetMetadataRepo().Return(mockMetadataRepo)
+	expectPermission(controller, mockDepFactory, entityID, repositories.PermissionWrite)
+
+	// ==== test execution =====
+	request := newAuthenticatedRequest("POST", "/api/filesystem/metadata/set")
+	response := endpoints.SetMetadata(models.ValidMetadataRequest{EntityID: entityID, Key: "seo_title", Value: "Hello"}, httptest.NewRecorder(), request, mockDepFactory)
+	assert.Equal(http.StatusOK, response.Status)
+
+	// keys are bounded
+	response = endpoints.SetMetadata(models.ValidMetadataRequest{EntityID: entityID, Key: strings.Repeat("k", 100)}, httptest.NewRecorder(), request, mockDepFactory)
+	assert.Equal(http.StatusBadRequest, response.Status)
+}
+
+func TestSetMetadataRequiresWriteAccess(t *testing.T) {
+	controller := gomock.NewController(t)
+	assert := assert.New(t)
+	defer controller.Finish()
+
+	// ==== test setup =====
+	entityID := uuid.New()
+	mockDepFactory := createMockDependencyFactory(controller, nil, false)
+	mockDepFactory.EXPECT().GetLogger().Return(logger.OpenLog("new log")).AnyTimes()
+	expectPermission(controller, mockDepFactory, entityID, repositories.PermissionRead)
+
+	// ==== test execution =====
+	form := models.ValidMetadataRequest{EntityID: entityID, Key: "seo_title", Value: "Hello"}
+	response := endpoints.SetMetadata(form, httptest.NewRecorder(), newAuthenticatedRequest("POST", "/api/filesystem/metadata/set"), mockDepFactory)
+	assert.Equal(http.StatusForbidden, response.Status)
+
+	// requests without a session are rejected before the handler is reached
+	mux := http.NewServeMux()
+	endpoints.RegisterFilesystemEndpoints(mux)
+	recorder := httptest.NewRecorder()
+	mux.ServeHTTP(recorder, httptest.NewRequest("POST", "/api/filesystem/metadata/set?EntityID="+entityID.String()+"&Key=seo_title", nil))
+	assert.Equal(http.StatusUnauthorized, getResponseStatus(t, recorder))
+}
+
+func TestCreateTag(t *testing.T) {
+	controller := gomock.NewController(t)
+	assert := assert.New(t)
+	defer controller.Finish()
+
+	// ==== test setup =====
+	mockTagsRepo := repMocks.NewMockTagsRepository(controller)
+	mockTagsRepo.EXPECT().CreateTag("workshops", 1).Return(repositories.Tag{TagID: 2, Name: "workshops", Parent: 1}, nil).Times(1)
+	mockTagsRepo.EXPECT().CreateTag("workshops", 1).Return(repositories.Tag{}, errors.New("duplicate key value violates unique constraint")).Times(1)
+
+	mockDepFactory := createMockDependencyFactory(controller, nil, true)
+	mockDepFactory.EXPECT().GetTagsRepo().Return(mockTagsRepo).Times(2)
+	expectAdmin(controller, mockDepFactory, true)
+
+	// ==== test execution =====
+	request := newAuthenticatedRequest("POST", "/api/filesystem/tags/create")
+	response := endpoints.CreateTag(models.ValidTagCreationRequest{Name: "workshops", Parent: 1}, httptest.NewRecorder(), request, mockDepFactory)
+	assert.Equal(http.StatusOK, response.Status)
+	assert.Equal(models.NewTagResponse{NewID: 2}, response.Response)
+
+	response = endpoints.CreateTag(models.ValidTagCreationRequest{Name: "workshops", Parent: 1}, httptest.NewRecorder(), request, mockDepFactory)
+	assert.Equal(http.StatusNotAcceptable, response.Status)
+}
+
+func TestOnlyAdminsCanChangeTheTaxonomy(t *testing.T) {
+	controller := gomock.NewController(t)
+	assert := assert.New(t)
+	defer controller.Finish()
+
+	// ==== test setup =====
+	mockDepFactory := createMockDependencyFactory(controller, nil, false)
+	expectAdmin(controller, mockDepFactory, false)
+
+	// ==== test execution =====
+	request := newAuthenticatedRequest("POST", "/api/filesystem/tags/create")
+	response := endpoints.CreateTag(models.ValidTagCreationRequest{Name: "workshops"}, httptest.NewRecorder(), request, mockDepFactory)
+	assert.Equal(http.StatusForbidden, response.Status)
+	assert.Equal(http.StatusForbidden, endpoints.DeleteTag(models.ValidTagRequest{TagID: 1}, httptest.NewRecorder(), request, mockDepFactory).Status)
+}
+
+func TestTagEntity(t *testing.T) {
+	controller := gomock.NewController(t)
+	assert := assert.New(t)
+	defer controller.Finish()
+
+	// ==== test setup =====
+	entityID := uuid.New()
+	mockTagsRepo := repMocks.NewMockTagsRepository(controller)
+	mockTagsRepo.EXPECT().TagEntity(entityID, 2).Return(nil).Times(1)
+	mockTagsRepo.EXPECT().UntagEntity(entityID, 2).Return(nil).Times(1)
+
+	mockDepFactory := createMockDependencyFactory(controller, nil, false)
+	mockDepFactory.EXPECT().GetTagsRepo().Return(mockTagsRepo).Times(2)
+	mockDepFactory.EXPECT().GetLogger().Return(logger.OpenLog("new log")).Times(2)
+	mockPermissionsRepo := expectPermission(controller, mockDepFactory, entityID, repositories.PermissionWrite)
+
+	// ==== test execution =====
+	form := models.ValidEntityTagRequest{EntityID: entityID, TagID: 2}
+	request := newAuthenticatedRequest("POST", "/api/filesystem/tags/add")
+	assert.Equal(http.StatusOK, endpoints.TagEntity(form, httptest.NewRecorder(), request, mockDepFactory).Status)
+	assert.Equal(http.StatusOK, endpoints.UntagEntity(form, httptest.NewRecorder(), request, mockDepFactory).Status)
+
+	// clients that can only read the entity can't tag it
+	otherEntityID := uuid.New()
+	mockDepFactory.EXPECT().GetLogger().Return(logger.OpenLog("new log")).AnyTimes()
+	mockPermissionsRepo.EXPECT().GetPermission(1, otherEntityID).Return(repositories.PermissionRead, nil)
+	form.EntityID = otherEntityID
+	assert.Equal(http.StatusForbidden, endpoints.TagEntity(form, httptest.NewRecorder(), request, mockDepFactory).Status)
+}
+
+// newAuthenticatedRequest creates a request that carries a session logged in as the test user
+func newAuthenticatedRequest(method string, target string) *http.Request {
+	loginRecorder := httptest.NewRecorder()
+	session.CreateSession(loginRecorder, httptest.NewRequest("POST", "/login", nil), TEST_EMAIL, uuid.Nil)
+
+	request := httptest.NewRequest(method, target, nil)
+	for _, cookie := range loginRecorder.Result().Cookies() {
+		request.AddCookie(cookie)
+	}
+
+	return request
+}
+
+// expectPermission sets up the test user (UID 1) to hold a permission over an entity, further permissions can be set
+// up through the returned repository
+func expectPermission(controller *gomock.Controller, mockDepFactory *mock_endpoints.MockDependencyFactory, entityID uuid.UUID, permission repositories.Permission) *repMocks.MockPermissionsRepository {
+	expectTestPerson(controller, mockDepFactory)
+
+	mockPermissionsRepo := repMocks.NewMockPermissionsRepository(controller)
+	mockPermissionsRepo.EXPECT().GetPermission(1, entityID).Return(permission, nil).AnyTimes()
+	mockDepFactory.EXPECT().GetPermissionsRepo().Return(mockPermissionsRepo).AnyTimes()
+	return mockPermissionsRepo
+}
+
+// expectAdmin sets up the test user (UID 1) to be (or not be) a member of the admin group
+func expectAdmin(controller *gomock.Controller, mockDepFactory *mock_endpoints.MockDependencyFactory, isAdmin bool) {
+	expectTestPerson(controller, mockDepFactory)
+
+	mockGroupsRepo := repMocks.NewMockGroupsRepository(controller)
+	mockGroupsRepo.EXPECT().IsMemberOf(1, repositories.GROUPS_ADMIN).Return(isAdmin, nil).AnyTimes()
+	mockDepFactory.EXPECT().GetGroupsRepo().Return(mockGroupsRepo).AnyTimes()
+}
+
+// expectTestPerson sets up the person repository to find the test user
+func expectTestPerson(controller *gomock.Controller, mockDepFactory *mock_endpoints.MockDependencyFactory) {
+	mockPersonRepo := repMocks.NewMockPersonRepository(controller)
+	mockPersonRepo.EXPECT().GetPersonWithEmail(TEST_EMAIL).Return(repositories.Person{UID: 1, Email: TEST_EMAIL, Verified: true}, nil).AnyTimes()
+	mockDepFactory.EXPECT().GetPersonsRepo().Return(mockPersonRepo).AnyTimes()
+}
@@ -0,0 +1,73 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"cms.csesoc.unsw.edu.au/editor/OT/operations"
+	"cms.csesoc.unsw.edu.au/endpoints"
+	"cms.csesoc.unsw.edu.au/endpoints/models"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// calloutSchema declares a "callout" component for a single frontend
+const calloutSchema = `{
+	"components": {
+		"callout": {"fields": [
+			{"name": "CalloutColour", "type": "string", "enum": ["red", "blue"]},
+			{"name": "CalloutText", "type": "string"}
+		]}
+	}
+}`
+
+func TestGetSchema(t *testing.T) {
+	controller := gomock.NewController(t)
+	assert := assert.New(t)
+	defer controller.Finish()
+
+	// ==== test setup =====
+	directory := t.TempDir()
+	frontendID := uuid.New()
+	assert.Nil(os.WriteFile(filepath.Join(directory, frontendID.String()+".json"), []byte(calloutSchema), 0o644))
+
+	registry, err := operations.LoadRegistry(directory)
+	if !assert.Nil(err) {
+		return
+	}
+
+	mockDepFactory := createMockDependencyFactory(controller, nil, false)
+	mockDepFactory.EXPECT().GetComponentRegistry().Return(registry, nil).AnyTimes()
+
+	// ==== test execution =====
+	response := endpoints.GetSchema(models.ValidSchemaRequest{FrontendID: frontendID}, mockDepFactory)
+	assert.Equal(http.StatusOK, response.Status)
+	assert.Equal("application/schema+json", response.ContentType)
+
+	var schema struct {
+		Definitions map[string]interface{} `json:"$defs"`
+	}
+	if assert.Nil(json.Unmarshal(response.Response, &schema)) {
+		assert.Contains(schema.Definitions, "Document")
+		assert.Contains(schema.Definitions, "Operation")
+		assert.Contains(schema.Definitions, "Callout")
+	}
+
+	response = endpoints.GetSchema(models.ValidSchemaRequest{FrontendID: frontendID, Format: "typescript"}, mockDepFactory)
+	assert.Equal(http.StatusOK, response.Status)
+	assert.Equal("application/typescript", response.ContentType)
+	assert.Contains(string(response.Response), `CalloutColour: "red" | "blue";`)
+
+	// frontends without a schema only see the built in components
+	response = endpoints.GetSchema(models.ValidSchemaRequest{FrontendID: uuid.New(), Format: "typescript"}, mockDepFactory)
+	assert.Equal(http.StatusOK, response.Status)
+	assert.NotContains(string(response.Response), "Callout")
+
+	response = endpoints.GetSchema(models.ValidSchemaRequest{FrontendID: frontendID, Format: "yaml"}, mockDepFactory)
+	assert.Equal(http.StatusBadRequest, response.Status)
+	assert.Equal([]string{`unknown format "yaml", expected json or typescript`}, response.Errors)
+}
@@ -0,0 +1,147 @@
+package tests
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"cms.csesoc.unsw.edu.au/database/repositories"
+	"cms.csesoc.unsw.edu.au/endpoints"
+	"cms.csesoc.unsw.edu.au/endpoints/models"
+	"cms.csesoc.unsw.edu.au/internal/logger"
+	"cms.csesoc.unsw.edu.au/internal/throttle"
+
+	. "cms.csesoc.unsw.edu.au/database/repositories/mocks"
+	. "cms.csesoc.unsw.edu.au/endpoints/mocks"
+)
+
+// Test that [endpoints.LoginHandler] throttles repeated failures against an account and audits every attempt.
+func TestLoginThrottlesRepeatedFailures(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+	assert := assert.New(t)
+
+	// Every password is wrong.
+	mockPersonRepository := NewMockPersonRepository(controller)
+	mockPersonRepository.EXPECT().PersonExists(gomock.Any()).Return(false).AnyTimes()
+
+	outcomes := []string{}
+	mockLoginAttemptsRepository := NewMockLoginAttemptsRepository(controller)
+	mockLoginAttemptsRepository.EXPECT().RecordAttempt(gomock.Any()).DoAndReturn(func(attempt repositories.LoginAttempt) error {
+		assert.Equal(TEST_EMAIL, attempt.Email)
+		assert.Equal("192.0.2.1", attempt.IPAddress)
+		assert.False(attempt.Successful)
+		outcomes = append(outcomes, attempt.Outcome)
+		return nil
+	}).AnyTimes()
+
+	mockDependencyFactory := NewMockDependencyFactory(controller)
+	mockDependencyFactory.EXPECT().GetPersonsRepo().Return(mockPersonRepository).AnyTimes()
+	mockDependencyFactory.EXPECT().GetLoginAttemptsRepo().Return(mockLoginAttemptsRepository).AnyTimes()
+	mockDependencyFactory.EXPECT().GetLoginThrottler().Return(endpoints.NewLoginThrottler(throttle.NewMemoryStore())).AnyTimes()
+	mockDependencyFactory.EXPECT().GetLogger().Return(logger.OpenLog("throttle log")).AnyTimes()
+
+	login := func() (handlerStatus int, recorder *httptest.ResponseRecorder) {
+		recorder = httptest.NewRecorder()
+		request := httptest.NewRequest("POST", "/login", nil)
+		request.RemoteAddr = "192.0.2.1:1234"
+
+		form := models.User{Email: TEST_EMAIL, Password: "wrong password"}
+		return endpoints.LoginHandler(form, recorder, request, mockDependencyFactory).Status, recorder
+	}
+
+	// The first few failures are free.
+	for i := 0; i < 4; i++ {
+		status, _ := login()
+		assert.Equal(http.StatusUnauthorized, status)
+	}
+
+	// Now the account is backing off.
+	status, recorder := login()
+	assert.Equal(http.StatusTooManyRequests, status)
+	assert.NotEmpty(recorder.Header().Get("Retry-After"))
+
+	assert.Equal([]string{
+		"invalid_credentials", "invalid_credentials", "invalid_credentials", "invalid_credentials", "throttled",
+	}, outcomes)
+}
+
+// Test that concurrent guesses can't all get through [endpoints.LoginHandler] before any of them have failed.
+func TestLoginThrottlesConcurrentFailures(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+	assert := assert.New(t)
+
+	// Every password is wrong.
+	mockPersonRepository := NewMockPersonRepository(controller)
+	mockPersonRepository.EXPECT().PersonExists(gomock.Any()).Return(false).AnyTimes()
+
+	mockDependencyFactory := NewMockDependencyFactory(controller)
+	mockDependencyFactory.EXPECT().GetPersonsRepo().Return(mockPersonRepository).AnyTimes()
+	addLoginThrottling(controller, mockDependencyFactory)
+
+	var wg sync.WaitGroup
+	var lock sync.Mutex
+	statuses := map[int]int{}
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			request := httptest.NewRequest("POST", "/login", nil)
+			request.RemoteAddr = "192.0.2.1:1234"
+
+			form := models.User{Email: TEST_EMAIL, Password: "wrong password"}
+			status := endpoints.LoginHandler(form, httptest.NewRecorder(), request, mockDependencyFactory).Status
+
+			lock.Lock()
+			defer lock.Unlock()
+			statuses[status]++
+		}()
+	}
+
+	wg.Wait()
+
+	// Only as many guesses as a client making them one after another would get are checked.
+	assert.Equal(4, statuses[http.StatusUnauthorized])
+	assert.Equal(46, statuses[http.StatusTooManyRequests])
+}
+
+// Test that only the X-Forwarded-For entries appended by our own proxies are used to identify the client.
+func TestLoginAuditsTheForwardedAddressOfTrustedProxies(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+	assert := assert.New(t)
+	t.Setenv("TRUSTED_PROXY_HOPS", "1")
+
+	mockPersonRepository := NewMockPersonRepository(controller)
+	mockPersonRepository.EXPECT().PersonExists(gomock.Any()).Return(false).AnyTimes()
+
+	addresses := []string{}
+	mockLoginAttemptsRepository := NewMockLoginAttemptsRepository(controller)
+	mockLoginAttemptsRepository.EXPECT().RecordAttempt(gomock.Any()).DoAndReturn(func(attempt repositories.LoginAttempt) error {
+		addresses = append(addresses, attempt.IPAddress)
+		return nil
+	}).AnyTimes()
+
+	mockDependencyFactory := NewMockDependencyFactory(controller)
+	mockDependencyFactory.EXPECT().GetPersonsRepo().Return(mockPersonRepository).AnyTimes()
+	mockDependencyFactory.EXPECT().GetLoginAttemptsRepo().Return(mockLoginAttemptsRepository).AnyTimes()
+	mockDependencyFactory.EXPECT().GetLoginThrottler().Return(endpoints.NewLoginThrottler(throttle.NewMemoryStore())).AnyTimes()
+	mockDependencyFactory.EXPECT().GetLogger().Return(logger.OpenLog("throttle log")).AnyTimes()
+
+	for _, forwarded := range []string{"203.0.113.9, 198.51.100.7", "not an address"} {
+		request := httptest.NewRequest("POST", "/login", nil)
+		request.RemoteAddr = "192.0.2.1:1234"
+		request.Header.Set("X-Forwarded-For", forwarded)
+
+		form := models.User{Email: TEST_EMAIL, Password: "wrong password"}
+		endpoints.LoginHandler(form, httptest.NewRecorder(), request, mockDependencyFactory)
+	}
+
+	// The spoofable leftmost entry is ignored and junk falls back to the proxy's own address.
+	assert.Equal([]string{"198.51.100.7", "192.0.2.1"}, addresses)
+}
@@ -0,0 +1,140 @@
+package tests
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+
+	"cms.csesoc.unsw.edu.au/database/repositories"
+	"cms.csesoc.unsw.edu.au/endpoints"
+	"cms.csesoc.unsw.edu.au/endpoints/models"
+	"cms.csesoc.unsw.edu.au/internal/totp"
+
+	. "cms.csesoc.unsw.edu.au/database/repositories/mocks"
+	. "cms.csesoc.unsw.edu.au/endpoints/mocks"
+)
+
+// Test that logging in with 2FA enabled requires a second step, and that the second step accepts a valid code.
+func TestTwoStepLogin(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+	assert := assert.New(t)
+
+	secret, _ := totp.GenerateSecret()
+	mockDependencyFactory, mockTwoFactorRepository := setUpTwoFactorRepositories(controller, false)
+	mockTwoFactorRepository.EXPECT().GetTwoFactor(1).Return(repositories.TwoFactor{UID: 1, Secret: secret, Enabled: true}, nil).AnyTimes()
+	mockTwoFactorRepository.EXPECT().MarkStepUsed(1, gomock.Any()).Return(true, nil).Times(1)
+
+	// Step 1: the password is correct but a code is still required.
+	loginRecorder := httptest.NewRecorder()
+	loginResponse := endpoints.LoginHandler(newTestUser(), loginRecorder, httptest.NewRequest("POST", "/login", nil), mockDependencyFactory)
+	assert.Equal(http.StatusOK, loginResponse.Status)
+	assert.Equal(models.LoginResponse{TwoFactorRequired: true}, loginResponse.Response)
+
+	// Step 2 without the pending session fails.
+	response := endpoints.VerifyTwoFactorLogin(models.ValidTwoFactorLoginRequest{Code: "000000"}, httptest.NewRecorder(),
+		httptest.NewRequest("POST", "/login/2fa", nil), mockDependencyFactory)
+	assert.Equal(http.StatusUnauthorized, response.Status)
+
+	// Step 2 with the pending session and a valid code logs the user in.
+	code, _ := totp.GenerateCode(secret, time.Now())
+	request := httptest.NewRequest("POST", "/login/2fa", nil)
+	for _, cookie := range loginRecorder.Result().Cookies() {
+		request.AddCookie(cookie)
+	}
+
+	recorder := httptest.NewRecorder()
+	response = endpoints.VerifyTwoFactorLogin(models.ValidTwoFactorLoginRequest{Code: code}, recorder, request, mockDependencyFactory)
+	assert.Equal(http.StatusMovedPermanently, response.Status)
+	assert.Equal(http.StatusMovedPermanently, recorder.Result().StatusCode)
+}
+
+// Test that admins must enrol in 2FA when the frontend requires it.
+func TestAdminMustEnrol(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+	assert := assert.New(t)
+
+	mockDependencyFactory, mockTwoFactorRepository := setUpTwoFactorRepositories(controller, true)
+	mockTwoFactorRepository.EXPECT().GetTwoFactor(1).Return(repositories.TwoFactor{UID: 1}, nil).AnyTimes()
+
+	request := httptest.NewRequest("POST", "/login", nil)
+	request.Header.Set("Origin", "http://localhost:3000")
+	response := endpoints.LoginHandler(newTestUser(), httptest.NewRecorder(), request, mockDependencyFactory)
+
+	assert.Equal(http.StatusOK, response.Status)
+	assert.Equal(models.LoginResponse{EnrolmentRequired: true}, response.Response)
+}
+
+// Test that admins can't skip enrolment by claiming to come from a frontend that doesn't require 2FA.
+func TestAdminMustEnrolWithSpoofedOrigin(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+	assert := assert.New(t)
+
+	mockDependencyFactory, mockTwoFactorRepository := setUpTwoFactorRepositories(controller, true)
+	mockTwoFactorRepository.EXPECT().GetTwoFactor(1).Return(repositories.TwoFactor{UID: 1}, nil).AnyTimes()
+
+	for _, origin := range []string{"http://lax.csesoc.unsw.edu.au", "http://unregistered.example.com", ""} {
+		request := httptest.NewRequest("POST", "/login", nil)
+		request.Header.Set("Origin", origin)
+		response := endpoints.LoginHandler(newTestUser(), httptest.NewRecorder(), request, mockDependencyFactory)
+
+		assert.Equal(http.StatusOK, response.Status)
+		assert.Equal(models.LoginResponse{EnrolmentRequired: true}, response.Response)
+	}
+}
+
+// Test that the 2FA policy of the frontend being logged into is the one that applies.
+func TestAdminTwoFactorPolicyIsPerFrontend(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+	assert := assert.New(t)
+
+	mockDependencyFactory, mockTwoFactorRepository := setUpTwoFactorRepositories(controller, true)
+	mockTwoFactorRepository.EXPECT().GetTwoFactor(1).Return(repositories.TwoFactor{UID: 1}, nil).AnyTimes()
+
+	// CASE: the frontend doesn't require admins to use 2FA
+	form := newTestUser()
+	form.FrontendID = laxFrontend
+	response := endpoints.LoginHandler(form, httptest.NewRecorder(), httptest.NewRequest("POST", "/login", nil), mockDependencyFactory)
+	assert.Equal(http.StatusMovedPermanently, response.Status)
+
+	// CASE: the frontend requires admins to use 2FA
+	form.FrontendID = uuid.New()
+	response = endpoints.LoginHandler(form, httptest.NewRecorder(), httptest.NewRequest("POST", "/login", nil), mockDependencyFactory)
+	assert.Equal(http.StatusOK, response.Status)
+	assert.Equal(models.LoginResponse{EnrolmentRequired: true}, response.Response)
+}
+
+// laxFrontend is the only frontend that doesn't require admins to use 2FA in the tests below
+var laxFrontend = uuid.MustParse("6b6a4ec8-2a4c-4c0e-9a55-3c5b0f0b2d7e")
+
+// Create a dependency factory for the test user where the test user may or may not be an admin of a frontend that requires 2FA.
+func setUpTwoFactorRepositories(controller *gomock.Controller, isAdmin bool) (*MockDependencyFactory, *MockTwoFactorRepository) {
+	form := newTestUser()
+	mockPersonRepository := NewMockPersonRepository(controller)
+	mockPersonRepository.EXPECT().PersonExists(gomock.Any()).Return(true).AnyTimes()
+	mockPersonRepository.EXPECT().GetPersonWithEmail(form.Email).Return(repositories.Person{UID: 1, Email: form.Email, Verified: true}, nil).AnyTimes()
+
+	mockGroupsRepository := NewMockGroupsRepository(controller)
+	mockGroupsRepository.EXPECT().IsMemberOf(1, repositories.GROUPS_ADMIN).Return(isAdmin, nil).AnyTimes()
+	mockFrontendsRepository := NewMockFrontendsRepository(controller)
+	mockFrontendsRepository.EXPECT().RequiresAdminTwoFactor(laxFrontend).Return(false, nil).AnyTimes()
+	mockFrontendsRepository.EXPECT().RequiresAdminTwoFactor(gomock.Not(laxFrontend)).Return(true, nil).AnyTimes()
+
+	mockTwoFactorRepository := NewMockTwoFactorRepository(controller)
+	mockDependencyFactory := NewMockDependencyFactory(controller)
+	mockDependencyFactory.EXPECT().GetPersonsRepo().Return(mockPersonRepository).AnyTimes()
+	mockDependencyFactory.EXPECT().GetGroupsRepo().Return(mockGroupsRepository).AnyTimes()
+	mockDependencyFactory.EXPECT().GetFrontendsRepo().Return(mockFrontendsRepository).AnyTimes()
+	mockDependencyFactory.EXPECT().GetTwoFactorRepo().Return(mockTwoFactorRepository).AnyTimes()
+	addLoginThrottling(controller, mockDependencyFactory)
+
+	return mockDependencyFactory, mockTwoFactorRepository
+}
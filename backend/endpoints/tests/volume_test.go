@@ -1,21 +1,82 @@
 package tests
 
 import (
+	"errors"
 	"io/ioutil"
 	"net/http"
 	"os"
+	"path/filepath"
 	"testing"
 
 	"cms.csesoc.unsw.edu.au/database/repositories"
 	repMocks "cms.csesoc.unsw.edu.au/database/repositories/mocks"
 	"cms.csesoc.unsw.edu.au/endpoints"
+	mock_endpoints "cms.csesoc.unsw.edu.au/endpoints/mocks"
 	"cms.csesoc.unsw.edu.au/endpoints/models"
+	"cms.csesoc.unsw.edu.au/internal/logger"
 	"github.com/golang/mock/gomock"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 )
 
 func TestUploadDocument(t *testing.T) {
+	controller := gomock.NewController(t)
+	assert := assert.New(t)
+	defer controller.Finish()
+
+	// ==== test setup =====
+	entityID := uuid.New()
+	parentID := uuid.New()
+	entityToCreate := repositories.FilesystemEntry{
+		LogicalName:  "post",
+		ParentFileID: parentID,
+		IsDocument:   true,
+		OwnerUserId:  1,
+	}
+
+	mockFileRepo := repMocks.NewMockIFilesystemRepository(controller)
+	mockFileRepo.EXPECT().CreateEntry(entityToCreate).Return(repositories.FilesystemEntry{
+		EntityID:     entityID,
+		LogicalName:  "post",
+		IsDocument:   true,
+		ParentFileID: parentID,
+	}, nil).Times(1)
+
+	tempFile, _ := ioutil.TempFile(os.TempDir(), "expected")
+	defer os.Remove(tempFile.Name())
+
+	mockDockerFileSystemRepo := repMocks.NewMockIUnpublishedVolumeRepository(controller)
+	mockDockerFileSystemRepo.EXPECT().GetFromVolume(entityID.String()).Return(tempFile, nil).Times(1)
+
+	mockDocumentTypesRepo := repMocks.NewMockDocumentTypesRepository(controller)
+	mockDocumentTypesRepo.EXPECT().GetDirectoryType(parentID).Return(repositories.DocumentType{
+		TypeID: 1,
+		Name:   "blog post",
+		Fields: []repositories.DocumentField{{Name: "title", Kind: repositories.FieldString, Required: true}},
+	}, nil).Times(2)
+
+	mockDepFactory := mock_endpoints.NewMockDependencyFactory(controller)
+	mockDepFactory.EXPECT().GetFilesystemRepo().Return(mockFileRepo, nil).Times(2)
+	mockDepFactory.EXPECT().GetUnpublishedVolumeRepo().Return(mockDockerFileSystemRepo).Times(2)
+	mockDepFactory.EXPECT().GetDocumentTypesRepo().Return(mockDocumentTypesRepo).Times(2)
+	mockDepFactory.EXPECT().GetLogger().Return(logger.OpenLog("upload log")).AnyTimes()
+
+	// ==== test execution =====
+	// documents that don't match the type of their directory are refused
+	form := models.ValidDocumentUploadRequest{Parent: parentID, DocumentName: "post", Content: `{"title": false}`}
+	response := endpoints.UploadDocument(form, mockDepFactory)
+	assert.Equal(http.StatusUnprocessableEntity, response.Status)
+	assert.Equal([]string{"/title: expected a string"}, response.Errors)
+
+	form.Content = `{"title": "Hello"}`
+	response = endpoints.UploadDocument(form, mockDepFactory)
+	assert.Equal(http.StatusOK, response.Status)
+	assert.Equal(models.NewEntityResponse{NewID: entityID}, response.Response)
+
+	content, err := os.ReadFile(tempFile.Name())
+	assert.Nil(err)
+	// uploaded documents are stamped with the current datamodel version
+	assert.Equal(`{"SchemaVersion":1,"title":"Hello"}`, string(content))
 }
 
 func TestGetPublishedDocument(t *testing.T) {
@@ -47,6 +108,41 @@ func TestGetPublishedDocument(t *testing.T) {
 	assert.Equal(response.Response, []byte("{\"Contents\": hello world}"))
 }
 
+func TestGetPublishedDocumentUpgradesLegacyDocuments(t *testing.T) {
+	controller := gomock.NewController(t)
+	assert := assert.New(t)
+	defer controller.Finish()
+
+	// ==== test setup =====
+	entityID := uuid.New()
+
+	tempFile, _ := ioutil.TempFile(os.TempDir(), "legacy")
+	if _, err := tempFile.WriteString(`{"DocumentName": "legacy", "Content": []}`); err != nil {
+		panic(err)
+	}
+	tempFile.Seek(0, 0)
+	defer os.Remove(tempFile.Name())
+
+	mockDockerFileSystemRepo := repMocks.NewMockIPublishedVolumeRepository(controller)
+	mockDockerFileSystemRepo.EXPECT().GetFromVolume(entityID.String()).Return(tempFile, nil).Times(1)
+
+	mockDepFactory := createMockDependencyFactory(controller, nil, true)
+	mockDepFactory.EXPECT().GetPublishedVolumeRepo().Return(mockDockerFileSystemRepo)
+
+	// ==== test execution =====
+	form := models.ValidGetPublishedDocumentRequest{DocumentID: entityID}
+	response := endpoints.GetPublishedDocument(form, mockDepFactory)
+
+	upgraded := `{"Content":[],"DocumentName":"legacy","SchemaVersion":1}`
+	assert.Equal(http.StatusOK, response.Status)
+	assert.Equal([]byte(`{"Contents": `+upgraded+`}`), response.Response)
+
+	// reading the document doesn't modify it, the upgrade is only persisted once it's published again
+	content, err := os.ReadFile(tempFile.Name())
+	assert.Nil(err)
+	assert.Equal(`{"DocumentName": "legacy", "Content": []}`, string(content))
+}
+
 func TestUploadImage(t *testing.T) {
 	controller := gomock.NewController(t)
 	assert := assert.New(t)
@@ -110,3 +206,108 @@ func TestUploadImage(t *testing.T) {
 	assert.Nil(err)
 	assert.Equal([]byte(pngBytes), content)
 }
+
+func TestDiffDocument(t *testing.T) {
+	controller := gomock.NewController(t)
+	assert := assert.New(t)
+	defer controller.Finish()
+
+	// ==== test setup =====
+	entityID := uuid.New()
+	createFile := func(contents string) *os.File {
+		tempFile, _ := ioutil.TempFile(os.TempDir(), "diff")
+		if _, err := tempFile.WriteString(contents); err != nil {
+			panic(err)
+		}
+		tempFile.Seek(0, 0)
+		return tempFile
+	}
+
+	// the published version is in the legacy datamodel, upgrading it shouldn't show up in the diff
+	published := createFile(`{"DocumentName": "post", "Content": [], "Title": "Hello world"}`)
+	unpublished := createFile(`{"DocumentName": "post", "Content": [], "SchemaVersion": 1, "Title": "Hello there world"}`)
+	defer os.Remove(published.Name())
+	defer os.Remove(unpublished.Name())
+
+	mockFileRepo := repMocks.NewMockIFilesystemRepository(controller)
+	mockFileRepo.EXPECT().GetEntryWithID(entityID).Return(repositories.FilesystemEntry{EntityID: entityID, IsDocument: true}, nil).Times(1)
+
+	mockPublishedVolume := repMocks.NewMockIPublishedVolumeRepository(controller)
+	mockPublishedVolume.EXPECT().ReadFromVolume(entityID.String()).Return(published, nil).Times(1)
+	mockUnpublishedVolume := repMocks.NewMockIUnpublishedVolumeRepository(controller)
+	mockUnpublishedVolume.EXPECT().ReadFromVolume(entityID.String()).Return(unpublished, nil).Times(1)
+
+	mockDepFactory := createMockDependencyFactory(controller, mockFileRepo, true)
+	mockDepFactory.EXPECT().GetPublishedVolumeRepo().Return(mockPublishedVolume)
+	mockDepFactory.EXPECT().GetUnpublishedVolumeRepo().Return(mockUnpublishedVolume)
+
+	// ==== test execution =====
+	context := 0
+	form := models.ValidDocumentDiffRequest{DocumentID: entityID, Context: &context}
+	response := endpoints.DiffDocument(form, mockDepFactory)
+
+	assert.Equal(http.StatusOK, response.Status)
+	assert.Equal("text/plain; charset=utf-8", response.ContentType)
+	assert.Equal("--- published\n+++ unpublished\n@@ -5,1 +5,1 @@\n  \"Title\": \"Hello {+there +}world\"\n", string(response.Response))
+
+	// the published file isn't modified by the upgrade
+	content, err := os.ReadFile(published.Name())
+	assert.Nil(err)
+	assert.Equal(`{"DocumentName": "post", "Content": [], "Title": "Hello world"}`, string(content))
+
+	// unknown formats are rejected before any files are read
+	form.Format = "yaml"
+	response = endpoints.DiffDocument(form, mockDepFactory)
+	assert.Equal(http.StatusBadRequest, response.Status)
+}
+
+func TestDiffUnpublishedDocument(t *testing.T) {
+	controller := gomock.NewController(t)
+	assert := assert.New(t)
+	defer controller.Finish()
+
+	// ==== test setup =====
+	// real volumes so we can check that nothing is created by diffing
+	entityID := uuid.New()
+	publishedDir, unpublishedDir := t.TempDir(), t.TempDir()
+	publishedVolume, _ := repositories.NewLocalPublishedRepo(publishedDir)
+	unpublishedVolume, _ := repositories.NewLocalUnpublishedRepo(unpublishedDir)
+	os.WriteFile(filepath.Join(unpublishedDir, entityID.String()), []byte(`{"SchemaVersion": 1, "Title": "Hello"}`), 0o644)
+
+	mockFileRepo := repMocks.NewMockIFilesystemRepository(controller)
+	mockFileRepo.EXPECT().GetEntryWithID(entityID).Return(repositories.FilesystemEntry{EntityID: entityID, IsDocument: true}, nil).Times(1)
+
+	mockDepFactory := createMockDependencyFactory(controller, mockFileRepo, true)
+	mockDepFactory.EXPECT().GetPublishedVolumeRepo().Return(publishedVolume)
+	mockDepFactory.EXPECT().GetUnpublishedVolumeRepo().Return(unpublishedVolume)
+
+	// ==== test execution =====
+	form := models.ValidDocumentDiffRequest{DocumentID: entityID}
+	response := endpoints.DiffDocument(form, mockDepFactory)
+
+	// the document hasn't been published so it is compared against an empty document
+	assert.Equal(http.StatusOK, response.Status)
+	assert.Contains(string(response.Response), "{+{\n  \"SchemaVersion\": 1,\n  \"Title\": \"Hello\"\n}\n+}")
+
+	_, err := os.Stat(filepath.Join(publishedDir, entityID.String()))
+	assert.True(os.IsNotExist(err))
+}
+
+func TestDiffUnknownDocument(t *testing.T) {
+	controller := gomock.NewController(t)
+	assert := assert.New(t)
+	defer controller.Finish()
+
+	// ==== test setup =====
+	entityID := uuid.New()
+	mockFileRepo := repMocks.NewMockIFilesystemRepository(controller)
+	mockFileRepo.EXPECT().GetEntryWithID(entityID).Return(repositories.FilesystemEntry{}, errors.New("no such entity")).Times(1)
+
+	// the volumes are never touched
+	mockDepFactory := createMockDependencyFactory(controller, mockFileRepo, true)
+
+	// ==== test execution =====
+	form := models.ValidDocumentDiffRequest{DocumentID: entityID}
+	response := endpoints.DiffDocument(form, mockDepFactory)
+	assert.Equal(http.StatusNotFound, response.Status)
+}
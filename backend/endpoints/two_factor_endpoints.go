@@ -0,0 +1,264 @@
+package endpoints
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"cms.csesoc.unsw.edu.au/database/repositories"
+	. "cms.csesoc.unsw.edu.au/endpoints/models"
+	"cms.csesoc.unsw.edu.au/environment"
+	"cms.csesoc.unsw.edu.au/internal/session"
+	"cms.csesoc.unsw.edu.au/internal/totp"
+	"github.com/google/uuid"
+)
+
+const (
+	// twoFactorIssuer is the name authenticator apps display next to the account
+	twoFactorIssuer = "CSESoc CMS"
+	// recoveryCodeCount is the number of recovery codes issued when a user enables 2FA
+	recoveryCodeCount = 10
+)
+
+// VerifyTwoFactorLogin is the second step of logging in for users with 2FA enabled, it accepts either
+// a TOTP code or one of the user's recovery codes, failures count towards the account's login throttle
+func VerifyTwoFactorLogin(form ValidTwoFactorLoginRequest, w http.ResponseWriter, r *http.Request, df DependencyFactory) handlerResponse[empty] {
+	log := df.GetLogger()
+	pending, err := session.GetPendingUser(r)
+	if err != nil || pending.Enrolling {
+		return handlerResponse[empty]{Status: http.StatusUnauthorized}
+	}
+
+	if (form.Code == "") == (form.RecoveryCode == "") {
+		return handlerResponse[empty]{Status: http.StatusBadRequest}
+	}
+
+	throttler := df.GetLoginThrottler()
+	attempt := repositories.LoginAttempt{Email: pending.Email, IPAddress: getClientIP(r)}
+	accountKeys, addressKey := getLoginKeys(attempt)
+	keys := append(accountKeys, addressKey)
+
+	if decision, err := throttler.Reserve(keys...); err != nil || !decision.Allowed {
+		if err != nil {
+			log.Write(fmt.Sprintf("failed to check login throttle: %v", err))
+			return handlerResponse[empty]{Status: http.StatusInternalServerError}
+		}
+
+		attempt.Outcome = loginThrottled
+		recordLoginAttempt(df, attempt)
+		return handlerResponse[empty]{Status: http.StatusTooManyRequests}
+	}
+
+	person, err := df.GetPersonsRepo().GetPersonWithEmail(pending.Email)
+	if err != nil {
+		releaseLoginAttempt(df, keys...)
+		return handlerResponse[empty]{Status: http.StatusUnauthorized}
+	}
+
+	accepted, err := checkSecondFactor(df, person.UID, form)
+	if err != nil {
+		releaseLoginAttempt(df, keys...)
+		log.Write(fmt.Sprintf("failed to check second factor: %v", err))
+		return handlerResponse[empty]{Status: http.StatusInternalServerError}
+	}
+
+	if !accepted {
+		attempt.Outcome = loginInvalidSecondStep
+		recordLoginAttempt(df, attempt)
+		return handlerResponse[empty]{Status: http.StatusUnauthorized}
+	}
+
+	releaseLoginAttempt(df, keys...)
+	completeLogin(w, r, df, attempt, pending.FrontendID)
+	http.Redirect(w, r, fmt.Sprintf("%s/%s", environment.GetFrontendURI(), "dashboard"), http.StatusMovedPermanently)
+
+	return handlerResponse[empty]{
+		Status: http.StatusMovedPermanently,
+	}
+}
+
+// BeginTwoFactorEnrolment generates a new secret for the current user, the user must confirm they have
+// set up their authenticator by calling ConfirmTwoFactorEnrolment before 2FA is actually enabled
+func BeginTwoFactorEnrolment(form empty, w http.ResponseWriter, r *http.Request, df DependencyFactory) handlerResponse[TwoFactorEnrolmentResponse] {
+	log := df.GetLogger()
+	person, _, status := getSessionPerson(r, df, true)
+	if status != http.StatusOK {
+		return handlerResponse[TwoFactorEnrolmentResponse]{Status: status}
+	}
+
+	twoFactorRepo := df.GetTwoFactorRepo()
+	if twoFactor, err := twoFactorRepo.GetTwoFactor(person.UID); err != nil || twoFactor.Enabled {
+		if err != nil {
+			log.Write(fmt.Sprintf("failed to fetch 2FA enrolment: %v", err))
+			return handlerResponse[TwoFactorEnrolmentResponse]{Status: http.StatusInternalServerError}
+		}
+
+		// 2FA has to be disabled before re-enrolling
+		return handlerResponse[TwoFactorEnrolmentResponse]{Status: http.StatusNotAcceptable}
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err == nil {
+		err = twoFactorRepo.BeginEnrolment(person.UID, secret)
+	}
+
+	if err != nil {
+		log.Write(fmt.Sprintf("failed to begin 2FA enrolment: %v", err))
+		return handlerResponse[TwoFactorEnrolmentResponse]{Status: http.StatusInternalServerError}
+	}
+
+	return handlerResponse[TwoFactorEnrolmentResponse]{
+		Status: http.StatusOK,
+		Response: TwoFactorEnrolmentResponse{
+			Secret:          secret,
+			ProvisioningURI: totp.ProvisioningURI(twoFactorIssuer, person.Email, secret),
+		},
+	}
+}
+
+// ConfirmTwoFactorEnrolment enables 2FA once the user has proven they can generate codes, it responds with
+// the user's recovery codes. If the user was only part way through logging in (because the frontend requires
+// them to use 2FA) then confirming their enrolment also completes their login
+func ConfirmTwoFactorEnrolment(form ValidTwoFactorCodeRequest, w http.ResponseWriter, r *http.Request, df DependencyFactory) handlerResponse[RecoveryCodesResponse] {
+	log := df.GetLogger()
+	person, enrolling, status := getSessionPerson(r, df, true)
+	if status != http.StatusOK {
+		return handlerResponse[RecoveryCodesResponse]{Status: status}
+	}
+
+	twoFactorRepo := df.GetTwoFactorRepo()
+	twoFactor, err := twoFactorRepo.GetTwoFactor(person.UID)
+	if err != nil {
+		log.Write(fmt.Sprintf("failed to fetch 2FA enrolment: %v", err))
+		return handlerResponse[RecoveryCodesResponse]{Status: http.StatusInternalServerError}
+	} else if twoFactor.Secret == "" || twoFactor.Enabled {
+		return handlerResponse[RecoveryCodesResponse]{Status: http.StatusNotAcceptable}
+	}
+
+	step, ok := totp.Validate(twoFactor.Secret, form.Code, time.Now())
+	if !ok {
+		return handlerResponse[RecoveryCodesResponse]{Status: http.StatusUnauthorized}
+	}
+
+	codes, err := totp.GenerateRecoveryCodes(recoveryCodeCount)
+	if err == nil {
+		hashes := make([]string, len(codes))
+		for i, code := range codes {
+			hashes[i] = totp.HashRecoveryCode(code)
+		}
+
+		err = twoFactorRepo.Enable(person.UID, step, hashes)
+	}
+
+	if err != nil {
+		log.Write(fmt.Sprintf("failed to enable 2FA: %v", err))
+		return handlerResponse[RecoveryCodesResponse]{Status: http.StatusInternalServerError}
+	}
+
+	if enrolling {
+		completeLogin(w, r, df, repositories.LoginAttempt{Email: person.Email, IPAddress: getClientIP(r)}, getSessionFrontend(r))
+	}
+
+	log.Write(fmt.Sprintf("enabled 2FA for user %d", person.UID))
+	return handlerResponse[RecoveryCodesResponse]{
+		Status:   http.StatusOK,
+		Response: RecoveryCodesResponse{RecoveryCodes: codes},
+	}
+}
+
+// DisableTwoFactor disables 2FA for the current user, they must provide a valid code to do so. Users
+// that are required to use 2FA by the frontend's policy cannot disable it
+func DisableTwoFactor(form ValidTwoFactorCodeRequest, w http.ResponseWriter, r *http.Request, df DependencyFactory) handlerResponse[empty] {
+	log := df.GetLogger()
+	person, _, status := getSessionPerson(r, df, false)
+	if status != http.StatusOK {
+		return handlerResponse[empty]{Status: status}
+	}
+
+	if required, err := requiresTwoFactor(df, person, getSessionFrontend(r)); err != nil || required {
+		if err != nil {
+			log.Write(fmt.Sprintf("failed to determine 2FA policy: %v", err))
+			return handlerResponse[empty]{Status: http.StatusInternalServerError}
+		}
+
+		return handlerResponse[empty]{Status: http.StatusForbidden}
+	}
+
+	accepted, err := checkSecondFactor(df, person.UID, ValidTwoFactorLoginRequest{Code: form.Code})
+	if err != nil {
+		log.Write(fmt.Sprintf("failed to check second factor: %v", err))
+		return handlerResponse[empty]{Status: http.StatusInternalServerError}
+	} else if !accepted {
+		return handlerResponse[empty]{Status: http.StatusUnauthorized}
+	}
+
+	if err := df.GetTwoFactorRepo().Disable(person.UID); err != nil {
+		log.Write(fmt.Sprintf("failed to disable 2FA: %v", err))
+		return handlerResponse[empty]{Status: http.StatusInternalServerError}
+	}
+
+	log.Write(fmt.Sprintf("disabled 2FA for user %d", person.UID))
+	return handlerResponse[empty]{Status: http.StatusOK}
+}
+
+// checkSecondFactor validates either a TOTP code or a recovery code against a user's enrolment,
+// accepted codes are marked as used so they cannot be replayed
+func checkSecondFactor(df DependencyFactory, UID int, form ValidTwoFactorLoginRequest) (bool, error) {
+	twoFactorRepo := df.GetTwoFactorRepo()
+	twoFactor, err := twoFactorRepo.GetTwoFactor(UID)
+	if err != nil || !twoFactor.Enabled {
+		return false, err
+	}
+
+	if form.RecoveryCode != "" {
+		return twoFactorRepo.ConsumeRecoveryCode(UID, totp.HashRecoveryCode(form.RecoveryCode))
+	}
+
+	step, ok := totp.Validate(twoFactor.Secret, form.Code, time.Now())
+	if !ok {
+		return false, nil
+	}
+
+	return twoFactorRepo.MarkStepUsed(UID, step)
+}
+
+// requiresTwoFactor determines if the frontend requires the person to use 2FA, currently frontends can only require
+// 2FA for members of the admin group
+func requiresTwoFactor(df DependencyFactory, person repositories.Person, frontendID uuid.UUID) (bool, error) {
+	isAdmin, err := df.GetGroupsRepo().IsMemberOf(person.UID, repositories.GROUPS_ADMIN)
+	if err != nil || !isAdmin {
+		return false, err
+	}
+
+	return df.GetFrontendsRepo().RequiresAdminTwoFactor(frontendID)
+}
+
+// getSessionFrontend fetches the frontend the current session (or login that is still in progress) belongs to
+func getSessionFrontend(r *http.Request) uuid.UUID {
+	if user, err := session.GetSessionUser(r); err == nil {
+		return user.FrontendID
+	}
+
+	pending, _ := session.GetPendingUser(r)
+	return pending.FrontendID
+}
+
+// getSessionPerson fetches the person the current session belongs to, if allowEnrolling is set then users
+// that are part way through logging in because they must enrol in 2FA are accepted too (and reported as enrolling)
+func getSessionPerson(r *http.Request, df DependencyFactory, allowEnrolling bool) (repositories.Person, bool, int) {
+	email, enrolling := "", false
+	if user, err := session.GetSessionUser(r); err == nil {
+		email = user.Email
+	} else if pending, err := session.GetPendingUser(r); err == nil && pending.Enrolling && allowEnrolling {
+		email, enrolling = pending.Email, true
+	} else {
+		return repositories.Person{}, false, http.StatusUnauthorized
+	}
+
+	person, err := df.GetPersonsRepo().GetPersonWithEmail(email)
+	if err != nil {
+		return repositories.Person{}, false, http.StatusUnauthorized
+	}
+
+	return person, enrolling, http.StatusOK
+}
@@ -2,12 +2,17 @@ package endpoints
 
 import (
 	"bytes"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"os"
 	"strings"
 
+	"cms.csesoc.unsw.edu.au/algorithms"
 	"cms.csesoc.unsw.edu.au/database/repositories"
+	"cms.csesoc.unsw.edu.au/editor/OT/datamodel"
 	. "cms.csesoc.unsw.edu.au/endpoints/models"
 )
 
@@ -76,6 +81,17 @@ func UploadDocument(form ValidDocumentUploadRequest, df DependencyFactory) handl
 
 	log := df.GetLogger()
 
+	// directories with a document type only accept documents matching it
+	if status, problems := validateDocument(df, form.Parent, []byte(form.Content)); status != http.StatusOK {
+		return handlerResponse[NewEntityResponse]{Status: status, Errors: problems}
+	}
+
+	// uploaded documents are written with the current datamodel
+	content, err := datamodel.Upgrades.Stamp([]byte(form.Content))
+	if err != nil {
+		return handlerResponse[NewEntityResponse]{Status: http.StatusUnprocessableEntity, Errors: []string{err.Error()}}
+	}
+
 	// fetch the target file form the unpublished volume
 	entityToCreate := repositories.FilesystemEntry{
 		LogicalName: form.DocumentName, ParentFileID: form.Parent,
@@ -100,8 +116,8 @@ func UploadDocument(form ValidDocumentUploadRequest, df DependencyFactory) handl
 		}
 	}
 
-	bytes, err := file.WriteString(form.Content)
-	if (bytes == 0 && len(form.Content) != 0) || err != nil {
+	bytes, err := file.Write(content)
+	if (bytes == 0 && len(content) != 0) || err != nil {
 		log.Write("was an error writing to file")
 		log.Write(err.Error())
 		return handlerResponse[NewEntityResponse]{
@@ -120,6 +136,10 @@ func PublishDocument(form ValidPublishDocumentRequest, df DependencyFactory) han
 	unpublishedVol := df.GetUnpublishedVolumeRepo()
 	publishedVol := df.GetPublishedVolumeRepo()
 	log := df.GetLogger()
+	fsRepo, err := df.GetFilesystemRepo()
+	if err != nil {
+		return handlerResponse[empty]{Status: http.StatusNotFound}
+	}
 
 	// fetch the target file form the unpublished volume
 	filename := form.DocumentID.String()
@@ -132,7 +152,40 @@ func PublishDocument(form ValidPublishDocumentRequest, df DependencyFactory) han
 		}
 	}
 
+	// documents that don't match the type of their directory can't be published
+	entity, err := fsRepo.GetEntryWithID(form.DocumentID)
+	if err != nil {
+		file.Close()
+		return handlerResponse[empty]{Status: http.StatusNotFound}
+	}
+
+	content, err := io.ReadAll(file)
+	if err != nil {
+		file.Close()
+		log.Write(fmt.Sprintf("failed to read file: %s", err.Error()))
+		return handlerResponse[empty]{Status: http.StatusInternalServerError}
+	}
+
+	if status, problems := validateDocument(df, entity.ParentFileID, content); status != http.StatusOK {
+		file.Close()
+		return handlerResponse[empty]{Status: status, Errors: problems}
+	}
+
+	// documents written with an older datamodel are upgraded before they are published
+	if upgraded, changed, err := datamodel.Upgrades.Upgrade(content); err != nil {
+		file.Close()
+		log.Write(fmt.Sprintf("failed to upgrade document %s: %s", filename, err.Error()))
+		return handlerResponse[empty]{Status: http.StatusInternalServerError}
+	} else if changed {
+		if err := overwriteFile(file, upgraded); err != nil {
+			file.Close()
+			log.Write(fmt.Sprintf("failed to save the upgraded document %s: %s", filename, err.Error()))
+			return handlerResponse[empty]{Status: http.StatusInternalServerError}
+		}
+	}
+
 	// Copy over to the target volume
+	file.Seek(0, io.SeekStart)
 	err = publishedVol.CopyToVolume(file, filename)
 	if err != nil {
 		log.Write("failed to copy file to published volume")
@@ -141,11 +194,23 @@ func PublishDocument(form ValidPublishDocumentRequest, df DependencyFactory) han
 			Status: http.StatusInternalServerError,
 		}
 	}
-	return handlerResponse[empty]{}
+	return handlerResponse[empty]{Status: http.StatusOK}
 }
 
 const emptyFile string = "{}"
 
+// overwriteFile replaces the contents of a file
+func overwriteFile(file *os.File, contents []byte) error {
+	if err := file.Truncate(0); err != nil {
+		return err
+	} else if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	_, err := file.Write(contents)
+	return err
+}
+
 // GetPublishedDocument retrieves the contents of a published document from the published docker volume
 func GetPublishedDocument(form ValidGetPublishedDocumentRequest, df DependencyFactory) handlerResponse[[]byte] {
 	publishedVol := df.GetPublishedVolumeRepo()
@@ -172,6 +237,16 @@ func GetPublishedDocument(form ValidGetPublishedDocumentRequest, df DependencyFa
 		buf.WriteString(emptyFile)
 	}
 
+	// documents written with an older datamodel are upgraded as they are served, the stored copy is only upgraded
+	// when the document is next published (or by the upgrade-documents command)
+	if upgraded, changed, err := datamodel.Upgrades.Upgrade(buf.Bytes()); err != nil {
+		log.Write(fmt.Sprintf("failed to upgrade document %s: %s", filename, err.Error()))
+		return handlerResponse[[]byte]{Status: http.StatusInternalServerError}
+	} else if changed {
+		buf.Reset()
+		buf.Write(upgraded)
+	}
+
 	// Will return "text/..." if file contains text / json
 	contentType := http.DetectContentType(buf.Bytes())
 
@@ -187,3 +262,116 @@ func GetPublishedDocument(form ValidGetPublishedDocumentRequest, df DependencyFa
 		ContentType: contentType,
 	}
 }
+
+// diffContentTypes maps the formats a diff can be rendered in to the content type it is served with
+var diffContentTypes = map[string]string{
+	"unified": "text/plain; charset=utf-8",
+	"html":    "text/html; charset=utf-8",
+}
+
+// defaultDiffContext is the number of unchanged lines shown around each change if a request doesn't specify it
+const defaultDiffContext = 3
+
+// DiffDocument compares the published version of a document with its unpublished version (what would be published
+// next), both versions are upgraded to the current datamodel and formatted the same way before they are compared
+// word by word so only changes to the document's contents show up. Documents that haven't been published yet are
+// compared against an empty document
+func DiffDocument(form ValidDocumentDiffRequest, df DependencyFactory) handlerResponse[[]byte] {
+	format := form.Format
+	if format == "" {
+		format = "unified"
+	}
+
+	contentType, ok := diffContentTypes[format]
+	if !ok {
+		return handlerResponse[[]byte]{
+			Status: http.StatusBadRequest,
+			Errors: []string{fmt.Sprintf("unknown format %q, expected unified or html", form.Format)},
+		}
+	}
+
+	context := defaultDiffContext
+	if form.Context != nil {
+		context = *form.Context
+	}
+
+	if context < 0 {
+		return handlerResponse[[]byte]{Status: http.StatusBadRequest, Errors: []string{"context can't be negative"}}
+	}
+
+	log := df.GetLogger()
+	fsRepo, err := df.GetFilesystemRepo()
+	if err != nil {
+		return handlerResponse[[]byte]{Status: http.StatusNotFound}
+	}
+
+	// the volumes only hold documents that exist in the filesystem, checking first means we never go looking for others
+	if _, err := fsRepo.GetEntryWithID(form.DocumentID); err != nil {
+		return handlerResponse[[]byte]{Status: http.StatusNotFound}
+	}
+
+	filename := form.DocumentID.String()
+	published, err := readDocumentText(df.GetPublishedVolumeRepo(), filename)
+	if err != nil {
+		log.Write(fmt.Sprintf("failed to read the published version of %s: %s", filename, err.Error()))
+		return handlerResponse[[]byte]{Status: http.StatusInternalServerError}
+	}
+
+	unpublished, err := readDocumentText(df.GetUnpublishedVolumeRepo(), filename)
+	if err != nil {
+		log.Write(fmt.Sprintf("failed to read the unpublished version of %s: %s", filename, err.Error()))
+		return handlerResponse[[]byte]{Status: http.StatusInternalServerError}
+	}
+
+	diff := algorithms.WordDiff(published, unpublished)
+	rendered := diff.Unified("published", "unpublished", context)
+	if format == "html" {
+		rendered = diff.SideBySide("published", "unpublished", context)
+	}
+
+	return handlerResponse[[]byte]{
+		Status:      http.StatusOK,
+		Response:    []byte(rendered),
+		ContentType: contentType,
+	}
+}
+
+// readDocumentText reads a document from a volume as text that can be diffed, JSON documents are upgraded to the
+// current datamodel and formatted with sorted keys and each value on its own line. Documents that aren't in the
+// volume (eg. they haven't been published yet) are empty, reading them doesn't create them
+func readDocumentText(volume repositories.UnpublishedVolumeRepository, filename string) (string, error) {
+	file, err := volume.ReadFromVolume(filename)
+	if errors.Is(err, os.ErrNotExist) {
+		return "", nil
+	} else if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	contents, err := io.ReadAll(file)
+	if err != nil {
+		return "", err
+	}
+
+	// the upgrade isn't persisted, reading a document shouldn't change it
+	if upgraded, _, err := datamodel.Upgrades.Upgrade(contents); err == nil {
+		contents = upgraded
+	}
+
+	var document interface{}
+	decoder := json.NewDecoder(bytes.NewReader(contents))
+	decoder.UseNumber()
+	if decoder.Decode(&document) != nil {
+		return string(contents), nil
+	}
+
+	formatted := strings.Builder{}
+	encoder := json.NewEncoder(&formatted)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(document); err != nil {
+		return "", err
+	}
+
+	return formatted.String(), nil
+}
@@ -2,6 +2,8 @@ package environment
 
 import (
 	"os"
+	"strconv"
+	"time"
 )
 
 func GetFrontendURI() string {
@@ -27,3 +29,73 @@ func GetDB() string {
 func GetDBPort() string {
 	return os.Getenv("PG_PORT")
 }
+
+func GetTokenSecret() string {
+	return os.Getenv("TOKEN_SECRET")
+}
+
+// GetSessionKey is the key session cookies are signed with, the backend refuses to start without one (outside of dev mode)
+func GetSessionKey() string {
+	return os.Getenv("SESSION_KEY")
+}
+
+func GetSMTPHost() string {
+	return os.Getenv("SMTP_HOST")
+}
+
+func GetSMTPPort() string {
+	return os.Getenv("SMTP_PORT")
+}
+
+func GetSMTPUser() string {
+	return os.Getenv("SMTP_USER")
+}
+
+func GetSMTPPassword() string {
+	return os.Getenv("SMTP_PASSWORD")
+}
+
+func GetMailSender() string {
+	return os.Getenv("MAIL_FROM")
+}
+
+// GetMailOutputFile is the file the development mailer appends outgoing mail to,
+// if it is unset mail is just written to the log
+func GetMailOutputFile() string {
+	return os.Getenv("MAIL_OUTPUT_FILE")
+}
+
+// GetTrustedProxyHops is the number of reverse proxies in front of the backend that append to the X-Forwarded-For
+// header, it is read from TRUSTED_PROXY_HOPS. Setting TRUST_PROXY_HEADERS=true is the same as a single proxy, by
+// default the header is ignored. This should only be set if the backend is only reachable through those proxies
+func GetTrustedProxyHops() int {
+	if hops, err := strconv.Atoi(os.Getenv("TRUSTED_PROXY_HOPS")); err == nil && hops >= 0 {
+		return hops
+	} else if os.Getenv("TRUST_PROXY_HEADERS") == "true" {
+		return 1
+	}
+
+	return 0
+}
+
+// GetMigrateOnStartup determines if the backend should apply pending database migrations when it starts,
+// deployments that run migrations separately (eg: via `backend migrate up`) can disable this
+func GetMigrateOnStartup() bool {
+	return os.Getenv("MIGRATE_ON_STARTUP") != "false"
+}
+
+// GetDBQueryTimeout is the longest a single database query may run for before it is cancelled,
+// it is read from DB_QUERY_TIMEOUT (eg. "5s") and defaults to 5 seconds
+func GetDBQueryTimeout() time.Duration {
+	if timeout, err := time.ParseDuration(os.Getenv("DB_QUERY_TIMEOUT")); err == nil && timeout > 0 {
+		return timeout
+	}
+
+	return 5 * time.Second
+}
+
+// GetComponentSchemaDirectory is the directory holding the component schemas declared by each frontend (see
+// operations.LoadRegistry), if it is unset every frontend just uses the built in components
+func GetComponentSchemaDirectory() string {
+	return os.Getenv("COMPONENT_SCHEMA_DIRECTORY")
+}
@@ -5,6 +5,9 @@ import (
 	"testing"
 )
 
+// devMode is set by the --dev flag, it has to be defined here as the flags are parsed when this package is initialised
+var devMode = flag.Bool("dev", false, "run the backend against an in-memory database and local volumes (no Postgres or docker required)")
+
 func init() {
 	testing.Init()
 	flag.Parse()
@@ -14,3 +17,8 @@ func init() {
 func IsTestingEnvironment() bool {
 	return flag.Lookup("test.v") != nil
 }
+
+// IsDevMode determines if the backend was started with --dev
+func IsDevMode() bool {
+	return *devMode
+}
@@ -0,0 +1,178 @@
+// Package doctypes validates documents against the document type attached to the directory they live in
+// (see repositories.DocumentType). A typed document is a JSON object, every field declared by its type must
+// hold a value of the declared kind and required fields must be present. Fields the type doesn't declare
+// are left alone so that documents can carry additional data (eg: editor state).
+//
+// Validation failures are reported as cmsjson.ValidationErrors (just like values breaking the rules of the
+// datamodel), each identifying the offending value with an RFC 6901 JSON pointer (eg: /Content/3) so that
+// clients can point the user at the problem.
+package doctypes
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"cms.csesoc.unsw.edu.au/database/repositories"
+	"cms.csesoc.unsw.edu.au/pkg/cmsjson"
+	"github.com/google/uuid"
+)
+
+// MaxFieldNameLength is the longest name a field of a document type can have
+const MaxFieldNameLength = 64
+
+// Validate validates the contents of a document against a document type, documents within directories without a
+// type (ie. the type's TypeID is repositories.NoDocumentType) are always valid
+func Validate(documentType repositories.DocumentType, content []byte) error {
+	if documentType.TypeID == repositories.NoDocumentType {
+		return nil
+	}
+
+	var document map[string]interface{}
+	if err := json.Unmarshal(content, &document); err != nil || document == nil {
+		return cmsjson.ValidationErrors{{Path: "", Message: fmt.Sprintf("a %s must be a JSON object", documentType.Name)}}
+	}
+
+	errors := cmsjson.ValidationErrors{}
+	for _, field := range documentType.Fields {
+		path := "/" + cmsjson.EscapePointer(field.Name)
+		value, ok := document[field.Name]
+		if !ok || value == nil || value == "" {
+			if field.Required {
+				errors = append(errors, cmsjson.ValidationError{Path: path, Message: "required field is missing"})
+			}
+
+			continue
+		}
+
+		errors = append(errors, validateValue(path, field.Kind, value)...)
+	}
+
+	if len(errors) > 0 {
+		return errors
+	}
+
+	return nil
+}
+
+// ValidateDefinition checks that a document type is well formed, ie. it has a name and its fields have unique
+// names and known kinds
+func ValidateDefinition(documentType repositories.DocumentType) error {
+	errors := cmsjson.ValidationErrors{}
+	if strings.TrimSpace(documentType.Name) == "" {
+		errors = append(errors, cmsjson.ValidationError{Path: "/Name", Message: "a document type must have a name"})
+	}
+
+	seen := map[string]bool{}
+	for i, field := range documentType.Fields {
+		path := fmt.Sprintf("/Fields/%d", i)
+		switch {
+		case strings.TrimSpace(field.Name) == "" || len(field.Name) > MaxFieldNameLength:
+			errors = append(errors, cmsjson.ValidationError{Path: path + "/Name", Message: fmt.Sprintf("field names must be 1 to %d characters long", MaxFieldNameLength)})
+		case seen[field.Name]:
+			errors = append(errors, cmsjson.ValidationError{Path: path + "/Name", Message: fmt.Sprintf("%q is declared more than once", field.Name)})
+		}
+
+		if !isKnownKind(field.Kind) {
+			errors = append(errors, cmsjson.ValidationError{Path: path + "/Kind", Message: fmt.Sprintf("unknown kind %q", field.Kind)})
+		}
+
+		seen[field.Name] = true
+	}
+
+	if len(errors) > 0 {
+		return errors
+	}
+
+	return nil
+}
+
+// validateValue checks that a (non-null) value within a document has the expected kind
+func validateValue(path string, kind repositories.FieldKind, value interface{}) []cmsjson.ValidationError {
+	invalid := func(message string) []cmsjson.ValidationError {
+		return []cmsjson.ValidationError{{Path: path, Message: message}}
+	}
+
+	switch kind {
+	case repositories.FieldString:
+		if _, ok := value.(string); !ok {
+			return invalid("expected a string")
+		}
+
+	case repositories.FieldNumber:
+		if _, ok := value.(float64); !ok {
+			return invalid("expected a number")
+		}
+
+	case repositories.FieldBoolean:
+		if _, ok := value.(bool); !ok {
+			return invalid("expected a boolean")
+		}
+
+	case repositories.FieldDate:
+		if date, ok := value.(string); !ok || !isDate(date) {
+			return invalid("expected a date (YYYY-MM-DD) or an RFC 3339 timestamp")
+		}
+
+	case repositories.FieldImage:
+		if imageID, ok := value.(string); !ok || !isUUID(imageID) {
+			return invalid("expected the ID of an image")
+		}
+
+	case repositories.FieldURL:
+		if link, ok := value.(string); !ok || !isHTTPURL(link) {
+			return invalid("expected an absolute http(s) URL")
+		}
+
+	case repositories.FieldBlocks:
+		blocks, ok := value.([]interface{})
+		if !ok {
+			return invalid("expected an array of blocks")
+		}
+
+		errors := []cmsjson.ValidationError{}
+		for i, block := range blocks {
+			if _, ok := block.(map[string]interface{}); !ok {
+				errors = append(errors, cmsjson.ValidationError{Path: fmt.Sprintf("%s/%d", path, i), Message: "expected a block (JSON object)"})
+			}
+		}
+
+		return errors
+
+	default:
+		return invalid(fmt.Sprintf("field has an unknown kind %q", kind))
+	}
+
+	return nil
+}
+
+func isKnownKind(kind repositories.FieldKind) bool {
+	switch kind {
+	case repositories.FieldString, repositories.FieldNumber, repositories.FieldBoolean, repositories.FieldDate,
+		repositories.FieldImage, repositories.FieldURL, repositories.FieldBlocks:
+		return true
+	}
+
+	return false
+}
+
+func isDate(value string) bool {
+	if _, err := time.Parse("2006-01-02", value); err == nil {
+		return true
+	}
+
+	_, err := time.Parse(time.RFC3339, value)
+	return err == nil
+}
+
+func isUUID(value string) bool {
+	_, err := uuid.Parse(value)
+	return err == nil
+}
+
+func isHTTPURL(value string) bool {
+	parsed, err := url.Parse(value)
+	return err == nil && (parsed.Scheme == "http" || parsed.Scheme == "https") && parsed.Host != ""
+}
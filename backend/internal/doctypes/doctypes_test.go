@@ -0,0 +1,80 @@
+package doctypes
+
+import (
+	"testing"
+
+	"cms.csesoc.unsw.edu.au/database/repositories"
+	"cms.csesoc.unsw.edu.au/pkg/cmsjson"
+	"github.com/stretchr/testify/assert"
+)
+
+var blogPost = repositories.DocumentType{
+	TypeID: 1,
+	Name:   "blog post",
+	Fields: []repositories.DocumentField{
+		{Name: "title", Kind: repositories.FieldString, Required: true},
+		{Name: "author", Kind: repositories.FieldString, Required: true},
+		{Name: "date", Kind: repositories.FieldDate, Required: true},
+		{Name: "coverImage", Kind: repositories.FieldImage, Required: true},
+		{Name: "canonical/url", Kind: repositories.FieldURL},
+		{Name: "content", Kind: repositories.FieldBlocks},
+	},
+}
+
+func TestValidateAcceptsMatchingDocuments(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Nil(Validate(blogPost, []byte(`{
+		"title": "Hello", "author": "Jane", "date": "2022-07-01", "coverImage": "d1a4bbd8-5c2d-4b4c-9e3a-6a8d0ef2a1f0",
+		"content": [{"type": "paragraph"}], "somethingElse": 3
+	}`)))
+
+	// untyped directories accept anything
+	assert.Nil(Validate(repositories.DocumentType{TypeID: repositories.NoDocumentType}, []byte(`[1, 2, 3]`)))
+}
+
+func TestValidateReportsPaths(t *testing.T) {
+	assert := assert.New(t)
+
+	err := Validate(blogPost, []byte(`{
+		"title": 3, "author": "", "date": "last tuesday", "coverImage": "d1a4bbd8-5c2d-4b4c-9e3a-6a8d0ef2a1f0",
+		"canonical/url": "javascript:alert(1)", "content": [{}, "oops"]
+	}`))
+
+	if assert.IsType(cmsjson.ValidationErrors{}, err) {
+		assert.Equal([]string{
+			"/title: expected a string",
+			"/author: required field is missing",
+			"/date: expected a date (YYYY-MM-DD) or an RFC 3339 timestamp",
+			"/canonical~1url: expected an absolute http(s) URL",
+			"/content/1: expected a block (JSON object)",
+		}, err.(cmsjson.ValidationErrors).Messages())
+	}
+
+	err = Validate(blogPost, []byte(`["not", "an", "object"]`))
+	if assert.IsType(cmsjson.ValidationErrors{}, err) {
+		assert.Equal([]string{"a blog post must be a JSON object"}, err.(cmsjson.ValidationErrors).Messages())
+	}
+}
+
+func TestValidateDefinition(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Nil(ValidateDefinition(blogPost))
+
+	err := ValidateDefinition(repositories.DocumentType{
+		Name: " ",
+		Fields: []repositories.DocumentField{
+			{Name: "title", Kind: repositories.FieldString},
+			{Name: "title", Kind: "colour"},
+		},
+	})
+
+	if assert.IsType(cmsjson.ValidationErrors{}, err) {
+		assert.Equal([]string{
+			"/Name: a document type must have a name",
+			`/Fields/1/Name: "title" is declared more than once`,
+			`/Fields/1/Kind: unknown kind "colour"`,
+		}, err.(cmsjson.ValidationErrors).Messages())
+	}
+}
@@ -0,0 +1,51 @@
+package mailer
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+// @implements Mailer
+// fileMailer never actually delivers anything, it just appends the mail to a file
+// (or the log if no file was provided) so that developers can click on links sent during development
+type fileMailer struct {
+	path string
+}
+
+// writeLock is shared by every file mailer since they could all be writing to the same file
+var writeLock = sync.Mutex{}
+
+// NewFileMailer constructs a mailer that appends all mail to the file at path,
+// an empty path means that all mail is just written to the log
+func NewFileMailer(path string) Mailer {
+	return fileMailer{path: path}
+}
+
+// Send writes the message out to the configured file
+func (m fileMailer) Send(message Message) error {
+	formatted := fmt.Sprintf("== mail sent at %s ==\nTo: %s\nSubject: %s\n\n%s\n== end of mail ==\n",
+		time.Now().Format(time.RFC3339), message.To, message.Subject, message.Body)
+
+	writeLock.Lock()
+	defer writeLock.Unlock()
+
+	if m.path == "" {
+		log.Print(formatted)
+		return nil
+	}
+
+	file, err := os.OpenFile(m.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open mail output file: %w", err)
+	}
+	defer file.Close()
+
+	if _, err := file.WriteString(formatted); err != nil {
+		return fmt.Errorf("failed to write mail to output file: %w", err)
+	}
+
+	return nil
+}
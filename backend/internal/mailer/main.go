@@ -0,0 +1,42 @@
+// Package mailer is a small abstraction over the sending of emails, the CMS only ever needs to send
+// simple plaintext emails (password resets, verification links, etc) so the interface is tiny.
+// There are two implementations:
+//   - smtpMailer: actually delivers mail through an SMTP relay, used in production
+//   - fileMailer: appends mail to a file (or the log), used in development and testing
+package mailer
+
+//go:generate mockgen -source=main.go -destination=mocks/mailer_mock.go -package=mocks
+
+import (
+	"cms.csesoc.unsw.edu.au/environment"
+)
+
+type (
+	// Message is a single plaintext email
+	Message struct {
+		To      string
+		Subject string
+		Body    string
+	}
+
+	// Mailer is the interface all mail backends must implement
+	Mailer interface {
+		Send(message Message) error
+	}
+)
+
+// GetMailer returns the mailer appropriate for the current environment, if an SMTP host
+// has been configured then mail is delivered via SMTP otherwise it falls back to the file mailer
+func GetMailer() Mailer {
+	if host := environment.GetSMTPHost(); host != "" {
+		return NewSMTPMailer(SMTPConfig{
+			Host:     host,
+			Port:     environment.GetSMTPPort(),
+			Username: environment.GetSMTPUser(),
+			Password: environment.GetSMTPPassword(),
+			From:     environment.GetMailSender(),
+		})
+	}
+
+	return NewFileMailer(environment.GetMailOutputFile())
+}
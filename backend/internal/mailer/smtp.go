@@ -0,0 +1,71 @@
+package mailer
+
+import (
+	"fmt"
+	"net/smtp"
+	"strings"
+)
+
+// SMTPConfig contains everything required to connect to an SMTP relay
+type SMTPConfig struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+}
+
+// @implements Mailer
+type smtpMailer struct {
+	config SMTPConfig
+
+	// sendMail is swapped out in tests, it defaults to smtp.SendMail
+	sendMail func(addr string, a smtp.Auth, from string, to []string, msg []byte) error
+}
+
+// NewSMTPMailer constructs a mailer that delivers mail via the provided SMTP relay
+func NewSMTPMailer(config SMTPConfig) Mailer {
+	if config.Port == "" {
+		config.Port = "587"
+	}
+
+	return smtpMailer{
+		config:   config,
+		sendMail: smtp.SendMail,
+	}
+}
+
+// Send delivers a message via the configured SMTP relay, note that if no username is
+// configured then no authentication is attempted (useful for local relays)
+func (m smtpMailer) Send(message Message) error {
+	var auth smtp.Auth = nil
+	if m.config.Username != "" {
+		auth = smtp.PlainAuth("", m.config.Username, m.config.Password, m.config.Host)
+	}
+
+	addr := fmt.Sprintf("%s:%s", m.config.Host, m.config.Port)
+	if err := m.sendMail(addr, auth, m.config.From, []string{message.To}, m.buildMessage(message)); err != nil {
+		return fmt.Errorf("failed to send mail to %s: %w", message.To, err)
+	}
+
+	return nil
+}
+
+// buildMessage constructs the RFC 5322 body of the email
+func (m smtpMailer) buildMessage(message Message) []byte {
+	builder := strings.Builder{}
+	builder.WriteString(fmt.Sprintf("From: %s\r\n", m.config.From))
+	builder.WriteString(fmt.Sprintf("To: %s\r\n", sanitiseHeader(message.To)))
+	builder.WriteString(fmt.Sprintf("Subject: %s\r\n", sanitiseHeader(message.Subject)))
+	builder.WriteString("MIME-Version: 1.0\r\n")
+	builder.WriteString("Content-Type: text/plain; charset=\"utf-8\"\r\n")
+	builder.WriteString("\r\n")
+	builder.WriteString(message.Body)
+
+	return []byte(builder.String())
+}
+
+// sanitiseHeader strips any newlines from a header value to prevent header injection
+func sanitiseHeader(value string) string {
+	return strings.NewReplacer("\r", "", "\n", "").Replace(value)
+}
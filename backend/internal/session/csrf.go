@@ -0,0 +1,70 @@
+package session
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"net/http"
+)
+
+// CSRF protection uses the synchronizer token pattern, every session is issued a random token
+// that is stored within the (signed) session cookie. Clients must echo the token back in a header,
+// a cross-site attacker can make the browser send the cookie but has no way of reading the token.
+
+const csrfKey = "csrf-token"
+
+// HasSession determines if a request carries a session cookie, ie. whether or not the
+// browser would implicitly authenticate it
+func HasSession(r *http.Request) bool {
+	_, err := r.Cookie(cookie_prefix)
+	return err == nil
+}
+
+// RemoveSessionCookie strips the session cookie from a request, so the request is treated as though it has no session
+func RemoveSessionCookie(r *http.Request) {
+	cookies := r.Cookies()
+	r.Header.Del("Cookie")
+	for _, cookie := range cookies {
+		if cookie.Name != cookie_prefix {
+			r.AddCookie(cookie)
+		}
+	}
+}
+
+// GetCSRFToken fetches the CSRF token of the current session, if the session does not
+// have a token yet (or doesn't exist) then a new one is issued
+func GetCSRFToken(w http.ResponseWriter, r *http.Request) (string, error) {
+	session, err := store.Get(r, cookie_prefix)
+	if err != nil {
+		return "", errors.New("session error")
+	}
+
+	if token, ok := session.Values[csrfKey].(string); ok && token != "" {
+		return token, nil
+	}
+
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+
+	token := base64.RawURLEncoding.EncodeToString(raw)
+	session.Values[csrfKey] = token
+	if err := session.Save(r, w); err != nil {
+		return "", err
+	}
+
+	return token, nil
+}
+
+// ValidCSRFToken checks a token provided by the client against the one stored within their session
+func ValidCSRFToken(r *http.Request, token string) bool {
+	session, err := store.Get(r, cookie_prefix)
+	if err != nil || token == "" {
+		return false
+	}
+
+	expected, ok := session.Values[csrfKey].(string)
+	return ok && subtle.ConstantTimeCompare([]byte(expected), []byte(token)) == 1
+}
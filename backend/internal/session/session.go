@@ -12,25 +12,70 @@ This module handles the session logic
 package session
 
 import (
+	"crypto/rand"
 	"encoding/gob"
 	"errors"
+	"fmt"
 	"log"
 	"net/http"
+	"time"
 
+	"cms.csesoc.unsw.edu.au/environment"
+	"github.com/google/uuid"
 	"github.com/gorilla/sessions"
 )
 
 // global var
 var (
-	// will move this key to be part of environment variable some time else
-	key           = []byte("super-secret-key")
-	store         = sessions.NewCookieStore(key)
+	store         = sessions.NewCookieStore(getKey())
 	cookie_prefix = "session-token"
 )
 
 type User struct {
 	Email         string
 	Authenticated bool
+	// the frontend the user logged into, its 2FA policy applies to the session
+	FrontendID uuid.UUID
+}
+
+// PendingUser is a user that has provided their password but still has to
+// complete two factor authentication (or enrol in it) before they are logged in
+type PendingUser struct {
+	Email      string
+	FrontendID uuid.UUID
+	Enrolling  bool
+	IssuedAt   time.Time
+}
+
+// how long a user has to complete the second step of logging in
+const pendingExpiry = 5 * time.Minute
+
+// minKeyLength is the shortest SESSION_KEY accepted, session cookies are signed with HMAC-SHA256
+const minKeyLength = 32
+
+// CheckKey determines if a usable key to sign sessions with was configured, anyone that knows the key can forge
+// a session for any user so the backend must refuse to start without one
+func CheckKey() error {
+	if key := environment.GetSessionKey(); len(key) < minKeyLength {
+		return fmt.Errorf("SESSION_KEY must be set to at least %d random bytes", minKeyLength)
+	}
+
+	return nil
+}
+
+// getKey fetches the key sessions are signed with from the environment, if no key was configured (only allowed
+// when testing or in dev mode, see CheckKey) a random one is generated so sessions don't survive a restart
+func getKey() []byte {
+	if configured := environment.GetSessionKey(); configured != "" {
+		return []byte(configured)
+	}
+
+	key := make([]byte, minKeyLength)
+	if _, err := rand.Read(key); err != nil {
+		panic(err)
+	}
+
+	return key
 }
 
 func init() {
@@ -43,11 +88,12 @@ func init() {
 
 	//required
 	gob.Register(User{})
+	gob.Register(PendingUser{})
 }
 
 // if session does not exist, create it
 // else return session
-func CreateSession(w http.ResponseWriter, r *http.Request, email string) {
+func CreateSession(w http.ResponseWriter, r *http.Request, email string, frontendID uuid.UUID) {
 	session, err := store.Get(r, cookie_prefix)
 	if err != nil {
 		log.Println("an error has occurred getting session", err.Error())
@@ -58,8 +104,13 @@ func CreateSession(w http.ResponseWriter, r *http.Request, email string) {
 	user := User{
 		Email:         email,
 		Authenticated: true,
+		FrontendID:    frontendID,
 	}
 	session.Values["user"] = user
+	delete(session.Values, "pending")
+	// the token issued before logging in may have been planted by an attacker (eg. by fixating the session), so
+	// the user is issued a new one the next time they fetch it
+	delete(session.Values, csrfKey)
 
 	// save session
 	err = session.Save(r, w)
@@ -70,6 +121,44 @@ func CreateSession(w http.ResponseWriter, r *http.Request, email string) {
 
 }
 
+// creates a session for a user that still has to complete 2FA, note that
+// this does NOT authenticate the user
+func CreatePendingSession(w http.ResponseWriter, r *http.Request, email string, frontendID uuid.UUID, enrolling bool) {
+	session, err := store.Get(r, cookie_prefix)
+	if err != nil {
+		log.Println("an error has occurred getting session", err.Error())
+		return
+	}
+
+	session.Values["pending"] = PendingUser{
+		Email:      email,
+		FrontendID: frontendID,
+		Enrolling:  enrolling,
+		IssuedAt:   time.Now(),
+	}
+
+	err = session.Save(r, w)
+	if err != nil {
+		log.Println("an error has occurred saving session", err.Error())
+		return
+	}
+}
+
+// fetches the pending user associated with the current session
+func GetPendingUser(r *http.Request) (PendingUser, error) {
+	store, err := store.Get(r, cookie_prefix)
+	if err != nil {
+		return PendingUser{}, errors.New("session error")
+	}
+
+	pending, ok := store.Values["pending"].(PendingUser)
+	if !ok || time.Since(pending.IssuedAt) > pendingExpiry {
+		return PendingUser{}, errors.New("no pending login")
+	}
+
+	return pending, nil
+}
+
 // delete session
 func RemoveSession(w http.ResponseWriter, r *http.Request) {
 	session, err := store.Get(r, cookie_prefix)
@@ -101,6 +190,21 @@ func IsAuthenticated(w http.ResponseWriter, r *http.Request) (bool, error) {
 
 }
 
+// fetches the user associated with the current session
+func GetSessionUser(r *http.Request) (User, error) {
+	store, err := store.Get(r, cookie_prefix)
+	if err != nil {
+		return User{}, errors.New("session error")
+	}
+
+	user := getUsers(store)
+	if !user.Authenticated {
+		return User{}, errors.New("User is not authenticated")
+	}
+
+	return user, nil
+}
+
 // returns user from session store
 // if there exists such a user
 func getUsers(s *sessions.Session) User {
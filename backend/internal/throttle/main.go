@@ -0,0 +1,232 @@
+// Package throttle implements failure based throttling, it is used to protect endpoints (mainly /login)
+// from brute force attacks. Every failure against a key (eg. an account or an IP address) increases the
+// time the client must wait before it can try again (exponential backoff) and once too many failures
+// have been recorded the key is locked out entirely for a period of time.
+//
+// The counters themselves live within a Store, the default store is in-memory but any store that can
+// atomically increment a counter (eg. redis, postgres) can be plugged in to share counters between replicas.
+package throttle
+
+import (
+	"fmt"
+	"time"
+)
+
+type (
+	// Scope distinguishes between the different kinds of keys being throttled (accounts, IP addresses, etc)
+	// each scope has its own policy
+	Scope string
+
+	// Key identifies a single throttled entity
+	Key struct {
+		Scope Scope
+		Value string
+	}
+
+	// Policy describes how aggressively a scope is throttled
+	Policy struct {
+		// FreeAttempts is the number of failures tolerated before any backoff is applied
+		FreeAttempts int
+		// BaseDelay is the delay applied after the first failure beyond FreeAttempts, it doubles with each subsequent failure
+		BaseDelay time.Duration
+		// MaxDelay caps the backoff delay
+		MaxDelay time.Duration
+		// LockoutThreshold is the number of failures after which the key is locked out entirely
+		LockoutThreshold int
+		// LockoutDuration is how long a lockout lasts (measured from the last failure)
+		LockoutDuration time.Duration
+		// Window is how long failures are remembered for, it must be at least LockoutDuration
+		Window time.Duration
+	}
+
+	// Decision is the outcome of checking a set of keys
+	Decision struct {
+		Allowed    bool
+		LockedOut  bool
+		RetryAfter time.Duration
+	}
+
+	// Throttler applies policies to keys using the counters within a store
+	Throttler struct {
+		store    Store
+		policies map[Scope]Policy
+		now      func() time.Time
+	}
+)
+
+// New constructs a throttler, every scope that is used with the throttler must have a policy
+func New(store Store, policies map[Scope]Policy) *Throttler {
+	return &Throttler{
+		store:    store,
+		policies: policies,
+		now:      time.Now,
+	}
+}
+
+// String converts a key into the form used by the store
+func (k Key) String() string {
+	return fmt.Sprintf("%s:%s", k.Scope, k.Value)
+}
+
+// Check determines if a request associated with the provided keys is allowed to proceed,
+// if any of the keys is being throttled the request is denied
+func (t *Throttler) Check(keys ...Key) (Decision, error) {
+	decision := Decision{Allowed: true}
+	now := t.now()
+
+	for _, key := range keys {
+		policy, err := t.getPolicy(key)
+		if err != nil {
+			return Decision{}, err
+		}
+
+		record, err := t.store.Get(key.String())
+		if err != nil {
+			return Decision{}, fmt.Errorf("failed to fetch throttle record for %s: %w", key, err)
+		}
+
+		lockedOut, wait := policy.evaluate(record, now)
+		if wait > 0 {
+			decision.Allowed = false
+			decision.LockedOut = decision.LockedOut || lockedOut
+			if wait > decision.RetryAfter {
+				decision.RetryAfter = wait
+			}
+		}
+	}
+
+	return decision, nil
+}
+
+// Reserve is the atomic version of Check followed by Fail, if the request is allowed to proceed a failure is
+// recorded against every key before the request is actually attempted. Otherwise concurrent requests could all
+// pass the check before any of them have failed and bypass the throttle entirely. If the request turns out not to
+// fail then the failures must be undone with Release
+func (t *Throttler) Reserve(keys ...Key) (Decision, error) {
+	decision := Decision{Allowed: true}
+	reserved := []Key{}
+	now := t.now()
+
+	for _, key := range keys {
+		policy, err := t.getPolicy(key)
+		if err != nil {
+			t.Release(reserved...)
+			return Decision{}, err
+		}
+
+		allowed, err := t.store.Reserve(key.String(), now, policy.Window, func(record Record) bool {
+			lockedOut, wait := policy.evaluate(record, now)
+			if wait > 0 {
+				decision.Allowed = false
+				decision.LockedOut = decision.LockedOut || lockedOut
+				if wait > decision.RetryAfter {
+					decision.RetryAfter = wait
+				}
+			}
+
+			return wait == 0
+		})
+
+		if err != nil {
+			t.Release(reserved...)
+			return Decision{}, fmt.Errorf("failed to reserve an attempt for %s: %w", key, err)
+		} else if allowed {
+			reserved = append(reserved, key)
+		}
+	}
+
+	// every key has been checked so that RetryAfter accounts for all of them
+	if !decision.Allowed {
+		if err := t.Release(reserved...); err != nil {
+			return Decision{}, err
+		}
+	}
+
+	return decision, nil
+}
+
+// Release undoes the failures Reserve recorded against every provided key, it is used once
+// a request that was reserved turns out not to have failed (or shouldn't count against some keys)
+func (t *Throttler) Release(keys ...Key) error {
+	for _, key := range keys {
+		if err := t.store.Release(key.String()); err != nil {
+			return fmt.Errorf("failed to release %s: %w", key, err)
+		}
+	}
+
+	return nil
+}
+
+// Fail records a failed attempt against every provided key
+func (t *Throttler) Fail(keys ...Key) error {
+	now := t.now()
+	for _, key := range keys {
+		policy, err := t.getPolicy(key)
+		if err != nil {
+			return err
+		}
+
+		if _, err := t.store.RecordFailure(key.String(), now, policy.Window); err != nil {
+			return fmt.Errorf("failed to record failure for %s: %w", key, err)
+		}
+	}
+
+	return nil
+}
+
+// Succeed clears the failure history of every provided key
+func (t *Throttler) Succeed(keys ...Key) error {
+	for _, key := range keys {
+		if err := t.store.Reset(key.String()); err != nil {
+			return fmt.Errorf("failed to reset %s: %w", key, err)
+		}
+	}
+
+	return nil
+}
+
+// getPolicy fetches the policy for a key's scope
+func (t *Throttler) getPolicy(key Key) (Policy, error) {
+	policy, ok := t.policies[key.Scope]
+	if !ok {
+		return Policy{}, fmt.Errorf("no throttling policy for scope %s", key.Scope)
+	}
+
+	return policy, nil
+}
+
+// evaluate determines how long a client must wait before the next attempt given the
+// failure record, it also reports if the wait is due to a lockout
+func (p Policy) evaluate(record Record, now time.Time) (bool, time.Duration) {
+	if record.Failures == 0 || (p.Window > 0 && now.Sub(record.LastFailure) > p.Window) {
+		return false, 0
+	}
+
+	if p.LockoutThreshold > 0 && record.Failures >= p.LockoutThreshold {
+		return true, positive(record.LastFailure.Add(p.LockoutDuration).Sub(now))
+	}
+
+	excess := record.Failures - p.FreeAttempts
+	if excess <= 0 {
+		return false, 0
+	}
+
+	delay := p.MaxDelay
+	// guard against overflowing the shift, by then we would have hit MaxDelay anyway
+	if excess < 32 {
+		if backoff := p.BaseDelay << (excess - 1); backoff > 0 && backoff < p.MaxDelay {
+			delay = backoff
+		}
+	}
+
+	return false, positive(record.LastFailure.Add(delay).Sub(now))
+}
+
+// positive clamps a duration to be non-negative
+func positive(d time.Duration) time.Duration {
+	if d < 0 {
+		return 0
+	}
+
+	return d
+}
@@ -0,0 +1,148 @@
+package throttle
+
+import (
+	"sync"
+	"time"
+)
+
+type (
+	// Record is the failure history of a single key
+	Record struct {
+		Failures    int
+		LastFailure time.Time
+	}
+
+	// Store is the interface all counter stores must implement, implementations must be safe
+	// for concurrent use and both RecordFailure and Reserve must be atomic
+	Store interface {
+		// Get fetches the record for a key, a key with no history has an empty record
+		Get(key string) (Record, error)
+		// RecordFailure increments the failure count of a key, the record should be forgotten
+		// once ttl has passed without any further failures
+		RecordFailure(key string, at time.Time, ttl time.Duration) (Record, error)
+		// Reserve calls allow with the current record of a key and records a failure if it returns true, the
+		// two must happen atomically (eg. under a lock or within a single transaction) so that concurrent
+		// reservations always see each other's failures
+		Reserve(key string, at time.Time, ttl time.Duration, allow func(Record) bool) (bool, error)
+		// Release undoes a single failure recorded by Reserve, the time of the last failure is left as is
+		Release(key string) error
+		// Reset forgets the history of a key
+		Reset(key string) error
+	}
+)
+
+// @implements Store
+// memoryStore keeps all counters in memory, it is the default store but note that the
+// counters are neither persisted nor shared between replicas
+type memoryStore struct {
+	lock      sync.Mutex
+	records   map[string]memoryRecord
+	lastSweep time.Time
+}
+
+type memoryRecord struct {
+	Record
+	expiresAt time.Time
+}
+
+// how often the memory store purges expired records
+const sweepInterval = time.Minute
+
+// NewMemoryStore constructs a new in-memory store
+func NewMemoryStore() Store {
+	return &memoryStore{
+		records: map[string]memoryRecord{},
+	}
+}
+
+// Get fetches the record for a key
+func (s *memoryStore) Get(key string) (Record, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	record, ok := s.records[key]
+	if !ok || time.Now().After(record.expiresAt) {
+		return Record{}, nil
+	}
+
+	return record.Record, nil
+}
+
+// RecordFailure increments the failure count of a key
+func (s *memoryStore) RecordFailure(key string, at time.Time, ttl time.Duration) (Record, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.sweep(at)
+	record, ok := s.records[key]
+	if !ok || at.After(record.expiresAt) {
+		record = memoryRecord{}
+	}
+
+	record.Failures++
+	record.LastFailure = at
+	record.expiresAt = at.Add(ttl)
+	s.records[key] = record
+
+	return record.Record, nil
+}
+
+// Reserve records a failure against a key if allow permits it
+func (s *memoryStore) Reserve(key string, at time.Time, ttl time.Duration, allow func(Record) bool) (bool, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.sweep(at)
+	record, ok := s.records[key]
+	if !ok || at.After(record.expiresAt) {
+		record = memoryRecord{}
+	}
+
+	if !allow(record.Record) {
+		return false, nil
+	}
+
+	record.Failures++
+	record.LastFailure = at
+	record.expiresAt = at.Add(ttl)
+	s.records[key] = record
+
+	return true, nil
+}
+
+// Release undoes a failure recorded by Reserve
+func (s *memoryStore) Release(key string) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if record, ok := s.records[key]; ok && record.Failures > 0 {
+		record.Failures--
+		s.records[key] = record
+	}
+
+	return nil
+}
+
+// Reset forgets the history of a key
+func (s *memoryStore) Reset(key string) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	delete(s.records, key)
+	return nil
+}
+
+// sweep removes expired records so the store doesn't grow forever, the caller must hold the lock
+func (s *memoryStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < sweepInterval {
+		return
+	}
+
+	for key, record := range s.records {
+		if now.After(record.expiresAt) {
+			delete(s.records, key)
+		}
+	}
+
+	s.lastSweep = now
+}
@@ -0,0 +1,173 @@
+package throttle
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testScope Scope = "account"
+
+var testPolicy = Policy{
+	FreeAttempts:     2,
+	BaseDelay:        time.Second,
+	MaxDelay:         10 * time.Second,
+	LockoutThreshold: 6,
+	LockoutDuration:  time.Minute,
+	Window:           time.Hour,
+}
+
+// newTestThrottler constructs a throttler whose clock can be moved
+func newTestThrottler() (*Throttler, *time.Time) {
+	now := time.Now()
+	throttler := New(NewMemoryStore(), map[Scope]Policy{testScope: testPolicy})
+	throttler.now = func() time.Time { return now }
+
+	return throttler, &now
+}
+
+func TestFreeAttemptsAreNotThrottled(t *testing.T) {
+	assert := assert.New(t)
+	throttler, _ := newTestThrottler()
+	key := Key{Scope: testScope, Value: "adam"}
+
+	for i := 0; i < testPolicy.FreeAttempts; i++ {
+		decision, err := throttler.Check(key)
+		assert.Nil(err)
+		assert.True(decision.Allowed)
+		assert.Nil(throttler.Fail(key))
+	}
+
+	decision, _ := throttler.Check(key)
+	assert.True(decision.Allowed)
+}
+
+func TestExponentialBackoff(t *testing.T) {
+	assert := assert.New(t)
+	throttler, now := newTestThrottler()
+	key := Key{Scope: testScope, Value: "adam"}
+
+	for i := 0; i < testPolicy.FreeAttempts; i++ {
+		throttler.Fail(key)
+	}
+
+	// each failure past the free attempts doubles the delay (capped at MaxDelay)
+	expectedDelays := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second}
+	for _, expected := range expectedDelays {
+		throttler.Fail(key)
+
+		decision, _ := throttler.Check(key)
+		assert.False(decision.Allowed)
+		assert.False(decision.LockedOut)
+		assert.Equal(expected, decision.RetryAfter)
+
+		// once the delay passes the client can try again
+		*now = now.Add(expected)
+		decision, _ = throttler.Check(key)
+		assert.True(decision.Allowed)
+	}
+}
+
+func TestLockout(t *testing.T) {
+	assert := assert.New(t)
+	throttler, now := newTestThrottler()
+	key := Key{Scope: testScope, Value: "adam"}
+
+	for i := 0; i < testPolicy.LockoutThreshold; i++ {
+		throttler.Fail(key)
+	}
+
+	decision, _ := throttler.Check(key)
+	assert.False(decision.Allowed)
+	assert.True(decision.LockedOut)
+	assert.Equal(testPolicy.LockoutDuration, decision.RetryAfter)
+
+	*now = now.Add(testPolicy.LockoutDuration)
+	decision, _ = throttler.Check(key)
+	assert.True(decision.Allowed)
+}
+
+func TestSuccessResetsKey(t *testing.T) {
+	assert := assert.New(t)
+	throttler, _ := newTestThrottler()
+	account := Key{Scope: testScope, Value: "adam"}
+	other := Key{Scope: testScope, Value: "john"}
+
+	for i := 0; i < testPolicy.LockoutThreshold; i++ {
+		throttler.Fail(account, other)
+	}
+
+	assert.Nil(throttler.Succeed(account))
+	decision, _ := throttler.Check(account)
+	assert.True(decision.Allowed)
+
+	// a throttled key blocks the check even if the others are fine
+	decision, _ = throttler.Check(account, other)
+	assert.False(decision.Allowed)
+}
+
+func TestConcurrentReservations(t *testing.T) {
+	assert := assert.New(t)
+	throttler, _ := newTestThrottler()
+	key := Key{Scope: testScope, Value: "adam"}
+
+	// every attempt is made before any of them could have failed, the ones that are allowed through
+	// must still be limited to the attempts a client making them one after another would get
+	var wg sync.WaitGroup
+	var lock sync.Mutex
+	allowed := 0
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			decision, err := throttler.Reserve(key)
+			assert.Nil(err)
+
+			lock.Lock()
+			defer lock.Unlock()
+			if decision.Allowed {
+				allowed++
+			}
+		}()
+	}
+
+	wg.Wait()
+	assert.Equal(testPolicy.FreeAttempts+1, allowed)
+}
+
+func TestReleaseUndoesReservation(t *testing.T) {
+	assert := assert.New(t)
+	throttler, _ := newTestThrottler()
+	account := Key{Scope: testScope, Value: "adam"}
+	other := Key{Scope: testScope, Value: "john"}
+
+	for i := 0; i < testPolicy.FreeAttempts; i++ {
+		throttler.Fail(other)
+	}
+
+	// the attempts never failed so they never count towards the throttle
+	for i := 0; i < testPolicy.LockoutThreshold; i++ {
+		decision, err := throttler.Reserve(account)
+		assert.Nil(err)
+		assert.True(decision.Allowed)
+		assert.Nil(throttler.Release(account))
+	}
+
+	// a denied reservation doesn't count against the keys that were allowed
+	throttler.Fail(other)
+	decision, _ := throttler.Reserve(account, other)
+	assert.False(decision.Allowed)
+
+	decision, _ = throttler.Check(account)
+	assert.True(decision.Allowed)
+	record, _ := throttler.store.Get(account.String())
+	assert.Equal(0, record.Failures)
+}
+
+func TestUnknownScope(t *testing.T) {
+	throttler, _ := newTestThrottler()
+	_, err := throttler.Check(Key{Scope: "unknown", Value: "adam"})
+	assert.NotNil(t, err)
+}
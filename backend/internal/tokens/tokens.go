@@ -0,0 +1,129 @@
+// Package tokens implements signed, expiring tokens that can be handed out to users (eg. inside an email)
+// a token is of the form base64(claims).base64(hmac(claims)), the signature guarantees that the claims
+// were generated by us, single-use semantics are NOT provided by this package and must be enforced
+// by whoever redeems the token (see the TokensRepository)
+package tokens
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"cms.csesoc.unsw.edu.au/environment"
+	"github.com/google/uuid"
+)
+
+// Purpose restricts what a token can be used for, a token issued for one purpose can never be redeemed for another
+type Purpose string
+
+const (
+	PasswordReset     Purpose = "password_reset"
+	EmailVerification Purpose = "email_verification"
+)
+
+// Claims is the information that is signed within a token
+type Claims struct {
+	TokenID   uuid.UUID
+	UID       int
+	Purpose   Purpose
+	ExpiresAt time.Time
+}
+
+var (
+	ErrMalformedToken = errors.New("token is malformed")
+	ErrInvalidToken   = errors.New("token signature is invalid")
+	ErrExpiredToken   = errors.New("token has expired")
+	ErrWrongPurpose   = errors.New("token was not issued for this purpose")
+)
+
+var encoding = base64.RawURLEncoding
+
+// NewClaims constructs a fresh set of claims for a user that expire after the provided ttl
+func NewClaims(uid int, purpose Purpose, ttl time.Duration) Claims {
+	return Claims{
+		TokenID:   uuid.New(),
+		UID:       uid,
+		Purpose:   purpose,
+		ExpiresAt: time.Now().Add(ttl).UTC(),
+	}
+}
+
+// Sign signs a set of claims and returns the corresponding token
+func Sign(claims Claims) string {
+	// marshalling a struct of primitives cannot fail
+	payload, _ := json.Marshal(claims)
+	encodedPayload := encoding.EncodeToString(payload)
+
+	return encodedPayload + "." + encoding.EncodeToString(computeSignature(encodedPayload))
+}
+
+// Verify checks that a token was signed by us, hasn't expired and was issued for the requested purpose
+// it returns the claims contained within the token
+func Verify(token string, purpose Purpose) (Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 2 {
+		return Claims{}, ErrMalformedToken
+	}
+
+	signature, err := encoding.DecodeString(parts[1])
+	if err != nil {
+		return Claims{}, ErrMalformedToken
+	}
+
+	if !hmac.Equal(signature, computeSignature(parts[0])) {
+		return Claims{}, ErrInvalidToken
+	}
+
+	var claims Claims
+	payload, err := encoding.DecodeString(parts[0])
+	if err != nil || json.Unmarshal(payload, &claims) != nil {
+		return Claims{}, ErrMalformedToken
+	}
+
+	switch {
+	case claims.Purpose != purpose:
+		return Claims{}, ErrWrongPurpose
+	case time.Now().After(claims.ExpiresAt):
+		return Claims{}, ErrExpiredToken
+	}
+
+	return claims, nil
+}
+
+// computeSignature computes the HMAC of an encoded payload
+func computeSignature(encodedPayload string) []byte {
+	mac := hmac.New(sha256.New, getSecret())
+	mac.Write([]byte(encodedPayload))
+	return mac.Sum(nil)
+}
+
+var (
+	secret     []byte
+	secretOnce sync.Once
+)
+
+// getSecret fetches the signing secret from the environment, if no secret was configured a random one is generated
+// note that this means tokens will not survive a restart (or work across replicas) so TOKEN_SECRET should always be set in prod
+func getSecret() []byte {
+	secretOnce.Do(func() {
+		if configured := environment.GetTokenSecret(); configured != "" {
+			secret = []byte(configured)
+			return
+		}
+
+		log.Print("TOKEN_SECRET is not set, generating a random signing secret")
+		secret = make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			panic(err)
+		}
+	})
+
+	return secret
+}
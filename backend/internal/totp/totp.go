@@ -0,0 +1,158 @@
+// Package totp implements time-based one-time passwords as described in RFC 6238 (and the HOTP
+// algorithm from RFC 4226 it builds on). The parameters are fixed to the ones every authenticator
+// app supports: HMAC-SHA1, 6 digit codes and a 30 second period.
+//
+// The package also handles the generation of the single-use recovery codes handed out when
+// a user enrols, only the hashes of the recovery codes should ever be stored.
+package totp
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	// Digits is the length of a generated code
+	Digits = 6
+	// Period is how long a single code is valid for
+	Period = 30 * time.Second
+	// Skew is the number of periods either side of the current one we accept codes from,
+	// this accounts for clock drift and users who are slow at typing
+	Skew = 1
+
+	// secretSize is the length of generated secrets in bytes, RFC 4226 recommends 160 bits
+	secretSize = 20
+)
+
+var secretEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// GenerateSecret generates a new random base32 encoded shared secret
+func GenerateSecret() (string, error) {
+	secret := make([]byte, secretSize)
+	if _, err := rand.Read(secret); err != nil {
+		return "", fmt.Errorf("failed to generate secret: %w", err)
+	}
+
+	return secretEncoding.EncodeToString(secret), nil
+}
+
+// ProvisioningURI builds the otpauth:// URI that authenticator apps consume, this is what should be
+// rendered as a QR code for the user to scan
+func ProvisioningURI(issuer string, account string, secret string) string {
+	label := url.PathEscape(issuer) + ":" + url.PathEscape(account)
+	params := url.Values{}
+	params.Set("secret", secret)
+	params.Set("issuer", issuer)
+	params.Set("algorithm", "SHA1")
+	params.Set("digits", fmt.Sprint(Digits))
+	params.Set("period", fmt.Sprint(int(Period.Seconds())))
+
+	return fmt.Sprintf("otpauth://totp/%s?%s", label, params.Encode())
+}
+
+// Step computes the time step (RFC 6238's T) that a moment in time falls into
+func Step(at time.Time) int64 {
+	return at.Unix() / int64(Period.Seconds())
+}
+
+// GenerateCode generates the code for a secret at a specific moment in time
+func GenerateCode(secret string, at time.Time) (string, error) {
+	key, err := decodeSecret(secret)
+	if err != nil {
+		return "", err
+	}
+
+	return hotp(key, Step(at)), nil
+}
+
+// Validate checks a code against a secret, it returns the time step the code was generated for
+// so that callers can reject codes that have already been used (RFC 6238 section 5.2)
+func Validate(secret string, code string, at time.Time) (int64, bool) {
+	key, err := decodeSecret(secret)
+	code = strings.TrimSpace(code)
+	if err != nil || len(code) != Digits {
+		return 0, false
+	}
+
+	current := Step(at)
+	for offset := int64(-Skew); offset <= Skew; offset++ {
+		step := current + offset
+		if hmac.Equal([]byte(hotp(key, step)), []byte(code)) {
+			return step, true
+		}
+	}
+
+	return 0, false
+}
+
+// hotp implements the HOTP algorithm from RFC 4226 section 5.3
+func hotp(key []byte, counter int64) string {
+	message := make([]byte, 8)
+	binary.BigEndian.PutUint64(message, uint64(counter))
+
+	mac := hmac.New(sha1.New, key)
+	mac.Write(message)
+	sum := mac.Sum(nil)
+
+	// dynamic truncation
+	offset := sum[len(sum)-1] & 0x0f
+	binaryCode := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	modulus := uint32(1)
+	for i := 0; i < Digits; i++ {
+		modulus *= 10
+	}
+
+	return fmt.Sprintf("%0*d", Digits, binaryCode%modulus)
+}
+
+// decodeSecret decodes a base32 secret, authenticator apps are lenient about casing and padding so we are too
+func decodeSecret(secret string) ([]byte, error) {
+	normalised := strings.TrimRight(strings.ToUpper(strings.ReplaceAll(secret, " ", "")), "=")
+	key, err := secretEncoding.DecodeString(normalised)
+	if err != nil {
+		return nil, fmt.Errorf("invalid secret: %w", err)
+	}
+
+	return key, nil
+}
+
+// recoveryCodeAlphabet excludes characters that are easily confused (0/O, 1/I/L)
+const recoveryCodeAlphabet = "23456789ABCDEFGHJKMNPQRSTUVWXYZ"
+
+// GenerateRecoveryCodes generates n single-use recovery codes of the form XXXXX-XXXXX
+func GenerateRecoveryCodes(n int) ([]string, error) {
+	codes := make([]string, n)
+	for i := range codes {
+		raw := make([]byte, 10)
+		if _, err := rand.Read(raw); err != nil {
+			return nil, fmt.Errorf("failed to generate recovery code: %w", err)
+		}
+
+		code := make([]byte, len(raw))
+		for j, b := range raw {
+			code[j] = recoveryCodeAlphabet[int(b)%len(recoveryCodeAlphabet)]
+		}
+
+		codes[i] = string(code[:5]) + "-" + string(code[5:])
+	}
+
+	return codes, nil
+}
+
+// HashRecoveryCode hashes a recovery code for storage, the code is normalised first so users
+// don't have to worry about casing or the dash
+func HashRecoveryCode(code string) string {
+	normalised := strings.ToUpper(strings.NewReplacer("-", "", " ", "").Replace(code))
+	hash := sha256.Sum256([]byte(normalised))
+	return hex.EncodeToString(hash[:])
+}
@@ -0,0 +1,69 @@
+package totp
+
+import (
+	"encoding/base32"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// the SHA1 secret used by the test vectors in RFC 6238 appendix B
+var rfcSecret = base32.StdEncoding.EncodeToString([]byte("12345678901234567890"))
+
+func TestRFC6238Vectors(t *testing.T) {
+	// the RFC vectors are 8 digits, our codes are the last 6 digits of them
+	vectors := map[int64]string{
+		59:          "287082",
+		1111111109:  "081804",
+		1111111111:  "050471",
+		1234567890:  "005924",
+		2000000000:  "279037",
+		20000000000: "353130",
+	}
+
+	for unix, expected := range vectors {
+		code, err := GenerateCode(rfcSecret, time.Unix(unix, 0))
+		assert.Nil(t, err)
+		assert.Equal(t, expected, code, "time %d", unix)
+	}
+}
+
+func TestValidateAllowsSkew(t *testing.T) {
+	assert := assert.New(t)
+	secret, err := GenerateSecret()
+	assert.Nil(err)
+
+	now := time.Now()
+	code, _ := GenerateCode(secret, now.Add(-Period))
+
+	step, ok := Validate(secret, code, now)
+	assert.True(ok)
+	assert.Equal(Step(now)-1, step)
+
+	// codes from too long ago are rejected
+	_, ok = Validate(secret, code, now.Add(2*Period))
+	assert.False(ok)
+
+	// as is garbage
+	_, ok = Validate(secret, "abcdef", now)
+	assert.False(ok)
+}
+
+func TestProvisioningURI(t *testing.T) {
+	uri := ProvisioningURI("CSESoc CMS", "adam@gmail.com", "JBSWY3DPEHPK3PXP")
+	assert.Equal(t, "otpauth://totp/CSESoc%20CMS:adam@gmail.com?algorithm=SHA1&digits=6&issuer=CSESoc+CMS&period=30&secret=JBSWY3DPEHPK3PXP", uri)
+}
+
+func TestRecoveryCodes(t *testing.T) {
+	assert := assert.New(t)
+	codes, err := GenerateRecoveryCodes(10)
+	assert.Nil(err)
+	assert.Len(codes, 10)
+
+	for _, code := range codes {
+		assert.Len(code, 11)
+		// hashing is insensitive to formatting
+		assert.Equal(HashRecoveryCode(code), HashRecoveryCode(" "+code[:5]+code[6:]+" "))
+	}
+}
@@ -1,16 +1,51 @@
 package main
 
 import (
+	"flag"
 	"log"
 	"net/http"
+	"os"
+	"path/filepath"
 
+	"cms.csesoc.unsw.edu.au/database/repositories"
 	"cms.csesoc.unsw.edu.au/endpoints"
 	"cms.csesoc.unsw.edu.au/environment"
+	"cms.csesoc.unsw.edu.au/internal/session"
 
 	"github.com/rs/cors"
 )
 
 func main() {
+	// flags are parsed by the environment package
+	if flag.Arg(0) == "migrate" {
+		if err := runMigrateCommand(flag.Args()[1:]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	} else if flag.Arg(0) == "upgrade-documents" {
+		if err := runUpgradeCommand(flag.Args()[1:]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	} else if flag.Arg(0) == "schema" {
+		if err := runSchemaCommand(flag.Args()[1:]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	if environment.IsDevMode() {
+		if err := enableDevMode(); err != nil {
+			log.Fatalf("failed to start in dev mode: %v", err)
+		}
+	} else if err := session.CheckKey(); err != nil {
+		log.Fatal(err)
+	} else if environment.GetMigrateOnStartup() {
+		if err := migrateOnStartup(); err != nil {
+			log.Fatalf("failed to migrate the database: %v", err)
+		}
+	}
+
 	mux := http.NewServeMux()
 
 	endpoints.RegisterFilesystemEndpoints(mux)
@@ -25,10 +60,23 @@ func main() {
 		// for testing purposes
 		AllowedOrigins:   []string{frontend_URI},
 		AllowedMethods:   []string{http.MethodGet, http.MethodPost},
+		AllowedHeaders:   []string{"Origin", "Accept", "Content-Type", "X-Requested-With", endpoints.CSRFHeader},
 		AllowCredentials: true,
 	})
-	handler := cors.Default().Handler(mux)
-	handler = c.Handler(handler)
+	handler := c.Handler(mux)
 
 	log.Fatal(http.ListenAndServe(":8080", handler))
 }
+
+// enableDevMode runs the backend against an in-memory database seeded with the same dummy data as the migrations,
+// documents are stored in a temporary directory. The database starts afresh every run
+func enableDevMode() error {
+	db := repositories.NewMemoryDatabase()
+	if err := db.SeedDummyData(); err != nil {
+		return err
+	}
+
+	volumeDirectory := filepath.Join(os.TempDir(), "cms-dev-volumes")
+	log.Printf("== Running in dev mode, documents are stored in %s ==", volumeDirectory)
+	return endpoints.EnableDevMode(db, volumeDirectory)
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"cms.csesoc.unsw.edu.au/database/contexts"
+	"cms.csesoc.unsw.edu.au/database/migrations"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+const migrateUsage = "usage: migrate [up | down [steps] | status | baseline version]"
+
+// runMigrateCommand implements the `migrate` subcommand:
+//   - migrate up: applies all pending migrations
+//   - migrate down [steps]: rolls back the last `steps` migrations (defaults to 1)
+//   - migrate status: lists every migration and whether it has been applied
+//   - migrate baseline version: marks the migrations up to `version` as applied without running them, this is
+//     only needed to take over a database created by postgres/migrate.py that `migrate up` fails to recognise
+func runMigrateCommand(args []string) error {
+	if len(args) == 0 {
+		return errors.New(migrateUsage)
+	}
+
+	runner, closeRunner, err := newMigrationRunner()
+	if err != nil {
+		return err
+	}
+	defer closeRunner()
+
+	ctx := context.Background()
+	switch {
+	case args[0] == "up" && len(args) == 1:
+		applied, err := runner.Up(ctx)
+		logMigrations("applied", applied)
+		return err
+
+	case args[0] == "down" && len(args) <= 2:
+		steps := 1
+		if len(args) == 2 {
+			if steps, err = strconv.Atoi(args[1]); err != nil || steps < 1 {
+				return fmt.Errorf("steps must be a positive integer: %s", args[1])
+			}
+		}
+
+		rolledBack, err := runner.Down(ctx, steps)
+		logMigrations("rolled back", rolledBack)
+		return err
+
+	case args[0] == "status" && len(args) == 1:
+		statuses, err := runner.Status(ctx)
+		for _, status := range statuses {
+			appliedAt := "pending"
+			if status.Applied {
+				appliedAt = status.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+
+			fmt.Printf("%04d %-40s %s\n", status.Version, status.Name, appliedAt)
+		}
+
+		return err
+
+	case args[0] == "baseline" && len(args) == 2:
+		version, err := strconv.Atoi(args[1])
+		if err != nil || version < 1 {
+			return fmt.Errorf("version must be a positive integer: %s", args[1])
+		}
+
+		baselined, err := runner.Baseline(ctx, version)
+		logMigrations("baselined", baselined)
+		return err
+	}
+
+	return errors.New(migrateUsage)
+}
+
+// startupConnectionAttempts is the number of times we try to connect to the database on startup, the
+// database is usually started alongside the backend so it may not be accepting connections yet
+const startupConnectionAttempts = 10
+
+// migrateOnStartup applies any pending migrations before the backend starts serving requests
+func migrateOnStartup() error {
+	runner, closeRunner, err := newMigrationRunner()
+	for attempt := 1; err != nil && attempt < startupConnectionAttempts; attempt++ {
+		log.Printf("waiting for the database: %v", err)
+		time.Sleep(2 * time.Second)
+		runner, closeRunner, err = newMigrationRunner()
+	}
+
+	if err != nil {
+		return err
+	}
+	defer closeRunner()
+
+	applied, err := runner.Up(context.Background())
+	logMigrations("applied", applied)
+	return err
+}
+
+// newMigrationRunner creates a migration runner for the live database
+func newMigrationRunner() (*migrations.Runner, func(), error) {
+	scripts, err := migrations.GetMigrations()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load migrations: %w", err)
+	}
+
+	pool, err := pgxpool.Connect(context.Background(), contexts.GetConnectionString())
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to connect to the database: %w", err)
+	}
+
+	return migrations.NewRunner(pool, scripts), pool.Close, nil
+}
+
+func logMigrations(action string, changed []migrations.Migration) {
+	for _, migration := range changed {
+		log.Printf("%s migration %04d (%s)", action, migration.Version, migration.Name)
+	}
+}
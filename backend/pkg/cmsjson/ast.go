@@ -31,6 +31,9 @@ type (
 		UpdateOrAddArrayElement(int, AstNode) error
 		UpdateOrAddObjectElement(int, AstNode) error
 
+		// InsertArrayElement shifts the elements from the index along rather than replacing the element at the index
+		InsertArrayElement(int, AstNode) error
+
 		RemoveArrayElement(int) error
 	}
 
@@ -45,6 +48,10 @@ type (
 		// underlying type is the type modelled by this jsonNode, isObject allows us distinguish between arrays and objects
 		underlyingType reflect.Type
 		isObject       bool
+
+		// field is the struct field this node was parsed from (nil for the root and array elements), its
+		// validation rules are enforced whenever the node is updated
+		field *reflect.StructField
 	}
 
 	// jsonPrimitives is a generic constraint for json primitive values
@@ -105,26 +112,22 @@ func (node *jsonNode) UpdateOrAddPrimitiveElement(replacement AstNode) error {
 		return errors.New("ast node is not a primitive")
 	}
 
+	if node.field != nil {
+		if err := checkRules("", *node.field, value, noLength).asError(); err != nil {
+			return err
+		}
+	}
+
 	node.value = value
 	return nil
 }
 
-// UpdateArray updates an array AST node to contain an additional entry :D
+// UpdateArray updates an array AST node to contain an additional entry :D, entries are either primitives or objects
+// (if the array holds an interface type the object just has to implement it)
 func (node *jsonNode) UpdateOrAddArrayElement(index int, newValue AstNode) error {
-	value, underlyingType := newValue.JsonPrimitive()
-	asJsonNode, couldCast := newValue.(*jsonNode)
-
-	switch {
-	case !couldCast:
-		return errors.New("incompatible AstNode implementation")
-	case value == nil:
-		return errors.New("provided target is not a json primitive")
-	case underlyingType != node.underlyingType:
-		return errors.New("type mismatch between target node and value to insert")
-	case node.children == nil || node.isObject:
-		return errors.New("ast node is not an array")
-	case index > len(node.children):
-		return errors.New("cannot insert past the existing size of the array")
+	asJsonNode, err := node.checkArrayElement(index, newValue, index == len(node.children))
+	if err != nil {
+		return err
 	}
 
 	asJsonNode.key = strconv.Itoa(index)
@@ -136,13 +139,74 @@ func (node *jsonNode) UpdateOrAddArrayElement(index int, newValue AstNode) error
 	return nil
 }
 
+// InsertArrayElement inserts an entry before the element at the given index (or at the end of the array), the
+// following elements are shifted along
+func (node *jsonNode) InsertArrayElement(index int, newValue AstNode) error {
+	asJsonNode, err := node.checkArrayElement(index, newValue, true)
+	if err != nil {
+		return err
+	}
+
+	node.children = append(node.children[:index], append([]*jsonNode{asJsonNode}, node.children[index:]...)...)
+	for i := index; i < len(node.children); i++ {
+		// the shifted elements are copied before being re-keyed since they may be shared with a persistent AST
+		if i != index {
+			node.children[i] = node.children[i].shallowCopy()
+		}
+		node.children[i].key = strconv.Itoa(i)
+	}
+	return nil
+}
+
+// checkArrayElement checks that a value can be placed within an array node at the given index, grows indicates
+// that the array will gain an element
+func (node *jsonNode) checkArrayElement(index int, newValue AstNode, grows bool) (*jsonNode, error) {
+	value, underlyingType := newValue.JsonPrimitive()
+	if fields, objectType := newValue.JsonObject(); fields != nil {
+		value, underlyingType = fields, objectType
+	}
+	asJsonNode, couldCast := mutableNode(newValue)
+
+	switch {
+	case !couldCast:
+		return nil, errors.New("incompatible AstNode implementation")
+	case value == nil:
+		return nil, errors.New("provided target is not a json primitive or object")
+	case node.children == nil || node.isObject:
+		return nil, errors.New("ast node is not an array")
+	case underlyingType != node.underlyingType &&
+		!(node.underlyingType.Kind() == reflect.Interface && underlyingType.Implements(node.underlyingType)):
+		return nil, errors.New("type mismatch between target node and value to insert")
+	case index < 0 || index > len(node.children):
+		return nil, errors.New("cannot insert past the existing size of the array")
+	}
+
+	if node.field != nil && grows {
+		if err := checkRules("", *node.field, nil, len(node.children)+1).asError(); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := validateNode(asJsonNode).asError(); err != nil {
+		return nil, err
+	}
+
+	return asJsonNode, nil
+}
+
 // RemoveArrayElement removes an array element given its index, it shrinks the array accordingly
 func (node *jsonNode) RemoveArrayElement(index int) error {
 	switch {
 	case node.children == nil || node.isObject:
 		return errors.New("ast node is not an array")
-	case len(node.children) > index:
-		return errors.New("cannot insert past the existing size of the array")
+	case index < 0 || index >= len(node.children):
+		return errors.New("cannot remove past the existing size of the array")
+	}
+
+	if node.field != nil {
+		if err := checkRules("", *node.field, nil, len(node.children)-1).asError(); err != nil {
+			return err
+		}
 	}
 
 	node.children = append(node.children[:index], node.children[index+1:]...)
@@ -155,7 +219,7 @@ func (node *jsonNode) UpdateOrAddObjectElement(index int, newValue AstNode) erro
 	if value == nil {
 		value, underlyingType = newValue.JsonObject()
 	}
-	asJsonNode, couldCast := newValue.(*jsonNode)
+	asJsonNode, couldCast := mutableNode(newValue)
 
 	switch {
 	case !couldCast:
@@ -170,11 +234,40 @@ func (node *jsonNode) UpdateOrAddObjectElement(index int, newValue AstNode) erro
 		return errors.New("cannot insert past the existing field count of the object")
 	}
 
+	replacement := asJsonNode.children[index]
+	replacement.field = node.children[index].field
+	if err := validateNode(replacement).asError(); err != nil {
+		return err
+	}
+
 	asJsonNode.key = node.children[index].key
-	node.children[index] = asJsonNode.children[index]
+	node.children[index] = replacement
 	return nil
 }
 
+// CloneAST deep copies an AST, updates to the copy don't affect the original (cloning a persistent AST produces a
+// regular mutable AST)
+func CloneAST(source AstNode) (AstNode, error) {
+	node, ok := unwrapNode(source)
+	if !ok {
+		return nil, errors.New("incompatible AstNode implementation")
+	}
+
+	return node.clone(), nil
+}
+
+func (node *jsonNode) clone() *jsonNode {
+	cloned := *node
+	if node.children != nil {
+		cloned.children = make([]*jsonNode, len(node.children))
+		for i, child := range node.children {
+			cloned.children[i] = child.clone()
+		}
+	}
+
+	return &cloned
+}
+
 // validateNode determines if the current node configuration was corrupted or not
 func (node *jsonNode) validateNode() {
 	if (node.value == nil && node.children == nil) || (node.value != nil && node.children != nil) {
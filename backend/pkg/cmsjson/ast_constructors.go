@@ -2,6 +2,7 @@ package cmsjson
 
 import (
 	"reflect"
+	"sort"
 	"strconv"
 )
 
@@ -18,11 +19,12 @@ func astFromStruct(key string, underlyingValue reflect.Value) *jsonNode {
 	childrenArray := []*jsonNode{}
 	underlyingType := underlyingValue.Type()
 
-	for i := 0; i < underlyingType.NumField(); i++ {
-		fieldName := underlyingType.Field(i).Name
-		fieldValue := underlyingValue.Field(i)
+	fields := cachedFields(underlyingType)
+	for i := range fields {
+		child := astFromCore(fields[i].options.name, underlyingValue.Field(fields[i].field.Index[0]))
+		child.field = &fields[i].field
 
-		childrenArray = append(childrenArray, astFromCore(fieldName, fieldValue))
+		childrenArray = append(childrenArray, child)
 	}
 
 	return newJsonObject(key, childrenArray, underlyingType)
@@ -42,6 +44,34 @@ func astFromArray(key string, underlyingValue reflect.Value) *jsonNode {
 	return newJsonArray(key, childrenArray, arrayType)
 }
 
+// astFromMap constructs a new AST object from a map with string keys, the object's fields are the map's keys in
+// sorted order
+func astFromMap(key string, underlyingValue reflect.Value) *jsonNode {
+	keys := make([]string, 0, underlyingValue.Len())
+	for _, mapKey := range underlyingValue.MapKeys() {
+		keys = append(keys, mapKey.String())
+	}
+	sort.Strings(keys)
+
+	childrenArray := []*jsonNode{}
+	for _, mapKey := range keys {
+		value := underlyingValue.MapIndex(reflect.ValueOf(mapKey).Convert(underlyingValue.Type().Key()))
+		childrenArray = append(childrenArray, astFromCore(mapKey, value))
+	}
+
+	return newJsonObject(key, childrenArray, underlyingValue.Type())
+}
+
+// astFromPointer constructs a new AST from the value a pointer points to, nil pointers become the zero value of
+// the type they point to
+func astFromPointer(key string, underlyingValue reflect.Value) *jsonNode {
+	if underlyingValue.IsNil() {
+		return astFromCore(key, reflect.Zero(underlyingValue.Type().Elem()))
+	}
+
+	return astFromCore(key, underlyingValue.Elem())
+}
+
 // astFromPrimitive constructs a new AST from a primitive type
 func astFromPrimitive(key string, underlyingValue reflect.Value) *jsonNode {
 	return newJsonPrimitive(key, underlyingValue.Interface(), underlyingValue.Type())
@@ -60,6 +90,12 @@ func astFromCore(key string, underlyingValue reflect.Value) *jsonNode {
 		return astFromStruct(key, underlyingValue)
 	case _interface:
 		return astFromStruct(key, underlyingValue.Elem())
+	case _pointer:
+		return astFromPointer(key, underlyingValue)
+	case _map:
+		return astFromMap(key, underlyingValue)
+	case _custom:
+		return astFromPrimitive(key, underlyingValue)
 	}
 
 	return nil
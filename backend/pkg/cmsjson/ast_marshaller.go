@@ -1,47 +1,122 @@
 package cmsjson
 
 import (
-	"fmt"
-	"strconv"
+	"bufio"
+	"io"
+	"reflect"
 	"strings"
 )
 
-// Marshall can potentially be a rather expensive operation
-// why? Strings in go are immutable, if we recursively modify
-// a string each invocation ends up allocation a new string on the heap
-// resulting in GC overhead :P
+// MarshallAST marshalls an AST into a string, see MarshallASTTo
 func (c Configuration) MarshallAST(source AstNode) string {
-	asPrimitive, _ := source.JsonPrimitive()
-	asObject, _ := source.JsonObject()
-	asArray, _ := source.JsonArray()
+	marshalled := strings.Builder{}
+	c.newEncoder(&marshalled).encodeAST(source)
+	return marshalled.String()
+}
+
+// MarshallASTTo marshalls an AST into a writer, like MarshallTo the JSON is written through a single buffer as it
+// is generated
+func (c Configuration) MarshallASTTo(w io.Writer, source AstNode) error {
+	buffered := bufio.NewWriter(w)
+	e := c.newEncoder(buffered)
+	e.encodeAST(source)
+	return e.flush(buffered)
+}
+
+// encodeAST writes an AST node
+func (e *encoder) encodeAST(source AstNode) {
+	if asPrimitive, _ := source.JsonPrimitive(); asPrimitive != nil {
+		e.encodeASTPrimitive(asPrimitive)
+		return
+	}
+
+	if asObject, objectType := source.JsonObject(); asObject != nil {
+		e.w.WriteByte('{')
+		isFirst := true
+
+		// objects of a registered type are annotated with their type name so they can be un-marshalled again
+		if typeName, isRegistered := e.configuration.registeredName(objectType); isRegistered {
+			e.writeKey("$type")
+			e.writeString(typeName)
+			isFirst = false
+		}
 
-	switch {
-	case asPrimitive != nil:
-		return stringifyPrimitive(asPrimitive)
-	case asObject != nil:
-		result := strings.Builder{}
 		for _, node := range asObject {
-			result.WriteString(fmt.Sprintf("\"%s\": %s", node.GetKey(), c.MarshallAST(node)))
+			if isOmitted(node) {
+				continue
+			}
+
+			if !isFirst {
+				e.w.WriteByte(',')
+			}
+			isFirst = false
+
+			e.writeKey(node.GetKey())
+			e.encodeAST(node)
 		}
 
-		return fmt.Sprintf("{%s}", result.String())
-	default:
-		result := strings.Builder{}
-		for _, node := range asArray {
-			result.WriteString(c.MarshallAST(node) + ",")
+		e.w.WriteByte('}')
+		return
+	}
+
+	asArray, _ := source.JsonArray()
+	e.w.WriteByte('[')
+	for i, node := range asArray {
+		if i != 0 {
+			e.w.WriteByte(',')
 		}
 
-		return fmt.Sprintf("[%s]", result.String())
+		e.encodeAST(node)
 	}
+	e.w.WriteByte(']')
 }
 
-func stringifyPrimitive(primitive interface{}) string {
-	switch parsedPrimitive := primitive.(type) {
+// encodeASTPrimitive writes the value of a primitive node, numbers parsed from JSON are float64s so they're
+// written in their shortest form rather than the fixed form used for struct fields
+func (e *encoder) encodeASTPrimitive(primitive interface{}) {
+	switch value := primitive.(type) {
 	case string:
-		return "\"" + parsedPrimitive + "\""
+		e.writeString(value)
+	case float64:
+		e.writeFloat(value)
+	case int:
+		e.writeInt(int64(value))
 	case bool:
-		return strconv.FormatBool(parsedPrimitive)
+		e.writeBool(value)
 	default:
-		return strconv.FormatInt(parsedPrimitive.(int64), 10)
+		reflected := reflect.ValueOf(primitive)
+		switch {
+		case resolveType(reflected.Type()) == _custom:
+			e.encodeCustom(reflected)
+		case reflected.Kind() == reflect.Float32 || reflected.Kind() == reflect.Float64:
+			e.writeFloat(reflected.Float())
+		default:
+			e.encodePrimitive(reflected)
+		}
+	}
+}
+
+// registeredName finds the name a type is registered under
+func (c Configuration) registeredName(underlyingType reflect.Type) (string, bool) {
+	for _, registrations := range c.RegisteredTypes {
+		for name, registeredType := range registrations {
+			if registeredType == underlyingType {
+				return name, true
+			}
+		}
+	}
+
+	return "", false
+}
+
+// isOmitted determines if a node is left out of its object because it is empty and its field is marked omitempty
+func isOmitted(source AstNode) bool {
+	node, ok := unwrapNode(source)
+	if !ok || node.field == nil || !parseFieldOptions(*node.field).omitEmpty {
+		return false
+	} else if node.value != nil {
+		return isEmptyValue(reflect.ValueOf(node.value))
 	}
+
+	return len(node.children) == 0 && (!node.isObject || node.underlyingType.Kind() == reflect.Map)
 }
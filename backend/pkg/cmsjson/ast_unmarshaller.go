@@ -12,7 +12,17 @@ import (
 func UnmarshallAST[T any](c Configuration, source string) (AstNode, error) {
 	base := gjson.Parse(source)
 	underlyingType := reflect.TypeOf(*new(T))
-	return c.parseASTCore(base, "root", underlyingType)
+	root, err := c.parseASTCore(base, "root", underlyingType)
+	if err != nil {
+		return nil, err
+	}
+
+	// finally ensure that every value satisfies the rules declared by its field
+	if err := validateNode(root).asError(); err != nil {
+		return nil, err
+	}
+
+	return root, nil
 }
 
 // visitStructAST constructs an AST by visiting a struct type
@@ -21,16 +31,21 @@ func (c Configuration) visitStructAST(node gjson.Result, key string, underlyingT
 	childrenArray := []*jsonNode{}
 	var rollingErrors error = nil
 
-	for i := 0; i < underlyingType.NumField(); i++ {
-		fieldName := underlyingType.Field(i).Name
-		parsedField, err := c.parseASTCore(node.Get(fieldName), fieldName, underlyingType.Field(i).Type)
+	fields := cachedFields(underlyingType)
+	for i := range fields {
+		field := &fields[i].field
+		element := getField(node, fields[i].options.name)
+		parsedField, err := c.parseASTCore(element, fields[i].options.name, field.Type)
+		if err == nil {
+			parsedField.field = field
+		}
 
 		// we want to maintain a rolling list of errors that ocurred when attempting to parse the incoming reflect type
 		if err != nil {
 			if rollingErrors != nil {
 				rollingErrors = errors.New("failed to parse incoming structure failure reasons: ")
 			}
-			rollingErrors = fmt.Errorf("%v\n	[failed to parse field %s]: %v", rollingErrors, underlyingType.Field(i).Name, err)
+			rollingErrors = fmt.Errorf("%v\n	[failed to parse field %s]: %v", rollingErrors, field.Name, err)
 		} else {
 			childrenArray = append(childrenArray, parsedField)
 		}
@@ -46,9 +61,12 @@ func (c Configuration) visitStructAST(node gjson.Result, key string, underlyingT
 // visitInterfaceAST visits a gjson.Result under the assumption that its an interface type
 func (c Configuration) visitInterfaceAST(node gjson.Result, key string, underlyingType reflect.Type) (*jsonNode, error) {
 	targetType := node.Get("$type").String()
-	typeRegistration := c.RegisteredTypes[underlyingType]
+	implementation, isRegistered := c.RegisteredTypes[underlyingType][targetType]
+	if !isRegistered {
+		return nil, fmt.Errorf("%q is not registered against %v", targetType, underlyingType)
+	}
 
-	parsedStruct, err := c.visitStructAST(node, key, typeRegistration[targetType])
+	parsedStruct, err := c.visitStructAST(node, key, implementation)
 	if err != nil {
 		return nil, fmt.Errorf("attempted to parse node into type %s but failed with %v", targetType, err)
 	}
@@ -75,32 +93,84 @@ func (c Configuration) visitArrayAST(node gjson.Result, key string, underlyingTy
 	return newJsonArray(key, childrenArray, arrayType), nil
 }
 
-// visitPrimitive visits an AST primitive
+// visitMapAST constructs an AST object from a map type, the object's fields are the map's keys in sorted order
+func (c Configuration) visitMapAST(node gjson.Result, key string, underlyingType reflect.Type) (*jsonNode, error) {
+	switch {
+	case underlyingType.Key().Kind() != reflect.String:
+		return nil, fmt.Errorf("%v does not have string keys", underlyingType)
+	case !isNull(node) && !node.IsObject():
+		return nil, fmt.Errorf("failed to parse map for %v as it is not an object", node)
+	}
+
+	entries := node.Map()
+	childrenArray := []*jsonNode{}
+	for _, entryKey := range sortedKeys(entries) {
+		childAst, err := c.parseASTCore(entries[entryKey], entryKey, underlyingType.Elem())
+		if err != nil {
+			return nil, err
+		}
+
+		childrenArray = append(childrenArray, childAst)
+	}
+
+	return newJsonObject(key, childrenArray, underlyingType), nil
+}
+
+// visitCustomAST visits a value that unmarshalls itself, within the AST the value is a primitive
+func (c Configuration) visitCustomAST(node gjson.Result, key string, underlyingType reflect.Type) (*jsonNode, error) {
+	parsed, err := parseCustomValue(node, underlyingType)
+	if err != nil {
+		return nil, err
+	}
+
+	return newJsonPrimitive(key, parsed.Interface(), underlyingType), nil
+}
+
+// visitPrimitive visits an AST primitive, since ASTs have no nulls null (or missing) primitives are read as the
+// zero value of their type
 func (c Configuration) visitPrimitiveAST(node gjson.Result, key string, underlyingType reflect.Type) (*jsonNode, error) {
-	if !verifyPrimitive(node, underlyingType) {
+	if isNull(node) {
+		return newJsonPrimitive(key, zeroPrimitive(underlyingType), underlyingType), nil
+	} else if !verifyPrimitive(node, underlyingType) {
 		return nil, fmt.Errorf("failed to parse primitive for %v as it is not an instance of %v", node, underlyingType)
 	}
 
 	return newJsonPrimitive(key, node.Value(), underlyingType), nil
 }
 
+// zeroPrimitive is the value of a primitive that is missing, like the numbers parsed from JSON numbers are float64s
+func zeroPrimitive(underlyingType reflect.Type) interface{} {
+	if isNumber(underlyingType) {
+		return float64(0)
+	}
+
+	return reflect.Zero(underlyingType).Interface()
+}
+
+// isNumber determines if a type is one of the numeric kinds
+func isNumber(underlyingType reflect.Type) bool {
+	switch underlyingType.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+
+	return false
+}
+
 // verifyPrimitive verifies an incoming AST primitive and returns false of its not valid
 func verifyPrimitive(node gjson.Result, underlyingType reflect.Type) bool {
-	verifiers := map[gjson.Type]func() bool{
-		gjson.True:  func() bool { return underlyingType.Kind() == reflect.Bool },
-		gjson.False: func() bool { return underlyingType.Kind() == reflect.Bool },
-
-		gjson.String: func() bool { return underlyingType.Kind() == reflect.String },
-		gjson.Null:   func() bool { return underlyingType.AssignableTo(reflect.TypeOf(nil)) },
-		gjson.Number: func() bool {
-			return underlyingType.Kind() == reflect.Int ||
-				underlyingType.Kind() == reflect.Float32 ||
-				underlyingType.Kind() == reflect.Float64
-		},
+	switch node.Type {
+	case gjson.True, gjson.False:
+		return underlyingType.Kind() == reflect.Bool
+	case gjson.String:
+		return underlyingType.Kind() == reflect.String
+	case gjson.Number:
+		return isNumber(underlyingType)
 	}
 
-	verifier, hasVerifier := verifiers[node.Type]
-	return hasVerifier && verifier()
+	return false
 }
 
 // parseCore is the core method for parsing (really its just a way to reduce code duplication)
@@ -116,6 +186,13 @@ func (c Configuration) parseASTCore(result gjson.Result, key string, primitiveTy
 		return c.visitStructAST(result, key, primitiveType)
 	case _interface:
 		return c.visitInterfaceAST(result, key, primitiveType)
+	case _pointer:
+		// ASTs have no nulls so a pointer is represented by the value it points to
+		return c.parseASTCore(result, key, primitiveType.Elem())
+	case _map:
+		return c.visitMapAST(result, key, primitiveType)
+	case _custom:
+		return c.visitCustomAST(result, key, primitiveType)
 	}
 
 	return nil, fmt.Errorf("failed to parse node %v into %v, unidentified primitive type", result, primitiveType)
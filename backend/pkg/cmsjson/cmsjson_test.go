@@ -1,6 +1,7 @@
 package cmsjson
 
 import (
+	"fmt"
 	"reflect"
 	"testing"
 
@@ -122,3 +123,83 @@ func TestMarshallsProperly(t *testing.T) {
 
 	assert.Equal(config.Marshall(sampleJson), `{"Int": 3,"String": "hello world","Float": 3.200000,"NestedStruct": {"Key": "Key","Val": "Val"},"Interfaces": [{"$type": "NestedStruct", "Key": "Key","Val": "Val"},{"$type": "UnnestedStruct", "RandomInteger": 3}]}`)
 }
+
+func TestSchemaBuildsTypes(t *testing.T) {
+	assert := assert.New(t)
+
+	schema := Schema{
+		Components: map[string]TypeSchema{
+			"table": {Fields: []FieldSchema{
+				{Name: "Align", Type: "string", Enum: []string{"left", "right"}},
+				{Name: "Rows", Type: "array", Items: &FieldSchema{Type: "array", Items: &FieldSchema{Type: "int"}}},
+				{Name: "Caption", Type: "object", Fields: []FieldSchema{{Name: "Text", Type: "string"}}},
+			}},
+		},
+	}
+
+	components, err := schema.Build()
+	if !assert.Nil(err) {
+		return
+	}
+
+	table := components["table"]
+	assert.Equal(reflect.TypeOf([][]int{}), table.Field(1).Type)
+	assert.Equal("left|right", table.Field(0).Tag.Get(enumTag))
+
+	config, err := Configuration{}.Extend(reflect.TypeOf((*DummyInterface)(nil)).Elem(), components)
+	assert.Nil(err)
+
+	document := `{
+		"Int": 3, "String": "hello", "Float": 3.2, "NestedStruct": {"Key": "Key", "Val": "Val"},
+		"Interfaces": [{"$type": "%s", "Align": "%s", "Rows": [[1, 2], [3]], "Caption": {"Text": "hi"}}]
+	}`
+
+	_, err = UnmarshallAST[TestJson](config, fmt.Sprintf(document, "table", "left"))
+	assert.Nil(err)
+
+	_, err = UnmarshallAST[TestJson](config, fmt.Sprintf(document, "table", "middle"))
+	assert.NotNil(err)
+
+	_, err = UnmarshallAST[TestJson](config, fmt.Sprintf(document, "chair", "left"))
+	assert.NotNil(err)
+
+	// names can't be registered twice
+	_, err = config.Extend(reflect.TypeOf((*DummyInterface)(nil)).Elem(), components)
+	assert.NotNil(err)
+}
+
+// Component has methods so the types built from a schema can't implement it
+type Component interface{ Render() string }
+
+type ComponentJson struct {
+	Components []DummyInterface
+}
+
+func TestSchemaTypesCanBeInsertedIntoTheAST(t *testing.T) {
+	assert := assert.New(t)
+
+	schema := Schema{Components: map[string]TypeSchema{
+		"callout": {Fields: []FieldSchema{{Name: "Colour", Type: "string", Enum: []string{"red", "blue"}}}},
+	}}
+
+	components, err := schema.Build()
+	assert.Nil(err)
+
+	// built types can only be registered against interfaces without methods
+	_, err = Configuration{}.Extend(reflect.TypeOf((*Component)(nil)).Elem(), components)
+	assert.NotNil(err)
+
+	config, err := Configuration{}.Extend(reflect.TypeOf((*DummyInterface)(nil)).Elem(), components)
+	assert.Nil(err)
+
+	document, err := UnmarshallAST[ComponentJson](config, `{"Components": []}`)
+	if !assert.Nil(err) {
+		return
+	}
+
+	fields, _ := document.JsonObject()
+	callout := reflect.New(components["callout"]).Elem()
+	callout.Field(0).SetString("blue")
+	assert.Nil(fields[0].InsertArrayElement(0, ASTFromValue(callout.Interface())))
+	assert.Equal(`{"Components": [{"$type": "callout","Colour": "blue"}]}`, config.MarshallAST(document))
+}
@@ -1,6 +1,11 @@
 package cmsjson
 
-import "reflect"
+import (
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+)
 
 // What is this? Well the default go marshaller does not support interface types
 // this is a partial custom implementation that adds the support for interface types
@@ -23,6 +28,26 @@ const (
 	_array
 	_slice
 	_astRequest
+	_pointer
+	_map
+	_custom
+)
+
+// Marshaller is implemented by types that marshall themselves, the returned JSON is written as is
+type Marshaller interface {
+	MarshallCMSJSON() ([]byte, error)
+}
+
+// Unmarshaller is implemented by (pointers to) types that unmarshall themselves, the JSON they are given is the raw
+// JSON of their value
+type Unmarshaller interface {
+	UnmarshallCMSJSON([]byte) error
+}
+
+var (
+	timeType         = reflect.TypeOf(time.Time{})
+	marshallerType   = reflect.TypeOf((*Marshaller)(nil)).Elem()
+	unmarshallerType = reflect.TypeOf((*Unmarshaller)(nil)).Elem()
 )
 
 // resolveType takes a reflection field and determines what "type category" it falls into
@@ -32,6 +57,12 @@ func resolveType(t reflect.Type) typeCategory {
 		return _astRequest
 	}
 
+	// types that (un)marshall themselves are opaque, they are treated as a single value regardless of their kind
+	isCustom := t == timeType || t.Implements(marshallerType) || reflect.PointerTo(t).Implements(unmarshallerType)
+	if isCustom && t.Kind() != reflect.Pointer && t.Kind() != reflect.Interface {
+		return _custom
+	}
+
 	switch t.Kind() {
 	case reflect.Struct:
 		return _struct
@@ -41,7 +72,104 @@ func resolveType(t reflect.Type) typeCategory {
 		return _slice
 	case reflect.Array:
 		return _array
+	case reflect.Pointer:
+		return _pointer
+	case reflect.Map:
+		return _map
 	}
 
 	return _primitive
 }
+
+// fieldOptions are the options a struct field declares with its cmsjson tag, eg: `cmsjson:"name,omitempty"`
+type fieldOptions struct {
+	// name is the key of the field within JSON, it defaults to the field's name
+	name string
+
+	// omitEmpty omits the field when it is marshalled if it holds an empty value (false, 0, "", an empty array or
+	// map or a nil pointer)
+	omitEmpty bool
+
+	// skip is set for unexported fields and fields tagged with `cmsjson:"-"`, they are never (un)marshalled
+	skip bool
+}
+
+// cmsjsonTag is the struct tag fields declare their options with
+const cmsjsonTag = "cmsjson"
+
+// parseFieldOptions parses the options declared by a field's cmsjson tag
+func parseFieldOptions(field reflect.StructField) fieldOptions {
+	tag := field.Tag.Get(cmsjsonTag)
+	if !field.IsExported() || tag == "-" {
+		return fieldOptions{name: field.Name, skip: true}
+	}
+
+	name, flags, _ := strings.Cut(tag, ",")
+	if name == "" {
+		name = field.Name
+	}
+
+	options := fieldOptions{name: name}
+	for flags != "" {
+		var flag string
+		flag, flags, _ = strings.Cut(flags, ",")
+		if flag == "omitempty" {
+			options.omitEmpty = true
+		}
+	}
+
+	return options
+}
+
+// cachedField is a field of a struct that is (un)marshalled alongside the options declared by its tag
+type cachedField struct {
+	field   reflect.StructField
+	options fieldOptions
+}
+
+// fieldCache maps struct types to their cachedFields, looking up a struct's fields through reflection allocates so
+// each type's fields are only looked up once
+var fieldCache sync.Map
+
+// cachedFields fetches the fields of a struct that are (un)marshalled (ie: every field that isn't skipped)
+func cachedFields(t reflect.Type) []cachedField {
+	if fields, isCached := fieldCache.Load(t); isCached {
+		return fields.([]cachedField)
+	}
+
+	fields := []cachedField{}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if options := parseFieldOptions(field); !options.skip {
+			fields = append(fields, cachedField{field: field, options: options})
+		}
+	}
+
+	cached, _ := fieldCache.LoadOrStore(t, fields)
+	return cached.([]cachedField)
+}
+
+// fieldName is the key of a struct field within JSON
+func fieldName(field reflect.StructField) string {
+	return parseFieldOptions(field).name
+}
+
+// isEmptyValue determines if a value is omitted by omitempty
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.String, reflect.Slice, reflect.Array, reflect.Map:
+		return v.Len() == 0
+	case reflect.Bool:
+		return !v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return v.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return v.Float() == 0
+	case reflect.Pointer, reflect.Interface:
+		return v.IsNil()
+	}
+
+	return false
+}
@@ -0,0 +1,78 @@
+package cmsjson
+
+import (
+	"encoding/json"
+	"io"
+	"strings"
+)
+
+// UnmarshallFrom reads a JSON value from a reader and unmarshalls it into dest, the value is read straight into
+// the string that is parsed so unlike Unmarshall it isn't copied
+func UnmarshallFrom[T any](c Configuration, dest interface{}, source io.Reader) error {
+	json, err := readString(source)
+	if err != nil {
+		return err
+	}
+
+	return unmarshallString[T](c, dest, json)
+}
+
+// UnmarshallASTFrom reads a JSON value from a reader and unmarshalls it into an AST
+func UnmarshallASTFrom[T any](c Configuration, source io.Reader) (AstNode, error) {
+	json, err := readString(source)
+	if err != nil {
+		return nil, err
+	}
+
+	return UnmarshallAST[T](c, json)
+}
+
+// readString reads the entirety of a reader into a string
+func readString(source io.Reader) (string, error) {
+	read := strings.Builder{}
+	if _, err := io.Copy(&read, source); err != nil {
+		return "", err
+	}
+
+	return read.String(), nil
+}
+
+// Decoder reads a stream of JSON values (eg: the operations a client sends over a socket) from a reader, each
+// value is read as it is needed
+type Decoder struct {
+	configuration Configuration
+	source        *json.Decoder
+
+	// value is the raw JSON of the most recently read value, its buffer is reused by each read
+	value json.RawMessage
+}
+
+// NewDecoder creates a decoder reading from the given reader
+func (c Configuration) NewDecoder(source io.Reader) *Decoder {
+	return &Decoder{configuration: c, source: json.NewDecoder(source)}
+}
+
+// More determines if there is another value to decode
+func (d *Decoder) More() bool {
+	return d.source.More()
+}
+
+// Decode reads the next value from the decoder and unmarshalls it into dest, io.EOF is returned once the stream
+// has ended
+func Decode[T any](d *Decoder, dest interface{}) error {
+	if err := d.source.Decode(&d.value); err != nil {
+		return err
+	}
+
+	return unmarshallString[T](d.configuration, dest, string(d.value))
+}
+
+// DecodeAST reads the next value from the decoder and unmarshalls it into an AST, io.EOF is returned once the
+// stream has ended
+func DecodeAST[T any](d *Decoder) (AstNode, error) {
+	if err := d.source.Decode(&d.value); err != nil {
+		return nil, err
+	}
+
+	return UnmarshallAST[T](d.configuration, string(d.value))
+}
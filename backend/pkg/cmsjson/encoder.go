@@ -0,0 +1,169 @@
+package cmsjson
+
+import (
+	"bufio"
+	"io"
+	"math"
+	"strconv"
+	"unicode/utf8"
+)
+
+// The encoder is shared by Marshall and MarshallAST, rather than building JSON up from intermediate strings (which
+// allocates a new string at every level of nesting) values are written directly to a single buffered writer
+
+// jsonWriter is the destination of an encoder, both *bufio.Writer and *strings.Builder are jsonWriters
+type jsonWriter interface {
+	io.Writer
+	io.ByteWriter
+	io.StringWriter
+}
+
+// encoder writes JSON to a jsonWriter, write errors are ignored since both *bufio.Writer and *strings.Builder
+// remember them (bufio reports the first error when it is flushed). Values that can't be encoded are remembered
+// the same way, they're written as null so the rest of the value can still be written
+type encoder struct {
+	configuration Configuration
+	w             jsonWriter
+	err           error
+
+	// scratch is used to format numbers without allocating
+	scratch [64]byte
+}
+
+func (c Configuration) newEncoder(w jsonWriter) *encoder {
+	return &encoder{configuration: c, w: w}
+}
+
+// fail records that a value couldn't be encoded (only the first failure is kept) and writes null in its place
+func (e *encoder) fail(err error) {
+	if e.err == nil {
+		e.err = err
+	}
+
+	e.w.WriteString("null")
+}
+
+// flush flushes a buffered writer the encoder has written to, returning the first error encountered
+func (e *encoder) flush(buffered *bufio.Writer) error {
+	if err := buffered.Flush(); err != nil {
+		return err
+	}
+
+	return e.err
+}
+
+// writeKey writes an object key and the separator that follows it
+func (e *encoder) writeKey(key string) {
+	e.writeString(key)
+	e.w.WriteString(": ")
+}
+
+// writeInt writes an integer
+func (e *encoder) writeInt(value int64) {
+	e.w.Write(strconv.AppendInt(e.scratch[:0], value, 10))
+}
+
+// writeUint writes an unsigned integer
+func (e *encoder) writeUint(value uint64) {
+	e.w.Write(strconv.AppendUint(e.scratch[:0], value, 10))
+}
+
+// writeFixedFloat writes a float with 6 decimal places (how struct fields have always been written)
+func (e *encoder) writeFixedFloat(value float64) {
+	e.w.Write(strconv.AppendFloat(e.scratch[:0], value, 'f', 6, 64))
+}
+
+// writeFloat writes a float in its shortest form the same way encoding/json does, values that can't be
+// represented in JSON are written as null
+func (e *encoder) writeFloat(value float64) {
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		e.w.WriteString("null")
+		return
+	}
+
+	format := byte('f')
+	if abs := math.Abs(value); abs != 0 && (abs < 1e-6 || abs >= 1e21) {
+		format = 'e'
+	}
+
+	formatted := strconv.AppendFloat(e.scratch[:0], value, format, -1, 64)
+	if format == 'e' {
+		// clean up e-09 to e-9
+		if n := len(formatted); n >= 4 && formatted[n-4] == 'e' && formatted[n-3] == '-' && formatted[n-2] == '0' {
+			formatted[n-2] = formatted[n-1]
+			formatted = formatted[:n-1]
+		}
+	}
+
+	e.w.Write(formatted)
+}
+
+// writeBool writes a boolean
+func (e *encoder) writeBool(value bool) {
+	if value {
+		e.w.WriteString("true")
+	} else {
+		e.w.WriteString("false")
+	}
+}
+
+// hex is used to write \u escapes
+const hex = "0123456789abcdef"
+
+// writeString writes a quoted string, it is escaped the same way encoding/json escapes strings (including the
+// escaping of HTML characters)
+func (e *encoder) writeString(value string) {
+	e.w.WriteByte('"')
+
+	// unescaped runs of characters are written in a single write
+	start := 0
+	for i := 0; i < len(value); {
+		if b := value[i]; b < utf8.RuneSelf {
+			if b >= 0x20 && b != '"' && b != '\\' && b != '<' && b != '>' && b != '&' {
+				i++
+				continue
+			}
+
+			e.w.WriteString(value[start:i])
+			switch b {
+			case '"', '\\':
+				e.w.WriteByte('\\')
+				e.w.WriteByte(b)
+			case '\n':
+				e.w.WriteString(`\n`)
+			case '\r':
+				e.w.WriteString(`\r`)
+			case '\t':
+				e.w.WriteString(`\t`)
+			default:
+				e.w.WriteString(`\u00`)
+				e.w.WriteByte(hex[b>>4])
+				e.w.WriteByte(hex[b&0xF])
+			}
+
+			i++
+			start = i
+			continue
+		}
+
+		character, size := utf8.DecodeRuneInString(value[i:])
+		switch {
+		case character == utf8.RuneError && size == 1:
+			e.w.WriteString(value[start:i])
+			e.w.WriteString("\ufffd")
+		case character == '\u2028' || character == '\u2029':
+			e.w.WriteString(value[start:i])
+			e.w.WriteString(`\u202`)
+			e.w.WriteByte(hex[character&0xF])
+		default:
+			i += size
+			continue
+		}
+
+		i += size
+		start = i
+	}
+
+	e.w.WriteString(value[start:])
+	e.w.WriteByte('"')
+}
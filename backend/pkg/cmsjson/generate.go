@@ -0,0 +1,386 @@
+package cmsjson
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// The generators within this file describe the JSON a configuration accepts so that clients (eg: the Next.js
+// frontend) don't have to maintain their own copy of the types. Both generators start from a set of root types and
+// walk every type reachable from them:
+//   - named structs become definitions, unnamed structs (eg: inline objects declared by a Schema) are inlined
+//   - registered interfaces become unions of their implementations discriminated by $type, unregistered
+//     interfaces accept anything
+//   - registered types carry their $type since it is always written when they are marshalled
+//   - enum tags and validate rules become the equivalent JSON Schema keywords
+//   - fields marked omitempty and pointers are optional, pointers may also be null
+
+// jsonSchemaDialect is the JSON Schema version the generated schemas conform to
+const jsonSchemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+// JSONSchema generates a JSON Schema describing the roots, each root is referenced by the schema's anyOf and
+// every named type is a definition within $defs
+func (c Configuration) JSONSchema(roots ...reflect.Type) ([]byte, error) {
+	definitions, err := c.collectDefinitions(roots)
+	if err != nil {
+		return nil, err
+	}
+
+	schemaDefinitions := map[string]interface{}{}
+	for _, name := range sortedKeys(definitions) {
+		schemaDefinitions[name] = c.jsonSchemaDefinition(definitions[name])
+	}
+
+	rootReferences := make([]interface{}, len(roots))
+	for i, root := range roots {
+		rootReferences[i] = c.jsonSchemaOf(root, nil)
+	}
+
+	return json.MarshalIndent(map[string]interface{}{
+		"$schema": jsonSchemaDialect,
+		"anyOf":   rootReferences,
+		"$defs":   schemaDefinitions,
+	}, "", "  ")
+}
+
+// TypeScript generates TypeScript declarations for the roots and every named type reachable from them
+func (c Configuration) TypeScript(roots ...reflect.Type) (string, error) {
+	definitions, err := c.collectDefinitions(roots)
+	if err != nil {
+		return "", err
+	}
+
+	declarations := strings.Builder{}
+	declarations.WriteString("// Code generated by cmsjson from the backend's datamodel. DO NOT EDIT.\n")
+	for _, name := range sortedKeys(definitions) {
+		definition := definitions[name]
+		declarations.WriteString("\n")
+
+		if definition.Kind() == reflect.Interface {
+			declarations.WriteString(fmt.Sprintf("export type %s = %s;\n", name, c.typeScriptUnion(definition)))
+		} else {
+			declarations.WriteString(fmt.Sprintf("export interface %s %s\n", name, c.typeScriptStruct(definition, "")))
+		}
+	}
+
+	return declarations.String(), nil
+}
+
+// definitionName is the name a type is declared under, unnamed structs are only declared if they are registered
+func (c Configuration) definitionName(t reflect.Type) (string, bool) {
+	if t.Name() != "" {
+		return t.Name(), true
+	} else if registeredName, isRegistered := c.registeredName(t); isRegistered {
+		return strings.ToUpper(registeredName[:1]) + registeredName[1:], true
+	}
+
+	return "", false
+}
+
+// collectDefinitions finds every type requiring a definition that is reachable from the roots
+func (c Configuration) collectDefinitions(roots []reflect.Type) (map[string]reflect.Type, error) {
+	definitions := map[string]reflect.Type{}
+
+	var visit func(t reflect.Type) error
+	visit = func(t reflect.Type) error {
+		if resolveType(t) == _custom {
+			return nil
+		}
+
+		switch t.Kind() {
+		case reflect.Pointer, reflect.Slice, reflect.Array, reflect.Map:
+			return visit(t.Elem())
+		case reflect.Struct, reflect.Interface:
+		default:
+			return nil
+		}
+
+		_, isRegistered := c.RegisteredTypes[t]
+		if name, isNamed := c.definitionName(t); isNamed && (t.Kind() == reflect.Struct || isRegistered) {
+			if existing, isDefined := definitions[name]; isDefined {
+				if existing != t {
+					return fmt.Errorf("%v and %v would both be declared as %s", existing, t, name)
+				}
+
+				return nil
+			}
+
+			definitions[name] = t
+		}
+
+		if t.Kind() == reflect.Interface {
+			for _, name := range sortedKeys(c.RegisteredTypes[t]) {
+				if err := visit(c.RegisteredTypes[t][name]); err != nil {
+					return err
+				}
+			}
+
+			return nil
+		}
+
+		for i := 0; i < t.NumField(); i++ {
+			if field := t.Field(i); !parseFieldOptions(field).skip {
+				if err := visit(field.Type); err != nil {
+					return err
+				}
+			}
+		}
+
+		return nil
+	}
+
+	for _, root := range roots {
+		if err := visit(root); err != nil {
+			return nil, err
+		}
+	}
+
+	return definitions, nil
+}
+
+// jsonSchemaDefinition is the schema of a definition
+func (c Configuration) jsonSchemaDefinition(t reflect.Type) map[string]interface{} {
+	if t.Kind() == reflect.Interface {
+		implementations := []interface{}{}
+		for _, name := range sortedKeys(c.RegisteredTypes[t]) {
+			implementations = append(implementations, c.jsonSchemaOf(c.RegisteredTypes[t][name], nil))
+		}
+
+		return map[string]interface{}{"oneOf": implementations, "discriminator": map[string]string{"propertyName": "$type"}}
+	}
+
+	return c.jsonSchemaStruct(t)
+}
+
+// jsonSchemaStruct is the schema of the fields of a struct
+func (c Configuration) jsonSchemaStruct(t reflect.Type) map[string]interface{} {
+	properties := map[string]interface{}{}
+	required := []string{}
+	if registeredName, isRegistered := c.registeredName(t); isRegistered {
+		properties["$type"] = map[string]interface{}{"const": registeredName}
+		required = append(required, "$type")
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		options := parseFieldOptions(field)
+		if options.skip {
+			continue
+		}
+
+		properties[options.name] = c.jsonSchemaOf(field.Type, &field)
+		if !isOptional(field) {
+			required = append(required, options.name)
+		}
+	}
+
+	return map[string]interface{}{
+		"type":                 "object",
+		"properties":           properties,
+		"required":             required,
+		"additionalProperties": false,
+	}
+}
+
+// jsonSchemaOf is the schema of a value of the given type, field is the struct field holding the value (if any)
+func (c Configuration) jsonSchemaOf(t reflect.Type, field *reflect.StructField) map[string]interface{} {
+	schema := map[string]interface{}{}
+	if t == timeType {
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	} else if resolveType(t) == _custom {
+		// values that marshall themselves could be anything
+		return schema
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		return map[string]interface{}{"anyOf": []interface{}{c.jsonSchemaOf(t.Elem(), field), map[string]string{"type": "null"}}}
+	case reflect.Map:
+		schema["type"] = "object"
+		schema["additionalProperties"] = c.jsonSchemaOf(t.Elem(), nil)
+	case reflect.String:
+		schema["type"] = "string"
+	case reflect.Bool:
+		schema["type"] = "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		schema["type"] = "integer"
+	case reflect.Float32, reflect.Float64:
+		schema["type"] = "number"
+	case reflect.Slice, reflect.Array:
+		schema["type"] = "array"
+		schema["items"] = c.jsonSchemaOf(t.Elem(), nil)
+		if t.Kind() == reflect.Array {
+			schema["minItems"], schema["maxItems"] = t.Len(), t.Len()
+		}
+	case reflect.Struct, reflect.Interface:
+		if name, isNamed := c.definitionName(t); isNamed && (t.Kind() == reflect.Struct || c.RegisteredTypes[t] != nil) {
+			schema["$ref"] = "#/$defs/" + name
+		} else if t.Kind() == reflect.Struct {
+			schema = c.jsonSchemaStruct(t)
+		}
+	}
+
+	if field != nil {
+		addJSONSchemaRules(schema, t, *field)
+	}
+
+	return schema
+}
+
+// addJSONSchemaRules adds the JSON Schema equivalent of a field's enum tag and validation rules to its schema
+func addJSONSchemaRules(schema map[string]interface{}, t reflect.Type, field reflect.StructField) {
+	if allowed, ok := field.Tag.Lookup(enumTag); ok {
+		schema["enum"] = strings.Split(allowed, "|")
+	}
+
+	rules, err := parseRules(field)
+	if err != nil {
+		return
+	}
+
+	isArray := t.Kind() == reflect.Slice || t.Kind() == reflect.Array || t.Kind() == reflect.Map
+	switch {
+	case rules.required && isArray:
+		schema["minItems"] = 1
+	case rules.required:
+		schema["minLength"] = 1
+	}
+
+	switch {
+	case rules.maxLength != nil && isArray:
+		schema["maxItems"] = *rules.maxLength
+	case rules.maxLength != nil:
+		schema["maxLength"] = *rules.maxLength
+	}
+
+	if rules.min != nil {
+		schema["minimum"] = *rules.min
+	}
+	if rules.max != nil {
+		schema["maximum"] = *rules.max
+	}
+	if rules.url {
+		schema["format"] = "uri"
+	}
+}
+
+// typeScriptStruct declares the fields of a struct as a TypeScript object type, indent is the indentation of the
+// declaration the struct is within
+func (c Configuration) typeScriptStruct(t reflect.Type, indent string) string {
+	declaration := strings.Builder{}
+	declaration.WriteString("{\n")
+	if registeredName, isRegistered := c.registeredName(t); isRegistered {
+		declaration.WriteString(fmt.Sprintf("%s  $type: %s;\n", indent, strconv.Quote(registeredName)))
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		options := parseFieldOptions(field)
+		if options.skip {
+			continue
+		}
+
+		key := typeScriptKey(options.name)
+		if isOptional(field) {
+			key += "?"
+		}
+
+		declaration.WriteString(fmt.Sprintf("%s  %s: %s;\n", indent, key, c.typeScriptOf(field.Type, &field, indent+"  ")))
+	}
+
+	declaration.WriteString(indent + "}")
+	return declaration.String()
+}
+
+// typeScriptUnion declares a registered interface as the union of its implementations
+func (c Configuration) typeScriptUnion(t reflect.Type) string {
+	members := []string{}
+	for _, name := range sortedKeys(c.RegisteredTypes[t]) {
+		member, _ := c.definitionName(c.RegisteredTypes[t][name])
+		members = append(members, member)
+	}
+
+	if len(members) == 0 {
+		return "never"
+	}
+
+	return strings.Join(members, " | ")
+}
+
+// typeScriptOf is the TypeScript type of a value of the given type, field is the struct field holding the value
+// (if any) and indent is the indentation of the declaration the type is within
+func (c Configuration) typeScriptOf(t reflect.Type, field *reflect.StructField, indent string) string {
+	if t == timeType {
+		return "string"
+	} else if resolveType(t) == _custom {
+		return "unknown"
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		return c.typeScriptOf(t.Elem(), field, indent) + " | null"
+	case reflect.Map:
+		return fmt.Sprintf("Record<string, %s>", c.typeScriptOf(t.Elem(), nil, indent))
+	case reflect.String:
+		if field == nil {
+			return "string"
+		} else if allowed, ok := field.Tag.Lookup(enumTag); ok {
+			values := strings.Split(allowed, "|")
+			for i, value := range values {
+				values[i] = strconv.Quote(value)
+			}
+
+			return strings.Join(values, " | ")
+		}
+
+		return "string"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Slice, reflect.Array:
+		element := c.typeScriptOf(t.Elem(), nil, indent)
+		if strings.Contains(element, " | ") {
+			element = "(" + element + ")"
+		}
+
+		return element + "[]"
+	case reflect.Struct:
+		if name, isNamed := c.definitionName(t); isNamed {
+			return name
+		}
+
+		return c.typeScriptStruct(t, indent)
+	case reflect.Interface:
+		if name, isNamed := c.definitionName(t); isNamed && c.RegisteredTypes[t] != nil {
+			return name
+		}
+	}
+
+	return "unknown"
+}
+
+// isOptional determines if a field can be left out of the JSON, fields marked omitempty are only written when
+// they aren't empty and nil pointers may be left out entirely
+func isOptional(field reflect.StructField) bool {
+	return parseFieldOptions(field).omitEmpty || field.Type.Kind() == reflect.Pointer
+}
+
+// typeScriptKey is the key of a property within a TypeScript object type, keys that aren't identifiers are quoted
+func typeScriptKey(name string) string {
+	for i, character := range name {
+		isLetter := character == '_' || character == '$' || unicode.IsLetter(character)
+		if !isLetter && (i == 0 || !unicode.IsDigit(character)) {
+			return strconv.Quote(name)
+		}
+	}
+
+	return name
+}
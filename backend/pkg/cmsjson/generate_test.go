@@ -0,0 +1,96 @@
+package cmsjson
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJSONSchema(t *testing.T) {
+	assert := assert.New(t)
+
+	schema, err := validationConfig.JSONSchema(reflect.TypeOf(ValidatedDocument{}))
+	if !assert.Nil(err) {
+		return
+	}
+
+	assert.JSONEq(`{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"anyOf": [{"$ref": "#/$defs/ValidatedDocument"}],
+		"$defs": {
+			"ValidatedDocument": {
+				"type": "object",
+				"properties": {
+					"Title": {"type": "string", "minLength": 1, "maxLength": 5},
+					"Blocks": {"type": "array", "items": {"$ref": "#/$defs/ValidatedInterface"}},
+					"Tags": {"type": "array", "items": {"type": "string"}, "maxItems": 2}
+				},
+				"required": ["Title", "Blocks", "Tags"],
+				"additionalProperties": false
+			},
+			"ValidatedInterface": {
+				"oneOf": [{"$ref": "#/$defs/ValidatedLink"}],
+				"discriminator": {"propertyName": "$type"}
+			},
+			"ValidatedLink": {
+				"type": "object",
+				"properties": {
+					"$type": {"const": "link"},
+					"Href": {"type": "string", "minLength": 1},
+					"Embed": {"type": "string", "format": "uri"},
+					"Align": {"type": "string", "enum": ["left", "right"]},
+					"Level": {"type": "integer", "minimum": 1, "maximum": 6}
+				},
+				"required": ["$type", "Href", "Embed", "Align", "Level"],
+				"additionalProperties": false
+			}
+		}
+	}`, string(schema))
+}
+
+func TestTypeScript(t *testing.T) {
+	assert := assert.New(t)
+
+	declarations, err := validationConfig.TypeScript(reflect.TypeOf(ValidatedDocument{}))
+	if !assert.Nil(err) {
+		return
+	}
+
+	assert.Equal(`// Code generated by cmsjson from the backend's datamodel. DO NOT EDIT.
+
+export interface ValidatedDocument {
+  Title: string;
+  Blocks: ValidatedInterface[];
+  Tags: string[];
+}
+
+export type ValidatedInterface = ValidatedLink;
+
+export interface ValidatedLink {
+  $type: "link";
+  Href: string;
+  Embed: string;
+  Align: "left" | "right";
+  Level: number;
+}
+`, declarations)
+}
+
+func TestGenerationRejectsCollidingNames(t *testing.T) {
+	assert := assert.New(t)
+
+	colliding := Configuration{
+		RegisteredTypes: map[reflect.Type]map[string]reflect.Type{
+			reflect.TypeOf((*ValidatedInterface)(nil)).Elem(): {
+				"link":          reflect.TypeOf(ValidatedLink{}),
+				"validatedLink": reflect.TypeOf(struct{ Href string }{}),
+			},
+		},
+	}
+
+	_, err := colliding.JSONSchema(reflect.TypeOf(ValidatedDocument{}))
+	assert.NotNil(err)
+	_, err = colliding.TypeScript(reflect.TypeOf(ValidatedDocument{}))
+	assert.NotNil(err)
+}
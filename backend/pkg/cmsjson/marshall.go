@@ -1,142 +1,200 @@
 package cmsjson
 
 import (
+	"bufio"
+	"encoding/json"
 	"fmt"
+	"io"
 	"reflect"
+	"sort"
 	"strings"
+	"time"
 )
 
-// Marshall can potentially be a rather expensive operation
-// why? Strings in go are immutable, if we recursively modify
-// a string each invocation ends up allocation a new string on the heap
-// resulting in GC overhead :P
+// Marshall marshalls a value into a string, see MarshallTo. Values that can't be marshalled are written as null,
+// use MarshallTo to find out about them
 func (c Configuration) Marshall(source interface{}) string {
-	return c.marshallStruct(reflect.ValueOf(source))
+	marshalled := strings.Builder{}
+	c.newEncoder(&marshalled).encodeValue(reflect.ValueOf(source))
+	return marshalled.String()
 }
 
-// marshallStruct takes a struct and marshalls it into a string
-func (c Configuration) marshallStruct(v reflect.Value) string {
-	// iterate over each field
-	marshalledString := strings.Builder{}
-	marshalledString.WriteByte('{')
-
-	for i := 0; i < v.NumField(); i++ {
-		field := v.Field(i)
-		underlyingType := resolveType(field.Type())
-		isNestedField := underlyingType != _primitive
-
-		if !isNestedField {
-			marshalledString.WriteString(c.marshallKeyValuePair(field, v.Type().Field(i)))
-		} else {
-			marshalledString.WriteString(
-				fmt.Sprintf(
-					`"%s": %s`,
-					v.Type().Field(i).Name,
-					c.marshallCore(field),
-				),
-			)
+// MarshallTo marshalls a value into a writer, the JSON is written through a single buffer as it is generated so
+// marshalling a large document doesn't allocate a string for every value within it. An error is returned if
+// writing fails or a value can't be marshalled (eg: a Marshaller fails)
+func (c Configuration) MarshallTo(w io.Writer, source interface{}) error {
+	buffered := bufio.NewWriter(w)
+	e := c.newEncoder(buffered)
+	e.encodeValue(reflect.ValueOf(source))
+	return e.flush(buffered)
+}
+
+// encodeStruct writes a struct as an object
+func (e *encoder) encodeStruct(v reflect.Value) {
+	e.w.WriteByte('{')
+	e.encodeFields(v, true)
+	e.w.WriteByte('}')
+}
+
+// encodeFields writes each field of a struct as a key value pair, skipped fields and empty fields marked with
+// omitempty are left out, isFirst indicates that nothing has been written to the object yet
+func (e *encoder) encodeFields(v reflect.Value, isFirst bool) {
+	for _, field := range cachedFields(v.Type()) {
+		value := v.Field(field.field.Index[0])
+		if field.options.omitEmpty && isEmptyValue(value) {
+			continue
 		}
 
-		// if this isnt the last field we require a comma deliminater
-		if i != v.NumField()-1 {
-			marshalledString.WriteByte(',')
+		if !isFirst {
+			e.w.WriteByte(',')
 		}
-	}
+		isFirst = false
 
-	marshalledString.WriteByte('}')
-	return marshalledString.String()
+		e.writeKey(field.options.name)
+		e.encodeValue(value)
+	}
 }
 
-// marshallArray takes an array and marshalls it into a string
-func (c Configuration) marshallArray(source reflect.Value) string {
-	marshalledString := strings.Builder{}
-	marshalledString.WriteByte('[')
-
+// encodeArray writes an array or slice
+func (e *encoder) encodeArray(source reflect.Value) {
+	e.w.WriteByte('[')
 	for i := 0; i < source.Len(); i++ {
-		elementValue := source.Index(i)
-		marshalledString.WriteString(c.marshallCore(elementValue))
+		if i != 0 {
+			e.w.WriteByte(',')
+		}
 
-		// if this isnt the last field we require a comma delimiter
-		if i != source.Len()-1 {
-			marshalledString.WriteByte(',')
+		e.encodeValue(source.Index(i))
+	}
+	e.w.WriteByte(']')
+}
+
+// encodeInterface resolves the type held by an interface and writes it as an object annotated with the type's
+// registered name
+func (e *encoder) encodeInterface(source reflect.Value) {
+	if source.IsNil() {
+		e.w.WriteString("null")
+		return
+	}
+
+	typeName := ""
+	for name, implementation := range e.configuration.RegisteredTypes[source.Type()] {
+		if implementation == source.Elem().Type() {
+			typeName = name
 		}
 	}
 
-	marshalledString.WriteByte(']')
-	return marshalledString.String()
+	e.w.WriteString(`{"$type": `)
+	e.writeString(typeName)
+	if len(cachedFields(source.Elem().Type())) > 0 {
+		e.w.WriteString(", ")
+	}
+
+	e.encodeFields(source.Elem(), true)
+	e.w.WriteByte('}')
 }
 
-// marshallInterface takes an interface, resolves the types and marshalls it into a
-// string
-func (c Configuration) marshallInterface(source reflect.Value) string {
-	typeMappings := c.RegisteredTypes[source.Type()]
-	implementingType := source.Elem().Type()
-	var typeName string = ""
-
-	// just locate the required name for this struct
-	for key, v := range typeMappings {
-		if v == implementingType {
-			typeName = key
-		}
+// encodePointer writes the value a pointer points to, nil pointers are null
+func (e *encoder) encodePointer(source reflect.Value) {
+	if source.IsNil() {
+		e.w.WriteString("null")
+		return
 	}
 
-	// this is some hacky string manipulation, basically chop the opening brace
-	// so we can insert a type annotation in
-	generatedJson := c.marshallStruct(source.Elem())
-	generatedJson = generatedJson[1:]
+	e.encodeValue(source.Elem())
+}
+
+// encodeMap writes a map with string keys as an object, the keys are sorted so the output is deterministic
+func (e *encoder) encodeMap(source reflect.Value) {
+	if source.IsNil() {
+		e.w.WriteString("null")
+		return
+	}
+
+	keys := make([]string, 0, source.Len())
+	for _, key := range source.MapKeys() {
+		keys = append(keys, key.String())
+	}
+	sort.Strings(keys)
+
+	e.w.WriteByte('{')
+	for i, key := range keys {
+		if i != 0 {
+			e.w.WriteByte(',')
+		}
 
-	return fmt.Sprintf(`{"$type": "%s", %s`, typeName, generatedJson)
+		e.writeKey(key)
+		e.encodeValue(source.MapIndex(reflect.ValueOf(key).Convert(source.Type().Key())))
+	}
+	e.w.WriteByte('}')
 }
 
-// Helper type constants just to make conversion a little cleaner
-var (
-	toFloat  = func(v reflect.Value) float64 { return v.Convert(reflect.TypeOf(float64(0.3))).Float() }
-	toInt    = func(v reflect.Value) int64 { return v.Convert(reflect.TypeOf(int64(0))).Int() }
-	toString = func(v reflect.Value) string { return v.Convert(reflect.TypeOf("string")).String() }
-)
+// encodeCustom writes a value that marshalls itself
+func (e *encoder) encodeCustom(source reflect.Value) {
+	if marshalled, err := marshallCustom(source); err != nil {
+		e.fail(err)
+	} else {
+		e.w.Write(marshalled)
+	}
+}
 
-// marshallKeyValuePair parses a key value pair within a struct
-func (c Configuration) marshallKeyValuePair(field reflect.Value, structEntry reflect.StructField) string {
-	switch field.Type().Kind() {
-	case reflect.Int:
-		return fmt.Sprintf(`"%s": %d`, structEntry.Name, toInt(field))
-	case reflect.Float32, reflect.Float64:
-		return fmt.Sprintf(`"%s": %f`, structEntry.Name, toFloat(field))
-	case reflect.String:
-		return fmt.Sprintf(`"%s": "%s"`, structEntry.Name, toString(field))
+// marshallCustom marshalls a value that marshalls itself, times are written in RFC 3339 format
+func marshallCustom(source reflect.Value) ([]byte, error) {
+	var marshalled []byte
+	var err error
+	switch {
+	case source.Type() == timeType:
+		marshalled, err = json.Marshal(source.Interface().(time.Time).Format(time.RFC3339Nano))
+	case source.Type().Implements(marshallerType):
+		marshalled, err = source.Interface().(Marshaller).MarshallCMSJSON()
 	default:
-		return ""
+		marshalled, err = json.Marshal(source.Interface())
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshall %v: %w", source.Type(), err)
+	} else if !json.Valid(marshalled) {
+		return nil, fmt.Errorf("%v marshalled itself into invalid JSON", source.Type())
 	}
+
+	return marshalled, nil
 }
 
-// parsePrimitive just parses a lone primitive
-// parseKeyValuePair parses a key value pair within a struct
-func (c Configuration) marshallPrimitive(field reflect.Value) string {
-	switch field.Type().Kind() {
-	case reflect.Int:
-		return fmt.Sprintf(`%d`, toInt(field))
+// encodePrimitive writes a lone primitive
+func (e *encoder) encodePrimitive(field reflect.Value) {
+	switch field.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		e.writeInt(field.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		e.writeUint(field.Uint())
 	case reflect.Float32, reflect.Float64:
-		return fmt.Sprintf(`%f`, toFloat(field))
+		e.writeFixedFloat(field.Float())
 	case reflect.String:
-		return fmt.Sprintf(`"%s"`, toString(field))
+		e.writeString(field.String())
+	case reflect.Bool:
+		e.writeBool(field.Bool())
 	default:
-		return ""
+		e.fail(fmt.Errorf("%v values can't be marshalled", field.Type()))
 	}
 }
 
-// marshallCore marshalls the value within source
-func (c Configuration) marshallCore(source reflect.Value) string {
-	underlyingType := resolveType(source.Type())
-	switch underlyingType {
+// encodeValue writes the value within source
+func (e *encoder) encodeValue(source reflect.Value) {
+	switch resolveType(source.Type()) {
 	case _primitive:
-		return c.marshallPrimitive(source)
+		e.encodePrimitive(source)
 	case _array, _slice:
-		return c.marshallArray(source)
+		e.encodeArray(source)
 	case _struct:
-		return c.marshallStruct(source)
+		e.encodeStruct(source)
 	case _interface:
-		return c.marshallInterface(source)
+		e.encodeInterface(source)
+	case _pointer:
+		e.encodePointer(source)
+	case _map:
+		e.encodeMap(source)
+	case _custom:
+		e.encodeCustom(source)
 	default:
-		return ""
+		e.fail(fmt.Errorf("%v values can't be marshalled", source.Type()))
 	}
 }
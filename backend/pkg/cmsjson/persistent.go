@@ -0,0 +1,155 @@
+package cmsjson
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// Persistent ASTs are immutable, rather than modifying a document in place an update produces a new root that
+// shares every subtree the update didn't touch with the previous root. Only the nodes along the path to the update
+// are copied so keeping every revision of a document around is cheap and readers holding an old revision never
+// observe a partially applied update (or need to take a lock to read it)
+
+// ErrImmutable is returned by the in place update functions of a persistent AST
+var ErrImmutable = errors.New("persistent ASTs can't be modified in place, use Update instead")
+
+type (
+	// PersistentAST is an immutable AstNode, the in place update functions (UpdateOrAddPrimitiveElement, etc.) all
+	// return ErrImmutable. The children of a PersistentAST are also PersistentASTs
+	PersistentAST interface {
+		AstNode
+
+		// Update produces a new AST by copying the nodes along the path and applying update to the copy of the
+		// node at path[:len(path)-1] (the parent of the node being updated) along with the last index of the path,
+		// if the path points at an existing node that node is copied as well. The copies are ordinary mutable
+		// AstNodes that only exist for the duration of update, if update fails the original AST is unaffected
+		Update(path []int, update func(parent AstNode, index int) error) (PersistentAST, error)
+	}
+
+	// persistentNode is the implementation of PersistentAST, it wraps a jsonNode that is never modified
+	persistentNode struct {
+		node *jsonNode
+	}
+)
+
+// NewPersistentAST creates a persistent AST from a regular AST, the source is copied so later updates to it don't
+// affect the persistent AST
+func NewPersistentAST(source AstNode) (PersistentAST, error) {
+	switch node := source.(type) {
+	case persistentNode:
+		return node, nil
+	case *jsonNode:
+		return persistentNode{node: node.clone()}, nil
+	}
+
+	return nil, errors.New("incompatible AstNode implementation")
+}
+
+// GetKey returns the key of the underlying node
+func (p persistentNode) GetKey() string { return p.node.key }
+
+// JsonPrimitive returns the primitive value held by the node, see AstNode
+func (p persistentNode) JsonPrimitive() (interface{}, reflect.Type) { return p.node.JsonPrimitive() }
+
+// JsonObject returns the fields of the node if it is an object, see AstNode
+func (p persistentNode) JsonObject() ([]AstNode, reflect.Type) {
+	p.node.validateNode()
+	if p.node.children != nil && p.node.isObject {
+		return persistentArrFromNodes(p.node.children), p.node.underlyingType
+	}
+
+	return nil, nil
+}
+
+// JsonArray returns the elements of the node if it is an array, see AstNode
+func (p persistentNode) JsonArray() ([]AstNode, reflect.Type) {
+	p.node.validateNode()
+	if p.node.children != nil && !p.node.isObject {
+		return persistentArrFromNodes(p.node.children), p.node.underlyingType
+	}
+
+	return nil, nil
+}
+
+func (p persistentNode) UpdateOrAddPrimitiveElement(AstNode) error   { return ErrImmutable }
+func (p persistentNode) UpdateOrAddArrayElement(int, AstNode) error  { return ErrImmutable }
+func (p persistentNode) UpdateOrAddObjectElement(int, AstNode) error { return ErrImmutable }
+func (p persistentNode) InsertArrayElement(int, AstNode) error       { return ErrImmutable }
+func (p persistentNode) RemoveArrayElement(int) error                { return ErrImmutable }
+
+// Update produces a new AST with an update applied, see PersistentAST
+func (p persistentNode) Update(path []int, update func(parent AstNode, index int) error) (PersistentAST, error) {
+	if len(path) == 0 {
+		return nil, errors.New("cannot update an empty path")
+	}
+
+	root := p.node.shallowCopy()
+	parent := root
+	for depth, index := range path[:len(path)-1] {
+		if index < 0 || index >= len(parent.children) {
+			return nil, fmt.Errorf("path index %d at depth %d is out of bounds", index, depth)
+		}
+
+		parent.children[index] = parent.children[index].shallowCopy()
+		parent = parent.children[index]
+	}
+
+	// the target may not exist yet (eg. when appending to an array) and primitives don't have children
+	index := path[len(path)-1]
+	if index >= 0 && index < len(parent.children) {
+		parent.children[index] = parent.children[index].shallowCopy()
+	}
+
+	if err := update(parent, index); err != nil {
+		return nil, err
+	}
+
+	return persistentNode{node: root}, nil
+}
+
+// shallowCopy copies a node and the slice holding its children but not the children themselves
+func (node *jsonNode) shallowCopy() *jsonNode {
+	copied := *node
+	if node.children != nil {
+		copied.children = make([]*jsonNode, len(node.children))
+		copy(copied.children, node.children)
+	}
+
+	return &copied
+}
+
+// unwrapNode returns the jsonNode underlying an AstNode, the result must not be modified if the AstNode is persistent
+func unwrapNode(source AstNode) (*jsonNode, bool) {
+	switch node := source.(type) {
+	case *jsonNode:
+		return node, true
+	case persistentNode:
+		return node.node, true
+	}
+
+	return nil, false
+}
+
+// mutableNode returns a jsonNode that can be placed within a mutable AST, persistent nodes are copied since other
+// revisions of their AST share them
+func mutableNode(source AstNode) (*jsonNode, bool) {
+	switch node := source.(type) {
+	case *jsonNode:
+		return node, true
+	case persistentNode:
+		return node.node.clone(), true
+	}
+
+	return nil, false
+}
+
+// persistentArrFromNodes wraps an array of jsonNodes as persistent AstNodes
+func persistentArrFromNodes(nodes []*jsonNode) []AstNode {
+	astNodes := make([]AstNode, len(nodes))
+	for i, node := range nodes {
+		astNodes[i] = persistentNode{node: node}
+	}
+
+	return astNodes
+}
@@ -0,0 +1,139 @@
+package cmsjson
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const persistentDocument = `{
+	"Title": "hello",
+	"Blocks": [{"$type": "link", "Href": "https://example.com", "Embed": "", "Align": "left", "Level": 1}],
+	"Tags": ["a"]
+}`
+
+func newPersistentDocument(t *testing.T) PersistentAST {
+	source, err := UnmarshallAST[ValidatedDocument](validationConfig, persistentDocument)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	document, err := NewPersistentAST(source)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return document
+}
+
+func TestPersistentUpdatesLeaveOldRevisions(t *testing.T) {
+	assert := assert.New(t)
+	document := newPersistentDocument(t)
+
+	// Title
+	updated, err := document.Update([]int{0}, func(parent AstNode, index int) error {
+		fields, _ := parent.JsonObject()
+		return fields[index].UpdateOrAddPrimitiveElement(ASTFromValue("bye"))
+	})
+	if !assert.Nil(err) {
+		return
+	}
+
+	assert.JSONEq(persistentDocument, validationConfig.MarshallAST(document))
+	assert.JSONEq(`{
+		"Title": "bye",
+		"Blocks": [{"$type": "link", "Href": "https://example.com", "Embed": "", "Align": "left", "Level": 1}],
+		"Tags": ["a"]
+	}`, validationConfig.MarshallAST(updated))
+
+	// only the nodes along the path are copied, everything else is shared between the revisions
+	original, _ := unwrapNode(document)
+	revised, _ := unwrapNode(updated)
+	assert.NotSame(original, revised)
+	assert.NotSame(original.children[0], revised.children[0])
+	assert.Same(original.children[1], revised.children[1])
+	assert.Same(original.children[2], revised.children[2])
+}
+
+func TestPersistentArrayInsertions(t *testing.T) {
+	assert := assert.New(t)
+	document := newPersistentDocument(t)
+
+	// Tags/0
+	updated, err := document.Update([]int{2, 0}, func(parent AstNode, index int) error {
+		return parent.InsertArrayElement(index, ASTFromValue("z"))
+	})
+	if !assert.Nil(err) {
+		return
+	}
+
+	assert.JSONEq(`["a"]`, validationConfig.MarshallAST(fieldAt(document, 2)))
+	assert.JSONEq(`["z", "a"]`, validationConfig.MarshallAST(fieldAt(updated, 2)))
+
+	// the shifted element was re-keyed in the new revision but not the old one
+	oldTags, _ := fieldAt(document, 2).JsonArray()
+	newTags, _ := fieldAt(updated, 2).JsonArray()
+	assert.Equal("0", oldTags[0].GetKey())
+	assert.Equal("1", newTags[1].GetKey())
+
+	// Tags/2 is one past the end of the array, Tags has a maxLength of 2 so the update fails
+	_, err = updated.Update([]int{2, 2}, func(parent AstNode, index int) error {
+		return parent.InsertArrayElement(index, ASTFromValue("y"))
+	})
+	if assert.IsType(ValidationErrors{}, err) {
+		assert.Equal([]string{"must have at most 2 elements"}, err.(ValidationErrors).Messages())
+	}
+	assert.JSONEq(`["z", "a"]`, validationConfig.MarshallAST(fieldAt(updated, 2)))
+}
+
+func TestPersistentASTsAreImmutable(t *testing.T) {
+	assert := assert.New(t)
+
+	source, _ := UnmarshallAST[ValidatedDocument](validationConfig, persistentDocument)
+	document, err := NewPersistentAST(source)
+	if !assert.Nil(err) {
+		return
+	}
+
+	assert.Equal(ErrImmutable, fieldAt(document, 0).UpdateOrAddPrimitiveElement(ASTFromValue("bye")))
+	assert.Equal(ErrImmutable, fieldAt(document, 2).InsertArrayElement(0, ASTFromValue("z")))
+	assert.Equal(ErrImmutable, fieldAt(document, 2).RemoveArrayElement(0))
+
+	// the persistent AST is a copy of its source
+	assert.Nil(fieldAt(source, 0).UpdateOrAddPrimitiveElement(ASTFromValue("bye")))
+	assert.JSONEq(persistentDocument, validationConfig.MarshallAST(document))
+
+	// clones of a persistent AST can be modified without affecting it
+	cloned, err := CloneAST(document)
+	if assert.Nil(err) {
+		assert.Nil(fieldAt(cloned, 2).InsertArrayElement(0, ASTFromValue("z")))
+		assert.JSONEq(persistentDocument, validationConfig.MarshallAST(document))
+	}
+
+	// as can ASTs that persistent nodes are inserted into
+	assert.Nil(fieldAt(source, 2).UpdateOrAddArrayElement(0, fieldAt(fieldAt(document, 2), 0)))
+	assert.Nil(fieldAt(source, 2).InsertArrayElement(0, ASTFromValue("z")))
+	assert.JSONEq(persistentDocument, validationConfig.MarshallAST(document))
+	assert.Nil(ValidateAST(document))
+}
+
+func TestPersistentUpdatesRejectInvalidPaths(t *testing.T) {
+	assert := assert.New(t)
+	document := newPersistentDocument(t)
+
+	noop := func(AstNode, int) error { return nil }
+	_, err := document.Update([]int{}, noop)
+	assert.NotNil(err)
+	_, err = document.Update([]int{5, 0}, noop)
+	assert.NotNil(err)
+}
+
+// fieldAt fetches a child of an object or array node
+func fieldAt(node AstNode, index int) AstNode {
+	if fields, _ := node.JsonObject(); fields != nil {
+		return fields[index]
+	}
+
+	elements, _ := node.JsonArray()
+	return elements[index]
+}
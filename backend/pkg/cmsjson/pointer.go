@@ -0,0 +1,138 @@
+package cmsjson
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ASTs are navigated by paths of child indexes (eg: [2, 0, 1]) while external tools address values with JSON
+// pointers (RFC 6901, eg: /Content/0/ImageSource), the functions within this file convert between the two
+
+// the replacers are shared since building a replacer is far more expensive than using one
+var (
+	pointerEscaper   = strings.NewReplacer("~", "~0", "/", "~1")
+	pointerUnescaper = strings.NewReplacer("~1", "/", "~0", "~")
+)
+
+// EscapePointer escapes a key so that it can be used as a JSON pointer reference token (RFC 6901 section 3)
+func EscapePointer(key string) string {
+	return pointerEscaper.Replace(key)
+}
+
+// ParsePointer splits a JSON pointer into its (unescaped) reference tokens, the empty pointer references the root
+func ParsePointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return []string{}, nil
+	} else if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("JSON pointer %q must start with a /", pointer)
+	}
+
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = pointerUnescaper.Replace(token)
+	}
+
+	return tokens, nil
+}
+
+// FormatPointer joins reference tokens into a JSON pointer
+func FormatPointer(tokens []string) string {
+	pointer := strings.Builder{}
+	for _, token := range tokens {
+		pointer.WriteString("/" + EscapePointer(token))
+	}
+
+	return pointer.String()
+}
+
+// ResolvePointer converts a JSON pointer into the path of child indexes leading to the value it references
+func ResolvePointer(root AstNode, pointer string) ([]int, error) {
+	tokens, err := ParsePointer(pointer)
+	if err != nil {
+		return nil, err
+	}
+
+	path := make([]int, 0, len(tokens))
+	curr := root
+	for depth, token := range tokens {
+		index, err := childIndex(curr, token)
+		if err != nil {
+			return nil, fmt.Errorf("%s does not exist: %w", FormatPointer(tokens[:depth+1]), err)
+		}
+
+		path = append(path, index)
+		curr, _ = Child(curr, index)
+	}
+
+	return path, nil
+}
+
+// PathToPointer converts a path of child indexes into the JSON pointer of the value it leads to
+func PathToPointer(root AstNode, path []int) (string, error) {
+	tokens := make([]string, 0, len(path))
+	curr := root
+	for _, index := range path {
+		child, err := Child(curr, index)
+		if err != nil {
+			return "", fmt.Errorf("%s has no child %d: %w", FormatPointer(tokens), index, err)
+		}
+
+		if elements, _ := curr.JsonArray(); elements != nil {
+			tokens = append(tokens, strconv.Itoa(index))
+		} else {
+			tokens = append(tokens, child.GetKey())
+		}
+
+		curr = child
+	}
+
+	return FormatPointer(tokens), nil
+}
+
+// Child fetches the child of an object or array node by its index
+func Child(node AstNode, index int) (AstNode, error) {
+	children, _ := node.JsonObject()
+	if children == nil {
+		children, _ = node.JsonArray()
+	}
+
+	switch {
+	case children == nil:
+		return nil, errors.New("primitives have no children")
+	case index < 0 || index >= len(children):
+		return nil, fmt.Errorf("index %d is out of bounds", index)
+	}
+
+	return children[index], nil
+}
+
+// childIndex finds the index of the child referenced by a pointer's reference token
+func childIndex(node AstNode, token string) (int, error) {
+	if fields, _ := node.JsonObject(); fields != nil {
+		for i, field := range fields {
+			if field.GetKey() == token {
+				return i, nil
+			}
+		}
+
+		return 0, fmt.Errorf("no field named %q", token)
+	}
+
+	elements, _ := node.JsonArray()
+	if elements == nil {
+		return 0, errors.New("primitives have no children")
+	}
+
+	// array indexes are written in decimal without leading zeros (RFC 6901 section 4)
+	index, err := strconv.Atoi(token)
+	switch {
+	case err != nil || index < 0 || (len(token) > 1 && token[0] == '0'):
+		return 0, fmt.Errorf("%q is not an array index", token)
+	case index >= len(elements):
+		return 0, fmt.Errorf("index %d is out of bounds", index)
+	}
+
+	return index, nil
+}
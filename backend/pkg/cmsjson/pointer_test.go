@@ -0,0 +1,70 @@
+package cmsjson
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPointersResolveToPaths(t *testing.T) {
+	assert := assert.New(t)
+
+	document, err := UnmarshallAST[ValidatedDocument](validationConfig, `{
+		"Title": "hello",
+		"Blocks": [
+			{"$type": "link", "Href": "https://example.com", "Embed": "", "Align": "left", "Level": 1},
+			{"$type": "link", "Href": "/about", "Embed": "", "Align": "right", "Level": 2}
+		],
+		"Tags": ["a/b", "c"]
+	}`)
+	if !assert.Nil(err) {
+		return
+	}
+
+	for pointer, expected := range map[string][]int{
+		"":               {},
+		"/Title":         {0},
+		"/Blocks/1/Href": {1, 1, 0},
+		"/Tags/1":        {2, 1},
+	} {
+		path, err := ResolvePointer(document, pointer)
+		if assert.Nil(err, pointer) {
+			assert.Equal(expected, path, pointer)
+			roundTrip, _ := PathToPointer(document, path)
+			assert.Equal(pointer, roundTrip)
+		}
+	}
+
+	for _, pointer := range []string{"Title", "/Subtitle", "/Blocks/2", "/Blocks/01", "/Blocks/-", "/Title/0"} {
+		_, err := ResolvePointer(document, pointer)
+		assert.NotNil(err, pointer)
+	}
+
+	_, err = PathToPointer(document, []int{1, 5})
+	assert.NotNil(err)
+
+	tokens, _ := ParsePointer("/a~1b/c~0d")
+	assert.Equal([]string{"a/b", "c~d"}, tokens)
+	assert.Equal("/a~1b/c~0d", FormatPointer(tokens))
+}
+
+func TestCloneASTIsIndependent(t *testing.T) {
+	assert := assert.New(t)
+
+	document, err := UnmarshallAST[ValidatedDocument](validationConfig, `{"Title": "hello", "Blocks": [], "Tags": ["a"]}`)
+	if !assert.Nil(err) {
+		return
+	}
+
+	clone, err := CloneAST(document)
+	if !assert.Nil(err) {
+		return
+	}
+
+	fields, _ := clone.JsonObject()
+	assert.Nil(fields[0].UpdateOrAddPrimitiveElement(ASTFromValue("bye")))
+	assert.Nil(fields[2].UpdateOrAddArrayElement(1, ASTFromValue("b")))
+
+	assert.JSONEq(`{"Title": "hello", "Blocks": [], "Tags": ["a"]}`, validationConfig.MarshallAST(document))
+	assert.JSONEq(`{"Title": "bye", "Blocks": [], "Tags": ["a", "b"]}`, validationConfig.MarshallAST(clone))
+}
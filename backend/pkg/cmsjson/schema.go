@@ -0,0 +1,211 @@
+package cmsjson
+
+import (
+	"errors"
+	"fmt"
+	"go/token"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Schemas let types be declared at runtime (eg: from a configuration file) rather than as Go structs, the declared
+// types are constructed with reflect.StructOf so the rest of the library treats them exactly like regular structs.
+// Note that types built from a schema have no methods, so they can only be registered against interfaces without
+// methods or used through the AST (see UnmarshallAST)
+type (
+	// Schema is a set of declared types, Components are the types that can be registered against an interface
+	// (keyed by their $type name) while Types are named helper types that fields can refer to (eg: Text)
+	Schema struct {
+		Types      map[string]TypeSchema `json:"types"`
+		Components map[string]TypeSchema `json:"components"`
+	}
+
+	// TypeSchema declares the fields of a struct type
+	TypeSchema struct {
+		Fields []FieldSchema `json:"fields"`
+	}
+
+	// FieldSchema declares a single field, Type is either a primitive (string, int, float, bool), "array"
+	// (with the element type declared by Items), "object" (with its fields declared by Fields) or the name of
+	// one of the schema's Types. Validate holds the field's validation rules in the same format as the
+	// validate struct tag (see validateTag)
+	FieldSchema struct {
+		Name     string        `json:"name"`
+		Type     string        `json:"type"`
+		Enum     []string      `json:"enum,omitempty"`
+		Validate string        `json:"validate,omitempty"`
+		Items    *FieldSchema  `json:"items,omitempty"`
+		Fields   []FieldSchema `json:"fields,omitempty"`
+	}
+)
+
+// enumTag is the struct tag restricting a string field to a set of values, the values are separated by |
+// eg: `enum:"left|right|center"`
+const enumTag = "enum"
+
+var schemaPrimitives = map[string]reflect.Type{
+	"string": reflect.TypeOf(""),
+	"int":    reflect.TypeOf(0),
+	"float":  reflect.TypeOf(float64(0)),
+	"bool":   reflect.TypeOf(false),
+}
+
+// Build constructs the component types declared by the schema, the returned map can be registered against an
+// interface type with Configuration.Extend
+func (s Schema) Build() (map[string]reflect.Type, error) {
+	builder := schemaBuilder{schema: s, built: map[string]reflect.Type{}, building: map[string]bool{}}
+	components := map[string]reflect.Type{}
+
+	for _, name := range sortedKeys(s.Components) {
+		component, err := builder.buildStruct(name, s.Components[name])
+		if err != nil {
+			return nil, fmt.Errorf("component %s: %w", name, err)
+		}
+
+		components[name] = component
+	}
+
+	return components, nil
+}
+
+// schemaBuilder builds the types declared by a schema, named types are only built once
+type schemaBuilder struct {
+	schema   Schema
+	built    map[string]reflect.Type
+	building map[string]bool
+}
+
+func (b schemaBuilder) buildStruct(name string, declaration TypeSchema) (reflect.Type, error) {
+	fields := []reflect.StructField{}
+	seen := map[string]bool{}
+
+	for _, field := range declaration.Fields {
+		if !token.IsExported(field.Name) || !token.IsIdentifier(field.Name) {
+			return nil, fmt.Errorf("field name %q must be an identifier starting with an upper case letter", field.Name)
+		} else if seen[field.Name] {
+			return nil, fmt.Errorf("field %s is declared more than once", field.Name)
+		}
+
+		fieldType, err := b.buildField(field)
+		if err != nil {
+			return nil, fmt.Errorf("field %s: %w", field.Name, err)
+		}
+
+		tags := []string{}
+		if len(field.Enum) > 0 {
+			tags = append(tags, fmt.Sprintf(`%s:"%s"`, enumTag, strings.Join(field.Enum, "|")))
+		}
+
+		if field.Validate != "" {
+			tags = append(tags, fmt.Sprintf(`%s:"%s"`, validateTag, field.Validate))
+		}
+
+		structField := reflect.StructField{Name: field.Name, Type: fieldType, Tag: reflect.StructTag(strings.Join(tags, " "))}
+		if _, err := parseRules(structField); err != nil {
+			return nil, err
+		}
+
+		seen[field.Name] = true
+		fields = append(fields, structField)
+	}
+
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("%s must declare at least one field", name)
+	}
+
+	return reflect.StructOf(fields), nil
+}
+
+func (b schemaBuilder) buildField(field FieldSchema) (reflect.Type, error) {
+	if len(field.Enum) > 0 && field.Type != "string" {
+		return nil, errors.New("only string fields can be enums")
+	}
+
+	if primitive, ok := schemaPrimitives[field.Type]; ok {
+		return primitive, nil
+	}
+
+	switch field.Type {
+	case "array":
+		if field.Items == nil {
+			return nil, errors.New("arrays must declare the type of their items")
+		}
+
+		items, err := b.buildField(*field.Items)
+		if err != nil {
+			return nil, err
+		}
+
+		return reflect.SliceOf(items), nil
+
+	case "object":
+		return b.buildStruct(field.Name, TypeSchema{Fields: field.Fields})
+	}
+
+	return b.buildNamed(field.Type)
+}
+
+// buildNamed builds one of the schema's named types, types can't (directly or indirectly) contain themselves
+func (b schemaBuilder) buildNamed(name string) (reflect.Type, error) {
+	if built, ok := b.built[name]; ok {
+		return built, nil
+	}
+
+	declaration, ok := b.schema.Types[name]
+	switch {
+	case !ok:
+		return nil, fmt.Errorf("unknown type %q", name)
+	case b.building[name]:
+		return nil, fmt.Errorf("type %s contains itself", name)
+	}
+
+	b.building[name] = true
+	built, err := b.buildStruct(name, declaration)
+	delete(b.building, name)
+	if err != nil {
+		return nil, fmt.Errorf("type %s: %w", name, err)
+	}
+
+	b.built[name] = built
+	return built, nil
+}
+
+// Extend returns a copy of the configuration with additional implementations registered against an interface type,
+// an implementation can't replace one that is already registered under the same name and must implement the interface
+// (types built from a Schema have no methods, so they can only be registered against interfaces without any)
+func (c Configuration) Extend(interfaceType reflect.Type, implementations map[string]reflect.Type) (Configuration, error) {
+	extended := Configuration{RegisteredTypes: map[reflect.Type]map[string]reflect.Type{}}
+	for registeredInterface, registrations := range c.RegisteredTypes {
+		extended.RegisteredTypes[registeredInterface] = map[string]reflect.Type{}
+		for name, implementation := range registrations {
+			extended.RegisteredTypes[registeredInterface][name] = implementation
+		}
+	}
+
+	if extended.RegisteredTypes[interfaceType] == nil {
+		extended.RegisteredTypes[interfaceType] = map[string]reflect.Type{}
+	}
+
+	for name, implementation := range implementations {
+		if _, ok := extended.RegisteredTypes[interfaceType][name]; ok {
+			return Configuration{}, fmt.Errorf("%s is already registered against %v", name, interfaceType)
+		} else if !implementation.Implements(interfaceType) {
+			return Configuration{}, fmt.Errorf("%s does not implement %v", name, interfaceType)
+		}
+
+		extended.RegisteredTypes[interfaceType][name] = implementation
+	}
+
+	return extended, nil
+}
+
+func sortedKeys[V any](source map[string]V) []string {
+	keys := make([]string, 0, len(source))
+	for key := range source {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,109 @@
+package cmsjson
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMarshallToMatchesMarshall(t *testing.T) {
+	assert := assert.New(t)
+
+	var document ValidatedDocument
+	source := `{"Title": "hi", "Blocks": [{"$type": "link", "Href": "/a", "Embed": "", "Align": "left", "Level": 1}], "Tags": []}`
+	if !assert.Nil(Unmarshall[ValidatedDocument](validationConfig, &document, []byte(source))) {
+		return
+	}
+
+	written := bytes.Buffer{}
+	assert.Nil(validationConfig.MarshallTo(&written, document))
+	assert.Equal(validationConfig.Marshall(document), written.String())
+	assert.JSONEq(source, written.String())
+
+	ast, _ := UnmarshallAST[ValidatedDocument](validationConfig, source)
+	written.Reset()
+	assert.Nil(validationConfig.MarshallASTTo(&written, ast))
+	assert.Equal(validationConfig.MarshallAST(ast), written.String())
+	assert.JSONEq(source, written.String())
+}
+
+func TestEncoderMatchesEncodingJSON(t *testing.T) {
+	assert := assert.New(t)
+
+	for _, value := range []interface{}{
+		"plain", `"quoted" \ back\slash`, "new\nline\ttab\r", "\x00\x1f", "<script>&</script>",
+		"ünïcödé 🎉", "\u2028\u2029", "invalid \xff utf8",
+		float64(0), 3.2, -1.5, 1e-7, 1e21, 123456789.125, 0.000001, true, false,
+	} {
+		expected, _ := json.Marshal(value)
+		written := strings.Builder{}
+		validationConfig.newEncoder(&written).encodeASTPrimitive(value)
+		assert.Equal(string(expected), written.String(), value)
+	}
+}
+
+func TestDecoderReadsAStream(t *testing.T) {
+	assert := assert.New(t)
+
+	decoder := validationConfig.NewDecoder(strings.NewReader(`
+		{"Title": "one", "Blocks": [], "Tags": []}
+		{"Title": "two", "Blocks": [], "Tags": ["a"]}
+		{"Title": "a long title", "Blocks": [], "Tags": []}
+	`))
+
+	var document ValidatedDocument
+	assert.True(decoder.More())
+	if assert.Nil(Decode[ValidatedDocument](decoder, &document)) {
+		assert.Equal("one", document.Title)
+	}
+
+	ast, err := DecodeAST[ValidatedDocument](decoder)
+	if assert.Nil(err) {
+		assert.JSONEq(`{"Title": "two", "Blocks": [], "Tags": ["a"]}`, validationConfig.MarshallAST(ast))
+	}
+
+	// values are still validated
+	assert.IsType(ValidationErrors{}, Decode[ValidatedDocument](decoder, &document))
+
+	assert.False(decoder.More())
+	assert.Equal(io.EOF, Decode[ValidatedDocument](decoder, &document))
+}
+
+func TestUnmarshallFromAReader(t *testing.T) {
+	assert := assert.New(t)
+
+	var document ValidatedDocument
+	if assert.Nil(UnmarshallFrom[ValidatedDocument](validationConfig, &document, strings.NewReader(`{"Title": "hi", "Blocks": [], "Tags": ["a"]}`))) {
+		assert.Equal(ValidatedDocument{Title: "hi", Blocks: []ValidatedInterface{}, Tags: []string{"a"}}, document)
+	}
+
+	ast, err := UnmarshallASTFrom[ValidatedDocument](validationConfig, strings.NewReader(`{"Title": "hi", "Blocks": [], "Tags": []}`))
+	if assert.Nil(err) {
+		assert.JSONEq(`{"Title": "hi", "Blocks": [], "Tags": []}`, validationConfig.MarshallAST(ast))
+	}
+}
+
+// BrokenColour fails to marshall itself
+type BrokenColour struct{}
+
+func (BrokenColour) MarshallCMSJSON() ([]byte, error) { return nil, errors.New("no colour") }
+
+func TestMarshallToReportsValuesThatCantBeMarshalled(t *testing.T) {
+	assert := assert.New(t)
+
+	for _, value := range []interface{}{
+		struct{ Colour BrokenColour }{},
+		struct{ Phase complex128 }{},
+	} {
+		written := bytes.Buffer{}
+		assert.NotNil(taggedConfig.MarshallTo(&written, value), value)
+
+		// the rest of the value is still written
+		assert.True(json.Valid(written.Bytes()), written.String())
+	}
+}
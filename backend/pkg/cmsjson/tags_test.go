@@ -0,0 +1,163 @@
+package cmsjson
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type TaggedEvent struct {
+	Title    string            `cmsjson:"title" validate:"maxLength=10"`
+	Subtitle string            `cmsjson:"subtitle,omitempty"`
+	Starts   time.Time         `cmsjson:"starts"`
+	Venue    *TaggedVenue      `cmsjson:"venue,omitempty"`
+	Capacity *int              `cmsjson:"capacity"`
+	Labels   map[string]string `cmsjson:"labels,omitempty"`
+	Colour   Colour            `cmsjson:"colour"`
+	Internal string            `cmsjson:"-"`
+}
+
+type TaggedVenue struct {
+	Room string `cmsjson:"room"`
+}
+
+// Colour marshalls itself as a hex string
+type Colour struct {
+	R, G, B uint8
+}
+
+func (c Colour) MarshallCMSJSON() ([]byte, error) {
+	return []byte(fmt.Sprintf(`"#%02x%02x%02x"`, c.R, c.G, c.B)), nil
+}
+
+func (c *Colour) UnmarshallCMSJSON(raw []byte) error {
+	_, err := fmt.Sscanf(string(raw), `"#%02x%02x%02x"`, &c.R, &c.G, &c.B)
+	return err
+}
+
+var taggedConfig = Configuration{}
+
+func TestTaggedFieldsRoundTrip(t *testing.T) {
+	assert := assert.New(t)
+
+	capacity := 30
+	event := TaggedEvent{
+		Title:    "launch",
+		Starts:   time.Date(2026, 10, 19, 18, 0, 0, 0, time.UTC),
+		Venue:    &TaggedVenue{Room: "K17 G01"},
+		Capacity: &capacity,
+		Labels:   map[string]string{"b": "2", "a": "1"},
+		Colour:   Colour{R: 255, G: 128},
+		Internal: "secret",
+	}
+
+	marshalled := taggedConfig.Marshall(event)
+	assert.JSONEq(`{
+		"title": "launch",
+		"starts": "2026-10-19T18:00:00Z",
+		"venue": {"room": "K17 G01"},
+		"capacity": 30,
+		"labels": {"a": "1", "b": "2"},
+		"colour": "#ff8000"
+	}`, marshalled)
+
+	var unmarshalled TaggedEvent
+	if assert.Nil(Unmarshall[TaggedEvent](taggedConfig, &unmarshalled, []byte(marshalled))) {
+		event.Internal = ""
+		assert.Equal(event, unmarshalled)
+	}
+
+	// nil pointers are written as null unless they're omitted
+	assert.JSONEq(`{"title": "", "starts": "0001-01-01T00:00:00Z", "capacity": null, "colour": "#000000"}`,
+		taggedConfig.Marshall(TaggedEvent{}))
+}
+
+func TestMissingAndNullFields(t *testing.T) {
+	assert := assert.New(t)
+	source := `{"title": "launch", "capacity": null, "colour": "#000000"}`
+
+	var event TaggedEvent
+	if assert.Nil(Unmarshall[TaggedEvent](taggedConfig, &event, []byte(source))) {
+		assert.Nil(event.Venue)
+		assert.Nil(event.Capacity)
+		assert.Nil(event.Labels)
+		assert.True(event.Starts.IsZero())
+	}
+
+	// ASTs have no nulls, missing values are read as the zero value of their type
+	document, err := UnmarshallAST[TaggedEvent](taggedConfig, source)
+	if !assert.Nil(err) {
+		return
+	}
+
+	assert.JSONEq(`{"title": "launch", "starts": "0001-01-01T00:00:00Z", "venue": {"room": ""}, "capacity": 0, "colour": "#000000"}`,
+		taggedConfig.MarshallAST(document))
+
+	path, err := ResolvePointer(document, "/venue/room")
+	if assert.Nil(err) {
+		assert.Equal([]int{3, 0}, path)
+	}
+
+	_, err = UnmarshallAST[TaggedEvent](taggedConfig, `{"title": "launch", "starts": "yesterday"}`)
+	assert.NotNil(err)
+}
+
+func TestMapsWithinASTs(t *testing.T) {
+	assert := assert.New(t)
+
+	document, err := UnmarshallAST[TaggedEvent](taggedConfig, `{"title": "launch", "labels": {"b": "2", "a": "1"}, "colour": "#000000"}`)
+	if !assert.Nil(err) {
+		return
+	}
+
+	path, err := ResolvePointer(document, "/labels/b")
+	if assert.Nil(err) {
+		assert.Equal([]int{5, 1}, path)
+	}
+
+	fields, _ := document.JsonObject()
+	labels, _ := fields[5].JsonObject()
+	assert.Nil(labels[1].UpdateOrAddPrimitiveElement(ASTFromValue("3")))
+	assert.Contains(taggedConfig.MarshallAST(document), `"labels": {"a": "1","b": "3"}`)
+}
+
+func TestValidationErrorsUseFieldNames(t *testing.T) {
+	assert := assert.New(t)
+
+	var event TaggedEvent
+	err := Unmarshall[TaggedEvent](taggedConfig, &event, []byte(`{"title": "a very long title", "colour": "#000000"}`))
+	if assert.IsType(ValidationErrors{}, err) {
+		assert.Equal([]string{"/title: must be at most 10 characters long"}, err.(ValidationErrors).Messages())
+	}
+}
+
+func TestGenerationOfTaggedFields(t *testing.T) {
+	assert := assert.New(t)
+
+	declarations, err := taggedConfig.TypeScript(reflect.TypeOf(TaggedEvent{}))
+	if assert.Nil(err) {
+		assert.Contains(declarations, `export interface TaggedEvent {
+  title: string;
+  subtitle?: string;
+  starts: string;
+  venue?: TaggedVenue | null;
+  capacity?: number | null;
+  labels?: Record<string, string>;
+  colour: unknown;
+}`)
+		assert.NotContains(declarations, "Internal")
+		assert.NotContains(declarations, "interface Time")
+	}
+
+	schema, err := taggedConfig.JSONSchema(reflect.TypeOf(TaggedEvent{}))
+	if assert.Nil(err) {
+		assert.Contains(string(schema), `"required": [
+        "title",
+        "starts",
+        "colour"
+      ]`)
+	}
+}
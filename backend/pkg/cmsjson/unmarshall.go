@@ -1,8 +1,12 @@
 package cmsjson
 
 import (
+	"encoding/json"
 	"errors"
+	"fmt"
 	"reflect"
+	"strings"
+	"time"
 
 	"github.com/tidwall/gjson"
 )
@@ -11,19 +15,27 @@ import (
 // is that we use reflection to iterate over the members of dest and match them
 // with the top level members of the parsed json
 func Unmarshall[T any](c Configuration, dest interface{}, json []byte) error {
+	// gjson parses strings, the values it returns are slices of the string so the conversion (and its copy) is
+	// required to stop the parsed values from changing if json is reused
+	return unmarshallString[T](c, dest, string(json))
+}
+
+// unmarshallString unmarshalls some json that has already been converted into a string
+func unmarshallString[T any](c Configuration, dest interface{}, json string) error {
 	if reflect.TypeOf(dest).Kind() != reflect.Pointer {
 		return errors.New("unmarshal expects a pointer type")
 	}
 
 	var instance T
-	base := gjson.Parse(string(json))
+	base := gjson.Parse(json)
 
 	err := c.parseStruct(base, reflect.TypeOf(instance), reflect.ValueOf(dest).Elem())
 	if err != nil {
 		return err
 	}
 
-	return nil
+	// finally ensure that every value satisfies the rules declared by its field
+	return validateValue(nil, reflect.ValueOf(dest).Elem()).asError()
 }
 
 // when users request partial AST un-marshalling they just add this type to their struct field
@@ -32,28 +44,50 @@ var astMarshallRequestType = reflect.TypeOf((*AstNode)(nil)).Elem()
 // the base helper function, this function calls parseCore which recursively evaluates the json
 func (c Configuration) parseStruct(root gjson.Result, underlyingType reflect.Type, dest reflect.Value) error {
 	// Iterate over all fields in the underlyingType struct
-	for i := 0; i < underlyingType.NumField(); i++ {
-		field := underlyingType.Field(i)
-		element := root.Get(field.Name)
-		destField := dest.Field(i)
+	for _, field := range cachedFields(underlyingType) {
+		element := getField(root, field.options.name)
+		destField := dest.Field(field.field.Index[0])
 
 		if destField.Type() == astMarshallRequestType {
-			unmarshalledAst, err := c.parseASTCore(element, field.Name, field.Type)
+			unmarshalledAst, err := c.parseASTCore(element, field.options.name, field.field.Type)
 			if err != nil {
 				return err
 			}
 
 			destField.Set(reflect.ValueOf(unmarshalledAst))
 		} else {
-			err := c.parseCore(element, field.Type, destField)
+			err := c.parseCore(element, field.field.Type, destField)
 			if err != nil {
 				return err
 			}
 		}
+
 	}
 	return nil
 }
 
+// getField fetches the value of an object's field, unlike root.Get the name isn't interpreted as a gjson path
+func getField(root gjson.Result, name string) gjson.Result {
+	if !strings.ContainsAny(name, `\.*?|#@!`) {
+		return root.Get(name)
+	}
+
+	escaped := strings.Builder{}
+	for _, character := range name {
+		if strings.ContainsRune(`\.*?|#@!`, character) {
+			escaped.WriteByte('\\')
+		}
+		escaped.WriteRune(character)
+	}
+
+	return root.Get(escaped.String())
+}
+
+// isNull determines if a JSON value is null or missing entirely
+func isNull(result gjson.Result) bool {
+	return !result.Exists() || result.Type == gjson.Null
+}
+
 // parseArray generates an array using reflection based on reflection and
 // some gjson.Result, it can also optionally create a slice
 func (c Configuration) parseArray(result gjson.Result, underlyingType reflect.Type, dest reflect.Value) error {
@@ -94,10 +128,22 @@ func (c Configuration) parseArray(result gjson.Result, underlyingType reflect.Ty
 // that the interface points to :O, this is done via the type registration within the configuration
 // note: unlike parseStruct the actual output of parseInterface is written to reflect.Value
 func (c Configuration) parseInterface(root gjson.Result, underlyingType reflect.Type, dest reflect.Value) error {
+	if isNull(root) {
+		dest.Set(reflect.Zero(underlyingType))
+		return nil
+	}
+
 	targetType := root.Get("$type").String()
-	typeRegistration := c.RegisteredTypes[underlyingType]
-	alternativeDest := reflect.New(typeRegistration[targetType]).Elem()
-	if err := c.parseStruct(root, typeRegistration[targetType], alternativeDest); err != nil {
+	implementation, isRegistered := c.RegisteredTypes[underlyingType][targetType]
+	switch {
+	case !isRegistered:
+		return fmt.Errorf("%q is not registered against %v", targetType, underlyingType)
+	case !implementation.AssignableTo(underlyingType):
+		return fmt.Errorf("%q does not implement %v (types declared by a schema can only be read as an AST)", targetType, underlyingType)
+	}
+
+	alternativeDest := reflect.New(implementation).Elem()
+	if err := c.parseStruct(root, implementation, alternativeDest); err != nil {
 		return err
 	}
 
@@ -105,6 +151,98 @@ func (c Configuration) parseInterface(root gjson.Result, underlyingType reflect.
 	return nil
 }
 
+// parsePointer parses the value a pointer points to, null (or missing) values are nil pointers
+func (c Configuration) parsePointer(result gjson.Result, underlyingType reflect.Type, dest reflect.Value) error {
+	if isNull(result) {
+		dest.Set(reflect.Zero(underlyingType))
+		return nil
+	}
+
+	pointer := reflect.New(underlyingType.Elem())
+	if err := c.parseCore(result, underlyingType.Elem(), pointer.Elem()); err != nil {
+		return err
+	}
+
+	dest.Set(pointer)
+	return nil
+}
+
+// parseMap parses an object into a map, only maps with string keys are supported
+func (c Configuration) parseMap(result gjson.Result, underlyingType reflect.Type, dest reflect.Value) error {
+	switch {
+	case underlyingType.Key().Kind() != reflect.String:
+		return fmt.Errorf("%v does not have string keys", underlyingType)
+	case isNull(result):
+		dest.Set(reflect.Zero(underlyingType))
+		return nil
+	case !result.IsObject():
+		return fmt.Errorf("%v is not an object", result)
+	}
+
+	parsed := reflect.MakeMap(underlyingType)
+	var err error
+	result.ForEach(func(key, value gjson.Result) bool {
+		element := reflect.New(underlyingType.Elem()).Elem()
+		if err = c.parseCore(value, underlyingType.Elem(), element); err != nil {
+			return false
+		}
+
+		parsed.SetMapIndex(reflect.ValueOf(key.String()).Convert(underlyingType.Key()), element)
+		return true
+	})
+	if err != nil {
+		return err
+	}
+
+	dest.Set(parsed)
+	return nil
+}
+
+// parseCustom parses a value that unmarshalls itself, times are read in RFC 3339 format
+func (c Configuration) parseCustom(result gjson.Result, underlyingType reflect.Type, dest reflect.Value) error {
+	parsed, err := parseCustomValue(result, underlyingType)
+	if err != nil {
+		return err
+	}
+
+	dest.Set(parsed)
+	return nil
+}
+
+// parseCustomValue parses a value that unmarshalls itself into a new value of the given type
+func parseCustomValue(result gjson.Result, underlyingType reflect.Type) (reflect.Value, error) {
+	parsed := reflect.New(underlyingType)
+	switch {
+	case underlyingType == timeType && isNull(result):
+	case underlyingType == timeType:
+		parsedTime, err := time.Parse(time.RFC3339Nano, result.String())
+		if err != nil {
+			return reflect.Value{}, fmt.Errorf("%v is not an RFC 3339 time", result)
+		}
+
+		parsed.Elem().Set(reflect.ValueOf(parsedTime))
+	case parsed.Type().Implements(unmarshallerType):
+		if err := parsed.Interface().(Unmarshaller).UnmarshallCMSJSON([]byte(rawOrNull(result))); err != nil {
+			return reflect.Value{}, err
+		}
+	default:
+		if err := json.Unmarshal([]byte(rawOrNull(result)), parsed.Interface()); err != nil {
+			return reflect.Value{}, err
+		}
+	}
+
+	return parsed.Elem(), nil
+}
+
+// rawOrNull is the raw JSON of a value, missing values are null
+func rawOrNull(result gjson.Result) string {
+	if !result.Exists() {
+		return "null"
+	}
+
+	return result.Raw
+}
+
 // parseCore is the core method for parsing (really its just a way to reduce code duplication)
 func (c Configuration) parseCore(result gjson.Result, primitiveType reflect.Type, dest reflect.Value) error {
 	underlyingType := resolveType(primitiveType)
@@ -118,31 +256,34 @@ func (c Configuration) parseCore(result gjson.Result, primitiveType reflect.Type
 		return c.parseStruct(result, primitiveType, dest)
 	case _interface:
 		return c.parseInterface(result, primitiveType, dest)
+	case _pointer:
+		return c.parsePointer(result, primitiveType, dest)
+	case _map:
+		return c.parseMap(result, primitiveType, dest)
+	case _custom:
+		return c.parseCustom(result, primitiveType, dest)
 	}
 
 	return errors.New("unable to parse input, unrecognised base type")
 }
 
-// parse just parses a gjson result and returns a reflect.Value
+// parsePrimitive parses a gjson result into a primitive, the value is set directly rather than through an
+// interface since converting the value into an interface allocates
 func (c Configuration) parsePrimitive(result gjson.Result, expected reflect.Type, dest reflect.Value) error {
-	var value interface{}
 	switch expected.Kind() {
 	case reflect.String:
-		value = result.String()
-	case reflect.Int:
-		value = result.Int()
+		dest.SetString(result.String())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		dest.SetInt(result.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		dest.SetUint(result.Uint())
 	case reflect.Float32, reflect.Float64:
-		value = result.Float()
+		dest.SetFloat(result.Float())
 	case reflect.Bool:
-		value = result.Bool()
+		dest.SetBool(result.Bool())
 	default:
-		value = nil
+		return errors.New("unrecognised primitive type")
 	}
 
-	if value != nil {
-		dest.Set(reflect.ValueOf(value).Convert(expected))
-		return nil
-	}
-
-	return errors.New("unrecognised primitive type")
+	return nil
 }
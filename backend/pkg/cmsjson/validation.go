@@ -0,0 +1,397 @@
+package cmsjson
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Struct fields can declare rules their values must satisfy with the validate tag, rules are separated by commas
+// eg: `validate:"required,maxLength=2048,schemes=http|https"`. The supported rules are:
+//   - required: strings and arrays can't be empty
+//   - min=N, max=N: numbers must be within the (inclusive) bound
+//   - maxLength=N: strings can have at most N characters, arrays at most N elements
+//   - url: strings must be absolute URLs (ie. they have a scheme and a host)
+//   - schemes=a|b: strings containing a URL scheme (eg: javascript:...) must use one of the listed schemes
+//
+// Along with the enum tag (see enumTag) the rules are enforced when un-marshalling (both into structs and ASTs)
+// and whenever an AST is modified, so a document can never hold an invalid value. Apart from required, rules
+// don't apply to empty strings
+const validateTag = "validate"
+
+// ValidationError describes a single invalid value, Path is the JSON pointer (RFC 6901) of the value
+type ValidationError struct {
+	Path    string
+	Message string
+}
+
+func (e ValidationError) Error() string {
+	if e.Path == "" {
+		return e.Message
+	}
+
+	return fmt.Sprintf("%s: %s", e.Path, e.Message)
+}
+
+// ValidationErrors is every invalid value found, it is only ever returned when non-empty
+type ValidationErrors []ValidationError
+
+func (e ValidationErrors) Error() string {
+	return strings.Join(e.Messages(), "; ")
+}
+
+// Messages formats each of the errors as "path: message"
+func (e ValidationErrors) Messages() []string {
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Error()
+	}
+
+	return messages
+}
+
+// At returns the errors relocated beneath the value pointed at by a JSON pointer
+func (e ValidationErrors) At(pointer string) ValidationErrors {
+	relocated := make(ValidationErrors, len(e))
+	for i, err := range e {
+		relocated[i] = ValidationError{Path: pointer + err.Path, Message: err.Message}
+	}
+
+	return relocated
+}
+
+// asError converts a (possibly empty) set of validation errors into an error
+func (e ValidationErrors) asError() error {
+	if len(e) == 0 {
+		return nil
+	}
+
+	return e
+}
+
+// Validate checks every value within source against the rules declared by the struct fields containing it
+func Validate(source interface{}) error {
+	return validateValue(nil, reflect.ValueOf(source)).asError()
+}
+
+// ValidateField checks a value that is about to be assigned to a struct field against the field's rules
+func ValidateField(field reflect.StructField, value reflect.Value) error {
+	return validateValue(&field, value).asError()
+}
+
+// ValidateAST checks every value within an AST against the rules declared by the struct fields they were parsed from
+func ValidateAST(source AstNode) error {
+	node, ok := unwrapNode(source)
+	if !ok {
+		return errors.New("incompatible AstNode implementation")
+	}
+
+	return validateNode(node).asError()
+}
+
+// validateValue validates a reflected value, field is the struct field holding the value (if any)
+func validateValue(field *reflect.StructField, value reflect.Value) ValidationErrors {
+	validator := validator{}
+	validator.validateValue(field, value)
+	return validator.errors
+}
+
+// validateNode validates a node and all its descendants
+func validateNode(node *jsonNode) ValidationErrors {
+	validator := validator{}
+	validator.validateNode(node)
+	return validator.errors
+}
+
+// validator collects the invalid values within a value or AST, the path to the value being validated is kept as
+// a stack of reference tokens that is only formatted into a JSON pointer when an invalid value is found (most
+// values are valid so building every value's pointer up front would be wasted work)
+type validator struct {
+	path   []pathToken
+	errors ValidationErrors
+}
+
+// pathToken is a reference token within a JSON pointer, either the key of a field or an array index
+type pathToken struct {
+	key     string
+	index   int
+	isIndex bool
+}
+
+// pointer formats the path to the current value as a JSON pointer
+func (v *validator) pointer() string {
+	pointer := strings.Builder{}
+	for _, token := range v.path {
+		pointer.WriteByte('/')
+		if token.isIndex {
+			pointer.WriteString(strconv.Itoa(token.index))
+		} else {
+			pointer.WriteString(EscapePointer(token.key))
+		}
+	}
+
+	return pointer.String()
+}
+
+// check checks the current value against the rules of the field holding it, see checkRules
+func (v *validator) check(field reflect.StructField, value interface{}, length int) {
+	if errors := checkRules("", field, value, length); len(errors) > 0 {
+		pointer := v.pointer()
+		for _, err := range errors {
+			v.errors = append(v.errors, ValidationError{Path: pointer + err.Path, Message: err.Message})
+		}
+	}
+}
+
+func (v *validator) validateValue(field *reflect.StructField, value reflect.Value) {
+	if !value.IsValid() {
+		return
+	} else if value.Kind() == reflect.Pointer {
+		// rules apply to the value a pointer points to, nil pointers are optional values that weren't provided
+		if !value.IsNil() {
+			v.validateValue(field, value.Elem())
+		}
+
+		return
+	}
+
+	// values are only converted into interfaces (which allocates) if they have rules to check
+	if field != nil && hasRules(*field) {
+		switch value.Kind() {
+		case reflect.Slice, reflect.Array, reflect.Map:
+			v.check(*field, nil, value.Len())
+		case reflect.Struct, reflect.Interface:
+		default:
+			v.check(*field, value.Interface(), noLength)
+		}
+	}
+
+	// values that unmarshall themselves are opaque so only the rules of their field apply
+	if resolveType(value.Type()) == _custom {
+		return
+	}
+
+	switch value.Kind() {
+	case reflect.Struct:
+		fields := cachedFields(value.Type())
+		for i := range fields {
+			v.path = append(v.path, pathToken{key: fields[i].options.name})
+			v.validateValue(&fields[i].field, value.Field(fields[i].field.Index[0]))
+			v.path = v.path[:len(v.path)-1]
+		}
+
+	case reflect.Map:
+		keys := value.MapKeys()
+		sort.Slice(keys, func(i, j int) bool { return keys[i].String() < keys[j].String() })
+		for _, key := range keys {
+			v.path = append(v.path, pathToken{key: key.String()})
+			v.validateValue(nil, value.MapIndex(key))
+			v.path = v.path[:len(v.path)-1]
+		}
+
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < value.Len(); i++ {
+			v.path = append(v.path, pathToken{index: i, isIndex: true})
+			v.validateValue(nil, value.Index(i))
+			v.path = v.path[:len(v.path)-1]
+		}
+
+	case reflect.Interface:
+		v.validateValue(nil, value.Elem())
+	}
+}
+
+func (v *validator) validateNode(node *jsonNode) {
+	if node.field != nil && hasRules(*node.field) {
+		switch {
+		case node.value != nil:
+			v.check(*node.field, node.value, noLength)
+		case !node.isObject || node.underlyingType.Kind() == reflect.Map:
+			v.check(*node.field, nil, len(node.children))
+		}
+	}
+
+	for i, child := range node.children {
+		token := pathToken{key: child.key}
+		if !node.isObject {
+			// array elements are identified by their position
+			token = pathToken{index: i, isIndex: true}
+		}
+
+		v.path = append(v.path, token)
+		v.validateNode(child)
+		v.path = v.path[:len(v.path)-1]
+	}
+}
+
+// hasRules determines if a struct field declares any rules (either with the validate or enum tags)
+func hasRules(field reflect.StructField) bool {
+	_, hasValidateTag := field.Tag.Lookup(validateTag)
+	_, hasEnumTag := field.Tag.Lookup(enumTag)
+	return hasValidateTag || hasEnumTag
+}
+
+// noLength is the length provided to checkRules for values that aren't arrays
+const noLength = -1
+
+// checkRules checks a single value against the rules of the struct field holding it, value is nil for arrays
+// (which are checked using their length instead)
+func checkRules(path string, field reflect.StructField, value interface{}, length int) ValidationErrors {
+	rules, err := parseRules(field)
+	if err != nil {
+		return ValidationErrors{{Path: path, Message: err.Error()}}
+	}
+
+	invalid := func(format string, args ...interface{}) ValidationErrors {
+		return ValidationErrors{{Path: path, Message: fmt.Sprintf(format, args...)}}
+	}
+
+	text, isString := value.(string)
+	if length == noLength && isString {
+		length = utf8.RuneCountInString(text)
+	}
+
+	switch {
+	case rules.required && length == 0:
+		return invalid("required field is missing")
+	case length == 0:
+		// nothing else applies to empty values
+		return nil
+	case rules.maxLength != nil && length > *rules.maxLength && isString:
+		return invalid("must be at most %d characters long", *rules.maxLength)
+	case rules.maxLength != nil && length > *rules.maxLength:
+		return invalid("must have at most %d elements", *rules.maxLength)
+	}
+
+	if number, ok := toNumber(value); ok {
+		switch {
+		case rules.min != nil && number < *rules.min:
+			return invalid("must be at least %v", *rules.min)
+		case rules.max != nil && number > *rules.max:
+			return invalid("must be at most %v", *rules.max)
+		}
+	}
+
+	if !isString {
+		return nil
+	}
+
+	if err := checkEnum(field, text); err != nil {
+		return invalid("%v", err)
+	}
+
+	if rules.url || len(rules.schemes) > 0 {
+		parsed, err := url.Parse(text)
+		switch {
+		case err != nil || (rules.url && (parsed.Scheme == "" || parsed.Host == "")):
+			return invalid("must be an absolute URL")
+		case len(rules.schemes) > 0 && parsed.Scheme != "" && !contains(rules.schemes, strings.ToLower(parsed.Scheme)):
+			return invalid("links must use one of the schemes %s but used %q", strings.Join(rules.schemes, ", "), parsed.Scheme)
+		}
+	}
+
+	return nil
+}
+
+// fieldRules are the rules declared by a validate tag
+type fieldRules struct {
+	required  bool
+	url       bool
+	min, max  *float64
+	maxLength *int
+	schemes   []string
+}
+
+// parseRules parses the validate tag of a struct field
+func parseRules(field reflect.StructField) (fieldRules, error) {
+	rules := fieldRules{}
+	tag, ok := field.Tag.Lookup(validateTag)
+	if !ok || tag == "" {
+		return rules, nil
+	}
+
+	for _, rule := range strings.Split(tag, ",") {
+		name, argument, hasArgument := strings.Cut(rule, "=")
+		switch {
+		case name == "required" && !hasArgument:
+			rules.required = true
+		case name == "url" && !hasArgument:
+			rules.url = true
+		case name == "schemes" && argument != "":
+			rules.schemes = strings.Split(strings.ToLower(argument), "|")
+		case name == "maxLength":
+			maxLength, err := strconv.Atoi(argument)
+			if err != nil || maxLength < 0 {
+				return rules, fmt.Errorf("%s has an invalid maxLength rule %q", field.Name, rule)
+			}
+
+			rules.maxLength = &maxLength
+		case name == "min" || name == "max":
+			bound, err := strconv.ParseFloat(argument, 64)
+			if err != nil {
+				return rules, fmt.Errorf("%s has an invalid %s rule %q", field.Name, name, rule)
+			}
+
+			if name == "min" {
+				rules.min = &bound
+			} else {
+				rules.max = &bound
+			}
+		default:
+			return rules, fmt.Errorf("%s has an unknown validation rule %q", field.Name, rule)
+		}
+	}
+
+	return rules, nil
+}
+
+// checkEnum ensures that the value of a struct field restricted by an enum tag is one of the allowed values
+func checkEnum(field reflect.StructField, value string) error {
+	allowed, ok := field.Tag.Lookup(enumTag)
+	if !ok {
+		return nil
+	}
+
+	for remaining := allowed; ; {
+		option, rest, hasMore := strings.Cut(remaining, "|")
+		if option == value {
+			return nil
+		} else if !hasMore {
+			break
+		}
+
+		remaining = rest
+	}
+
+	return fmt.Errorf("must be one of %s but was %q", strings.ReplaceAll(allowed, "|", ", "), value)
+}
+
+// toNumber converts a numeric primitive (numbers within an AST are float64s until they are updated) into a float64
+func toNumber(value interface{}) (float64, bool) {
+	reflected := reflect.ValueOf(value)
+	switch reflected.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(reflected.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(reflected.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return reflected.Float(), true
+	}
+
+	return 0, false
+}
+
+func contains(options []string, value string) bool {
+	for _, option := range options {
+		if option == value {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,118 @@
+package cmsjson
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type ValidatedDocument struct {
+	Title  string `validate:"required,maxLength=5"`
+	Blocks []ValidatedInterface
+	Tags   []string `validate:"maxLength=2"`
+}
+
+type ValidatedInterface interface{}
+
+type ValidatedLink struct {
+	Href  string `validate:"required,schemes=http|https|mailto"`
+	Embed string `validate:"url"`
+	Align string `enum:"left|right"`
+	Level int    `validate:"min=1,max=6"`
+}
+
+var validationConfig = Configuration{
+	RegisteredTypes: map[reflect.Type]map[string]reflect.Type{
+		reflect.TypeOf((*ValidatedInterface)(nil)).Elem(): {
+			"link": reflect.TypeOf(ValidatedLink{}),
+		},
+	},
+}
+
+const invalidDocument = `{
+	"Title": "a long title",
+	"Blocks": [
+		{"$type": "link", "Href": "mailto:jane@example.com", "Embed": "", "Align": "left", "Level": 1},
+		{"$type": "link", "Href": "javascript:alert(1)", "Embed": "www.example.com", "Align": "up", "Level": 7}
+	],
+	"Tags": ["a", "b", "c"]
+}`
+
+var expectedValidationErrors = []string{
+	"/Title: must be at most 5 characters long",
+	`/Blocks/1/Href: links must use one of the schemes http, https, mailto but used "javascript"`,
+	"/Blocks/1/Embed: must be an absolute URL",
+	`/Blocks/1/Align: must be one of left, right but was "up"`,
+	"/Blocks/1/Level: must be at most 6",
+	"/Tags: must have at most 2 elements",
+}
+
+func TestUnmarshallValidates(t *testing.T) {
+	assert := assert.New(t)
+
+	var document ValidatedDocument
+	err := Unmarshall[ValidatedDocument](validationConfig, &document, []byte(invalidDocument))
+	if assert.IsType(ValidationErrors{}, err) {
+		assert.Equal(expectedValidationErrors, err.(ValidationErrors).Messages())
+	}
+
+	_, err = UnmarshallAST[ValidatedDocument](validationConfig, invalidDocument)
+	if assert.IsType(ValidationErrors{}, err) {
+		assert.Equal(expectedValidationErrors, err.(ValidationErrors).Messages())
+	}
+
+	// relative links are fine, only links with a scheme are restricted
+	assert.Nil(Validate(ValidatedLink{Href: "/about", Level: 2}))
+	assert.Equal("/Href: required field is missing", Validate(ValidatedLink{Level: 2}).Error())
+}
+
+func TestUpdatingASTValidates(t *testing.T) {
+	assert := assert.New(t)
+
+	document, err := UnmarshallAST[ValidatedDocument](validationConfig, `{
+		"Title": "hello",
+		"Blocks": [{"$type": "link", "Href": "https://example.com", "Embed": "", "Align": "left", "Level": 1}],
+		"Tags": ["a", "b"]
+	}`)
+	if !assert.Nil(err) {
+		return
+	}
+
+	fields, _ := document.JsonObject()
+	title, tags := fields[0], fields[2]
+
+	err = title.UpdateOrAddPrimitiveElement(ASTFromValue("hello world"))
+	assert.Equal("must be at most 5 characters long", err.Error())
+	value, _ := title.JsonPrimitive()
+	assert.Equal("hello", value)
+
+	assert.NotNil(title.UpdateOrAddPrimitiveElement(ASTFromValue("")))
+	assert.Nil(title.UpdateOrAddPrimitiveElement(ASTFromValue("hi")))
+
+	assert.NotNil(tags.UpdateOrAddArrayElement(2, ASTFromValue("c")))
+	assert.Nil(tags.UpdateOrAddArrayElement(1, ASTFromValue("c")))
+
+	blocks, _ := fields[1].JsonArray()
+	err = blocks[0].UpdateOrAddObjectElement(3, ASTFromValue(ValidatedLink{Href: "https://example.com", Level: 9}))
+	assert.Equal("must be at most 6", err.Error())
+}
+
+func TestSchemaValidationRules(t *testing.T) {
+	assert := assert.New(t)
+
+	schema := Schema{Components: map[string]TypeSchema{
+		"button": {Fields: []FieldSchema{{Name: "ButtonLink", Type: "string", Validate: "required,schemes=https"}}},
+	}}
+
+	components, err := schema.Build()
+	if assert.Nil(err) {
+		field, _ := components["button"].FieldByName("ButtonLink")
+		assert.NotNil(ValidateField(field, reflect.ValueOf("http://example.com")))
+		assert.Nil(ValidateField(field, reflect.ValueOf("https://example.com")))
+	}
+
+	schema.Components["button"].Fields[0].Validate = "required,shiny"
+	_, err = schema.Build()
+	assert.NotNil(err)
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"cms.csesoc.unsw.edu.au/editor/OT/operations"
+	"cms.csesoc.unsw.edu.au/endpoints"
+	"cms.csesoc.unsw.edu.au/environment"
+	"github.com/google/uuid"
+)
+
+const schemaUsage = "usage: schema [json|typescript] [frontend id]"
+
+// runSchemaCommand implements the `schema` subcommand, it prints the JSON Schema (or TypeScript declarations) of
+// the documents a frontend works with, the output is the same as /api/schema:
+//   - schema: the JSON Schema of the built in components
+//   - schema typescript <frontend id>: TypeScript declarations including the components declared by the frontend
+func runSchemaCommand(args []string) error {
+	format := "json"
+	if len(args) > 0 {
+		format, args = args[0], args[1:]
+	}
+
+	if format != "json" && format != "typescript" {
+		return fmt.Errorf("%s\nunknown format %q", schemaUsage, format)
+	}
+
+	frontendID := uuid.Nil
+	if len(args) > 0 {
+		parsed, err := uuid.Parse(args[0])
+		if err != nil {
+			return fmt.Errorf("%s\ninvalid frontend id %q: %w", schemaUsage, args[0], err)
+		}
+
+		frontendID = parsed
+	}
+
+	registry := operations.NewRegistry()
+	if directory := environment.GetComponentSchemaDirectory(); directory != "" {
+		loaded, err := operations.LoadRegistry(directory)
+		if err != nil {
+			return err
+		}
+
+		registry = loaded
+	}
+
+	schema, err := endpoints.GenerateSchema(registry, frontendID, format)
+	if err != nil {
+		return err
+	}
+
+	_, err = os.Stdout.Write(append(schema, '\n'))
+	return err
+}
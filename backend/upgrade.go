@@ -0,0 +1,95 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+
+	"cms.csesoc.unsw.edu.au/database/repositories"
+	"cms.csesoc.unsw.edu.au/editor/OT/datamodel"
+)
+
+const upgradeUsage = "usage: upgrade-documents [--dry-run] [volume directory...]"
+
+// runUpgradeCommand implements the `upgrade-documents` subcommand, it upgrades every document stored within the
+// given volume directories (defaults to the docker volumes) to the current datamodel version:
+//   - upgrade-documents: upgrades and rewrites every outdated document
+//   - upgrade-documents --dry-run: only reports the documents that would be upgraded (and those that would fail)
+func runUpgradeCommand(args []string) error {
+	dryRun := len(args) > 0 && args[0] == "--dry-run"
+	if dryRun {
+		args = args[1:]
+	}
+
+	directories := args
+	if len(directories) == 0 {
+		directories = repositories.VolumePaths()
+	}
+
+	failures := 0
+	for _, directory := range directories {
+		documents, err := os.ReadDir(directory)
+		if err != nil {
+			return fmt.Errorf("%s\nfailed to read %s: %w", upgradeUsage, directory, err)
+		}
+
+		for _, document := range documents {
+			if document.IsDir() {
+				continue
+			}
+
+			path := filepath.Join(directory, document.Name())
+			if err := upgradeDocument(path, dryRun); err != nil {
+				log.Printf("failed to upgrade %s: %v", path, err)
+				failures++
+			}
+		}
+	}
+
+	if failures > 0 {
+		return fmt.Errorf("%d documents could not be upgraded", failures)
+	}
+
+	return nil
+}
+
+// upgradeDocument upgrades a single stored document, documents that are already up to date (or aren't datamodel
+// documents, eg: images) are left alone
+func upgradeDocument(path string, dryRun bool) error {
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	version, isDocument, err := datamodel.Upgrades.Version(contents)
+	if err != nil || !isDocument || version == datamodel.Upgrades.CurrentVersion() {
+		return err
+	} else if version > datamodel.Upgrades.CurrentVersion() {
+		return datamodel.ErrNewerVersion
+	}
+
+	// the upgrade is run even for a dry run so that it reports the documents that can't be upgraded
+	upgraded, changed, err := datamodel.Upgrades.Upgrade(contents)
+	if err != nil {
+		return err
+	} else if !changed {
+		return errors.New("document was not upgraded")
+	} else if dryRun {
+		fmt.Printf("would upgrade %s from version %d to %d\n", path, version, datamodel.Upgrades.CurrentVersion())
+		return nil
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(path, upgraded, info.Mode().Perm()); err != nil {
+		return err
+	}
+
+	fmt.Printf("upgraded %s from version %d to %d\n", path, version, datamodel.Upgrades.CurrentVersion())
+	return nil
+}
@@ -1,6 +0,0 @@
-DROP TABLE IF EXISTS frontend CASCADE;
-DROP TABLE IF EXISTS groups CASCADE;
-DROP TABLE IF EXISTS person CASCADE;
-DROP TABLE IF EXISTS filesystem CASCADE;
-
-DROP TYPE IF EXISTS permissions_enum;
\ No newline at end of file